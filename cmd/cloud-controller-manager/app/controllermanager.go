@@ -56,6 +56,7 @@ import (
 	"sigs.k8s.io/cloud-provider-azure/cmd/cloud-controller-manager/app/dynamic"
 	"sigs.k8s.io/cloud-provider-azure/cmd/cloud-controller-manager/app/options"
 	armmetrics "sigs.k8s.io/cloud-provider-azure/pkg/azclient/metrics"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
 	"sigs.k8s.io/cloud-provider-azure/pkg/log"
 	"sigs.k8s.io/cloud-provider-azure/pkg/provider"
 	"sigs.k8s.io/cloud-provider-azure/pkg/trace"
@@ -463,7 +464,11 @@ func KnownControllers() []string {
 }
 
 // ControllersDisabledByDefault is the controller disabled default when starting cloud-controller managers.
-var ControllersDisabledByDefault = sets.NewString()
+var ControllersDisabledByDefault = sets.NewString(
+	consts.OrphanedResourceGCControllerName,
+	consts.MultipleStandardLoadBalancerConfigurationControllerName,
+	consts.PublicIPPoolControllerName,
+)
 
 // newControllerInitializers is a private map of named controller groups (you can start more than one in an init func)
 // paired to their initFunc.  This allows for structured downstream composition and subdivision.
@@ -474,6 +479,11 @@ func newControllerInitializers() map[string]initFunc {
 	controllers[names.ServiceLBController] = startServiceController
 	controllers[names.NodeRouteController] = startRouteController
 	controllers["node-ipam"] = startNodeIpamController
+	controllers[consts.OrphanedResourceGCControllerName] = startOrphanedResourceGCController
+	controllers[consts.MultipleStandardLoadBalancerConfigurationControllerName] = startMultipleStandardLoadBalancerConfigurationController
+	controllers[consts.PublicIPPoolControllerName] = startPublicIPPoolController
+	controllers[consts.LoadBalancerClassControllerName] = startLoadBalancerClassController
+	controllers[consts.IPGroupControllerName] = startIPGroupController
 	return controllers
 }
 
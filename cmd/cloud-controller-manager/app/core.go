@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/dynamic"
 	cloudprovider "k8s.io/cloud-provider"
 	nodecontroller "k8s.io/cloud-provider/controllers/node"
 	nodelifecyclecontroller "k8s.io/cloud-provider/controllers/nodelifecycle"
@@ -41,6 +42,7 @@ import (
 	nodeipamcontroller "sigs.k8s.io/cloud-provider-azure/pkg/nodeipam"
 	nodeipamconfig "sigs.k8s.io/cloud-provider-azure/pkg/nodeipam/config"
 	"sigs.k8s.io/cloud-provider-azure/pkg/nodeipam/ipam"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider"
 )
 
 func startCloudNodeController(ctx context.Context, controllerContext genericcontrollermanager.ControllerContext, completedConfig *cloudcontrollerconfig.CompletedConfig, cloud cloudprovider.Interface) (http.Handler, bool, error) {
@@ -276,3 +278,83 @@ func processCIDRs(cidrsList string) ([]*net.IPNet, bool, error) {
 
 	return cidrs, dualstack, nil
 }
+
+func startOrphanedResourceGCController(ctx context.Context, _ genericcontrollermanager.ControllerContext, completedConfig *cloudcontrollerconfig.CompletedConfig, cloud cloudprovider.Interface) (http.Handler, bool, error) {
+	az, ok := cloud.(*provider.Cloud)
+	if !ok {
+		klog.Warningf("cloud provider %T is not the Azure cloud provider. Will not collect the orphaned resources.", cloud)
+		return nil, false, nil
+	}
+
+	gc := provider.NewOrphanedResourceGarbageCollector(az, completedConfig.ComponentConfig.KubeCloudShared.ClusterName)
+	go gc.Run(ctx)
+
+	return nil, true, nil
+}
+
+func startMultipleStandardLoadBalancerConfigurationController(ctx context.Context, _ genericcontrollermanager.ControllerContext, completedConfig *cloudcontrollerconfig.CompletedConfig, cloud cloudprovider.Interface) (http.Handler, bool, error) {
+	az, ok := cloud.(*provider.Cloud)
+	if !ok {
+		klog.Warningf("cloud provider %T is not the Azure cloud provider. Will not watch the multiple standard load balancer configurations.", cloud)
+		return nil, false, nil
+	}
+
+	client, err := dynamic.NewForConfig(completedConfig.ClientBuilder.ConfigOrDie(consts.MultipleStandardLoadBalancerConfigurationControllerName))
+	if err != nil {
+		return nil, false, err
+	}
+	c := provider.NewMultipleStandardLoadBalancerConfigurationController(az, client)
+	go c.Run(ctx)
+
+	return nil, true, nil
+}
+
+func startPublicIPPoolController(ctx context.Context, _ genericcontrollermanager.ControllerContext, completedConfig *cloudcontrollerconfig.CompletedConfig, cloud cloudprovider.Interface) (http.Handler, bool, error) {
+	az, ok := cloud.(*provider.Cloud)
+	if !ok {
+		klog.Warningf("cloud provider %T is not the Azure cloud provider. Will not keep the public IP pool.", cloud)
+		return nil, false, nil
+	}
+
+	c := provider.NewPublicIPPoolController(az, completedConfig.ComponentConfig.KubeCloudShared.ClusterName)
+	go c.Run(ctx)
+
+	return nil, true, nil
+}
+
+func startLoadBalancerClassController(ctx context.Context, _ genericcontrollermanager.ControllerContext, completedConfig *cloudcontrollerconfig.CompletedConfig, cloud cloudprovider.Interface) (http.Handler, bool, error) {
+	az, ok := cloud.(*provider.Cloud)
+	if !ok {
+		klog.Warningf("cloud provider %T is not the Azure cloud provider. Will not reconcile the services with load balancer classes.", cloud)
+		return nil, false, nil
+	}
+
+	c := provider.NewLoadBalancerClassController(
+		az,
+		completedConfig.ClientBuilder.ClientOrDie(consts.LoadBalancerClassControllerName),
+		completedConfig.SharedInformers.Core().V1().Services(),
+		completedConfig.SharedInformers.Core().V1().Nodes(),
+		completedConfig.ComponentConfig.KubeCloudShared.ClusterName,
+	)
+	go c.Run(ctx, int(completedConfig.ComponentConfig.ServiceController.ConcurrentServiceSyncs))
+
+	return nil, true, nil
+}
+
+func startIPGroupController(ctx context.Context, _ genericcontrollermanager.ControllerContext, completedConfig *cloudcontrollerconfig.CompletedConfig, cloud cloudprovider.Interface) (http.Handler, bool, error) {
+	az, ok := cloud.(*provider.Cloud)
+	if !ok {
+		klog.Warningf("cloud provider %T is not the Azure cloud provider. Will not reconcile the services whose allowed IP Groups change.", cloud)
+		return nil, false, nil
+	}
+
+	c := provider.NewIPGroupController(
+		az,
+		completedConfig.SharedInformers.Core().V1().Services(),
+		completedConfig.SharedInformers.Core().V1().Nodes(),
+		completedConfig.ComponentConfig.KubeCloudShared.ClusterName,
+	)
+	go c.Run(ctx)
+
+	return nil, true, nil
+}
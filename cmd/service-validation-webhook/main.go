@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The service validation webhook rejects the LoadBalancer services with invalid Azure annotations.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/component-base/logs"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider"
+	"sigs.k8s.io/cloud-provider-azure/pkg/webhook"
+)
+
+func main() {
+	var (
+		bindAddress  string
+		securePort   int
+		certFile     string
+		keyFile      string
+		readTimeout  time.Duration
+		writeTimeout time.Duration
+	)
+
+	command := &cobra.Command{
+		Use:   "service-validation-webhook",
+		Short: "Validating admission webhook for Azure service annotations",
+		Long:  `The service validation webhook rejects the LoadBalancer services whose Azure annotations cannot be reconciled by the cloud controller manager`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if certFile == "" || keyFile == "" {
+				return fmt.Errorf("--tls-cert-file and --tls-private-key-file are required")
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle(webhook.ServiceValidationPath, webhook.NewServiceValidationHandler(provider.ValidateService))
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			})
+			server := &http.Server{
+				Addr:         net.JoinHostPort(bindAddress, strconv.Itoa(securePort)),
+				Handler:      mux,
+				ReadTimeout:  readTimeout,
+				WriteTimeout: writeTimeout,
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				if err := server.Shutdown(shutdownCtx); err != nil {
+					klog.Errorf("Failed to shut down the webhook server: %v", err)
+				}
+			}()
+
+			klog.Infof("Serving the service validation webhook on %s", server.Addr)
+			if err := server.ListenAndServeTLS(certFile, keyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	// Flags
+	command.Flags().StringVar(&bindAddress, "bind-address", "0.0.0.0", "The IP address on which to serve the webhook")
+	command.Flags().IntVar(&securePort, "secure-port", 9443, "The port on which to serve the webhook with TLS")
+	command.Flags().StringVar(&certFile, "tls-cert-file", "", "File containing the x509 certificate for serving the webhook")
+	command.Flags().StringVar(&keyFile, "tls-private-key-file", "", "File containing the x509 private key matching --tls-cert-file")
+	command.Flags().DurationVar(&readTimeout, "read-timeout", 10*time.Second, "The maximum duration for reading the admission request")
+	command.Flags().DurationVar(&writeTimeout, "write-timeout", 10*time.Second, "The maximum duration for writing the admission response")
+
+	if err := command.Execute(); err != nil {
+		klog.Errorf("Error running the service validation webhook: %v", err)
+		os.Exit(1)
+	}
+}
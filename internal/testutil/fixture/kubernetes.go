@@ -96,11 +96,36 @@ func (f *KubernetesServiceFixture) WithAllowedIPRanges(parts ...string) *Kuberne
 	return f
 }
 
+func (f *KubernetesServiceFixture) WithAllowedIPGroups(ids ...string) *KubernetesServiceFixture {
+	f.svc.Annotations[consts.ServiceAnnotationAllowedIPGroups] = strings.Join(ids, ",")
+	return f
+}
+
+func (f *KubernetesServiceFixture) WithPortAllowedIPRanges(port int32, parts ...string) *KubernetesServiceFixture {
+	f.svc.Annotations[consts.BuildAnnotationKeyForPort(port, consts.PortAnnotationAllowedIPRanges)] = strings.Join(parts, ",")
+	return f
+}
+
+func (f *KubernetesServiceFixture) WithPortAllowedServiceTags(port int32, parts ...string) *KubernetesServiceFixture {
+	f.svc.Annotations[consts.BuildAnnotationKeyForPort(port, consts.PortAnnotationAllowedServiceTags)] = strings.Join(parts, ",")
+	return f
+}
+
 func (f *KubernetesServiceFixture) WithAllowedServiceTags(parts ...string) *KubernetesServiceFixture {
 	f.svc.Annotations[consts.ServiceAnnotationAllowedServiceTags] = strings.Join(parts, ",")
 	return f
 }
 
+func (f *KubernetesServiceFixture) WithAllowedApplicationSecurityGroups(ids ...string) *KubernetesServiceFixture {
+	f.svc.Annotations[consts.ServiceAnnotationAllowedApplicationSecurityGroups] = strings.Join(ids, ",")
+	return f
+}
+
+func (f *KubernetesServiceFixture) WithDestinationApplicationSecurityGroup(id string) *KubernetesServiceFixture {
+	f.svc.Annotations[consts.ServiceAnnotationDestinationApplicationSecurityGroup] = id
+	return f
+}
+
 func (f *KubernetesServiceFixture) WithDisableFloatingIP() *KubernetesServiceFixture {
 	f.svc.Annotations[consts.ServiceAnnotationDisableLoadBalancerFloatingIP] = "true"
 	return f
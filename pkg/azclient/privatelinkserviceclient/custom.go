@@ -20,12 +20,14 @@ import (
 	"context"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	armnetwork "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/metrics"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/utils"
 )
 
 const DeletePEConnectionOperationName = "PrivateLinkServicesClient.DeletePrivateEndpointConnection"
+const UpdatePEConnectionOperationName = "PrivateLinkServicesClient.UpdatePrivateEndpointConnection"
 
 func (client *Client) DeletePrivateEndpointConnection(ctx context.Context, resourceGroupName string, serviceName string, peConnectionName string) (err error) {
 	metricsCtx := metrics.BeginARMRequest(client.subscriptionID, resourceGroupName, "PrivateLinkService", "deletePrivateEndpointConnection")
@@ -39,3 +41,16 @@ func (client *Client) DeletePrivateEndpointConnection(ctx context.Context, resou
 
 	return err
 }
+
+func (client *Client) UpdatePrivateEndpointConnection(ctx context.Context, resourceGroupName string, serviceName string, peConnectionName string, parameters armnetwork.PrivateEndpointConnection) (result *armnetwork.PrivateEndpointConnection, err error) {
+	metricsCtx := metrics.BeginARMRequest(client.subscriptionID, resourceGroupName, "PrivateLinkService", "updatePrivateEndpointConnection")
+	defer func() { metricsCtx.Observe(ctx, err) }()
+	ctx, endSpan := runtime.StartSpan(ctx, UpdatePEConnectionOperationName, client.tracer, nil)
+	defer endSpan(err)
+
+	resp, err := client.PrivateLinkServicesClient.UpdatePrivateEndpointConnection(ctx, resourceGroupName, serviceName, peConnectionName, parameters, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.PrivateEndpointConnection, nil
+}
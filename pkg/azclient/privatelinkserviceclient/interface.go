@@ -32,4 +32,5 @@ type Interface interface {
 	utils.DeleteFunc[armnetwork.PrivateLinkService]
 	utils.ListFunc[armnetwork.PrivateLinkService]
 	DeletePrivateEndpointConnection(ctx context.Context, resourceGroupName string, serviceName string, peConnectionName string) error
+	UpdatePrivateEndpointConnection(ctx context.Context, resourceGroupName string, serviceName string, peConnectionName string, parameters armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error)
 }
@@ -252,3 +252,42 @@ func (c *MockInterfaceListCall) DoAndReturn(f func(context.Context, string) ([]*
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// UpdatePrivateEndpointConnection mocks base method.
+func (m *MockInterface) UpdatePrivateEndpointConnection(ctx context.Context, resourceGroupName, serviceName, peConnectionName string, parameters armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrivateEndpointConnection", ctx, resourceGroupName, serviceName, peConnectionName, parameters)
+	ret0, _ := ret[0].(*armnetwork.PrivateEndpointConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePrivateEndpointConnection indicates an expected call of UpdatePrivateEndpointConnection.
+func (mr *MockInterfaceMockRecorder) UpdatePrivateEndpointConnection(ctx, resourceGroupName, serviceName, peConnectionName, parameters any) *MockInterfaceUpdatePrivateEndpointConnectionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrivateEndpointConnection", reflect.TypeOf((*MockInterface)(nil).UpdatePrivateEndpointConnection), ctx, resourceGroupName, serviceName, peConnectionName, parameters)
+	return &MockInterfaceUpdatePrivateEndpointConnectionCall{Call: call}
+}
+
+// MockInterfaceUpdatePrivateEndpointConnectionCall wrap *gomock.Call
+type MockInterfaceUpdatePrivateEndpointConnectionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockInterfaceUpdatePrivateEndpointConnectionCall) Return(arg0 *armnetwork.PrivateEndpointConnection, arg1 error) *MockInterfaceUpdatePrivateEndpointConnectionCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockInterfaceUpdatePrivateEndpointConnectionCall) Do(f func(context.Context, string, string, string, armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error)) *MockInterfaceUpdatePrivateEndpointConnectionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockInterfaceUpdatePrivateEndpointConnectionCall) DoAndReturn(f func(context.Context, string, string, string, armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error)) *MockInterfaceUpdatePrivateEndpointConnectionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
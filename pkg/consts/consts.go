@@ -207,6 +207,12 @@ var (
 		false: "service.beta.kubernetes.io/azure-load-balancer-ipv4",
 		true:  "service.beta.kubernetes.io/azure-load-balancer-ipv6",
 	}
+	// ServiceAnnotationLoadBalancerInternalIPAutoAssignRangeDualStack restricts the automatically assigned private IPs
+	// to a sub-range of the subnet, in the CIDR form (e.g. 10.0.0.64/26) or the start-end form (e.g. 10.0.0.10-10.0.0.20).
+	ServiceAnnotationLoadBalancerInternalIPAutoAssignRangeDualStack = map[bool]string{
+		false: "service.beta.kubernetes.io/azure-load-balancer-internal-ip-auto-assign-range",
+		true:  "service.beta.kubernetes.io/azure-load-balancer-internal-ip-auto-assign-range-ipv6",
+	}
 	// ServiceAnnotationPIPName specifies the pip that will be applied to load balancer
 	ServiceAnnotationPIPNameDualStack = map[bool]string{
 		false: "service.beta.kubernetes.io/azure-pip-name",
@@ -244,6 +250,41 @@ const (
 	// to specify what subnet it is exposed on
 	ServiceAnnotationLoadBalancerInternalSubnet = "service.beta.kubernetes.io/azure-load-balancer-internal-subnet"
 
+	// ServiceAnnotationLoadBalancerInternalIPAutoAssign is the annotation used on the internal service to let the
+	// provider pick an unused private IP from the target subnet as the static frontend IP. The chosen IP is persisted
+	// in the azure-load-balancer-ipv4/ipv6 annotations once the frontend is created, so that it survives the recreation
+	// of the load balancer.
+	ServiceAnnotationLoadBalancerInternalIPAutoAssign = "service.beta.kubernetes.io/azure-load-balancer-internal-ip-auto-assign"
+
+	// ServiceAnnotationLoadBalancerBackendZones is the annotation used on the service to restrict the backend nodes
+	// to the given availability zones, separated by comma, e.g., "1,2" or "eastus-1,eastus-2". The service gets
+	// dedicated backend pools with the nodes whose topology.kubernetes.io/zone label matches. It is not supported
+	// with the backend pool type nodeIPConfiguration and vmType vmss.
+	ServiceAnnotationLoadBalancerBackendZones = "service.beta.kubernetes.io/azure-load-balancer-backend-zones"
+
+	// ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID is the annotation used on the public service to chain
+	// its frontend IP configurations to the given gateway load balancer frontend IP configuration ID, so the traffic goes
+	// through the network virtual appliances behind the gateway load balancer. It overrides the default in the cloud config,
+	// and an empty value disables the chaining for the service.
+	ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID = "service.beta.kubernetes.io/azure-load-balancer-gateway-frontend-ip-configuration-id"
+
+	// ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange is the annotation used on the service to create
+	// inbound NAT rules which map a port in the given frontend port range, e.g., "50000-50099", to the backend port
+	// on each node of the vmSet given by ServiceAnnotationLoadBalancerNodeInboundNATVMSet, e.g., for debugging or SSH
+	// access. Each node gets one frontend port, so the range should be larger than the vmSet. The security rules allow
+	// the traffic from the allowed sources of the service to the backend port on the nodes.
+	ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange = "service.beta.kubernetes.io/azure-load-balancer-node-inbound-nat-frontend-port-range"
+
+	// ServiceAnnotationLoadBalancerNodeInboundNATBackendPort is the annotation used on the service to specify
+	// the TCP port on the nodes the inbound NAT rules map to. It is required with the frontend port range.
+	ServiceAnnotationLoadBalancerNodeInboundNATBackendPort = "service.beta.kubernetes.io/azure-load-balancer-node-inbound-nat-backend-port"
+
+	// ServiceAnnotationLoadBalancerNodeInboundNATVMSet is the annotation used on the service to specify the vmSet
+	// whose nodes get the inbound NAT rules. It is required with the frontend port range, so that the backend port is
+	// not exposed on every node by accident. The inbound NAT rules have dedicated backend pools with the nodes of
+	// the vmSet, and the backend nodes of the load balancing rules of the service are not affected.
+	ServiceAnnotationLoadBalancerNodeInboundNATVMSet = "service.beta.kubernetes.io/azure-load-balancer-node-inbound-nat-vmset"
+
 	// ServiceAnnotationLoadBalancerMode is the annotation used on the service to specify
 	// which load balancer should be associated with the service. This is valid when using the basic
 	// SKU load balancer, or it would be ignored.
@@ -277,6 +318,24 @@ const (
 	// ServiceAnnotationIPTagsForPublicIP specifies the iptags used when dynamically creating a public ip
 	ServiceAnnotationIPTagsForPublicIP = "service.beta.kubernetes.io/azure-pip-ip-tags"
 
+	// ServiceAnnotationPIPDDoSProtectionMode specifies the DDoS protection mode of the public IPs of the service.
+	// Supported values are `VirtualNetworkInherited`, `Enabled` and `Disabled`. It overrides the default in the cloud config.
+	ServiceAnnotationPIPDDoSProtectionMode = "service.beta.kubernetes.io/azure-pip-ddos-protection-mode"
+
+	// ServiceAnnotationPIPDDoSProtectionPlanID specifies the ID of the custom DDoS protection plan associated with the
+	// public IPs of the service. It can only be set when the DDoS protection mode is `Enabled`.
+	ServiceAnnotationPIPDDoSProtectionPlanID = "service.beta.kubernetes.io/azure-pip-ddos-protection-plan-id"
+
+	// ServiceAnnotationPIPRoutingPreference specifies the routing preference of the public IPs of the service.
+	// Supported values are `MicrosoftNetwork` and `Internet`. The `Internet` routing preference is applied by the
+	// `RoutingPreference` IP tag. It cannot be changed on an existing public IP, which is kept unchanged with a warning event.
+	ServiceAnnotationPIPRoutingPreference = "service.beta.kubernetes.io/azure-pip-routing-preference"
+
+	// ServiceAnnotationPIPTier specifies the SKU tier of the public IPs of the service. The supported value is `Regional`.
+	// The `Global` tier is rejected because the global public IPs cannot be attached to the regional load balancers.
+	// It cannot be changed on an existing public IP, which is kept unchanged with a warning event.
+	ServiceAnnotationPIPTier = "service.beta.kubernetes.io/azure-pip-tier"
+
 	// ServiceAnnotationAllowedServiceTags is the annotation used on the service
 	// to specify a list of allowed service tags separated by comma
 	// Refer https://docs.microsoft.com/en-us/azure/virtual-network/security-overview#service-tags for all supported service tags.
@@ -287,6 +346,20 @@ const (
 	// It is compatible with both IPv4 and IPV6 CIDR formats.
 	ServiceAnnotationAllowedIPRanges = "service.beta.kubernetes.io/azure-allowed-ip-ranges"
 
+	// ServiceAnnotationAllowedIPGroups is the annotation used on the service to specify a list of Azure IP Group
+	// resource IDs separated by comma. The addresses of the IP Groups are merged into the allowed IP ranges.
+	ServiceAnnotationAllowedIPGroups = "service.beta.kubernetes.io/azure-allowed-ip-groups"
+
+	// ServiceAnnotationAllowedApplicationSecurityGroups is the annotation used on the service
+	// to specify a list of application security group resource IDs separated by comma,
+	// which are allowed to access the service as the sources of the security rules.
+	ServiceAnnotationAllowedApplicationSecurityGroups = "service.beta.kubernetes.io/azure-allowed-application-security-groups"
+
+	// ServiceAnnotationDestinationApplicationSecurityGroup is the annotation used on the service to specify the resource ID
+	// of the application security group the security rules of the service are attached to, instead of the explicit IPs.
+	// The rules are cleaned up with the annotation value when the service is deleted, so it should not be removed before.
+	ServiceAnnotationDestinationApplicationSecurityGroup = "service.beta.kubernetes.io/azure-destination-application-security-group"
+
 	// ServiceAnnotationDenyAllExceptLoadBalancerSourceRanges  denies all traffic to the load balancer except those
 	// within the service.Spec.LoadBalancerSourceRanges. Ref: https://github.com/kubernetes-sigs/cloud-provider-azure/issues/374.
 	ServiceAnnotationDenyAllExceptLoadBalancerSourceRanges = "service.beta.kubernetes.io/azure-deny-all-except-load-balancer-source-ranges"
@@ -334,9 +407,20 @@ const (
 	// The list is separated by comma. It will be omitted if multi-slb is not used.
 	ServiceAnnotationLoadBalancerConfigurations = "service.beta.kubernetes.io/azure-load-balancer-configurations"
 
+	// ServiceAnnotationLoadBalancerRebalanceTarget is set by the cloud provider to the name of the load balancer configuration
+	// the service is moved to by the multiple standard load balancers rebalancer. It is ignored if the load balancer is not
+	// eligible for the service, and removed after the move finishes or times out. It will be omitted if multi-slb is not used.
+	ServiceAnnotationLoadBalancerRebalanceTarget = "service.beta.kubernetes.io/azure-load-balancer-rebalance-target"
+
 	// ServiceAnnotationDisableTCPReset is the annotation used on the service to disable TCP reset on the load balancer.
 	ServiceAnnotationDisableTCPReset = "service.beta.kubernetes.io/azure-load-balancer-disable-tcp-reset"
 
+	// ServiceAnnotationLoadBalancerPlanOnly is the annotation used on the service to only compute the changes
+	// the cloud provider would make to the Azure resources of the service. The changes are reported
+	// as a Kubernetes event and no request that modifies Azure resources is sent.
+	// If omitted, the default value is false
+	ServiceAnnotationLoadBalancerPlanOnly = "service.beta.kubernetes.io/azure-load-balancer-plan-only"
+
 	// ServiceTagKey is the service key applied for public IP tags.
 	ServiceTagKey       = "k8s-azure-service"
 	LegacyServiceTagKey = "service"
@@ -364,6 +448,8 @@ const (
 	BackendPoolIDTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/loadBalancers/%s/backendAddressPools/%s"
 	// LoadBalancerProbeIDTemplate is the template of the load balancer probe
 	LoadBalancerProbeIDTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/loadBalancers/%s/probes/%s"
+	// PublicIPAddressIDTemplate is the template of the public IP address
+	PublicIPAddressIDTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/publicIPAddresses/%s"
 
 	// InternalLoadBalancerNameSuffix is load balancer suffix
 	InternalLoadBalancerNameSuffix = "-internal"
@@ -372,6 +458,13 @@ const (
 	FrontendIPConfigNameMaxLength = 80
 	// LoadBalancerRuleNameMaxLength is the max length of the load balancing rule
 	LoadBalancerRuleNameMaxLength = 80
+	// PIPRoutingPreferenceMicrosoftNetwork routes the traffic of the public IP through the Microsoft network.
+	PIPRoutingPreferenceMicrosoftNetwork = "MicrosoftNetwork"
+	// PIPRoutingPreferenceInternet routes the traffic of the public IP through the ISP network.
+	PIPRoutingPreferenceInternet = "Internet"
+	// IPTagTypeRoutingPreference is the type of the IP tag that sets the routing preference of the public IP.
+	IPTagTypeRoutingPreference = "RoutingPreference"
+
 	// PIPPrefixNameMaxLength is the max length of the PIP prefix name
 	PIPPrefixNameMaxLength = 80
 	// IPFamilySuffixLength is the length of suffix length of IP family ("-IPv4", "-IPv6")
@@ -382,7 +475,6 @@ const (
 	// LoadBalancerBackendPoolConfigurationTypeNodeIP is the lb backend pool config type node ip
 	LoadBalancerBackendPoolConfigurationTypeNodeIP = "nodeIP"
 	// LoadBalancerBackendPoolConfigurationTypePODIP is the lb backend pool config type pod ip
-	// TODO (nilo19): support pod IP in the future
 	LoadBalancerBackendPoolConfigurationTypePODIP = "podIP"
 )
 
@@ -410,6 +502,26 @@ const (
 	OperationPreemptedErrorMessage = "Operation execution has been preempted by a more recent operation"
 )
 
+// service status conditions
+const (
+	// ServiceConditionLoadBalancerReady is the condition type of the service which indicates
+	// whether the Azure load balancer and public IPs of the service are reconciled
+	ServiceConditionLoadBalancerReady = "AzureLoadBalancerReady"
+	// ServiceConditionSecurityGroupReady is the condition type of the service which indicates
+	// whether the security rules of the service are reconciled
+	ServiceConditionSecurityGroupReady = "AzureSecurityGroupReady"
+	// ServiceConditionPrivateLinkServiceReady is the condition type of the service which indicates
+	// whether the private link service of the service is reconciled
+	ServiceConditionPrivateLinkServiceReady = "AzurePrivateLinkServiceReady"
+	// ServiceConditionReasonReconciled is the reason of a true service condition
+	ServiceConditionReasonReconciled = "Reconciled"
+	// ServiceConditionReasonReconcileFailed is the reason of a false service condition
+	// when the error is not classified by an Azure error code
+	ServiceConditionReasonReconcileFailed = "ReconcileFailed"
+	// ServiceConditionMessageMaxLength is the max length of the message of a service condition
+	ServiceConditionMessageMaxLength = 32768
+)
+
 // node ipam controller
 const (
 	// DefaultNodeMaskCIDRIPv4 is default mask size for IPv4 node cidr
@@ -441,6 +553,14 @@ const (
 
 	// DefaultRouteUpdateIntervalInSeconds defines the route reconciling interval.
 	DefaultRouteUpdateIntervalInSeconds = 30
+
+	// ExtraRouteNamePrefix is the name prefix of the extra routes managed by the route controller.
+	// Node names cannot contain underscores, so the extra routes never collide with the node routes.
+	ExtraRouteNamePrefix = "k8s_extra_"
+	// ExtraRoutesConfigMapKey is the key of the extra routes in the ConfigMap.
+	ExtraRoutesConfigMapKey = "routes"
+	// DefaultExtraRoutesConfigMapNamespace is the default namespace of the extra routes ConfigMap.
+	DefaultExtraRoutesConfigMapNamespace = "kube-system"
 )
 
 // cloud provider config secret
@@ -462,6 +582,15 @@ const (
 	PortAnnotationNoLBRule      PortParams = "no_lb_rule"
 	// NoHealthProbeRule determines whether the port is only used for health probe. no lb probe rule will be created.
 	PortAnnotationNoHealthProbeRule PortParams = "no_probe_rule"
+	// PortAnnotationAllowedIPRanges is the comma-separated IP ranges allowed to access the port, e.g.
+	// service.beta.kubernetes.io/port_8443_allowed-ip-ranges. Together with PortAnnotationAllowedServiceTags, it replaces
+	// the source ranges and the allowed IP ranges, IP Groups, service tags and application security groups of the service
+	// for the port. The other traffic to the port, including the traffic inside the VNet, is denied.
+	// In pod IP mode, the service ports sharing a target port must have the same allowed sources.
+	PortAnnotationAllowedIPRanges PortParams = "allowed-ip-ranges"
+	// PortAnnotationAllowedServiceTags is the comma-separated service tags allowed to access the port, e.g.
+	// service.beta.kubernetes.io/port_8443_allowed-service-tags.
+	PortAnnotationAllowedServiceTags PortParams = "allowed-service-tags"
 )
 
 type PortParams string
@@ -508,14 +637,21 @@ const (
 	// ServiceAnnotationPLSName determines name of the PLS resource to create.
 	ServiceAnnotationPLSName = "service.beta.kubernetes.io/azure-pls-name"
 
-	// ServiceAnnotationPLSIpConfigurationSubnet determines the subnet name to deploy the PLS resource.
+	// ServiceAnnotationPLSIpConfigurationSubnet determines a space separated list of subnet names to deploy the NAT IPs of
+	// the PLS resource. The dynamic NAT IPs are spread across the subnets of the same IP family.
 	ServiceAnnotationPLSIpConfigurationSubnet = "service.beta.kubernetes.io/azure-pls-ip-configuration-subnet"
 
-	// ServiceAnnotationPLSIpConfigurationIPAddressCount determines number of IPs to be associated with the PLS.
+	// ServiceAnnotationPLSIpConfigurationIPAddressCount determines number of IPv4 IPs to be associated with the PLS.
 	ServiceAnnotationPLSIpConfigurationIPAddressCount = "service.beta.kubernetes.io/azure-pls-ip-configuration-ip-address-count"
 
-	// ServiceAnnotationPLSIPConfigurationIPAddress determines a space separated list of static IPs for the PLS.
-	// Total number of IPs should not be greater than the IP count specified in ServiceAnnotationPLSIpConfigurationIPAddressCount.
+	// ServiceAnnotationPLSIpConfigurationIPv6AddressCount determines number of IPv6 IPs to be associated with the PLS.
+	// Default is 0. The subnets of the PLS must have IPv6 address prefixes.
+	ServiceAnnotationPLSIpConfigurationIPv6AddressCount = "service.beta.kubernetes.io/azure-pls-ip-configuration-ipv6-address-count"
+
+	// ServiceAnnotationPLSIPConfigurationIPAddress determines a space separated list of static IPv4 and IPv6 IPs for the PLS.
+	// Total number of IPs of each IP family should not be greater than the IP count specified in
+	// ServiceAnnotationPLSIpConfigurationIPAddressCount or ServiceAnnotationPLSIpConfigurationIPv6AddressCount.
+	// The first IPv4 IP is the primary IP. Each IP must be in one of the subnets if multiple subnets are specified.
 	// If there are fewer IPs specified, the rest are dynamically allocated. The first IP in the list is set as Primary.
 	ServiceAnnotationPLSIpConfigurationIPAddress = "service.beta.kubernetes.io/azure-pls-ip-configuration-ip-address"
 
@@ -533,6 +669,40 @@ const (
 	// automatically approved, only works when visibility is set to "*".
 	ServiceAnnotationPLSAutoApproval = "service.beta.kubernetes.io/azure-pls-auto-approval"
 
+	// ServiceAnnotationPLSConnectionApprovalSubscriptions determines a space separated list of Azure subscription IDs whose
+	// pending private endpoint connections are approved by the cloud provider. The other pending connections are rejected.
+	// The tenants cannot be allowlisted, because the private endpoint connections do not expose the tenant of the consumer,
+	// and the cloud provider cannot read the subscriptions of the other tenants to resolve it.
+	ServiceAnnotationPLSConnectionApprovalSubscriptions = "service.beta.kubernetes.io/azure-pls-connection-approval-subscriptions"
+
+	// ServiceAnnotationPLSConnectionRejectionMessage determines the description of the private endpoint connections
+	// rejected by the cloud provider.
+	ServiceAnnotationPLSConnectionRejectionMessage = "service.beta.kubernetes.io/azure-pls-connection-rejection-message"
+
+	// ServiceAnnotationPLSMaxConnections determines the maximum number of approved private endpoint connections.
+	// The pending connections beyond the limit are rejected by the cloud provider.
+	ServiceAnnotationPLSMaxConnections = "service.beta.kubernetes.io/azure-pls-max-connections"
+
+	// ServiceAnnotationPLSConnections is managed by the cloud provider and records the private endpoint connections of
+	// the PLS and their states in JSON. At most PLSConnectionsAnnotationMaxEntries connections are recorded, the pending
+	// ones first, and the numbers of all connections are in the connection count annotations.
+	ServiceAnnotationPLSConnections = "service.beta.kubernetes.io/azure-pls-connections"
+
+	// ServiceAnnotationPLSAlias is managed by the cloud provider and publishes the alias of the PLS,
+	// which can be used by the consumers to create private endpoints.
+	ServiceAnnotationPLSAlias = "service.beta.kubernetes.io/azure-pls-alias"
+
+	// ServiceAnnotationPLSID is managed by the cloud provider and publishes the resource ID of the PLS.
+	ServiceAnnotationPLSID = "service.beta.kubernetes.io/azure-pls-id"
+
+	// ServiceAnnotationPLSApprovedConnectionCount is managed by the cloud provider and publishes the number of
+	// approved private endpoint connections of the PLS.
+	ServiceAnnotationPLSApprovedConnectionCount = "service.beta.kubernetes.io/azure-pls-approved-connection-count"
+
+	// ServiceAnnotationPLSPendingConnectionCount is managed by the cloud provider and publishes the number of
+	// pending private endpoint connections of the PLS.
+	ServiceAnnotationPLSPendingConnectionCount = "service.beta.kubernetes.io/azure-pls-pending-connection-count"
+
 	// ID string used to create a not existing PLS placehold in plsCache to avoid redundant
 	PrivateLinkServiceNotExistID = "PrivateLinkServiceNotExistID"
 
@@ -544,6 +714,16 @@ const (
 
 	// Default number of IP configs for PLS
 	PLSDefaultNumOfIPConfig = 1
+
+	// Maximum number of IP configs for PLS
+	PLSMaximumNumOfIPConfig = 8
+
+	// PLSConnectionsAnnotationMaxEntries is the maximum number of private endpoint connections recorded in the
+	// annotation ServiceAnnotationPLSConnections.
+	PLSConnectionsAnnotationMaxEntries = 20
+
+	// DefaultPLSConnectionResyncIntervalInSeconds is the default interval for checking the private endpoint connections of the PLS.
+	DefaultPLSConnectionResyncIntervalInSeconds = 60
 )
 
 const (
@@ -558,9 +738,67 @@ const (
 
 	DefaultLoadBalancerBackendPoolUpdateIntervalInSeconds = 30
 
+	// DefaultLoadBalancerRebalanceMaxMoves is the default maximum number of services moved by one rebalance.
+	DefaultLoadBalancerRebalanceMaxMoves = 1
+	// DefaultLoadBalancerRebalanceRuleCountThreshold is the default minimum difference of the load balancing rule counts
+	// between two load balancers to move services from one to the other.
+	DefaultLoadBalancerRebalanceRuleCountThreshold = 10
+	// DefaultLoadBalancerRebalanceMoveTimeoutInSeconds is the default time a service can take to be moved to another
+	// load balancer before the move is abandoned.
+	DefaultLoadBalancerRebalanceMoveTimeoutInSeconds = 1800
+
 	ServiceNameLabel = "kubernetes.io/service-name"
 )
 
+// orphaned resource garbage collection
+const (
+	// OrphanedResourceGCControllerName is the name of the controller collecting the orphaned Azure network resources.
+	OrphanedResourceGCControllerName = "orphaned-resource-gc"
+	// DefaultOrphanedResourceGCIntervalInSeconds is the default interval of collecting the orphaned Azure network resources.
+	DefaultOrphanedResourceGCIntervalInSeconds = 3600
+	// DefaultOrphanedResourceGCGracePeriodInSeconds is the default time a resource must stay orphaned before it is deleted.
+	DefaultOrphanedResourceGCGracePeriodInSeconds = 3600
+	// OrphanedResourceGCStateConfigMapName is the name of the ConfigMap in the kube-system namespace which keeps
+	// the time the orphaned resources are found for the first time, so that the grace period survives the restarts.
+	OrphanedResourceGCStateConfigMapName = "cloud-provider-azure-orphaned-resources"
+	// OrphanedResourceGCStateConfigMapKey is the key of the orphaned resources in the state ConfigMap.
+	OrphanedResourceGCStateConfigMapKey = "firstSeen"
+	// MultipleStandardLoadBalancerConfigurationControllerName is the name of the controller managing the
+	// MultipleStandardLoadBalancerConfiguration custom resources.
+	MultipleStandardLoadBalancerConfigurationControllerName = "multiple-standard-load-balancer-configuration"
+	// DefaultMultipleStandardLoadBalancerConfigurationSyncIntervalInSeconds is the default interval of syncing the
+	// MultipleStandardLoadBalancerConfiguration custom resources and writing back their status.
+	DefaultMultipleStandardLoadBalancerConfigurationSyncIntervalInSeconds = 30
+	// PublicIPPoolControllerName is the name of the controller keeping the pool of pre-provisioned public IPs.
+	PublicIPPoolControllerName = "public-ip-pool"
+	// DefaultPublicIPPoolRefillIntervalInSeconds is the default interval of refilling the public IP pool.
+	DefaultPublicIPPoolRefillIntervalInSeconds = 60
+	// MaxPublicIPPoolSize is the maximum number of unassigned public IPs in the pool per IP family.
+	MaxPublicIPPoolSize = 50
+	// MaxPublicIPPoolCreationsPerRefill is the maximum number of public IPs created by one refill of the pool per IP family.
+	MaxPublicIPPoolCreationsPerRefill = 10
+	// PublicIPPoolTagKey is the tag key applied to the public IPs created for the pool. The public IPs
+	// in the pool without the service tag are not assigned to any service.
+	PublicIPPoolTagKey = "k8s-azure-pip-pool"
+	// IPGroupControllerName is the name of the controller reconciling the services whose allowed IP Groups change.
+	IPGroupControllerName = "ip-group"
+	// DefaultIPGroupResyncIntervalInSeconds is the default interval for checking the changes of the allowed IP Groups.
+	DefaultIPGroupResyncIntervalInSeconds = 300
+)
+
+// load balancer class
+const (
+	// LoadBalancerClassControllerName is the name of the controller reconciling the services
+	// whose `spec.loadBalancerClass` is one of the configured load balancer classes.
+	LoadBalancerClassControllerName = "load-balancer-class"
+	// LoadBalancerClassCleanupFinalizer is the finalizer added to the classed services reconciled by the
+	// cloud provider. It differs from the default cleanup finalizer of the service controller, which
+	// deletes the load balancer of any classed service with that finalizer.
+	LoadBalancerClassCleanupFinalizer = "service.beta.kubernetes.io/azure-load-balancer-class-cleanup"
+	// ToBeDeletedByClusterAutoscalerTaintKey is the taint added by the cluster autoscaler to the nodes being scaled down.
+	ToBeDeletedByClusterAutoscalerTaintKey = "ToBeDeletedByClusterAutoscaler"
+)
+
 // Load Balancer health probe mode
 const (
 	ClusterServiceLoadBalancerHealthProbeModeServiceNodePort = "servicenodeport"
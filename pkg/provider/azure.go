@@ -22,12 +22,14 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
@@ -113,6 +115,8 @@ type Cloud struct {
 	excludeLoadBalancerNodes   *utilsets.IgnoreCaseSet
 	nodePrivateIPs             map[string]*utilsets.IgnoreCaseSet
 	nodePrivateIPToNodeNameMap map[string]string
+	// drainingNodes holds the drain states of the nodes whose load balancer backend addresses are being drained.
+	drainingNodes map[string]*nodeDrainState
 	// nodeInformerSynced is for determining if the informer has synced.
 	nodeInformerSynced cache.InformerSynced
 
@@ -120,6 +124,9 @@ type Cloud struct {
 	routeCIDRsLock sync.Mutex
 	// routeCIDRs holds cache for route CIDRs.
 	routeCIDRs map[string]string
+	// routeDrifts holds the messages of the route drifts found by the last check of the route drift detector,
+	// keyed by the route table and the route. It is only accessed by the route drift detector.
+	routeDrifts map[string]string
 
 	// regionZonesMap stores all available zones for the subscription by region
 	regionZonesMap   map[string][]string
@@ -142,10 +149,35 @@ type Cloud struct {
 	// key: [resourceGroupName]
 	// Value: sync.Map of [pipName]*PublicIPAddress
 	pipCache azcache.Resource
+	// IP Group cache
+	// key: [lower-case IP Group ID]
+	// Value: *IPGroup
+	ipGroupCache azcache.Resource
+	// subnets of the nodes' primary IP configurations
+	// key: [node name]
+	// Value: subnet ID
+	nodeSubnetCache azcache.Resource
+	// route tables associated with the subnets
+	// key: [lower-case subnet ID]
+	// Value: route table ID
+	subnetRouteTableCache azcache.Resource
+	// private link services whose private endpoint connections are checked periodically
+	// key: [service name]
+	// Value: *plsConnectionTarget
+	plsConnectionTargets sync.Map
 	// Add service lister to always get latest service
 	serviceLister corelisters.ServiceLister
+	nodeLister    corelisters.NodeLister
+	// extraRoutesConfigMapLister reads the extra routes ConfigMap, which is the only ConfigMap watched by its informer
+	extraRoutesConfigMapLister corelisters.ConfigMapLister
+	extraRoutesConfigMapSynced cache.InformerSynced
 	// node-sync-loop routine and service-reconcile routine should not update LoadBalancer at the same time
 	serviceReconcileLock sync.Mutex
+	// publicIPPoolRefillCh notifies the public IP pool controller to refill the pool after a public IP is taken from it.
+	publicIPPoolRefillCh chan struct{}
+	// publicIPPoolDeleting holds the lower-case names of the public IPs in the pool being deleted,
+	// which must not be assigned to the services.
+	publicIPPoolDeleting sync.Map
 
 	// multipleStandardLoadBalancerConfigurationsSynced make sure the `reconcileMultipleStandardLoadBalancerConfigurations`
 	// runs only once every time the cloud provide restarts.
@@ -153,9 +185,25 @@ type Cloud struct {
 	// nodesWithCorrectLoadBalancerByPrimaryVMSet marks nodes that are matched with load balancers by primary vmSet.
 	nodesWithCorrectLoadBalancerByPrimaryVMSet      sync.Map
 	multipleStandardLoadBalancersActiveServicesLock sync.Mutex
-	multipleStandardLoadBalancersActiveNodesLock    sync.Mutex
-	localServiceNameToServiceInfoMap                sync.Map
-	endpointSlicesCache                             sync.Map
+	// loadBalancerRebalanceMoveStartTimes records when the rebalancer starts to move the services, keyed by the service name.
+	// It is only accessed by the rebalancer.
+	loadBalancerRebalanceMoveStartTimes          map[string]time.Time
+	multipleStandardLoadBalancersActiveNodesLock sync.Mutex
+	// multipleStandardLoadBalancerConfigurationsLock guards the replacement of the multiple standard load balancer
+	// configurations by the MultipleStandardLoadBalancerConfigurationController.
+	multipleStandardLoadBalancerConfigurationsLock sync.RWMutex
+	localServiceNameToServiceInfoMap               sync.Map
+	endpointSlicesCache                            sync.Map
+	// removedBackendPodIPs holds the pod IPs removed from the backend pools of the services in pod IP mode,
+	// which are removed from the destinations of the security rules when the security group is reconciled.
+	// key: [lower-case service name]
+	// Value: set of pod IPs
+	removedBackendPodIPs     map[string]*utilsets.IgnoreCaseSet
+	removedBackendPodIPsLock sync.Mutex
+
+	// securityGroupCapacityLow is set when the security group usage is above the warning ratio,
+	// so that the warning event is only emitted when the usage crosses the ratio.
+	securityGroupCapacityLow atomic.Bool
 
 	azureResourceLocker *AzureResourceLocker
 }
@@ -171,6 +219,8 @@ func NewCloud(ctx context.Context, clientBuilder cloudprovider.ControllerClientB
 		excludeLoadBalancerNodes:   utilsets.NewString(),
 		nodePrivateIPs:             map[string]*utilsets.IgnoreCaseSet{},
 		nodePrivateIPToNodeNameMap: map[string]string{},
+		drainingNodes:              map[string]*nodeDrainState{},
+		publicIPPoolRefillCh:       make(chan struct{}, 1),
 	}
 
 	err := az.InitializeCloudFromConfig(ctx, config, false, callFromCCM)
@@ -258,6 +308,10 @@ func (az *Cloud) InitializeCloudFromConfig(ctx context.Context, config *config.C
 		config.RouteTableResourceGroup = config.ResourceGroup
 	}
 
+	if config.ExtraRoutesConfigMapNamespace == "" {
+		config.ExtraRoutesConfigMapNamespace = consts.DefaultExtraRoutesConfigMapNamespace
+	}
+
 	if config.SecurityGroupResourceGroup == "" {
 		config.SecurityGroupResourceGroup = config.ResourceGroup
 	}
@@ -292,9 +346,7 @@ func (az *Cloud) InitializeCloudFromConfig(ctx context.Context, config *config.C
 		}
 	}
 
-	if config.LoadBalancerBackendPoolConfigurationType == "" ||
-		// TODO(nilo19): support pod IP mode in the future
-		strings.EqualFold(config.LoadBalancerBackendPoolConfigurationType, consts.LoadBalancerBackendPoolConfigurationTypePODIP) {
+	if config.LoadBalancerBackendPoolConfigurationType == "" {
 		config.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIPConfiguration
 	} else {
 		supportedLoadBalancerBackendPoolConfigurationTypes := utilsets.NewString(
@@ -368,14 +420,37 @@ func (az *Cloud) InitializeCloudFromConfig(ctx context.Context, config *config.C
 		az.LoadBalancerBackendPool = newBackendPoolTypeNodeIPConfig(az)
 	} else if az.IsLBBackendPoolTypeNodeIP() {
 		az.LoadBalancerBackendPool = newBackendPoolTypeNodeIP(az)
+	} else if az.IsLBBackendPoolTypePodIP() {
+		az.LoadBalancerBackendPool = newBackendPoolTypePodIP(az)
 	}
 
 	if az.UseMultipleStandardLoadBalancers() {
-		if err := az.checkEnableMultipleStandardLoadBalancers(); err != nil {
+		if err := az.checkEnableMultipleStandardLoadBalancers(az.MultipleStandardLoadBalancerConfigurations); err != nil {
+			return err
+		}
+	}
+
+	if err := az.checkLoadBalancerClasses(); err != nil {
+		return err
+	}
+
+	if az.GatewayLoadBalancerFrontendIPConfigurationID != "" {
+		if !az.UseStandardLoadBalancer() {
+			return fmt.Errorf("gatewayLoadBalancerFrontendIPConfigurationID can only be used with standard load balancer")
+		}
+		if err := validateGatewayLoadBalancerFrontendIPConfigurationID(az.GatewayLoadBalancerFrontendIPConfigurationID); err != nil {
 			return err
 		}
 	}
 
+	if err := az.validatePublicIPPoolConfig(); err != nil {
+		return err
+	}
+
+	if err := validatePublicIPSettings(az.getDefaultPublicIPSettings(), az.UseStandardLoadBalancer()); err != nil {
+		return fmt.Errorf("invalid public IP settings in the cloud config: %w", err)
+	}
+
 	if az.AuthProvider == nil {
 		var authProvider *azclient.AuthProvider
 		authProvider, err = azclient.NewAuthProvider(&az.ARMClientConfig, &az.AzureClientConfig.AzureAuthConfig)
@@ -475,12 +550,27 @@ func (az *Cloud) InitializeCloudFromConfig(ctx context.Context, config *config.C
 		az.routeUpdater = newDelayedRouteUpdater(az, time.Duration(az.RouteUpdateIntervalInSeconds)*time.Second)
 		go az.routeUpdater.run(ctx)
 
+		// start route drift detector.
+		if az.RouteDriftCheckIntervalInSeconds > 0 {
+			go az.runRouteDriftDetector(ctx)
+		}
+
 		// start backend pool updater.
-		if az.UseMultipleStandardLoadBalancers() {
+		if az.UseMultipleStandardLoadBalancers() || az.IsLBBackendPoolTypePodIP() {
 			az.backendPoolUpdater = newLoadBalancerBackendPoolUpdater(az, time.Duration(az.LoadBalancerBackendPoolUpdateIntervalInSeconds)*time.Second)
 			go az.backendPoolUpdater.run(ctx)
 		}
 
+		// start backend drainer.
+		if az.isLoadBalancerBackendDrainEnabled() {
+			go az.runLoadBalancerBackendDrainer(ctx)
+		}
+
+		// start multiple standard load balancers rebalancer.
+		if az.isLoadBalancerRebalanceEnabled() {
+			go az.runLoadBalancerRebalancer(ctx)
+		}
+
 		// Azure Stack does not support zone at the moment
 		// https://docs.microsoft.com/en-us/azure-stack/user/azure-stack-network-differences?view=azs-2102
 		if !az.IsStackCloud() {
@@ -499,14 +589,27 @@ func (az *Cloud) InitializeCloudFromConfig(ctx context.Context, config *config.C
 }
 
 // Multiple standard load balancer mode only supports IP-based load balancers.
-func (az *Cloud) checkEnableMultipleStandardLoadBalancers() error {
+func (az *Cloud) checkEnableMultipleStandardLoadBalancers(configs []config.MultipleStandardLoadBalancerConfiguration) error {
 	if az.IsLBBackendPoolTypeNodeIPConfig() {
 		return fmt.Errorf("multiple standard load balancers cannot be used with backend pool type %s", consts.LoadBalancerBackendPoolConfigurationTypeNodeIPConfiguration)
 	}
 
+	if err := validateMultipleStandardLoadBalancerConfigurations(configs); err != nil {
+		return err
+	}
+
+	if az.LoadBalancerBackendPoolUpdateIntervalInSeconds == 0 {
+		az.LoadBalancerBackendPoolUpdateIntervalInSeconds = consts.DefaultLoadBalancerBackendPoolUpdateIntervalInSeconds
+	}
+
+	return nil
+}
+
+// validateMultipleStandardLoadBalancerConfigurations checks the names and primary vmSets of the configurations are unique.
+func validateMultipleStandardLoadBalancerConfigurations(configs []config.MultipleStandardLoadBalancerConfiguration) error {
 	names := utilsets.NewString()
 	primaryVMSets := utilsets.NewString()
-	for _, multiSLBConfig := range az.MultipleStandardLoadBalancerConfigurations {
+	for _, multiSLBConfig := range configs {
 		if names.Has(multiSLBConfig.Name) {
 			return fmt.Errorf("duplicated multiple standard load balancer configuration name %s", multiSLBConfig.Name)
 		}
@@ -521,8 +624,37 @@ func (az *Cloud) checkEnableMultipleStandardLoadBalancers() error {
 		primaryVMSets.Insert(multiSLBConfig.PrimaryVMSet)
 	}
 
-	if az.LoadBalancerBackendPoolUpdateIntervalInSeconds == 0 {
-		az.LoadBalancerBackendPoolUpdateIntervalInSeconds = consts.DefaultLoadBalancerBackendPoolUpdateIntervalInSeconds
+	return nil
+}
+
+// checkLoadBalancerClasses validates the load balancer classes in the config.
+func (az *Cloud) checkLoadBalancerClasses() error {
+	names := sets.New[string]()
+	for _, lbClass := range az.LoadBalancerClasses {
+		if lbClass.Name == "" {
+			return fmt.Errorf("load balancer class name must not be empty")
+		}
+		if names.Has(lbClass.Name) {
+			return fmt.Errorf("duplicated load balancer class %s", lbClass.Name)
+		}
+		names.Insert(lbClass.Name)
+
+		if lbClass.LoadBalancerConfigurationName == "" {
+			continue
+		}
+		if !az.UseMultipleStandardLoadBalancers() {
+			return fmt.Errorf("load balancer class %s refers to load balancer configuration %s, but multiple standard load balancers are not enabled", lbClass.Name, lbClass.LoadBalancerConfigurationName)
+		}
+		var found bool
+		for _, multiSLBConfig := range az.MultipleStandardLoadBalancerConfigurations {
+			if strings.EqualFold(multiSLBConfig.Name, lbClass.LoadBalancerConfigurationName) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("load balancer class %s refers to load balancer configuration %s which is not found in multiple standard load balancer configurations", lbClass.Name, lbClass.LoadBalancerConfigurationName)
+		}
 	}
 
 	return nil
@@ -548,6 +680,21 @@ func (az *Cloud) initCaches() (err error) {
 		return err
 	}
 
+	az.ipGroupCache, err = az.newIPGroupCache()
+	if err != nil {
+		return err
+	}
+
+	az.nodeSubnetCache, err = az.newNodeSubnetCache()
+	if err != nil {
+		return err
+	}
+
+	az.subnetRouteTableCache, err = az.newSubnetRouteTableCache()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -570,6 +717,30 @@ func (az *Cloud) setLBDefaults(config *azureconfig.Config) error {
 		if config.DisableOutboundSNAT != nil && *config.DisableOutboundSNAT {
 			return fmt.Errorf("disableOutboundSNAT should only set when loadBalancerSKU is standard")
 		}
+		// The pod IPs can only be added to the IP-based backend pools of the standard load balancer.
+		if config.IsLBBackendPoolTypePodIP() {
+			return fmt.Errorf("loadBalancerBackendPoolConfigurationType %s should only set when loadBalancerSKU is standard", config.LoadBalancerBackendPoolConfigurationType)
+		}
+	}
+
+	if config.LoadBalancerBackendDrainTaintKey == "" {
+		config.LoadBalancerBackendDrainTaintKey = consts.ToBeDeletedByClusterAutoscalerTaintKey
+	}
+
+	if config.LoadBalancerRebalanceMaxMoves <= 0 {
+		config.LoadBalancerRebalanceMaxMoves = consts.DefaultLoadBalancerRebalanceMaxMoves
+	}
+	if config.LoadBalancerRebalanceRuleCountThreshold <= 0 {
+		config.LoadBalancerRebalanceRuleCountThreshold = consts.DefaultLoadBalancerRebalanceRuleCountThreshold
+	}
+	if config.LoadBalancerRebalanceMoveTimeoutInSeconds <= 0 {
+		config.LoadBalancerRebalanceMoveTimeoutInSeconds = consts.DefaultLoadBalancerRebalanceMoveTimeoutInSeconds
+	}
+	if config.IPGroupResyncIntervalInSeconds == 0 {
+		config.IPGroupResyncIntervalInSeconds = consts.DefaultIPGroupResyncIntervalInSeconds
+	}
+	if config.PLSConnectionResyncIntervalInSeconds == 0 {
+		config.PLSConnectionResyncIntervalInSeconds = consts.DefaultPLSConnectionResyncIntervalInSeconds
 	}
 	return nil
 }
@@ -615,11 +786,17 @@ func (az *Cloud) setCloudProviderBackoffDefaults(config *azureconfig.Config) wai
 }
 
 // Initialize passes a Kubernetes clientBuilder interface to the cloud provider
-func (az *Cloud) Initialize(clientBuilder cloudprovider.ControllerClientBuilder, _ <-chan struct{}) {
+func (az *Cloud) Initialize(clientBuilder cloudprovider.ControllerClientBuilder, stop <-chan struct{}) {
 	az.KubeClient = clientBuilder.ClientOrDie("azure-cloud-provider")
 	az.eventBroadcaster = record.NewBroadcaster()
 	az.eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: az.KubeClient.CoreV1().Events("")})
 	az.eventRecorder = az.eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "azure-cloud-provider"})
+	az.setUpExtraRoutesConfigMapInformer(stop)
+
+	// The private endpoint connections are only approved by the leader, which is the one initialized here.
+	if az.PLSConnectionResyncIntervalInSeconds > 0 {
+		go az.runPLSConnectionResync(wait.ContextForChannel(stop))
+	}
 }
 
 // LoadBalancer returns a balancer interface. Also returns true if the interface is supported, false otherwise.
@@ -711,6 +888,7 @@ func (az *Cloud) SetInformers(informerFactory informers.SharedInformerFactory) {
 		},
 	})
 	az.nodeInformerSynced = nodeInformer.HasSynced
+	az.nodeLister = informerFactory.Core().V1().Nodes().Lister()
 
 	az.serviceLister = informerFactory.Core().V1().Services().Lister()
 
@@ -819,6 +997,8 @@ func (az *Cloud) updateNodeCaches(prevNode, newNode *v1.Node) {
 			az.nodePrivateIPToNodeNameMap[address] = newNode.Name
 		}
 	}
+
+	az.updateNodeDrainState(prevNode, newNode)
 }
 
 // updateNodeTaint updates node out-of-service taint
@@ -972,11 +1152,41 @@ func (az *Cloud) ShouldNodeExcludedFromLoadBalancer(nodeName string) (bool, erro
 	return az.excludeLoadBalancerNodes.Has(nodeName), nil
 }
 
+// getMultipleStandardLoadBalancerConfigurations returns the multiple standard load balancer configurations.
+// The configurations can be replaced by the MultipleStandardLoadBalancerConfigurationController at any time,
+// so the callers not holding serviceReconcileLock should read them here.
+func (az *Cloud) getMultipleStandardLoadBalancerConfigurations() []config.MultipleStandardLoadBalancerConfiguration {
+	az.multipleStandardLoadBalancerConfigurationsLock.RLock()
+	defer az.multipleStandardLoadBalancerConfigurationsLock.RUnlock()
+
+	return az.MultipleStandardLoadBalancerConfigurations
+}
+
+// setMultipleStandardLoadBalancerConfigurations replaces the multiple standard load balancer configurations.
+func (az *Cloud) setMultipleStandardLoadBalancerConfigurations(configs []config.MultipleStandardLoadBalancerConfiguration) {
+	az.multipleStandardLoadBalancerConfigurationsLock.Lock()
+	defer az.multipleStandardLoadBalancerConfigurationsLock.Unlock()
+
+	az.MultipleStandardLoadBalancerConfigurations = configs
+}
+
+// UseMultipleStandardLoadBalancers returns true if there are multiple standard load balancer configurations.
+// It overrides the one of Config to read the configurations under the lock.
+func (az *Cloud) UseMultipleStandardLoadBalancers() bool {
+	return az.UseStandardLoadBalancer() && len(az.getMultipleStandardLoadBalancerConfigurations()) > 0
+}
+
+// UseSingleStandardLoadBalancer returns true if there is no multiple standard load balancer configuration.
+// It overrides the one of Config to read the configurations under the lock.
+func (az *Cloud) UseSingleStandardLoadBalancer() bool {
+	return az.UseStandardLoadBalancer() && len(az.getMultipleStandardLoadBalancerConfigurations()) == 0
+}
+
 func (az *Cloud) getActiveNodesByLoadBalancerName(lbName string) *utilsets.IgnoreCaseSet {
 	az.multipleStandardLoadBalancersActiveNodesLock.Lock()
 	defer az.multipleStandardLoadBalancersActiveNodesLock.Unlock()
 
-	for _, multiSLBConfig := range az.MultipleStandardLoadBalancerConfigurations {
+	for _, multiSLBConfig := range az.getMultipleStandardLoadBalancerConfigurations() {
 		if strings.EqualFold(trimSuffixIgnoreCase(lbName, consts.InternalLoadBalancerNameSuffix), multiSLBConfig.Name) {
 			return multiSLBConfig.ActiveNodes
 		}
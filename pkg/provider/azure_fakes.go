@@ -28,6 +28,7 @@ import (
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/backendaddresspoolclient/mock_backendaddresspoolclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/diskclient/mock_diskclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/interfaceclient/mock_interfaceclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/ipgroupclient/mock_ipgroupclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/loadbalancerclient/mock_loadbalancerclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/mock_azclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/privateendpointclient/mock_privateendpointclient"
@@ -109,6 +110,7 @@ func GetTestCloud(ctrl *gomock.Controller) (az *Cloud) {
 		unmanagedNodes:           utilsets.NewString(),
 		excludeLoadBalancerNodes: utilsets.NewString(),
 		nodePrivateIPs:           map[string]*utilsets.IgnoreCaseSet{},
+		drainingNodes:            map[string]*nodeDrainState{},
 		routeCIDRs:               map[string]string{},
 		eventRecorder:            &record.FakeRecorder{},
 		Environment:              &azclient.Environment{},
@@ -150,6 +152,8 @@ func GetTestCloud(ctrl *gomock.Controller) (az *Cloud) {
 	clientFactory.EXPECT().GetPrivateLinkServiceClient().Return(privatelinkserviceClient).AnyTimes()
 	routetableClient := mock_routetableclient.NewMockInterface(ctrl)
 	clientFactory.EXPECT().GetRouteTableClient().Return(routetableClient).AnyTimes()
+	ipGroupClient := mock_ipgroupclient.NewMockInterface(ctrl)
+	clientFactory.EXPECT().GetIPGroupClient().Return(ipGroupClient).AnyTimes()
 	privateendpointTrack2Client := mock_privateendpointclient.NewMockInterface(ctrl)
 	clientFactory.EXPECT().GetPrivateEndpointClient().Return(privateendpointTrack2Client).AnyTimes()
 	az.AuthProvider = &azclient.AuthProvider{
@@ -161,6 +165,9 @@ func GetTestCloud(ctrl *gomock.Controller) (az *Cloud) {
 	az.nsgRepo, _ = securitygroup.NewSecurityGroupRepo(az.SecurityGroupResourceGroup, az.SecurityGroupName, az.NsgCacheTTLInSeconds, az.Config.DisableAPICallCache, securtyGrouptrack2Client)
 	az.subnetRepo = subnet.NewMockRepository(ctrl)
 	az.pipCache, _ = az.newPIPCache()
+	az.ipGroupCache, _ = az.newIPGroupCache()
+	az.nodeSubnetCache, _ = az.newNodeSubnetCache()
+	az.subnetRouteTableCache, _ = az.newSubnetRouteTableCache()
 	az.LoadBalancerBackendPool = NewMockBackendPool(ctrl)
 
 	az.plsRepo = privatelinkservice.NewMockRepository(ctrl)
@@ -0,0 +1,933 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/log"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/securitygroup"
+	"sigs.k8s.io/cloud-provider-azure/pkg/util/errutils"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+// The types of the orphaned resources, which are used in the logs and metrics.
+const (
+	orphanedResourceTypePublicIPAddress         = "PublicIPAddress"
+	orphanedResourceTypeFrontendIPConfiguration = "FrontendIPConfiguration"
+	orphanedResourceTypeLoadBalancingRule       = "LoadBalancingRule"
+	orphanedResourceTypeProbe                   = "Probe"
+	orphanedResourceTypeSecurityRuleDestination = "SecurityRuleDestination"
+	orphanedResourceTypePrivateLinkService      = "PrivateLinkService"
+)
+
+var (
+	orphanedResourceTypes = []string{
+		orphanedResourceTypePublicIPAddress,
+		orphanedResourceTypeFrontendIPConfiguration,
+		orphanedResourceTypeLoadBalancingRule,
+		orphanedResourceTypeProbe,
+		orphanedResourceTypeSecurityRuleDestination,
+		orphanedResourceTypePrivateLinkService,
+	}
+
+	orphanedResourcesFound = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "orphaned_resources",
+			Help:           "Number of orphaned Azure network resources found by the last garbage collection",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"resource_type"},
+	)
+	orphanedResourcesDeleted = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "orphaned_resources_deleted_total",
+			Help:           "Number of orphaned Azure network resources deleted by the garbage collection",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"resource_type", "result"},
+	)
+	registerOrphanedResourceMetricsOnce sync.Once
+)
+
+func registerOrphanedResourceMetrics() {
+	registerOrphanedResourceMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(orphanedResourcesFound)
+		legacyregistry.MustRegister(orphanedResourcesDeleted)
+	})
+}
+
+func observeOrphanedResourceDeletion(resourceType string, err error) {
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	orphanedResourcesDeleted.WithLabelValues(resourceType, result).Inc()
+}
+
+// orphanedResource is an Azure network resource, or a part of it, owned by a service which no longer exists.
+type orphanedResource struct {
+	resourceType string
+	// id is the ID of the Azure resource. For the destination of the security rules,
+	// it is the ID of the security group followed by the destination address.
+	id string
+	// owner is the name of the service in the tags of the resource, or of the public IP or private link service
+	// referencing the frontend IP configuration of the load balancer sub-resource.
+	owner string
+}
+
+// liveServices are the LoadBalancer typed services of the cluster.
+type liveServices struct {
+	names    *utilsets.IgnoreCaseSet
+	ips      *utilsets.IgnoreCaseSet
+	services []*v1.Service
+}
+
+// OrphanedResourceGarbageCollector periodically deletes the Azure network resources owned by the services
+// of the cluster which no longer exist, e.g., when a service is deleted while the cloud controller manager is down.
+// Only the resources in the resource groups of the cluster and of the existing services are collected, and the ownership
+// is decided by the cluster and service tags of the public IPs and private link services. The load balancer sub-resources
+// are collected only if their frontend IP configuration references an orphaned public IP or private link service.
+type OrphanedResourceGarbageCollector struct {
+	az          *Cloud
+	clusterName string
+	interval    time.Duration
+	gracePeriod time.Duration
+	reportOnly  bool
+	now         func() time.Time
+
+	// firstSeen records when the orphaned resources are found for the first time, keyed by the lower-cased ID.
+	// It is persisted in the state ConfigMap, so that the grace period is kept across the restarts and the leader changes.
+	firstSeen map[string]time.Time
+}
+
+// NewOrphanedResourceGarbageCollector creates a new OrphanedResourceGarbageCollector for the cluster.
+func NewOrphanedResourceGarbageCollector(az *Cloud, clusterName string) *OrphanedResourceGarbageCollector {
+	interval := az.OrphanedResourceGCIntervalInSeconds
+	if interval <= 0 {
+		interval = consts.DefaultOrphanedResourceGCIntervalInSeconds
+	}
+	gracePeriod := az.OrphanedResourceGCGracePeriodInSeconds
+	if gracePeriod <= 0 {
+		gracePeriod = consts.DefaultOrphanedResourceGCGracePeriodInSeconds
+	}
+	return &OrphanedResourceGarbageCollector{
+		az:          az,
+		clusterName: clusterName,
+		interval:    time.Duration(interval) * time.Second,
+		gracePeriod: time.Duration(gracePeriod) * time.Second,
+		reportOnly:  az.OrphanedResourceGCReportOnly,
+		now:         time.Now,
+		firstSeen:   make(map[string]time.Time),
+	}
+}
+
+// Run starts the OrphanedResourceGarbageCollector, and stops if the context exits.
+func (gc *OrphanedResourceGarbageCollector) Run(ctx context.Context) {
+	registerOrphanedResourceMetrics()
+	klog.V(2).Infof("OrphanedResourceGarbageCollector.Run: started with interval %s, grace period %s and report only %t", gc.interval, gc.gracePeriod, gc.reportOnly)
+	err := wait.PollUntilContextCancel(ctx, gc.interval, false, func(ctx context.Context) (bool, error) {
+		if err := gc.collect(ctx); err != nil {
+			klog.Errorf("OrphanedResourceGarbageCollector.Run: failed to collect the orphaned resources: %s", err.Error())
+		}
+		return false, nil
+	})
+	klog.Infof("OrphanedResourceGarbageCollector.Run: stopped due to %s", err.Error())
+}
+
+// collect finds the orphaned resources, and deletes the ones which have been orphaned longer than the grace period.
+// The resources are listed without the service reconciliation lock, which is only held around each mutation
+// after the mutated resource is read again, so that the resources being created by the services are not collected.
+func (gc *OrphanedResourceGarbageCollector) collect(ctx context.Context) error {
+	logger := log.FromContextOrBackground(ctx).WithName("OrphanedResourceGarbageCollector").WithValues("cluster", gc.clusterName)
+	ctx = log.NewContext(ctx, logger)
+
+	live, err := gc.listLiveServices(ctx)
+	if err != nil {
+		return err
+	}
+	if err := gc.loadState(ctx); err != nil {
+		return err
+	}
+
+	plss, err := gc.listPrivateLinkServices(ctx, live)
+	if err != nil {
+		return err
+	}
+	lbs, err := gc.listManagedLoadBalancers(ctx)
+	if err != nil {
+		return err
+	}
+	pips, err := gc.listPublicIPs(ctx, live)
+	if err != nil {
+		return err
+	}
+	sg, err := gc.az.nsgRepo.GetSecurityGroup(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the security group: %w", err)
+	}
+
+	var orphans []orphanedResource
+	plsOrphans := gc.findOrphanedPrivateLinkServices(plss, live)
+	orphans = append(orphans, plsOrphans...)
+
+	// orphanedOwners are the owner services of the orphaned public IPs and of the frontend IP configurations
+	// used by the orphaned private link services, keyed by the lower-cased ID.
+	orphanedOwners := make(map[string]string)
+	keptPLSFrontendIPConfigIDs := utilsets.NewString()
+	for _, pls := range plss {
+		if pls.Properties == nil {
+			continue
+		}
+		orphaned := containsOrphanedResource(plsOrphans, ptr.Deref(pls.ID, ""))
+		for _, fip := range pls.Properties.LoadBalancerFrontendIPConfigurations {
+			if orphaned {
+				orphanedOwners[strings.ToLower(ptr.Deref(fip.ID, ""))] = ptr.Deref(pls.Tags[consts.OwnerServiceTagKey], "")
+				continue
+			}
+			keptPLSFrontendIPConfigIDs.Insert(ptr.Deref(fip.ID, ""))
+		}
+	}
+	for rg, rgPIPs := range pips {
+		for _, pip := range rgPIPs {
+			if gc.isOrphanedPublicIP(pip, live) {
+				id := ptr.Deref(pip.ID, fmt.Sprintf(consts.PublicIPAddressIDTemplate, gc.az.SubscriptionID, rg, ptr.Deref(pip.Name, "")))
+				orphanedOwners[strings.ToLower(id)] = getServiceFromPIPServiceTags(pip.Tags)
+			}
+		}
+	}
+
+	// orphanedIPs are the addresses of the orphaned frontend IP configurations and public IPs,
+	// mapped to the lower-cased ID of the resource which must be deleted before the address is collected.
+	orphanedIPs := make(map[string]string)
+	orphanedFrontendIPConfigIDs := utilsets.NewString()
+	for _, lb := range lbs {
+		lbOrphans := findOrphanedLoadBalancerSubResources(lb, orphanedOwners, keptPLSFrontendIPConfigIDs)
+		for _, orphan := range lbOrphans {
+			if orphan.resourceType == orphanedResourceTypeFrontendIPConfiguration {
+				orphanedFrontendIPConfigIDs.Insert(orphan.id)
+			}
+		}
+		for _, fip := range lb.Properties.FrontendIPConfigurations {
+			if orphanedFrontendIPConfigIDs.Has(ptr.Deref(fip.ID, "")) && fip.Properties != nil && fip.Properties.PrivateIPAddress != nil {
+				orphanedIPs[*fip.Properties.PrivateIPAddress] = strings.ToLower(ptr.Deref(fip.ID, ""))
+			}
+		}
+		orphans = append(orphans, lbOrphans...)
+	}
+
+	for rg, rgPIPs := range pips {
+		for _, pip := range rgPIPs {
+			id := ptr.Deref(pip.ID, fmt.Sprintf(consts.PublicIPAddressIDTemplate, gc.az.SubscriptionID, rg, ptr.Deref(pip.Name, "")))
+			if _, ok := orphanedOwners[strings.ToLower(id)]; !ok {
+				continue
+			}
+			// The public IP referenced by other resources than the orphaned frontend IP configurations is kept.
+			if pip.Properties.IPConfiguration != nil && !orphanedFrontendIPConfigIDs.Has(ptr.Deref(pip.Properties.IPConfiguration.ID, "")) {
+				continue
+			}
+			orphans = append(orphans, orphanedResource{
+				resourceType: orphanedResourceTypePublicIPAddress,
+				id:           id,
+				owner:        getServiceFromPIPServiceTags(pip.Tags),
+			})
+			if ip := ptr.Deref(pip.Properties.IPAddress, ""); ip != "" {
+				orphanedIPs[ip] = strings.ToLower(id)
+			}
+		}
+	}
+
+	orphans = append(orphans, findOrphanedSecurityRuleDestinations(sg, orphanedIPs, live)...)
+
+	expired := gc.observe(ctx, orphans)
+	if err := gc.saveState(ctx); err != nil {
+		return err
+	}
+	if gc.reportOnly {
+		logger.V(2).Info("Skipping the deletion because the garbage collection is in report only mode", "orphaned-resources", len(orphans))
+		return nil
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	var errs []error
+	deletedPLSFrontendIPConfigIDs, err := gc.deletePrivateLinkServices(ctx, plss, expired)
+	errs = append(errs, err)
+	blockedFrontendIPConfigIDs := utilsets.NewString()
+	for _, pls := range plss {
+		if pls.Properties == nil {
+			continue
+		}
+		for _, fip := range pls.Properties.LoadBalancerFrontendIPConfigurations {
+			if !deletedPLSFrontendIPConfigIDs.Has(ptr.Deref(fip.ID, "")) {
+				blockedFrontendIPConfigIDs.Insert(ptr.Deref(fip.ID, ""))
+			}
+		}
+	}
+
+	// deletedIDs are the lower-cased IDs of the deleted frontend IP configurations and public IPs.
+	deletedIDs := utilsets.NewString()
+	for _, lb := range lbs {
+		removed, err := gc.deleteLoadBalancerSubResources(ctx, ptr.Deref(lb.Name, ""), expired, blockedFrontendIPConfigIDs)
+		errs = append(errs, err)
+		deletedIDs.Insert(removed...)
+	}
+	for rg, rgPIPs := range pips {
+		deleted, err := gc.deletePublicIPs(ctx, rg, rgPIPs, expired, deletedIDs)
+		errs = append(errs, err)
+		deletedIDs.Insert(deleted...)
+	}
+	errs = append(errs, gc.deleteSecurityRuleDestinations(ctx, expired, orphanedIPs, deletedIDs))
+
+	return errors.Join(errs...)
+}
+
+// lockForMutation holds the service reconciliation lock while a resource is read again and mutated,
+// so that the garbage collection does not race with the services being reconciled.
+func (gc *OrphanedResourceGarbageCollector) lockForMutation() func() {
+	gc.az.serviceReconcileLock.Lock()
+	return gc.az.serviceReconcileLock.Unlock
+}
+
+// loadState reads the time the orphaned resources are found for the first time from the state ConfigMap.
+// The in-memory state is kept if the ConfigMap does not exist yet.
+func (gc *OrphanedResourceGarbageCollector) loadState(ctx context.Context) error {
+	cm, err := gc.az.KubeClient.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, consts.OrphanedResourceGCStateConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the state ConfigMap %s: %w", consts.OrphanedResourceGCStateConfigMapName, err)
+	}
+
+	firstSeen := make(map[string]time.Time)
+	if data := cm.Data[consts.OrphanedResourceGCStateConfigMapKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), &firstSeen); err != nil {
+			return fmt.Errorf("failed to parse the state ConfigMap %s: %w", consts.OrphanedResourceGCStateConfigMapName, err)
+		}
+	}
+	gc.firstSeen = firstSeen
+	return nil
+}
+
+// saveState writes the time the orphaned resources are found for the first time to the state ConfigMap.
+func (gc *OrphanedResourceGarbageCollector) saveState(ctx context.Context) error {
+	data, err := json.Marshal(gc.firstSeen)
+	if err != nil {
+		return err
+	}
+
+	configMaps := gc.az.KubeClient.CoreV1().ConfigMaps(metav1.NamespaceSystem)
+	cm, err := configMaps.Get(ctx, consts.OrphanedResourceGCStateConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the state ConfigMap %s: %w", consts.OrphanedResourceGCStateConfigMapName, err)
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      consts.OrphanedResourceGCStateConfigMapName,
+				Namespace: metav1.NamespaceSystem,
+			},
+			Data: map[string]string{consts.OrphanedResourceGCStateConfigMapKey: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create the state ConfigMap %s: %w", consts.OrphanedResourceGCStateConfigMapName, err)
+		}
+		return nil
+	}
+
+	if cm.Data[consts.OrphanedResourceGCStateConfigMapKey] == string(data) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[consts.OrphanedResourceGCStateConfigMapKey] = string(data)
+	if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update the state ConfigMap %s: %w", consts.OrphanedResourceGCStateConfigMapName, err)
+	}
+	return nil
+}
+
+// observe records the time the orphaned resources are found, updates the metrics,
+// and returns the resources which have been orphaned longer than the grace period.
+func (gc *OrphanedResourceGarbageCollector) observe(ctx context.Context, orphans []orphanedResource) []orphanedResource {
+	logger := log.FromContextOrBackground(ctx)
+	now := gc.now()
+
+	counts := make(map[string]int)
+	found := make(map[string]bool)
+	var expired []orphanedResource
+	for _, orphan := range orphans {
+		key := strings.ToLower(orphan.id)
+		firstSeen, ok := gc.firstSeen[key]
+		if !ok {
+			firstSeen = now
+			gc.firstSeen[key] = now
+		}
+		found[key] = true
+		counts[orphan.resourceType]++
+
+		age := now.Sub(firstSeen)
+		logger.Info("Found orphaned resource", "resource-type", orphan.resourceType, "id", orphan.id, "owner", orphan.owner, "age", age.String())
+		if age >= gc.gracePeriod {
+			expired = append(expired, orphan)
+		}
+	}
+	for key := range gc.firstSeen {
+		if !found[key] {
+			delete(gc.firstSeen, key)
+		}
+	}
+	for _, resourceType := range orphanedResourceTypes {
+		orphanedResourcesFound.WithLabelValues(resourceType).Set(float64(counts[resourceType]))
+	}
+	return expired
+}
+
+func containsOrphanedResource(orphans []orphanedResource, id string) bool {
+	for _, orphan := range orphans {
+		if strings.EqualFold(orphan.id, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// listLiveServices lists the LoadBalancer typed services from the API server rather than the informer cache,
+// so that a service is never considered deleted because the cache is not synced.
+func (gc *OrphanedResourceGarbageCollector) listLiveServices(ctx context.Context) (*liveServices, error) {
+	if gc.az.KubeClient == nil {
+		return nil, fmt.Errorf("kube client is nil")
+	}
+	svcs, err := gc.az.KubeClient.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	live := &liveServices{
+		names: utilsets.NewString(),
+		ips:   utilsets.NewString(),
+	}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
+		live.services = append(live.services, svc)
+		live.names.Insert(getServiceName(svc))
+		live.ips.Insert(getServiceLoadBalancerIPs(svc)...)
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			live.ips.Insert(ingress.IP)
+		}
+	}
+	return live, nil
+}
+
+// listManagedLoadBalancers lists the load balancers of the cluster. The load balancers of
+// the basic SKU named after the availability sets or scale sets are not collected.
+func (gc *OrphanedResourceGarbageCollector) listManagedLoadBalancers(ctx context.Context) ([]*armnetwork.LoadBalancer, error) {
+	rg := gc.az.getLoadBalancerResourceGroup()
+	allLBs, err := gc.az.NetworkClientFactory.GetLoadBalancerClient().List(ctx, rg)
+	if err != nil {
+		if exist, _ := errutils.CheckResourceExistsFromAzcoreError(err); !exist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list load balancers in resource group %s: %w", rg, err)
+	}
+
+	managedLBNames := utilsets.NewString(gc.clusterName)
+	for _, multiSLBConfig := range gc.az.getMultipleStandardLoadBalancerConfigurations() {
+		managedLBNames.Insert(multiSLBConfig.Name)
+	}
+	var lbs []*armnetwork.LoadBalancer
+	for _, lb := range allLBs {
+		if lb.Properties == nil || !managedLBNames.Has(trimSuffixIgnoreCase(ptr.Deref(lb.Name, ""), consts.InternalLoadBalancerNameSuffix)) {
+			continue
+		}
+		lbs = append(lbs, lb)
+	}
+	return lbs, nil
+}
+
+// listPublicIPs lists the public IPs in the resource groups of the cluster and of the existing services.
+func (gc *OrphanedResourceGarbageCollector) listPublicIPs(ctx context.Context, live *liveServices) (map[string][]*armnetwork.PublicIPAddress, error) {
+	rgs := utilsets.NewString(gc.az.ResourceGroup, gc.az.getLoadBalancerResourceGroup())
+	for _, svc := range live.services {
+		rgs.Insert(gc.az.getPublicIPAddressResourceGroup(svc))
+	}
+
+	pips := make(map[string][]*armnetwork.PublicIPAddress)
+	for _, rg := range rgs.UnsortedList() {
+		rgPIPs, err := gc.az.listPIP(ctx, rg, azcache.CacheReadTypeForceRefresh)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list public IPs in resource group %s: %w", rg, err)
+		}
+		pips[rg] = rgPIPs
+	}
+	return pips, nil
+}
+
+// listPrivateLinkServices lists the private link services in the resource groups of the cluster and of the existing services.
+func (gc *OrphanedResourceGarbageCollector) listPrivateLinkServices(ctx context.Context, live *liveServices) ([]*armnetwork.PrivateLinkService, error) {
+	rgs := utilsets.NewString(gc.az.PrivateLinkServiceResourceGroup)
+	for _, svc := range live.services {
+		rgs.Insert(gc.az.getPLSResourceGroup(svc))
+	}
+
+	var plss []*armnetwork.PrivateLinkService
+	for _, rg := range rgs.UnsortedList() {
+		rgPLSs, err := gc.az.plsRepo.List(ctx, rg)
+		if err != nil {
+			if exist, _ := errutils.CheckResourceExistsFromAzcoreError(err); !exist {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list private link services in resource group %s: %w", rg, err)
+		}
+		plss = append(plss, rgPLSs...)
+	}
+	return plss, nil
+}
+
+// findOrphanedPrivateLinkServices returns the private link services of the cluster whose owner service no longer exists.
+func (gc *OrphanedResourceGarbageCollector) findOrphanedPrivateLinkServices(plss []*armnetwork.PrivateLinkService, live *liveServices) []orphanedResource {
+	var orphans []orphanedResource
+	for _, pls := range plss {
+		clusterName := ptr.Deref(pls.Tags[consts.ClusterNameTagKey], "")
+		owner := ptr.Deref(pls.Tags[consts.OwnerServiceTagKey], "")
+		if !strings.EqualFold(clusterName, gc.clusterName) || owner == "" || live.names.Has(owner) {
+			continue
+		}
+		orphans = append(orphans, orphanedResource{
+			resourceType: orphanedResourceTypePrivateLinkService,
+			id:           ptr.Deref(pls.ID, ""),
+			owner:        owner,
+		})
+	}
+	return orphans
+}
+
+// findOrphanedLoadBalancerSubResources returns the frontend IP configurations referencing the orphaned public IPs or
+// used by the orphaned private link services, together with the load balancing rules using them and the probes used
+// only by these rules. A frontend IP configuration is kept if it is still used by a private link service, inbound NAT
+// rules or outbound rules. The owners of the orphaned resources are keyed by the lower-cased ID.
+func findOrphanedLoadBalancerSubResources(lb *armnetwork.LoadBalancer, orphanedOwners map[string]string, keptPLSFrontendIPConfigIDs *utilsets.IgnoreCaseSet) []orphanedResource {
+	var (
+		orphans           []orphanedResource
+		fipOwners         = make(map[string]string)
+		probeOwners       = make(map[string]string)
+		keptProbes        = utilsets.NewString()
+		orphanedFrontends []orphanedResource
+	)
+	for _, fip := range lb.Properties.FrontendIPConfigurations {
+		id := ptr.Deref(fip.ID, "")
+		owner, ok := orphanedOwners[strings.ToLower(id)]
+		if fip.Properties != nil && fip.Properties.PublicIPAddress != nil {
+			owner, ok = orphanedOwners[strings.ToLower(ptr.Deref(fip.Properties.PublicIPAddress.ID, ""))]
+		}
+		if !ok {
+			continue
+		}
+		fipOwners[strings.ToLower(id)] = owner
+		if keptPLSFrontendIPConfigIDs.Has(id) {
+			continue
+		}
+		if fip.Properties != nil &&
+			(len(fip.Properties.InboundNatRules) > 0 || len(fip.Properties.InboundNatPools) > 0 || len(fip.Properties.OutboundRules) > 0) {
+			continue
+		}
+		orphanedFrontends = append(orphanedFrontends, orphanedResource{
+			resourceType: orphanedResourceTypeFrontendIPConfiguration,
+			id:           id,
+			owner:        owner,
+		})
+	}
+
+	for _, rule := range lb.Properties.LoadBalancingRules {
+		var fipID, probeID string
+		if rule.Properties != nil && rule.Properties.FrontendIPConfiguration != nil {
+			fipID = ptr.Deref(rule.Properties.FrontendIPConfiguration.ID, "")
+		}
+		if rule.Properties != nil && rule.Properties.Probe != nil {
+			probeID = strings.ToLower(ptr.Deref(rule.Properties.Probe.ID, ""))
+		}
+		owner, ok := fipOwners[strings.ToLower(fipID)]
+		if !ok {
+			keptProbes.Insert(probeID)
+			continue
+		}
+		if probeID != "" {
+			probeOwners[probeID] = owner
+		}
+		orphans = append(orphans, orphanedResource{
+			resourceType: orphanedResourceTypeLoadBalancingRule,
+			id:           ptr.Deref(rule.ID, ""),
+			owner:        owner,
+		})
+	}
+
+	for _, probe := range lb.Properties.Probes {
+		id := ptr.Deref(probe.ID, "")
+		owner, ok := probeOwners[strings.ToLower(id)]
+		if !ok || keptProbes.Has(id) {
+			continue
+		}
+		orphans = append(orphans, orphanedResource{
+			resourceType: orphanedResourceTypeProbe,
+			id:           id,
+			owner:        owner,
+		})
+	}
+	return append(orphans, orphanedFrontends...)
+}
+
+// isOrphanedPublicIP returns true if the tags of the public IP show it is created for the services
+// of the cluster which no longer exist, and none of the existing services uses it.
+func (gc *OrphanedResourceGarbageCollector) isOrphanedPublicIP(pip *armnetwork.PublicIPAddress, live *liveServices) bool {
+	if pip.Properties == nil || !strings.EqualFold(getClusterFromPIPClusterTags(pip.Tags), gc.clusterName) {
+		return false
+	}
+
+	serviceTag := getServiceFromPIPServiceTags(pip.Tags)
+	serviceNames := parsePIPServiceTag(&serviceTag)
+	if len(serviceNames) == 0 {
+		return false
+	}
+	for _, serviceName := range serviceNames {
+		if live.names.Has(strings.TrimSpace(serviceName)) {
+			return false
+		}
+	}
+	if ip := ptr.Deref(pip.Properties.IPAddress, ""); ip != "" && live.ips.Has(ip) {
+		return false
+	}
+	isIPv6 := ptr.Deref(pip.Properties.PublicIPAddressVersion, "") == armnetwork.IPVersionIPv6
+	for _, svc := range live.services {
+		if isServicePIPNameMatchesPIP(svc, pip, isIPv6) {
+			return false
+		}
+	}
+	return true
+}
+
+// findOrphanedSecurityRuleDestinations returns the orphaned IP addresses used as the destination of the security rules
+// managed by the cloud provider. The addresses of the existing services are never collected.
+func findOrphanedSecurityRuleDestinations(sg *armnetwork.SecurityGroup, orphanedIPs map[string]string, live *liveServices) []orphanedResource {
+	if sg == nil || sg.Properties == nil {
+		return nil
+	}
+
+	found := utilsets.NewString()
+	var orphans []orphanedResource
+	for _, rule := range sg.Properties.SecurityRules {
+		if rule.Properties == nil || rule.Properties.Priority == nil {
+			continue
+		}
+		priority := *rule.Properties.Priority
+		if priority < consts.LoadBalancerMinimumPriority || consts.LoadBalancerMaximumPriority < priority {
+			continue
+		}
+		for _, dst := range securitygroup.ListDestinationPrefixes(rule) {
+			if _, ok := orphanedIPs[dst]; !ok || live.ips.Has(dst) || found.Has(dst) {
+				continue
+			}
+			found.Insert(dst)
+			orphans = append(orphans, orphanedResource{
+				resourceType: orphanedResourceTypeSecurityRuleDestination,
+				id:           fmt.Sprintf("%s/%s", ptr.Deref(sg.ID, ptr.Deref(sg.Name, "")), dst),
+				owner:        dst,
+			})
+		}
+	}
+	return orphans
+}
+
+// deletePrivateLinkServices deletes the expired private link services,
+// and returns the frontend IP configurations used by the deleted ones.
+func (gc *OrphanedResourceGarbageCollector) deletePrivateLinkServices(ctx context.Context, plss []*armnetwork.PrivateLinkService, expired []orphanedResource) (*utilsets.IgnoreCaseSet, error) {
+	logger := log.FromContextOrBackground(ctx)
+	deletedFrontendIPConfigIDs := utilsets.NewString()
+	var errs []error
+	for _, pls := range plss {
+		if !containsOrphanedResource(expired, ptr.Deref(pls.ID, "")) || pls.Properties == nil {
+			continue
+		}
+		resourceID, err := arm.ParseResourceID(ptr.Deref(pls.ID, ""))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse the ID of private link service %s: %w", ptr.Deref(pls.Name, ""), err))
+			continue
+		}
+		var fipConfigID string
+		if len(pls.Properties.LoadBalancerFrontendIPConfigurations) > 0 {
+			fipConfigID = ptr.Deref(pls.Properties.LoadBalancerFrontendIPConfigurations[0].ID, "")
+		}
+
+		unlock := gc.lockForMutation()
+		err = gc.deletePrivateLinkService(ctx, resourceID.ResourceGroupName, pls, fipConfigID)
+		unlock()
+		observeOrphanedResourceDeletion(orphanedResourceTypePrivateLinkService, err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logger.Info("Deleted orphaned private link service", "id", ptr.Deref(pls.ID, ""))
+		for _, fip := range pls.Properties.LoadBalancerFrontendIPConfigurations {
+			deletedFrontendIPConfigIDs.Insert(ptr.Deref(fip.ID, ""))
+		}
+	}
+	return deletedFrontendIPConfigIDs, errors.Join(errs...)
+}
+
+func (gc *OrphanedResourceGarbageCollector) deletePrivateLinkService(ctx context.Context, rg string, pls *armnetwork.PrivateLinkService, fipConfigID string) error {
+	plsName := ptr.Deref(pls.Name, "")
+	for _, peConn := range pls.Properties.PrivateEndpointConnections {
+		if err := gc.az.plsRepo.DeletePEConnection(ctx, rg, plsName, ptr.Deref(peConn.Name, "")); err != nil {
+			return fmt.Errorf("failed to delete the private endpoint connection %s of private link service %s: %w", ptr.Deref(peConn.Name, ""), plsName, err)
+		}
+	}
+	if err := gc.az.plsRepo.Delete(ctx, rg, plsName, fipConfigID); err != nil {
+		return fmt.Errorf("failed to delete private link service %s: %w", plsName, err)
+	}
+	return nil
+}
+
+// deleteLoadBalancerSubResources removes the expired rules, probes and frontend IP configurations from the load balancer
+// read again, and returns the IDs of the removed frontend IP configurations.
+func (gc *OrphanedResourceGarbageCollector) deleteLoadBalancerSubResources(
+	ctx context.Context,
+	lbName string,
+	expired []orphanedResource,
+	blockedFrontendIPConfigIDs *utilsets.IgnoreCaseSet,
+) ([]string, error) {
+	logger := log.FromContextOrBackground(ctx)
+	unlock := gc.lockForMutation()
+	defer unlock()
+
+	lb, exists, err := gc.az.getAzureLoadBalancer(ctx, lbName, azcache.CacheReadTypeForceRefresh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load balancer %s: %w", lbName, err)
+	}
+	if !exists || lb.Properties == nil {
+		return nil, nil
+	}
+
+	var (
+		removed              = make(map[string][]string)
+		rules                []*armnetwork.LoadBalancingRule
+		probes               []*armnetwork.Probe
+		fips                 []*armnetwork.FrontendIPConfiguration
+		usedFrontendIPConfig = utilsets.NewString()
+		usedProbes           = utilsets.NewString()
+	)
+	for _, rule := range lb.Properties.LoadBalancingRules {
+		if containsOrphanedResource(expired, ptr.Deref(rule.ID, "")) {
+			removed[orphanedResourceTypeLoadBalancingRule] = append(removed[orphanedResourceTypeLoadBalancingRule], ptr.Deref(rule.ID, ""))
+			continue
+		}
+		rules = append(rules, rule)
+		if rule.Properties != nil && rule.Properties.FrontendIPConfiguration != nil {
+			usedFrontendIPConfig.Insert(ptr.Deref(rule.Properties.FrontendIPConfiguration.ID, ""))
+		}
+		if rule.Properties != nil && rule.Properties.Probe != nil {
+			usedProbes.Insert(ptr.Deref(rule.Properties.Probe.ID, ""))
+		}
+	}
+	for _, probe := range lb.Properties.Probes {
+		id := ptr.Deref(probe.ID, "")
+		if containsOrphanedResource(expired, id) && !usedProbes.Has(id) {
+			removed[orphanedResourceTypeProbe] = append(removed[orphanedResourceTypeProbe], id)
+			continue
+		}
+		probes = append(probes, probe)
+	}
+	for _, fip := range lb.Properties.FrontendIPConfigurations {
+		id := ptr.Deref(fip.ID, "")
+		if containsOrphanedResource(expired, id) && !usedFrontendIPConfig.Has(id) && !blockedFrontendIPConfigIDs.Has(id) {
+			removed[orphanedResourceTypeFrontendIPConfiguration] = append(removed[orphanedResourceTypeFrontendIPConfiguration], id)
+			continue
+		}
+		fips = append(fips, fip)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	lb.Properties.LoadBalancingRules = rules
+	lb.Properties.Probes = probes
+	lb.Properties.FrontendIPConfigurations = fips
+	err = gc.az.CreateOrUpdateLB(ctx, nil, *lb)
+	for resourceType, ids := range removed {
+		for range ids {
+			observeOrphanedResourceDeletion(resourceType, err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove the orphaned sub-resources from load balancer %s: %w", lbName, err)
+	}
+	logger.Info("Removed orphaned sub-resources from load balancer", "load-balancer", lbName,
+		"rules", removed[orphanedResourceTypeLoadBalancingRule],
+		"probes", removed[orphanedResourceTypeProbe],
+		"frontend-ip-configurations", removed[orphanedResourceTypeFrontendIPConfiguration])
+	return removed[orphanedResourceTypeFrontendIPConfiguration], nil
+}
+
+// deletePublicIPs deletes the expired public IPs which are not referenced, or whose frontend IP configuration
+// has been removed, and returns the IDs of the deleted ones. The public IP is read again and kept
+// if its service tag has been changed since it is found orphaned.
+func (gc *OrphanedResourceGarbageCollector) deletePublicIPs(
+	ctx context.Context,
+	rg string,
+	pips []*armnetwork.PublicIPAddress,
+	expired []orphanedResource,
+	removedFrontendIPConfigIDs *utilsets.IgnoreCaseSet,
+) ([]string, error) {
+	logger := log.FromContextOrBackground(ctx)
+	var (
+		deleted []string
+		errs    []error
+	)
+	for _, pip := range pips {
+		if !containsOrphanedResource(expired, ptr.Deref(pip.ID, "")) {
+			continue
+		}
+		deletedPIP, err := gc.deletePublicIP(ctx, rg, pip, removedFrontendIPConfigIDs)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if deletedPIP {
+			logger.Info("Deleted orphaned public IP", "id", ptr.Deref(pip.ID, ""))
+			deleted = append(deleted, ptr.Deref(pip.ID, ""))
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
+func (gc *OrphanedResourceGarbageCollector) deletePublicIP(
+	ctx context.Context,
+	rg string,
+	pip *armnetwork.PublicIPAddress,
+	removedFrontendIPConfigIDs *utilsets.IgnoreCaseSet,
+) (bool, error) {
+	logger := log.FromContextOrBackground(ctx)
+	unlock := gc.lockForMutation()
+	defer unlock()
+
+	pipName := ptr.Deref(pip.Name, "")
+	current, exists, err := gc.az.getPublicIPAddress(ctx, rg, pipName, azcache.CacheReadTypeForceRefresh)
+	if err != nil {
+		return false, fmt.Errorf("failed to get public IP %s in resource group %s: %w", pipName, rg, err)
+	}
+	if !exists || current.Properties == nil {
+		return false, nil
+	}
+	if !strings.EqualFold(getServiceFromPIPServiceTags(current.Tags), getServiceFromPIPServiceTags(pip.Tags)) {
+		logger.V(2).Info("Skipping the orphaned public IP because its service tag has been changed", "id", ptr.Deref(pip.ID, ""))
+		return false, nil
+	}
+	if current.Properties.IPConfiguration != nil && !removedFrontendIPConfigIDs.Has(ptr.Deref(current.Properties.IPConfiguration.ID, "")) {
+		logger.V(2).Info("Skipping the orphaned public IP because it is still referenced", "id", ptr.Deref(pip.ID, ""), "ip-configuration", ptr.Deref(current.Properties.IPConfiguration.ID, ""))
+		return false, nil
+	}
+
+	err = gc.az.DeletePublicIP(ctx, nil, rg, pipName)
+	observeOrphanedResourceDeletion(orphanedResourceTypePublicIPAddress, err)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete public IP %s in resource group %s: %w", pipName, rg, err)
+	}
+	return true, nil
+}
+
+// deleteSecurityRuleDestinations removes the expired destination addresses from the security rules
+// read again, if the frontend IP configuration or public IP of the address has been deleted.
+func (gc *OrphanedResourceGarbageCollector) deleteSecurityRuleDestinations(
+	ctx context.Context,
+	expired []orphanedResource,
+	orphanedIPs map[string]string,
+	deletedIDs *utilsets.IgnoreCaseSet,
+) error {
+	logger := log.FromContextOrBackground(ctx)
+	var dsts []string
+	for _, orphan := range expired {
+		if orphan.resourceType == orphanedResourceTypeSecurityRuleDestination && deletedIDs.Has(orphanedIPs[orphan.owner]) {
+			dsts = append(dsts, orphan.owner)
+		}
+	}
+	if len(dsts) == 0 {
+		return nil
+	}
+
+	unlock := gc.lockForMutation()
+	defer unlock()
+
+	sg, err := gc.az.nsgRepo.GetSecurityGroup(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the security group: %w", err)
+	}
+	helper, err := securitygroup.NewSecurityGroupHelper(logger, sg)
+	if err != nil {
+		return err
+	}
+	for _, protocol := range []armnetwork.SecurityRuleProtocol{
+		armnetwork.SecurityRuleProtocolTCP,
+		armnetwork.SecurityRuleProtocolUDP,
+		armnetwork.SecurityRuleProtocolAsterisk,
+	} {
+		if err := helper.RemoveDestinationFromRules(protocol, dsts, nil); err != nil {
+			return err
+		}
+	}
+	updated, changed, err := helper.SecurityGroup()
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	err = gc.az.nsgRepo.CreateOrUpdateSecurityGroup(ctx, updated)
+	for range dsts {
+		observeOrphanedResourceDeletion(orphanedResourceTypeSecurityRuleDestination, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove the orphaned destinations from security group %s: %w", ptr.Deref(sg.Name, ""), err)
+	}
+	logger.Info("Removed orphaned destinations from security group", "security-group", ptr.Deref(sg.Name, ""), "destinations", dsts)
+	return nil
+}
@@ -0,0 +1,408 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/loadbalancerclient/mock_loadbalancerclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/publicipaddressclient/mock_publicipaddressclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/securitygroupclient/mock_securitygroupclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/privatelinkservice"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+func TestFindOrphanedLoadBalancerSubResources(t *testing.T) {
+	lbID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/kubernetes"
+	pipID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/"
+	fipID := func(name string) string { return lbID + "/frontendIPConfigurations/" + name }
+
+	lb := &armnetwork.LoadBalancer{
+		Name: ptr.To("kubernetes"),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{
+					Name:       ptr.To("live"),
+					ID:         ptr.To(fipID("live")),
+					Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PublicIPAddress: &armnetwork.PublicIPAddress{ID: ptr.To(pipID + "live")}},
+				},
+				{
+					Name:       ptr.To("orphan"),
+					ID:         ptr.To(fipID("orphan")),
+					Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PublicIPAddress: &armnetwork.PublicIPAddress{ID: ptr.To(pipID + "orphan")}},
+				},
+				{
+					Name: ptr.To("orphan-outbound"),
+					ID:   ptr.To(fipID("orphan-outbound")),
+					Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+						PublicIPAddress: &armnetwork.PublicIPAddress{ID: ptr.To(pipID + "orphan-outbound")},
+						OutboundRules:   []*armnetwork.SubResource{{ID: ptr.To("outbound")}},
+					},
+				},
+				{Name: ptr.To("orphan-internal"), ID: ptr.To(fipID("orphan-internal"))},
+				{Name: ptr.To("orphan-pls"), ID: ptr.To(fipID("orphan-pls"))},
+				{Name: ptr.To("user-created"), ID: ptr.To(fipID("user-created"))},
+			},
+			LoadBalancingRules: []*armnetwork.LoadBalancingRule{
+				{
+					ID: ptr.To(lbID + "/loadBalancingRules/live-TCP-80"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(fipID("live"))},
+						Probe:                   &armnetwork.SubResource{ID: ptr.To(lbID + "/probes/shared")},
+					},
+				},
+				{
+					ID: ptr.To(lbID + "/loadBalancingRules/orphan-TCP-80"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(fipID("orphan"))},
+						Probe:                   &armnetwork.SubResource{ID: ptr.To(lbID + "/probes/orphan-TCP-80")},
+					},
+				},
+				{
+					ID: ptr.To(lbID + "/loadBalancingRules/orphan-TCP-443"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(fipID("orphan"))},
+						Probe:                   &armnetwork.SubResource{ID: ptr.To(lbID + "/probes/shared")},
+					},
+				},
+				{
+					ID: ptr.To(lbID + "/loadBalancingRules/internal-TCP-80"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(fipID("orphan-internal"))},
+					},
+				},
+			},
+			Probes: []*armnetwork.Probe{
+				{ID: ptr.To(lbID + "/probes/orphan-TCP-80")},
+				{ID: ptr.To(lbID + "/probes/shared")},
+				{ID: ptr.To(lbID + "/probes/unused")},
+			},
+		},
+	}
+
+	orphanedOwners := map[string]string{
+		strings.ToLower(pipID + "orphan"):          "default/deleted",
+		strings.ToLower(pipID + "orphan-outbound"): "default/deleted",
+		strings.ToLower(fipID("orphan-pls")):       "default/deleted-pls",
+	}
+	orphans := findOrphanedLoadBalancerSubResources(lb, orphanedOwners, utilsets.NewString(fipID("orphan-pls")))
+	assert.Equal(t, []orphanedResource{
+		{resourceType: orphanedResourceTypeLoadBalancingRule, id: lbID + "/loadBalancingRules/orphan-TCP-80", owner: "default/deleted"},
+		{resourceType: orphanedResourceTypeLoadBalancingRule, id: lbID + "/loadBalancingRules/orphan-TCP-443", owner: "default/deleted"},
+		{resourceType: orphanedResourceTypeProbe, id: lbID + "/probes/orphan-TCP-80", owner: "default/deleted"},
+		{resourceType: orphanedResourceTypeFrontendIPConfiguration, id: fipID("orphan"), owner: "default/deleted"},
+	}, orphans)
+}
+
+func TestOrphanedResourceGarbageCollectorCollect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		description string
+		reportOnly  bool
+	}{
+		{
+			description: "should delete the orphaned resources after the grace period",
+		},
+		{
+			description: "should not delete the orphaned resources in report only mode",
+			reportOnly:  true,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			az.OrphanedResourceGCReportOnly = tc.reportOnly
+
+			liveService := getTestService("live", v1.ProtocolTCP, nil, false, 80)
+			liveService.UID = types.UID("11111111-2222-3333-4444-555555555555")
+			liveService.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "5.6.7.8"}}
+			deletedService := getTestService("deleted", v1.ProtocolTCP, nil, false, 80)
+			deletedService.UID = types.UID("99999999-8888-7777-6666-555555555555")
+			az.KubeClient = fake.NewSimpleClientset(&liveService)
+
+			livePrefix := cloudprovider.DefaultLoadBalancerName(&liveService)
+			orphanPrefix := cloudprovider.DefaultLoadBalancerName(&deletedService)
+			lbID := fmt.Sprintf("/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/%s", testClusterName)
+			liveFIPID := lbID + "/frontendIPConfigurations/" + livePrefix
+			orphanFIPID := lbID + "/frontendIPConfigurations/" + orphanPrefix
+			livePIPID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/live"
+			orphanPIPID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/orphan"
+			newLB := func() *armnetwork.LoadBalancer {
+				return &armnetwork.LoadBalancer{
+					Name: ptr.To(testClusterName),
+					Properties: &armnetwork.LoadBalancerPropertiesFormat{
+						FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+							{
+								Name:       ptr.To(livePrefix),
+								ID:         ptr.To(liveFIPID),
+								Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PublicIPAddress: &armnetwork.PublicIPAddress{ID: ptr.To(livePIPID)}},
+							},
+							{
+								Name:       ptr.To(orphanPrefix),
+								ID:         ptr.To(orphanFIPID),
+								Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PublicIPAddress: &armnetwork.PublicIPAddress{ID: ptr.To(orphanPIPID)}},
+							},
+						},
+						LoadBalancingRules: []*armnetwork.LoadBalancingRule{
+							{
+								Name:       ptr.To(livePrefix + "-TCP-80"),
+								ID:         ptr.To(lbID + "/loadBalancingRules/" + livePrefix + "-TCP-80"),
+								Properties: &armnetwork.LoadBalancingRulePropertiesFormat{FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(liveFIPID)}},
+							},
+							{
+								Name: ptr.To(orphanPrefix + "-TCP-80"),
+								ID:   ptr.To(lbID + "/loadBalancingRules/" + orphanPrefix + "-TCP-80"),
+								Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+									FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(orphanFIPID)},
+									Probe:                   &armnetwork.SubResource{ID: ptr.To(lbID + "/probes/" + orphanPrefix + "-TCP-80")},
+								},
+							},
+						},
+						Probes: []*armnetwork.Probe{
+							{Name: ptr.To(orphanPrefix + "-TCP-80"), ID: ptr.To(lbID + "/probes/" + orphanPrefix + "-TCP-80")},
+						},
+					},
+				}
+			}
+			mockLBClient := az.NetworkClientFactory.GetLoadBalancerClient().(*mock_loadbalancerclient.MockInterface)
+			mockLBClient.EXPECT().List(gomock.Any(), "rg").DoAndReturn(func(_ context.Context, _ string) ([]*armnetwork.LoadBalancer, error) {
+				return []*armnetwork.LoadBalancer{newLB(), {Name: ptr.To("other"), Properties: &armnetwork.LoadBalancerPropertiesFormat{}}}, nil
+			}).AnyTimes()
+			mockLBClient.EXPECT().Get(gomock.Any(), "rg", testClusterName, gomock.Any()).DoAndReturn(func(_ context.Context, _, _ string, _ *string) (*armnetwork.LoadBalancer, error) {
+				return newLB(), nil
+			}).AnyTimes()
+
+			mockPIPClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+			mockPIPClient.EXPECT().List(gomock.Any(), "rg").DoAndReturn(func(_ context.Context, _ string) ([]*armnetwork.PublicIPAddress, error) {
+				return []*armnetwork.PublicIPAddress{
+					{
+						Name: ptr.To("orphan"),
+						ID:   ptr.To(orphanPIPID),
+						Tags: map[string]*string{
+							consts.ServiceTagKey:  ptr.To("default/deleted"),
+							consts.ClusterNameKey: ptr.To(testClusterName),
+						},
+						Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+							IPAddress:       ptr.To("1.2.3.4"),
+							IPConfiguration: &armnetwork.IPConfiguration{ID: ptr.To(orphanFIPID)},
+						},
+					},
+					{
+						Name: ptr.To("live"),
+						ID:   ptr.To(livePIPID),
+						Tags: map[string]*string{
+							consts.ServiceTagKey:  ptr.To("default/live,default/deleted"),
+							consts.ClusterNameKey: ptr.To(testClusterName),
+						},
+						Properties: &armnetwork.PublicIPAddressPropertiesFormat{IPAddress: ptr.To("5.6.7.8")},
+					},
+					{
+						Name: ptr.To("other-cluster"),
+						ID:   ptr.To("/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/other-cluster"),
+						Tags: map[string]*string{
+							consts.ServiceTagKey:  ptr.To("default/deleted"),
+							consts.ClusterNameKey: ptr.To("other"),
+						},
+						Properties: &armnetwork.PublicIPAddressPropertiesFormat{IPAddress: ptr.To("9.9.9.9")},
+					},
+				}, nil
+			}).AnyTimes()
+
+			mockSGClient := az.NetworkClientFactory.GetSecurityGroupClient().(*mock_securitygroupclient.MockInterface)
+			mockSGClient.EXPECT().Get(gomock.Any(), "rg", "nsg").DoAndReturn(func(_ context.Context, _, _ string) (*armnetwork.SecurityGroup, error) {
+				return &armnetwork.SecurityGroup{
+					Name: ptr.To("nsg"),
+					ID:   ptr.To("/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/nsg"),
+					Properties: &armnetwork.SecurityGroupPropertiesFormat{
+						SecurityRules: []*armnetwork.SecurityRule{
+							{
+								Name: ptr.To("k8s-azure-lb_allow_IPv4_test"),
+								Properties: &armnetwork.SecurityRulePropertiesFormat{
+									Protocol:                   ptr.To(armnetwork.SecurityRuleProtocolTCP),
+									Access:                     ptr.To(armnetwork.SecurityRuleAccessAllow),
+									Direction:                  ptr.To(armnetwork.SecurityRuleDirectionInbound),
+									Priority:                   ptr.To(int32(500)),
+									SourceAddressPrefix:        ptr.To("Internet"),
+									SourcePortRange:            ptr.To("*"),
+									DestinationAddressPrefixes: []*string{ptr.To("1.2.3.4"), ptr.To("5.6.7.8")},
+									DestinationPortRanges:      []*string{ptr.To("80")},
+								},
+							},
+						},
+					},
+				}, nil
+			}).AnyTimes()
+
+			orphanPLSID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/orphan-pls"
+			mockPLSRepo := az.plsRepo.(*privatelinkservice.MockRepository)
+			mockPLSRepo.EXPECT().List(gomock.Any(), "rg").Return([]*armnetwork.PrivateLinkService{
+				{
+					Name: ptr.To("orphan-pls"),
+					ID:   ptr.To(orphanPLSID),
+					Tags: map[string]*string{
+						consts.OwnerServiceTagKey: ptr.To("default/deleted"),
+						consts.ClusterNameTagKey:  ptr.To(testClusterName),
+					},
+					Properties: &armnetwork.PrivateLinkServiceProperties{
+						LoadBalancerFrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{{ID: ptr.To(orphanFIPID)}},
+						PrivateEndpointConnections:           []*armnetwork.PrivateEndpointConnection{{Name: ptr.To("pe-conn")}},
+					},
+				},
+				{
+					Name: ptr.To("live-pls"),
+					ID:   ptr.To("/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/live-pls"),
+					Tags: map[string]*string{
+						consts.OwnerServiceTagKey: ptr.To("default/live"),
+						consts.ClusterNameTagKey:  ptr.To(testClusterName),
+					},
+					Properties: &armnetwork.PrivateLinkServiceProperties{
+						LoadBalancerFrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{{ID: ptr.To(liveFIPID)}},
+					},
+				},
+			}, nil).AnyTimes()
+
+			now := time.Now()
+			gc := NewOrphanedResourceGarbageCollector(az, testClusterName)
+			gc.now = func() time.Time { return now }
+
+			// The orphaned resources are found but not deleted within the grace period.
+			assert.NoError(t, gc.collect(context.Background()))
+			assert.Equal(t, 6, len(gc.firstSeen))
+			assert.Contains(t, gc.firstSeen, "/subscriptions/subscription/resourcegroups/rg/providers/microsoft.network/networksecuritygroups/nsg/1.2.3.4")
+			assert.Contains(t, gc.firstSeen, strings.ToLower(fmt.Sprintf("%s/probes/%s-TCP-80", lbID, orphanPrefix)))
+			cm, err := az.KubeClient.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(context.Background(), consts.OrphanedResourceGCStateConfigMapName, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Contains(t, cm.Data[consts.OrphanedResourceGCStateConfigMapKey], strings.ToLower(orphanPIPID))
+
+			// The grace period is kept after the restart.
+			now = now.Add(time.Duration(consts.DefaultOrphanedResourceGCGracePeriodInSeconds) * time.Second)
+			gc = NewOrphanedResourceGarbageCollector(az, testClusterName)
+			gc.now = func() time.Time { return now }
+			if !tc.reportOnly {
+				mockPLSRepo.EXPECT().DeletePEConnection(gomock.Any(), "rg", "orphan-pls", "pe-conn").Return(nil)
+				mockPLSRepo.EXPECT().Delete(gomock.Any(), "rg", "orphan-pls", orphanFIPID).Return(nil)
+				mockLBClient.EXPECT().CreateOrUpdate(gomock.Any(), "rg", testClusterName, gomock.Any()).DoAndReturn(func(_ context.Context, _, _ string, lb armnetwork.LoadBalancer) (*armnetwork.LoadBalancer, error) {
+					assert.Len(t, lb.Properties.FrontendIPConfigurations, 1)
+					assert.Equal(t, liveFIPID, *lb.Properties.FrontendIPConfigurations[0].ID)
+					assert.Len(t, lb.Properties.LoadBalancingRules, 1)
+					assert.Empty(t, lb.Properties.Probes)
+					return &lb, nil
+				})
+				mockSGClient.EXPECT().CreateOrUpdate(gomock.Any(), "rg", "nsg", gomock.Any()).DoAndReturn(func(_ context.Context, _, _ string, sg armnetwork.SecurityGroup) (*armnetwork.SecurityGroup, error) {
+					assert.Len(t, sg.Properties.SecurityRules, 1)
+					assert.Equal(t, "5.6.7.8", ptr.Deref(sg.Properties.SecurityRules[0].Properties.DestinationAddressPrefix, ""))
+					return &sg, nil
+				})
+				mockPIPClient.EXPECT().Delete(gomock.Any(), "rg", "orphan").Return(nil)
+			}
+			assert.NoError(t, gc.collect(context.Background()))
+		})
+	}
+}
+
+func TestOrphanedResourceGarbageCollectorObserve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.OrphanedResourceGCGracePeriodInSeconds = 60
+	gc := NewOrphanedResourceGarbageCollector(az, testClusterName)
+	now := time.Now()
+	gc.now = func() time.Time { return now }
+
+	pip := orphanedResource{resourceType: orphanedResourceTypePublicIPAddress, id: "PIP"}
+	rule := orphanedResource{resourceType: orphanedResourceTypeLoadBalancingRule, id: "rule"}
+	assert.Empty(t, gc.observe(context.Background(), []orphanedResource{pip, rule}))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, []orphanedResource{pip}, gc.observe(context.Background(), []orphanedResource{pip}))
+	assert.Equal(t, map[string]time.Time{"pip": now.Add(-time.Minute)}, gc.firstSeen)
+
+	// The rule has been found again after it is no longer orphaned.
+	assert.Empty(t, gc.observe(context.Background(), []orphanedResource{rule}))
+}
+
+func TestOrphanedResourceGarbageCollectorDeletePublicIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newPIP := func(serviceTag string, ipConfigID *string) *armnetwork.PublicIPAddress {
+		pip := &armnetwork.PublicIPAddress{
+			Name: ptr.To("orphan"),
+			ID:   ptr.To("/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/orphan"),
+			Tags: map[string]*string{
+				consts.ServiceTagKey:  ptr.To(serviceTag),
+				consts.ClusterNameKey: ptr.To(testClusterName),
+			},
+			Properties: &armnetwork.PublicIPAddressPropertiesFormat{IPAddress: ptr.To("1.2.3.4")},
+		}
+		if ipConfigID != nil {
+			pip.Properties.IPConfiguration = &armnetwork.IPConfiguration{ID: ipConfigID}
+		}
+		return pip
+	}
+
+	for _, tc := range []struct {
+		description   string
+		current       *armnetwork.PublicIPAddress
+		expectDeleted bool
+	}{
+		{
+			description:   "should delete the public IP which is still orphaned",
+			current:       newPIP("default/deleted", nil),
+			expectDeleted: true,
+		},
+		{
+			description: "should keep the public IP adopted by a new service",
+			current:     newPIP("default/deleted,default/new", nil),
+		},
+		{
+			description: "should keep the public IP referenced after it is found orphaned",
+			current:     newPIP("default/deleted", ptr.To("fip")),
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			mockPIPClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+			mockPIPClient.EXPECT().List(gomock.Any(), "rg").Return([]*armnetwork.PublicIPAddress{tc.current}, nil)
+			if tc.expectDeleted {
+				mockPIPClient.EXPECT().Delete(gomock.Any(), "rg", "orphan").Return(nil)
+			}
+
+			gc := NewOrphanedResourceGarbageCollector(az, testClusterName)
+			deleted, err := gc.deletePublicIP(context.Background(), "rg", newPIP("default/deleted", nil), utilsets.NewString())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectDeleted, deleted)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"k8s.io/klog/v2"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/loadbalancer"
+)
+
+// newIPGroupCache caches the IP Groups allowed by the services. The key is the lower-case IP Group ID.
+func (az *Cloud) newIPGroupCache() (azcache.Resource, error) {
+	getter := func(ctx context.Context, key string) (interface{}, error) {
+		resourceID, err := arm.ParseResourceID(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP Group ID %q: %w", key, err)
+		}
+		if !strings.EqualFold(resourceID.SubscriptionID, az.getNetworkResourceSubscriptionID()) {
+			return nil, fmt.Errorf("IP Group %s is not in the network resource subscription %s", key, az.getNetworkResourceSubscriptionID())
+		}
+
+		ipGroup, err := az.NetworkClientFactory.GetIPGroupClient().Get(ctx, resourceID.ResourceGroupName, resourceID.Name, nil)
+		exists, rerr := checkResourceExistsFromError(err)
+		if rerr != nil {
+			return nil, rerr
+		}
+		if !exists {
+			klog.V(2).Infof("IP Group %s not found", key)
+			return nil, nil
+		}
+		return ipGroup, nil
+	}
+
+	if az.IPGroupCacheTTLInSeconds == 0 {
+		az.IPGroupCacheTTLInSeconds = ipGroupCacheTTLDefaultInSeconds
+	}
+	return azcache.NewTimedCache(time.Duration(az.IPGroupCacheTTLInSeconds)*time.Second, getter, az.Config.DisableAPICallCache)
+}
+
+// getIPGroupAddresses returns the addresses of the IP Group, which can be CIDRs, IP ranges or single IPs.
+func (az *Cloud) getIPGroupAddresses(ctx context.Context, ipGroupID string, crt azcache.AzureCacheReadType) ([]string, error) {
+	cached, err := az.ipGroupCache.Get(ctx, strings.ToLower(ipGroupID), crt)
+	if err != nil {
+		return nil, err
+	}
+	if cached == nil {
+		return nil, fmt.Errorf("IP Group %s not found", ipGroupID)
+	}
+
+	ipGroup := cached.(*armnetwork.IPGroup)
+	if ipGroup.Properties == nil {
+		return nil, nil
+	}
+	addresses := make([]string, 0, len(ipGroup.Properties.IPAddresses))
+	for _, address := range ipGroup.Properties.IPAddresses {
+		if address != nil {
+			addresses = append(addresses, *address)
+		}
+	}
+	return addresses, nil
+}
+
+// ipGroupResolver returns the resolver used by the access control to get the addresses of the allowed IP Groups.
+func (az *Cloud) ipGroupResolver(ctx context.Context) loadbalancer.IPGroupResolver {
+	return func(ipGroupID string) ([]string, error) {
+		return az.getIPGroupAddresses(ctx, ipGroupID, azcache.CacheReadTypeDefault)
+	}
+}
+
+// getIPGroupsRevision returns the fingerprint of the addresses of the IP Groups.
+func getIPGroupsRevision(ipGroupIDs []string, addressesByID map[string][]string) string {
+	ids := make([]string, 0, len(ipGroupIDs))
+	for _, id := range ipGroupIDs {
+		ids = append(ids, strings.ToLower(id))
+	}
+	sort.Strings(ids)
+
+	var b strings.Builder
+	for _, id := range ids {
+		addresses := append([]string{}, addressesByID[id]...)
+		sort.Strings(addresses)
+		b.WriteString(id)
+		b.WriteString("=")
+		b.WriteString(strings.Join(addresses, ","))
+		b.WriteString(";")
+	}
+	sum := md5.Sum([]byte(b.String())) //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
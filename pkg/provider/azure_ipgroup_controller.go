@@ -0,0 +1,236 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/klog/v2"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/loadbalancer"
+)
+
+// IPGroupController reconciles the services again when the addresses of their allowed IP Groups change,
+// which updates the security rules with the new addresses.
+//
+// The IP Groups are refreshed periodically and the fingerprints of their addresses are kept in memory.
+// The services are not reconciled for the fingerprints found for the first time, because all the
+// services are reconciled by the service controller when the controller manager starts.
+type IPGroupController struct {
+	balancer    cloudprovider.LoadBalancer
+	az          *Cloud
+	clusterName string
+
+	serviceLister  corelisters.ServiceLister
+	servicesSynced cache.InformerSynced
+	nodeLister     corelisters.NodeLister
+	nodesSynced    cache.InformerSynced
+
+	queue    workqueue.TypedRateLimitingInterface[string]
+	interval time.Duration
+	// revisions are the fingerprints of the addresses of the IP Groups allowed by the services, keyed by the service key.
+	revisions map[string]string
+}
+
+// NewIPGroupController creates a new IPGroupController.
+func NewIPGroupController(
+	az *Cloud,
+	serviceInformer coreinformers.ServiceInformer,
+	nodeInformer coreinformers.NodeInformer,
+	clusterName string,
+) *IPGroupController {
+	return &IPGroupController{
+		balancer:       az,
+		az:             az,
+		clusterName:    clusterName,
+		serviceLister:  serviceInformer.Lister(),
+		servicesSynced: serviceInformer.Informer().HasSynced,
+		nodeLister:     nodeInformer.Lister(),
+		nodesSynced:    nodeInformer.Informer().HasSynced,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: consts.IPGroupControllerName},
+		),
+		interval:  time.Duration(az.IPGroupResyncIntervalInSeconds) * time.Second,
+		revisions: make(map[string]string),
+	}
+}
+
+// Run starts the IPGroupController, and stops if the context exits.
+func (c *IPGroupController) Run(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	if c.interval <= 0 {
+		klog.V(2).Infof("IPGroupController.Run: the IP Groups resync is disabled")
+		return
+	}
+
+	if !cache.WaitForNamedCacheSync(consts.IPGroupControllerName, ctx.Done(), c.servicesSynced, c.nodesSynced) {
+		return
+	}
+
+	klog.Infof("IPGroupController.Run: started with interval %s", c.interval)
+	go wait.UntilWithContext(ctx, c.worker, time.Second)
+	wait.UntilWithContext(ctx, c.resync, c.interval)
+	klog.Infof("IPGroupController.Run: stopped due to %s", ctx.Err().Error())
+}
+
+// isServiceManaged returns true if the load balancer of the service is reconciled by the cloud provider.
+func (c *IPGroupController) isServiceManaged(service *v1.Service) bool {
+	return service.Spec.Type == v1.ServiceTypeLoadBalancer &&
+		service.DeletionTimestamp == nil &&
+		c.az.IsLoadBalancerClassManaged(service.Spec.LoadBalancerClass)
+}
+
+// resync refreshes the IP Groups allowed by the managed services, and enqueues the services whose
+// fingerprint of the IP Group addresses changes.
+func (c *IPGroupController) resync(ctx context.Context) {
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("IPGroupController.resync: failed to list services: %s", err.Error())
+		return
+	}
+
+	addressesByID := make(map[string][]string)
+	failedIDs := make(map[string]bool)
+	found := make(map[string]bool)
+	for _, service := range services {
+		if !c.isServiceManaged(service) {
+			continue
+		}
+		ipGroupIDs, err := loadbalancer.AllowedIPGroups(service)
+		if err != nil || len(ipGroupIDs) == 0 {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(service)
+		if err != nil {
+			continue
+		}
+		found[key] = true
+
+		resolved := true
+		for _, id := range ipGroupIDs {
+			idKey := strings.ToLower(id)
+			if _, ok := addressesByID[idKey]; ok {
+				continue
+			}
+			if failedIDs[idKey] {
+				resolved = false
+				continue
+			}
+			addresses, err := c.az.getIPGroupAddresses(ctx, id, azcache.CacheReadTypeForceRefresh)
+			if err != nil {
+				klog.Errorf("IPGroupController.resync: failed to get IP Group %s: %s", id, err.Error())
+				failedIDs[idKey] = true
+				resolved = false
+				continue
+			}
+			addressesByID[idKey] = addresses
+		}
+		if !resolved {
+			continue
+		}
+
+		revision := getIPGroupsRevision(ipGroupIDs, addressesByID)
+		previous, ok := c.revisions[key]
+		c.revisions[key] = revision
+		if ok && previous != revision {
+			klog.V(2).Infof("IPGroupController.resync: the allowed IP Groups of service %s changed", key)
+			c.queue.Add(key)
+		}
+	}
+
+	for key := range c.revisions {
+		if !found[key] {
+			delete(c.revisions, key)
+		}
+	}
+}
+
+func (c *IPGroupController) worker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *IPGroupController) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncService(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("error processing service %s (retrying with exponential backoff): %w", key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// syncService ensures the load balancer of the service with the given key, so that the security
+// rules are updated with the new addresses of the IP Groups.
+func (c *IPGroupController) syncService(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	service, err := c.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !c.isServiceManaged(service) {
+		return nil
+	}
+
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	var lbNodes []*v1.Node
+	for _, node := range nodes {
+		if isLoadBalancerClassNodeIncluded(node) {
+			lbNodes = append(lbNodes, node)
+		}
+	}
+
+	c.az.Event(service, v1.EventTypeNormal, "AllowedIPGroupsChanged", "Ensuring load balancer because the addresses of the allowed IP Groups changed")
+	if _, err := c.balancer.EnsureLoadBalancer(ctx, c.clusterName, service, lbNodes); err != nil {
+		c.az.Event(service, v1.EventTypeWarning, "SyncLoadBalancerFailed", fmt.Sprintf("Error syncing load balancer: %v", err))
+		return fmt.Errorf("failed to ensure load balancer: %w", err)
+	}
+	return nil
+}
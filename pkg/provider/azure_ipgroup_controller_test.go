@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/ipgroupclient/mock_ipgroupclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+func TestIPGroupControllerResync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.IPGroupResyncIntervalInSeconds = consts.DefaultIPGroupResyncIntervalInSeconds
+	newService := func(name string, annotations map[string]string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		}
+	}
+	svc1 := newService("svc1", map[string]string{consts.ServiceAnnotationAllowedIPGroups: testIPGroupID})
+	svc2 := newService("svc2", map[string]string{consts.ServiceAnnotationAllowedIPGroups: testIPGroupID})
+	svc3 := newService("svc3", nil)
+	// The services of the classes not managed by the cloud provider are skipped.
+	svc4 := newService("svc4", map[string]string{consts.ServiceAnnotationAllowedIPGroups: testIPGroupID})
+	svc4.Spec.LoadBalancerClass = ptr.To("example.com/other")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	kubeClient := fake.NewSimpleClientset(svc1, svc2, svc3, svc4, node)
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	c := NewIPGroupController(az, informerFactory.Core().V1().Services(), informerFactory.Core().V1().Nodes(), testClusterName)
+	defer c.queue.ShutDown()
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+	balancer := &fakeClassBalancer{}
+	c.balancer = balancer
+
+	ipGroupClient := az.NetworkClientFactory.GetIPGroupClient().(*mock_ipgroupclient.MockInterface)
+	getIPGroup := func(addresses ...string) *armnetwork.IPGroup {
+		ipGroup := &armnetwork.IPGroup{Properties: &armnetwork.IPGroupPropertiesFormat{}}
+		for _, address := range addresses {
+			ipGroup.Properties.IPAddresses = append(ipGroup.Properties.IPAddresses, ptr.To(address))
+		}
+		return ipGroup
+	}
+	gomock.InOrder(
+		ipGroupClient.EXPECT().Get(gomock.Any(), "ipg-rg", "ipg", nil).Return(getIPGroup("10.0.1.1", "10.0.0.0/24"), nil),
+		ipGroupClient.EXPECT().Get(gomock.Any(), "ipg-rg", "ipg", nil).Return(getIPGroup("10.0.0.0/24", "10.0.1.1"), nil),
+		ipGroupClient.EXPECT().Get(gomock.Any(), "ipg-rg", "ipg", nil).Return(getIPGroup("10.0.0.0/24"), nil),
+	)
+
+	// The services are not reconciled for the revisions found for the first time.
+	c.resync(context.Background())
+	assert.Equal(t, 0, c.queue.Len())
+	assert.Len(t, c.revisions, 2)
+	assert.Contains(t, c.revisions, "default/svc1")
+	assert.Contains(t, c.revisions, "default/svc2")
+
+	// The order of the addresses does not matter.
+	c.resync(context.Background())
+	assert.Equal(t, 0, c.queue.Len())
+
+	c.resync(context.Background())
+	assert.Equal(t, 2, c.queue.Len())
+	for c.queue.Len() > 0 {
+		assert.True(t, c.processNextWorkItem(context.Background()))
+	}
+	assert.ElementsMatch(t, []string{"svc1", "svc2"}, balancer.ensured)
+	assert.Equal(t, []string{"node1", "node1"}, balancer.ensuredNodes)
+
+	// The annotations of the services are not changed.
+	svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "svc1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{consts.ServiceAnnotationAllowedIPGroups: testIPGroupID}, svc.Annotations)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/ipgroupclient/mock_ipgroupclient"
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+)
+
+const testIPGroupID = "/subscriptions/subscription/resourceGroups/ipg-rg/providers/Microsoft.Network/ipGroups/ipg"
+
+func TestGetIPGroupAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	ipGroupClient := az.NetworkClientFactory.GetIPGroupClient().(*mock_ipgroupclient.MockInterface)
+	ipGroupClient.EXPECT().Get(gomock.Any(), "ipg-rg", "ipg", nil).Return(&armnetwork.IPGroup{
+		Properties: &armnetwork.IPGroupPropertiesFormat{
+			IPAddresses: []*string{ptr.To("10.0.0.0/24"), ptr.To("10.0.1.1")},
+		},
+	}, nil).Times(1)
+
+	resolver := az.ipGroupResolver(context.Background())
+	addresses, err := resolver(testIPGroupID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/24", "10.0.1.1"}, addresses)
+
+	// the second read should hit the cache
+	addresses, err = az.getIPGroupAddresses(context.Background(), testIPGroupID, azcache.CacheReadTypeDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/24", "10.0.1.1"}, addresses)
+
+	_, err = resolver("/subscriptions/other/resourceGroups/ipg-rg/providers/Microsoft.Network/ipGroups/ipg")
+	assert.Error(t, err, "IP Groups in other subscriptions are not supported")
+}
@@ -29,6 +29,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
 	"github.com/samber/lo"
@@ -137,9 +138,23 @@ func (az *Cloud) reconcileService(ctx context.Context, clusterName string, servi
 
 	logger.V(2).Info("Start reconciling Service", "lb", az.GetLoadBalancerName(ctx, clusterName, service))
 
+	if err := az.checkServiceBackendZones(service); err != nil {
+		logger.Error(err, "Unsupported backend zones")
+		setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, err)
+		return nil, err
+	}
+
+	// Take the pre-provisioned public IPs from the pool before the frontend IP configurations are reconciled.
+	if err := az.assignPublicIPsFromPool(ctx, clusterName, service); err != nil {
+		logger.Error(err, "Failed to assign PublicIPs from the pool")
+		setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, err)
+		return nil, err
+	}
+
 	lb, err := az.reconcileLoadBalancer(ctx, clusterName, service, nodes, true /* wantLb */)
 	if err != nil {
 		logger.Error(err, "Failed to reconcile LoadBalancer")
+		setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, err)
 		return nil, err
 	}
 
@@ -147,26 +162,36 @@ func (az *Cloud) reconcileService(ctx context.Context, clusterName string, servi
 	if err != nil {
 		logger.Error(err, "Failed to get LoadBalancer status")
 		if !errors.Is(err, ErrorNotVmssInstance) {
+			setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, err)
 			return nil, err
 		}
 	}
 
 	if _, err := az.reconcileSecurityGroup(ctx, clusterName, service, ptr.Deref(lb.Name, ""), lbIPsPrimaryPIPs, true /* wantLb */); err != nil {
 		logger.Error(err, "Failed to reconcile SecurityGroup")
+		setServiceCondition(ctx, consts.ServiceConditionSecurityGroupReady, err)
 		return nil, err
 	}
+	setServiceCondition(ctx, consts.ServiceConditionSecurityGroupReady, nil)
 
 	for _, fipConfig := range fipConfigs {
 		if err := az.reconcilePrivateLinkService(ctx, clusterName, service, fipConfig, true /* wantPLS */); err != nil {
 			logger.Error(err, "Failed to reconcile PrivateLinkService")
+			setServiceCondition(ctx, consts.ServiceConditionPrivateLinkServiceReady, err)
 			return nil, err
 		}
 	}
+	if consts.IsPLSEnabled(service.Annotations) {
+		setServiceCondition(ctx, consts.ServiceConditionPrivateLinkServiceReady, nil)
+	} else {
+		removeServiceCondition(ctx, consts.ServiceConditionPrivateLinkServiceReady)
+	}
 
 	updateService := updateServiceLoadBalancerIPs(service, lbIPsPrimaryPIPs)
 	flippedService := flipServiceInternalAnnotation(updateService)
 	if _, err := az.reconcileLoadBalancer(ctx, clusterName, flippedService, nil, false /* wantLb */); err != nil {
 		logger.Error(err, "Failed to reconcile flipped LoadBalancer")
+		setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, err)
 		return nil, err
 	}
 
@@ -174,23 +199,33 @@ func (az *Cloud) reconcileService(ctx context.Context, clusterName string, servi
 	logger.V(2).Info("Reconciling PublicIPs")
 	if _, err := az.reconcilePublicIPs(ctx, clusterName, updateService, ptr.Deref(lb.Name, ""), true /* wantLb */); err != nil {
 		logger.Error(err, "Failed to reconcile PublicIPs")
+		setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, err)
 		return nil, err
 	}
 
+	if isLoadBalancerPlanContext(ctx) {
+		return lbStatus, nil
+	}
+
 	lbName := strings.ToLower(ptr.Deref(lb.Name, ""))
 	key := strings.ToLower(getServiceName(service))
-	if az.UseMultipleStandardLoadBalancers() && isLocalService(service) {
-		az.localServiceNameToServiceInfoMap.Store(key, newServiceInfo(getServiceIPFamily(service), lbName))
+	if az.useServiceBackendPool(service) {
+		si := newServiceInfo(getServiceIPFamily(service), lbName)
+		si.backendZones = getServiceBackendZones(service)
+		si.clusterName = clusterName
+		az.localServiceNameToServiceInfoMap.Store(key, si)
 		// There are chances that the endpointslice changes after EnsureHostsInPool, so
 		// need to check endpointslice for a second time.
 		if err := az.checkAndApplyLocalServiceBackendPoolUpdates(*lb, service); err != nil {
 			logger.Error(err, "Failed to checkAndApplyLocalServiceBackendPoolUpdates")
+			setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, err)
 			return nil, err
 		}
 	} else {
 		az.localServiceNameToServiceInfoMap.Delete(key)
 	}
 
+	setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, nil)
 	return lbStatus, nil
 }
 
@@ -270,7 +305,33 @@ func (az *Cloud) EnsureLoadBalancer(ctx context.Context, clusterName string, ser
 		}
 	}()
 
+	if !az.isServiceLoadBalancerClassManaged(service) {
+		logger.V(2).Info("Releasing the owned resources and skipping because the load balancer class is not managed by the cloud provider",
+			"loadBalancerClass", ptr.Deref(service.Spec.LoadBalancerClass, ""))
+		if err = az.reconcileServiceDeletion(ctx, clusterName, service); err != nil {
+			return nil, err
+		}
+		az.removeServiceConditions(ctx, service)
+		isOperationSucceeded = true
+		return nil, cloudprovider.ImplementedElsewhere
+	}
+
+	if consts.IsK8sServiceLoadBalancerPlanOnly(service) {
+		var plan *LoadBalancerPlan
+		plan, err = az.planLoadBalancer(ctx, clusterName, service, nodes)
+		if err != nil {
+			return nil, err
+		}
+		logger.V(2).Info("Skipping the changes because only the plan is requested", "plan", plan.String())
+		az.Event(service, v1.EventTypeNormal, "LoadBalancerPlan", plan.eventMessage())
+		isOperationSucceeded = true
+		// Keep the current status since none of the changes is made.
+		return service.Status.LoadBalancer.DeepCopy(), nil
+	}
+
+	ctx, conditions := newServiceConditionsContext(ctx, service)
 	lbStatus, err = az.reconcileService(ctx, clusterName, service, nodes)
+	az.updateServiceConditions(ctx, service, conditions)
 	if err != nil {
 		return nil, err
 	}
@@ -386,7 +447,26 @@ func (az *Cloud) UpdateLoadBalancer(ctx context.Context, clusterName string, ser
 		}()
 	}
 
+	if consts.IsK8sServiceLoadBalancerPlanOnly(service) {
+		isOperationSucceeded = true
+		logger.V(2).Info("Skipping because only the plan is requested")
+		return nil
+	}
+
+	if !az.isServiceLoadBalancerClassManaged(service) {
+		logger.V(2).Info("Releasing the owned resources and skipping because the load balancer class is not managed by the cloud provider",
+			"loadBalancerClass", ptr.Deref(service.Spec.LoadBalancerClass, ""))
+		if err = az.reconcileServiceDeletion(ctx, clusterName, service); err != nil {
+			return err
+		}
+		az.removeServiceConditions(ctx, service)
+		isOperationSucceeded = true
+		return cloudprovider.ImplementedElsewhere
+	}
+
+	ctx, conditions := newServiceConditionsContext(ctx, service)
 	_, err = az.reconcileService(ctx, clusterName, service, nodes)
+	az.updateServiceConditions(ctx, service, conditions)
 	if err != nil {
 		return err
 	}
@@ -465,6 +545,19 @@ func (az *Cloud) EnsureLoadBalancerDeleted(ctx context.Context, clusterName stri
 		}
 	}()
 
+	if err = az.reconcileServiceDeletion(ctx, clusterName, service); err != nil {
+		return err
+	}
+	az.removeServiceConditions(ctx, service)
+
+	isOperationSucceeded = true
+
+	return nil
+}
+
+// reconcileServiceDeletion releases the Azure resources owned by the service,
+// including the security rules, load balancer resources and public IPs.
+func (az *Cloud) reconcileServiceDeletion(ctx context.Context, clusterName string, service *v1.Service) error {
 	lb, _, _, lbIPsPrimaryPIPs, _, err := az.getServiceLoadBalancer(ctx, service, clusterName, nil, false, []*armnetwork.LoadBalancer{})
 	if err != nil && !errutils.HasStatusForbiddenOrIgnoredError(err) {
 		return err
@@ -490,16 +583,20 @@ func (az *Cloud) EnsureLoadBalancerDeleted(ctx context.Context, clusterName stri
 		return err
 	}
 
-	if az.UseMultipleStandardLoadBalancers() && isLocalService(service) {
-		key := strings.ToLower(svcName)
+	if az.useServiceBackendPool(service) && !isLoadBalancerPlanContext(ctx) {
+		key := strings.ToLower(getServiceName(service))
 		az.localServiceNameToServiceInfoMap.Delete(key)
 	}
 
-	isOperationSucceeded = true
-
 	return nil
 }
 
+// isServiceLoadBalancerClassManaged returns true if the service should be reconciled
+// by the cloud provider according to its `spec.loadBalancerClass`.
+func (az *Cloud) isServiceLoadBalancerClassManaged(service *v1.Service) bool {
+	return az.IsLoadBalancerClassManaged(service.Spec.LoadBalancerClass)
+}
+
 // GetLoadBalancerName returns the name of the load balancer. Implementations must treat the
 // *v1.Service parameter as read-only and not modify it.
 func (az *Cloud) GetLoadBalancerName(_ context.Context, _ string, service *v1.Service) string {
@@ -598,6 +695,21 @@ func (az *Cloud) removeFrontendIPConfigurationFromLoadBalancer(ctx context.Conte
 		}
 		lb.Properties.Probes = lbProbes
 	}
+	if lb.Properties.InboundNatRules != nil {
+		natRules := lb.Properties.InboundNatRules
+		for i := len(natRules) - 1; i >= 0; i-- {
+			if natRules[i].Properties == nil || natRules[i].Properties.FrontendIPConfiguration == nil {
+				continue
+			}
+			for _, fip := range fips {
+				if strings.EqualFold(ptr.Deref(natRules[i].Properties.FrontendIPConfiguration.ID, ""), ptr.Deref(fip.ID, "")) {
+					natRules = append(natRules[:i], natRules[i+1:]...)
+					break
+				}
+			}
+		}
+		lb.Properties.InboundNatRules = natRules
+	}
 
 	// PLS does not support IPv6 so there will not be additional API calls.
 	for _, fip := range fips {
@@ -720,7 +832,7 @@ func (az *Cloud) safeDeleteLoadBalancer(ctx context.Context, lb armnetwork.LoadB
 			lbBackendPoolIDsToDelete = append(lbBackendPoolIDsToDelete, ptr.Deref(bp.ID, ""))
 		}
 	}
-	if _, err := az.VMSet.EnsureBackendPoolDeleted(ctx, service, lbBackendPoolIDsToDelete, vmSetName, lb.Properties.BackendAddressPools, true); err != nil {
+	if _, err := az.ensureBackendPoolDeleted(ctx, service, lbBackendPoolIDsToDelete, vmSetName, lb.Properties.BackendAddressPools, true); err != nil {
 		return fmt.Errorf("safeDeleteLoadBalancer: failed to EnsureBackendPoolDeleted: %w", err)
 	}
 
@@ -828,7 +940,7 @@ func (az *Cloud) getServiceLoadBalancer(
 				ptr.Deref(existingLB.Name, ""),
 			)
 
-			if isLocalService(service) && az.UseMultipleStandardLoadBalancers() {
+			if az.useServiceBackendPool(service) {
 				// No need for the endpoint slice informer to update the backend pool
 				// for the service because the main loop will delete the old backend pool
 				// and create a new one in the new load balancer.
@@ -836,8 +948,10 @@ func (az *Cloud) getServiceLoadBalancer(
 				if az.backendPoolUpdater != nil {
 					az.backendPoolUpdater.removeOperation(svcName)
 				}
+			}
 
-				// Remove backend pools on the previous load balancer for the local service
+			if az.hasServiceBackendPool(service) {
+				// Remove backend pools on the previous load balancer for the service
 				if deletedLBName == "" {
 					newLBs, err := az.cleanupLocalServiceBackendPool(ctx, service, nodes, existingLBs, clusterName)
 					if err != nil {
@@ -1031,7 +1145,7 @@ func (az *Cloud) getServiceLoadBalancerStatus(ctx context.Context, service *v1.S
 
 			klog.V(2).Infof("getServiceLoadBalancerStatus gets ingress IP %q from frontendIPConfiguration %q for service %q", ptr.Deref(lbIP, ""), ptr.Deref(ipConfiguration.Name, ""), serviceName)
 
-			lbIngresses = append(lbIngresses, v1.LoadBalancerIngress{IP: ptr.Deref(lbIP, "")})
+			lbIngresses = append(lbIngresses, v1.LoadBalancerIngress{IP: ptr.Deref(lbIP, ""), IPMode: az.getServiceIngressIPMode(service)})
 			lbIPsPrimaryPIPs = append(lbIPsPrimaryPIPs, ptr.Deref(lbIP, ""))
 			fipConfigs = append(fipConfigs, ipConfiguration)
 		}
@@ -1048,7 +1162,8 @@ func (az *Cloud) getServiceLoadBalancerStatus(ctx context.Context, service *v1.S
 	if len(additionalIPs) > 0 {
 		for _, pip := range additionalIPs {
 			lbIngresses = append(lbIngresses, v1.LoadBalancerIngress{
-				IP: pip.String(),
+				IP:     pip.String(),
+				IPMode: az.getServiceIngressIPMode(service),
 			})
 		}
 	}
@@ -1072,7 +1187,20 @@ func (az *Cloud) determinePublicIPName(ctx context.Context, clusterName string,
 	// If a secondary service doesn't set the loadBalancerIP, it is not allowed to share the IP.
 	if len(loadBalancerIP) == 0 {
 		pipName, err := az.getPublicIPName(clusterName, service, isIPv6)
-		return pipName, false, err
+		if err != nil {
+			return "", false, err
+		}
+		// Use the public IP taken from the pool by assignPublicIPsFromPool if there is one.
+		if az.isServiceEligibleForPublicIPPool(service, isIPv6) {
+			poolPIPName, err := az.getPublicIPNameFromPool(ctx, clusterName, service, isIPv6)
+			if err != nil {
+				return "", false, err
+			}
+			if poolPIPName != "" {
+				return poolPIPName, false, nil
+			}
+		}
+		return pipName, false, nil
 	}
 
 	// For the services with loadBalancerIP set, an existing public IP is required, primary
@@ -1121,6 +1249,10 @@ func (az *Cloud) ensurePublicIPExists(ctx context.Context, service *v1.Service,
 		return nil, err
 	}
 	serviceName := getServiceName(service)
+	pipSettings, err := az.getServicePublicIPSettings(service)
+	if err != nil {
+		return nil, err
+	}
 	ipVersion := to.Ptr(armnetwork.IPVersionIPv4)
 	if isIPv6 {
 		ipVersion = to.Ptr(armnetwork.IPVersionIPv6)
@@ -1147,14 +1279,18 @@ func (az *Cloud) ensurePublicIPExists(ctx context.Context, service *v1.Service,
 				klog.V(6).Infof("ensurePublicIPExists for service(%s): pip(%s) - "+
 					"the service is using the DNS label on the public IP", serviceName, pipName)
 
+				if pip.Properties != nil && az.reconcileIPSettings(ctx, pip, service, pipSettings, isUserAssignedPIP, isIPv6) {
+					changed = true
+				}
+
 				var err error
 				if changed {
 					klog.V(2).Infof("ensurePublicIPExists: updating the PIP %s for the incoming service %s", pipName, serviceName)
-					err = az.CreateOrUpdatePIP(service, pipResourceGroup, pip)
+					err = az.CreateOrUpdatePIP(ctx, service, pipResourceGroup, pip)
 					if err != nil {
 						return nil, err
 					}
-					pip, err = az.NetworkClientFactory.GetPublicIPAddressClient().Get(ctx, pipResourceGroup, *pip.Name, nil)
+					pip, err = az.getLatestPublicIPAddress(ctx, pipResourceGroup, *pip.Name)
 					if err != nil {
 						return nil, err
 					}
@@ -1196,7 +1332,7 @@ func (az *Cloud) ensurePublicIPExists(ctx context.Context, service *v1.Service,
 		pip.Properties = &armnetwork.PublicIPAddressPropertiesFormat{
 			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
 			PublicIPAddressVersion:   ipVersion,
-			IPTags:                   getServiceIPTagRequestForPublicIP(service).IPTags,
+			IPTags:                   applyRoutingPreferenceToIPTags(getServiceIPTagRequestForPublicIP(service).IPTags, pipSettings.routingPreference),
 		}
 		pip.Tags = map[string]*string{
 			consts.ServiceTagKey:  ptr.To(""),
@@ -1210,6 +1346,9 @@ func (az *Cloud) ensurePublicIPExists(ctx context.Context, service *v1.Service,
 			pip.SKU = &armnetwork.PublicIPAddressSKU{
 				Name: to.Ptr(armnetwork.PublicIPAddressSKUNameStandard),
 			}
+			if pipSettings.tier != "" {
+				pip.SKU.Tier = to.Ptr(armnetwork.PublicIPAddressSKUTier(pipSettings.tier))
+			}
 
 			if id := getServicePIPPrefixID(service, isIPv6); id != "" {
 				pip.Properties.PublicIPPrefix = &armnetwork.SubResource{ID: ptr.To(id)}
@@ -1246,14 +1385,14 @@ func (az *Cloud) ensurePublicIPExists(ctx context.Context, service *v1.Service,
 
 	// use the same family as the clusterIP as we support IPv6 single stack as well
 	// as dual-stack clusters
-	updatedIPSettings := az.reconcileIPSettings(pip, service, isIPv6)
+	updatedIPSettings := az.reconcileIPSettings(ctx, pip, service, pipSettings, isUserAssignedPIP, isIPv6)
 	if updatedIPSettings {
 		changed = true
 	}
 
 	if changed {
 		klog.V(2).Infof("CreateOrUpdatePIP(%s, %q): start", pipResourceGroup, *pip.Name)
-		err = az.CreateOrUpdatePIP(service, pipResourceGroup, pip)
+		err = az.CreateOrUpdatePIP(ctx, service, pipResourceGroup, pip)
 		if err != nil {
 			klog.V(2).Infof("ensure(%s) abort backoff: pip(%s)", serviceName, *pip.Name)
 			return nil, err
@@ -1262,14 +1401,17 @@ func (az *Cloud) ensurePublicIPExists(ctx context.Context, service *v1.Service,
 		klog.V(10).Infof("CreateOrUpdatePIP(%s, %q): end", pipResourceGroup, *pip.Name)
 	}
 
-	pip, rerr := az.NetworkClientFactory.GetPublicIPAddressClient().Get(ctx, pipResourceGroup, *pip.Name, nil)
+	pip, rerr := az.getLatestPublicIPAddress(ctx, pipResourceGroup, *pip.Name)
 	if rerr != nil {
 		return nil, rerr
 	}
 	return pip, nil
 }
 
-func (az *Cloud) reconcileIPSettings(pip *armnetwork.PublicIPAddress, service *v1.Service, isIPv6 bool) bool {
+// reconcileIPSettings reconciles the IP version, the allocation method and the public IP settings of the public IP.
+// The DDoS settings of the user-assigned public IPs are not changed, and a warning event is emitted if the settings
+// cannot be applied to the public IP. It returns true if the public IP is changed.
+func (az *Cloud) reconcileIPSettings(ctx context.Context, pip *armnetwork.PublicIPAddress, service *v1.Service, settings *publicIPSettings, isUserAssignedPIP, isIPv6 bool) bool {
 	var changed bool
 
 	serviceName := getServiceName(service)
@@ -1298,6 +1440,28 @@ func (az *Cloud) reconcileIPSettings(pip *armnetwork.PublicIPAddress, service *v
 		}
 	}
 
+	var notApplied []string
+	if !areDDoSSettingsUpToDate(pip, settings) {
+		if isUserAssignedPIP {
+			notApplied = append(notApplied, fmt.Sprintf("DDoS protection mode %s", settings.ddosProtectionMode))
+		} else {
+			klog.V(2).Infof("service(%s): pip(%s) - updating the DDoS protection mode to %s", serviceName, *pip.Name, settings.ddosProtectionMode)
+			setDDoSSettings(pip, settings)
+			changed = true
+		}
+	}
+	// The immutable settings cannot be changed on an existing public IP, which is never recreated for them.
+	notApplied = append(notApplied, getPublicIPImmutableSettingsDrift(pip, settings)...)
+	if len(notApplied) > 0 {
+		warningMsg := fmt.Sprintf("The public IP %s of the service cannot be changed to: %s. The public IP is kept unchanged, recreate it or update the service annotations to match it.",
+			ptr.Deref(pip.Name, ""), strings.Join(notApplied, ", "))
+		klog.Warningf("service(%s): %s", serviceName, warningMsg)
+		// The plan is computed without any side effect, so the warning is only logged.
+		if !isLoadBalancerPlanContext(ctx) {
+			az.Event(service, v1.EventTypeWarning, "PublicIPSettingsNotApplied", warningMsg)
+		}
+	}
+
 	return changed
 }
 
@@ -1477,6 +1641,204 @@ func convertIPTagMapToSlice(ipTagMap map[string]string) []*armnetwork.IPTag {
 	return outputTags
 }
 
+// publicIPSettings are the settings of the managed public IPs from the service annotations or the cloud config.
+// An empty value means the setting is not managed.
+type publicIPSettings struct {
+	ddosProtectionMode   string
+	ddosProtectionPlanID string
+	routingPreference    string
+	tier                 string
+	// routingPreferenceFromAnnotation and tierFromAnnotation are true if the immutable settings are set by the
+	// service annotations. The defaults in the cloud config only apply to the new public IPs, so changing them
+	// does not recreate the existing public IPs.
+	routingPreferenceFromAnnotation bool
+	tierFromAnnotation              bool
+}
+
+// getServicePublicIPSettings returns the public IP settings of the service. The service annotations
+// override the defaults in the cloud config.
+func (az *Cloud) getServicePublicIPSettings(service *v1.Service) (*publicIPSettings, error) {
+	settings := az.getDefaultPublicIPSettings()
+	if service != nil {
+		if value, found := service.Annotations[consts.ServiceAnnotationPIPDDoSProtectionMode]; found {
+			settings.ddosProtectionMode = strings.TrimSpace(value)
+			// The plan in the cloud config does not apply to the mode from the annotation.
+			settings.ddosProtectionPlanID = ""
+		}
+		if value, found := service.Annotations[consts.ServiceAnnotationPIPDDoSProtectionPlanID]; found {
+			settings.ddosProtectionPlanID = strings.TrimSpace(value)
+		}
+		if value, found := service.Annotations[consts.ServiceAnnotationPIPRoutingPreference]; found {
+			settings.routingPreference = strings.TrimSpace(value)
+			settings.routingPreferenceFromAnnotation = true
+		}
+		if value, found := service.Annotations[consts.ServiceAnnotationPIPTier]; found {
+			settings.tier = strings.TrimSpace(value)
+			settings.tierFromAnnotation = true
+		}
+	}
+
+	if err := validatePublicIPSettings(settings, az.UseStandardLoadBalancer()); err != nil {
+		return nil, fmt.Errorf("invalid public IP settings of service %s: %w", getServiceName(service), err)
+	}
+	return settings, nil
+}
+
+// getDefaultPublicIPSettings returns the public IP settings in the cloud config.
+func (az *Cloud) getDefaultPublicIPSettings() *publicIPSettings {
+	return &publicIPSettings{
+		ddosProtectionMode:   az.PublicIPDDoSProtectionMode,
+		ddosProtectionPlanID: az.PublicIPDDoSProtectionPlanID,
+		routingPreference:    az.PublicIPRoutingPreference,
+		tier:                 az.PublicIPTier,
+	}
+}
+
+// validatePublicIPSettings checks the values of the public IP settings and normalizes their case.
+func validatePublicIPSettings(settings *publicIPSettings, useStandardLoadBalancer bool) error {
+	if settings.ddosProtectionMode != "" {
+		mode, ok := findInPossibleValues(settings.ddosProtectionMode, armnetwork.PossibleDdosSettingsProtectionModeValues())
+		if !ok {
+			return fmt.Errorf("unsupported DDoS protection mode %q", settings.ddosProtectionMode)
+		}
+		settings.ddosProtectionMode = mode
+	}
+	if settings.ddosProtectionPlanID != "" {
+		if !strings.EqualFold(settings.ddosProtectionMode, string(armnetwork.DdosSettingsProtectionModeEnabled)) {
+			return fmt.Errorf("the DDoS protection plan can only be set when the DDoS protection mode is %s", armnetwork.DdosSettingsProtectionModeEnabled)
+		}
+		resourceID, err := arm.ParseResourceID(settings.ddosProtectionPlanID)
+		if err != nil {
+			return fmt.Errorf("invalid DDoS protection plan ID %q: %w", settings.ddosProtectionPlanID, err)
+		}
+		if !strings.EqualFold(resourceID.ResourceType.String(), "Microsoft.Network/ddosProtectionPlans") {
+			return fmt.Errorf("invalid DDoS protection plan ID %q: unexpected resource type %s", settings.ddosProtectionPlanID, resourceID.ResourceType.String())
+		}
+	}
+	if settings.routingPreference != "" {
+		routingPreference, ok := findInPossibleValues(settings.routingPreference, []string{consts.PIPRoutingPreferenceMicrosoftNetwork, consts.PIPRoutingPreferenceInternet})
+		if !ok {
+			return fmt.Errorf("unsupported routing preference %q", settings.routingPreference)
+		}
+		settings.routingPreference = routingPreference
+	}
+	if settings.tier != "" {
+		tier, ok := findInPossibleValues(settings.tier, armnetwork.PossiblePublicIPAddressSKUTierValues())
+		if !ok {
+			return fmt.Errorf("unsupported public IP tier %q", settings.tier)
+		}
+		// The global public IPs can only be attached to the cross-region load balancers,
+		// while the load balancers of the services are regional.
+		if strings.EqualFold(tier, string(armnetwork.PublicIPAddressSKUTierGlobal)) {
+			return fmt.Errorf("the %s tier is not supported because the public IP cannot be attached to a regional load balancer",
+				armnetwork.PublicIPAddressSKUTierGlobal)
+		}
+		settings.tier = tier
+	}
+
+	if strings.EqualFold(settings.routingPreference, consts.PIPRoutingPreferenceInternet) && !useStandardLoadBalancer {
+		return fmt.Errorf("the %s routing preference can only be used with standard load balancer", consts.PIPRoutingPreferenceInternet)
+	}
+	return nil
+}
+
+// findInPossibleValues returns the possible value which equals the given value case-insensitively.
+func findInPossibleValues[T ~string](value string, possibleValues []T) (string, bool) {
+	for _, possibleValue := range possibleValues {
+		if strings.EqualFold(value, string(possibleValue)) {
+			return string(possibleValue), true
+		}
+	}
+	return "", false
+}
+
+// applyRoutingPreferenceToIPTags replaces the routing preference IP tag in the given IP tags according to the routing
+// preference. The `Internet` routing preference is the `RoutingPreference` IP tag, and `MicrosoftNetwork` is no tag.
+func applyRoutingPreferenceToIPTags(ipTags []*armnetwork.IPTag, routingPreference string) []*armnetwork.IPTag {
+	if routingPreference == "" {
+		return ipTags
+	}
+
+	result := []*armnetwork.IPTag{}
+	for _, ipTag := range ipTags {
+		if !strings.EqualFold(ptr.Deref(ipTag.IPTagType, ""), consts.IPTagTypeRoutingPreference) {
+			result = append(result, ipTag)
+		}
+	}
+	if strings.EqualFold(routingPreference, consts.PIPRoutingPreferenceInternet) {
+		result = append(result, &armnetwork.IPTag{
+			IPTagType: ptr.To(consts.IPTagTypeRoutingPreference),
+			Tag:       ptr.To(consts.PIPRoutingPreferenceInternet),
+		})
+	}
+	return result
+}
+
+// getPublicIPRoutingPreference returns the routing preference of the public IP from its IP tags.
+func getPublicIPRoutingPreference(pip *armnetwork.PublicIPAddress) string {
+	if pip.Properties != nil {
+		for _, ipTag := range pip.Properties.IPTags {
+			if strings.EqualFold(ptr.Deref(ipTag.IPTagType, ""), consts.IPTagTypeRoutingPreference) &&
+				strings.EqualFold(ptr.Deref(ipTag.Tag, ""), consts.PIPRoutingPreferenceInternet) {
+				return consts.PIPRoutingPreferenceInternet
+			}
+		}
+	}
+	return consts.PIPRoutingPreferenceMicrosoftNetwork
+}
+
+// getPublicIPImmutableSettingsDrift returns the settings from the service annotations which are different on
+// the public IP but cannot be changed on an existing public IP. The defaults in the cloud config are not checked.
+func getPublicIPImmutableSettingsDrift(pip *armnetwork.PublicIPAddress, settings *publicIPSettings) []string {
+	var drift []string
+	if settings == nil {
+		return drift
+	}
+
+	if settings.routingPreferenceFromAnnotation && settings.routingPreference != "" {
+		if current := getPublicIPRoutingPreference(pip); !strings.EqualFold(current, settings.routingPreference) {
+			drift = append(drift, fmt.Sprintf("routing preference %s (current %s)", settings.routingPreference, current))
+		}
+	}
+	if settings.tierFromAnnotation && settings.tier != "" {
+		current := string(armnetwork.PublicIPAddressSKUTierRegional)
+		if pip.SKU != nil && pip.SKU.Tier != nil {
+			current = string(*pip.SKU.Tier)
+		}
+		if !strings.EqualFold(current, settings.tier) {
+			drift = append(drift, fmt.Sprintf("tier %s (current %s)", settings.tier, current))
+		}
+	}
+	return drift
+}
+
+// areDDoSSettingsUpToDate returns true if the DDoS settings of the public IP are the same as the settings,
+// or the DDoS settings are not managed.
+func areDDoSSettingsUpToDate(pip *armnetwork.PublicIPAddress, settings *publicIPSettings) bool {
+	if settings == nil || settings.ddosProtectionMode == "" {
+		return true
+	}
+
+	var currentMode, currentPlanID string
+	if pip.Properties.DdosSettings != nil {
+		currentMode = string(ptr.Deref(pip.Properties.DdosSettings.ProtectionMode, ""))
+		if pip.Properties.DdosSettings.DdosProtectionPlan != nil {
+			currentPlanID = ptr.Deref(pip.Properties.DdosSettings.DdosProtectionPlan.ID, "")
+		}
+	}
+	return strings.EqualFold(currentMode, settings.ddosProtectionMode) && strings.EqualFold(currentPlanID, settings.ddosProtectionPlanID)
+}
+
+// setDDoSSettings sets the DDoS settings of the public IP from the settings.
+func setDDoSSettings(pip *armnetwork.PublicIPAddress, settings *publicIPSettings) {
+	pip.Properties.DdosSettings = &armnetwork.DdosSettings{
+		ProtectionMode: to.Ptr(armnetwork.DdosSettingsProtectionMode(settings.ddosProtectionMode)),
+	}
+	if settings.ddosProtectionPlanID != "" {
+		pip.Properties.DdosSettings.DdosProtectionPlan = &armnetwork.SubResource{ID: ptr.To(settings.ddosProtectionPlanID)}
+	}
+}
+
 func getDomainNameLabel(pip *armnetwork.PublicIPAddress) string {
 	if pip == nil || pip.Properties == nil || pip.Properties.DNSSettings == nil {
 		return ""
@@ -1778,8 +2140,9 @@ func (az *Cloud) reconcileLoadBalancer(ctx context.Context, clusterName string,
 
 	// Delete backend pools for local service if:
 	// 1. the cluster is migrating from multi-slb to single-slb,
-	// 2. the service is changed from local to cluster.
-	if !az.UseMultipleStandardLoadBalancers() || !isLocalService(service) {
+	// 2. the service is changed from local to cluster,
+	// 3. the backend zones annotation is removed from the service.
+	if !az.hasServiceBackendPool(service) {
 		existingLBs, err = az.cleanupLocalServiceBackendPool(ctx, service, nodes, existingLBs, clusterName)
 		if err != nil {
 			klog.Errorf("reconcileLoadBalancer: failed to cleanup local service backend pool for service %q, error: %s", serviceName, err.Error())
@@ -1831,6 +2194,18 @@ func (az *Cloud) reconcileLoadBalancer(ctx context.Context, clusterName string,
 		addOrUpdateLBInList(&existingLBs, lb)
 	}
 
+	// The per-node inbound NAT rules of the service are removed before the frontend IP
+	// configurations, which cannot be deleted while being referenced by them.
+	if !wantLb {
+		changed, err := az.reconcileNodeInboundNATRules(lb, service, wantLb, nil)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			dirtyLb = true
+		}
+	}
+
 	// reconcile the load balancer's frontend IP configurations.
 	ownedFIPConfigs, toDeleteConfigs, fipChanged, err := az.reconcileFrontendIPConfigs(ctx, clusterName, service, lb, lbStatus, wantLb, lbFrontendIPConfigNames)
 	if err != nil {
@@ -1898,6 +2273,15 @@ func (az *Cloud) reconcileLoadBalancer(ctx context.Context, clusterName string,
 	if changed := az.reconcileLBRules(lb, service, serviceName, wantLb, expectedRules); changed {
 		dirtyLb = true
 	}
+	if wantLb {
+		changed, err := az.reconcileNodeInboundNATRules(lb, service, wantLb, lbFrontendIPConfigIDs)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			dirtyLb = true
+		}
+	}
 	if changed := az.ensureLoadBalancerTagged(lb); changed {
 		dirtyLb = true
 	}
@@ -1951,7 +2335,49 @@ func (az *Cloud) reconcileLoadBalancer(ctx context.Context, clusterName string,
 		}
 	}
 
-	if wantLb && nodes != nil && !isBackendPoolPreConfigured {
+	if wantLb {
+		if err := az.persistInternalIPs(ctx, service, lb); err != nil {
+			klog.Errorf("reconcileLoadBalancer for service(%s): lb(%s) - failed to persist the private IPs: %v", serviceName, lbName, err)
+			return nil, err
+		}
+	}
+
+	// In pod IP mode, or if the backend nodes are restricted to some zones, the backend pools
+	// of the service are not referenced by any load balancing rule once the service is removed
+	// from the load balancer.
+	if !wantLb && (az.IsLBBackendPoolTypePodIP() || isServiceBackendNodesRestricted(service)) &&
+		az.hasServiceBackendPool(service) && !isLoadBalancerPlanContext(ctx) &&
+		lb.Properties != nil && len(lb.Properties.FrontendIPConfigurations) > 0 {
+		if _, err := az.cleanupLocalServiceBackendPool(ctx, service, nodes, []*armnetwork.LoadBalancer{lb}, clusterName); err != nil {
+			klog.Errorf("reconcileLoadBalancer for service(%s): lb(%s) - failed to cleanup the backend pools of the service: %v", serviceName, lbName, err)
+			return nil, err
+		}
+	}
+
+	// The backend pools of the per-node inbound NAT rules are deleted after the rules are removed.
+	if (!wantLb || !hasNodeInboundNATRules(service)) && !isLoadBalancerPlanContext(ctx) &&
+		lb.Properties != nil && len(lb.Properties.FrontendIPConfigurations) > 0 {
+		changed, err := az.cleanupNodeInboundNATBackendPools(ctx, service, lb, clusterName)
+		if err != nil {
+			klog.Errorf("reconcileLoadBalancer for service(%s): lb(%s) - failed to cleanup the backend pools of the inbound NAT rules: %v", serviceName, lbName, err)
+			return nil, err
+		}
+		if changed {
+			// Refresh the load balancer to update the etag.
+			newLB, exist, err := az.getAzureLoadBalancer(ctx, lbName, azcache.CacheReadTypeForceRefresh)
+			if err != nil {
+				return nil, err
+			}
+			if !exist {
+				return nil, fmt.Errorf("load balancer %q not found", lbName)
+			}
+			lb = newLB
+			addOrUpdateLBInList(&existingLBs, newLB)
+		}
+	}
+
+	// The membership of the nodes in the backend pools is not part of the plan.
+	if wantLb && nodes != nil && !isBackendPoolPreConfigured && !isLoadBalancerPlanContext(ctx) {
 		// Add the machines to the backend pool if they're not already
 		vmSetName := az.mapLoadBalancerNameToVMSet(lbName, clusterName)
 		// Etag would be changed when updating backend pools, so invalidate lbCache after it.
@@ -2006,6 +2432,45 @@ func (az *Cloud) reconcileBackendPoolHosts(
 		if lb.Properties != nil && lb.Properties.BackendAddressPools != nil {
 			for i, backendPool := range lb.Properties.BackendAddressPools {
 				isIPv6 := isBackendPoolIPv6(ptr.Deref(backendPool.Name, ""))
+				if strings.EqualFold(lbName, ptr.Deref(currentLB.Name, "")) && isNodeInboundNATBackendPool(service, ptr.Deref(backendPool.Name, "")) {
+					// The backend pool of the per-node inbound NAT rules has the nodes of the vmSet of the rules.
+					natNodes, ok, err := az.getNodeInboundNATBackendNodes(ctx, service, nodes)
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						continue
+					}
+					if err := az.LoadBalancerBackendPool.EnsureHostsInPool(
+						ctx,
+						service,
+						natNodes,
+						az.getBackendPoolID(lbName, ptr.Deref(backendPool.Name, "")),
+						vmSetName,
+						clusterName,
+						lbName,
+						(lb.Properties.BackendAddressPools)[i],
+					); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				// In pod IP mode, the nodes are kept in the backend pool of the cluster for the outbound connectivity.
+				if az.IsLBBackendPoolTypePodIP() && strings.EqualFold(ptr.Deref(backendPool.Name, ""), getBackendPoolName(clusterName, isIPv6)) {
+					if err := az.LoadBalancerBackendPool.EnsureHostsInPool(
+						ctx,
+						service,
+						nodes,
+						az.getBackendPoolID(lbName, ptr.Deref(backendPool.Name, "")),
+						vmSetName,
+						clusterName,
+						lbName,
+						(lb.Properties.BackendAddressPools)[i],
+					); err != nil {
+						return nil, err
+					}
+					continue
+				}
 				if strings.EqualFold(ptr.Deref(backendPool.Name, ""), az.getBackendPoolNameForService(service, clusterName, isIPv6)) {
 					if err := az.LoadBalancerBackendPool.EnsureHostsInPool(
 						ctx,
@@ -2519,6 +2984,12 @@ func (az *Cloud) reconcileFrontendIPConfigs(
 			existsSubnet bool
 		)
 
+		var gatewayLBFrontendIPConfigID string
+		gatewayLBFrontendIPConfigID, err = az.getServiceGatewayLoadBalancerFrontendIPConfigurationID(service)
+		if err != nil {
+			return nil, toDeleteConfigs, false, err
+		}
+
 		if isInternal {
 			subnetName := getInternalSubnet(service)
 			if subnetName == nil {
@@ -2614,6 +3085,15 @@ func (az *Cloud) reconcileFrontendIPConfigs(
 					klog.V(4).Infof("reconcileFrontendIPConfigs for service (%s): keep the original private IP %s", serviceName, privateIP)
 					configProperties.PrivateIPAllocationMethod = to.Ptr(armnetwork.IPAllocationMethodStatic)
 					configProperties.PrivateIPAddress = ptr.To(privateIP)
+				} else if isInternalIPAutoAssignEnabled(service) {
+					privateIP, err := az.getAutoAssignedInternalIP(ctx, service, subnet, lb, isIPv6)
+					if err != nil {
+						klog.Errorf("reconcileFrontendIPConfigs for service (%s): failed to auto assign the private IP: %v", serviceName, err)
+						return err
+					}
+					klog.V(4).Infof("reconcileFrontendIPConfigs for service (%s): use the auto assigned private IP %s", serviceName, privateIP)
+					configProperties.PrivateIPAllocationMethod = to.Ptr(armnetwork.IPAllocationMethodStatic)
+					configProperties.PrivateIPAddress = ptr.To(privateIP)
 				} else {
 					// We'll need to call GetLoadBalancer later to retrieve allocated IP.
 					klog.V(4).Infof("reconcileFrontendIPConfigs for service (%s): dynamically allocate the private IP", serviceName)
@@ -2634,6 +3114,9 @@ func (az *Cloud) reconcileFrontendIPConfigs(
 				fipConfigurationProperties = &armnetwork.FrontendIPConfigurationPropertiesFormat{
 					PublicIPAddress: &armnetwork.PublicIPAddress{ID: pip.ID},
 				}
+				if gatewayLBFrontendIPConfigID != "" {
+					fipConfigurationProperties.GatewayLoadBalancer = &armnetwork.SubResource{ID: ptr.To(gatewayLBFrontendIPConfigID)}
+				}
 			}
 
 			newConfig := &armnetwork.FrontendIPConfiguration{
@@ -2654,6 +3137,15 @@ func (az *Cloud) reconcileFrontendIPConfigs(
 			return nil
 		}
 
+		if !isInternal {
+			for _, config := range ownedFIPConfigMap {
+				if reconcileGatewayLoadBalancer(config, gatewayLBFrontendIPConfigID) {
+					klog.V(2).Infof("reconcileLoadBalancer for service (%s)(%t): lb frontendconfig(%s) - updating the gateway load balancer to %q", serviceName, wantLb, ptr.Deref(config.Name, ""), gatewayLBFrontendIPConfigID)
+					dirtyConfigs = true
+				}
+			}
+		}
+
 		v4Enabled, v6Enabled := getIPFamiliesEnabled(service)
 		if v4Enabled && ownedFIPConfigMap[false] == nil {
 			if err := addNewFIPOfService(false); err != nil {
@@ -2674,6 +3166,68 @@ func (az *Cloud) reconcileFrontendIPConfigs(
 	return ownedFIPConfigs, toDeleteConfigs, dirtyConfigs, err
 }
 
+// getServiceGatewayLoadBalancerFrontendIPConfigurationID returns the ID of the gateway load balancer frontend IP configuration
+// the public frontends of the service should be chained to. The annotation overrides the default in the cloud config, and
+// an empty annotation disables the chaining. The default is ignored for internal services.
+func (az *Cloud) getServiceGatewayLoadBalancerFrontendIPConfigurationID(service *v1.Service) (string, error) {
+	id, found := service.Annotations[consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID]
+	id = strings.TrimSpace(id)
+	if !found {
+		if requiresInternalLoadBalancer(service) {
+			return "", nil
+		}
+		return az.GatewayLoadBalancerFrontendIPConfigurationID, nil
+	}
+	if id == "" {
+		return "", nil
+	}
+
+	if requiresInternalLoadBalancer(service) {
+		return "", fmt.Errorf("annotation %s can only be used on the public services", consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID)
+	}
+	if !az.UseStandardLoadBalancer() {
+		return "", fmt.Errorf("annotation %s can only be used with standard load balancer", consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID)
+	}
+	if err := validateGatewayLoadBalancerFrontendIPConfigurationID(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// validateGatewayLoadBalancerFrontendIPConfigurationID checks the ID is the ID of a load balancer frontend IP configuration.
+func validateGatewayLoadBalancerFrontendIPConfigurationID(id string) error {
+	resourceID, err := arm.ParseResourceID(id)
+	if err != nil {
+		return fmt.Errorf("invalid gateway load balancer frontend IP configuration ID %q: %w", id, err)
+	}
+	if !strings.EqualFold(resourceID.ResourceType.String(), "Microsoft.Network/loadBalancers/frontendIPConfigurations") {
+		return fmt.Errorf("invalid gateway load balancer frontend IP configuration ID %q: unexpected resource type %s", id, resourceID.ResourceType.String())
+	}
+	return nil
+}
+
+// reconcileGatewayLoadBalancer sets the gateway load balancer of the frontend IP configuration to the given frontend IP
+// configuration ID, or clears it if the ID is empty. It returns true if the frontend IP configuration is changed.
+func reconcileGatewayLoadBalancer(fipConfig *armnetwork.FrontendIPConfiguration, gatewayLBFrontendIPConfigID string) bool {
+	if fipConfig.Properties == nil {
+		fipConfig.Properties = &armnetwork.FrontendIPConfigurationPropertiesFormat{}
+	}
+	var currentID string
+	if fipConfig.Properties.GatewayLoadBalancer != nil {
+		currentID = ptr.Deref(fipConfig.Properties.GatewayLoadBalancer.ID, "")
+	}
+	if strings.EqualFold(currentID, gatewayLBFrontendIPConfigID) {
+		return false
+	}
+
+	if gatewayLBFrontendIPConfigID == "" {
+		fipConfig.Properties.GatewayLoadBalancer = nil
+	} else {
+		fipConfig.Properties.GatewayLoadBalancer = &armnetwork.SubResource{ID: ptr.To(gatewayLBFrontendIPConfigID)}
+	}
+	return true
+}
+
 func (az *Cloud) getFrontendZones(
 	ctx context.Context,
 	fipConfig *armnetwork.FrontendIPConfiguration,
@@ -2739,11 +3293,15 @@ func (az *Cloud) checkLoadBalancerResourcesConflicts(
 		if lb.Properties.InboundNatRules != nil {
 			for _, inboundNatRule := range lb.Properties.InboundNatRules {
 				if inboundNatRuleConflictsWithPort(inboundNatRule, frontendIPConfigID, port) {
+					// the per-node inbound NAT rules of the service are reconciled later
+					if az.serviceOwnsNodeInboundNATRule(service, ptr.Deref(inboundNatRule.Name, "")) {
+						continue
+					}
 					return fmt.Errorf("checkLoadBalancerResourcesConflicts: service port %s is trying to "+
 						"consume the port %d which is being referenced by an existing inbound NAT rule %s with "+
 						"the same protocol %s and frontend IP config with ID %s",
 						port.Name,
-						*inboundNatRule.Properties.FrontendPort,
+						port.Port,
 						*inboundNatRule.Name,
 						*inboundNatRule.Properties.Protocol,
 						*inboundNatRule.Properties.FrontendIPConfiguration.ID)
@@ -2785,13 +3343,7 @@ func inboundNatPoolConflictsWithPort(pool *armnetwork.InboundNatPool, frontendIP
 }
 
 func inboundNatRuleConflictsWithPort(inboundNatRule *armnetwork.InboundNatRule, frontendIPConfigID string, port v1.ServicePort) bool {
-	return inboundNatRule.Properties != nil &&
-		inboundNatRule.Properties.FrontendIPConfiguration != nil &&
-		inboundNatRule.Properties.FrontendIPConfiguration.ID != nil &&
-		strings.EqualFold(*inboundNatRule.Properties.FrontendIPConfiguration.ID, frontendIPConfigID) &&
-		strings.EqualFold(string(*inboundNatRule.Properties.Protocol), string(port.Protocol)) &&
-		inboundNatRule.Properties.FrontendPort != nil &&
-		*inboundNatRule.Properties.FrontendPort == port.Port
+	return inboundNatRuleConflictsWithPortRange(inboundNatRule, frontendIPConfigID, port.Protocol, port.Port, port.Port)
 }
 
 func lbRuleConflictsWithPort(rule *armnetwork.LoadBalancingRule, frontendIPConfigID string, port v1.ServicePort) bool {
@@ -2826,7 +3378,7 @@ func (az *Cloud) getExpectedLBRules(
 	if servicehelpers.NeedsHealthCheck(service) && !(consts.IsPLSEnabled(service.Annotations) && consts.IsPLSProxyProtocolEnabled(service.Annotations)) {
 		podPresencePath, podPresencePort := servicehelpers.GetServiceHealthCheckPathPort(service)
 		lbRuleName := az.getLoadBalancerRuleName(service, v1.ProtocolTCP, podPresencePort, isIPv6)
-		probeInterval, numberOfProbes, err := az.getHealthProbeConfigProbeIntervalAndNumOfProbe(service, podPresencePort)
+		probeInterval, numberOfProbes, err := getHealthProbeConfigProbeIntervalAndNumOfProbe(service, podPresencePort)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -2849,6 +3401,13 @@ func (az *Cloud) getExpectedLBRules(
 		useSharedProbe = true
 	}
 
+	// Pods are probed directly on their container ports when the backend pools are
+	// populated with pod IPs, so the node level health probes are not applicable.
+	if az.IsLBBackendPoolTypePodIP() {
+		nodeEndpointHealthprobe = nil
+		useSharedProbe = false
+	}
+
 	// In HA mode, lb forward traffic of all port to backend
 	// HA mode is only supported on standard loadbalancer SKU in internal mode
 	if consts.IsK8sServiceUsingInternalLoadBalancer(service) &&
@@ -2949,7 +3508,14 @@ func (az *Cloud) getExpectedLBRules(
 					}
 				}
 			}
-			if consts.IsK8sServiceDisableLoadBalancerFloatingIP(service) {
+			if az.IsLBBackendPoolTypePodIP() {
+				backendPort, err := az.getServicePortTargetPort(service, port)
+				if err != nil {
+					return expectedProbes, expectedRules, err
+				}
+				props.BackendPort = ptr.To(backendPort)
+				props.EnableFloatingIP = ptr.To(false)
+			} else if consts.IsK8sServiceDisableLoadBalancerFloatingIP(service) {
 				props.BackendPort = ptr.To(port.NodePort)
 				props.EnableFloatingIP = ptr.To(false)
 			}
@@ -3031,6 +3597,9 @@ func (az *Cloud) getExpectedHAModeLoadBalancingRuleProperties(
 		return nil, fmt.Errorf("error generate lb rule for ha mod loadbalancer. err: %w", err)
 	}
 	props.EnableTCPReset = ptr.To(!consts.IsTCPResetDisabled(service.Annotations))
+	if az.IsLBBackendPoolTypePodIP() {
+		props.EnableFloatingIP = ptr.To(false)
+	}
 
 	return props, nil
 }
@@ -3060,7 +3629,7 @@ func (az *Cloud) reconcileSecurityGroup(
 
 	var accessControl *loadbalancer.AccessControl
 	{
-		sg, err := az.nsgRepo.GetSecurityGroup(ctx)
+		sg, err := az.getSecurityGroup(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -3069,6 +3638,18 @@ func (az *Cloud) reconcileSecurityGroup(
 		if !wantLb {
 			// When deleting LB, we don't need to validate the annotation
 			opts = append(opts, loadbalancer.WithEventEmitter(az.Event))
+		} else {
+			// The addresses of the allowed IP Groups are only needed when the rules are added.
+			opts = append(opts, loadbalancer.WithIPGroupResolver(az.ipGroupResolver(ctx)))
+		}
+		if az.IsLBBackendPoolTypePodIP() {
+			// The security rules allow the traffic to the container ports of the pods.
+			dstPorts, err := az.getSecurityRuleDestinationPortsByProtocol(service)
+			if err != nil {
+				logger.Error(err, "Failed to get the destination ports of the security rules")
+				return nil, err
+			}
+			opts = append(opts, loadbalancer.WithSecurityRuleDestinationPortsByProtocol(dstPorts))
 		}
 		accessControl, err = loadbalancer.NewAccessControl(logger, service, sg, opts...)
 		if err != nil {
@@ -3078,12 +3659,19 @@ func (az *Cloud) reconcileSecurityGroup(
 	}
 
 	var (
-		disableFloatingIP                                = consts.IsK8sServiceDisableLoadBalancerFloatingIP(service)
+		disableFloatingIP                                = consts.IsK8sServiceDisableLoadBalancerFloatingIP(service) || az.IsLBBackendPoolTypePodIP()
 		lbIPAddresses, _                                 = iputil.ParseAddresses(lbIPs)
 		lbIPv4Addresses, lbIPv6Addresses                 = iputil.GroupAddressesByFamily(lbIPAddresses)
 		additionalIPv4Addresses, additionalIPv6Addresses = iputil.GroupAddressesByFamily(additionalIPs)
 		backendIPv4Addresses, backendIPv6Addresses       []netip.Addr
+		natIPv4Addresses, natIPv6Addresses               []netip.Addr
+		natConfig                                        *nodeInboundNATRuleConfig
 	)
+	if wantLb {
+		if natConfig, err = getServiceNodeInboundNATRuleConfig(service); err != nil {
+			return nil, err
+		}
+	}
 	{
 		// Get backend node IPs
 		lb, lbFound, err := az.getAzureLoadBalancer(ctx, lbName, azcache.CacheReadTypeDefault)
@@ -3099,6 +3687,9 @@ func (az *Cloud) reconcileSecurityGroup(
 		var backendIPv4List, backendIPv6List []string
 		if lbFound {
 			backendIPv4List, backendIPv6List = az.LoadBalancerBackendPool.GetBackendPrivateIPs(ctx, clusterName, service, lb)
+			if natConfig != nil {
+				natIPv4Addresses, natIPv6Addresses = az.getNodeInboundNATBackendPrivateIPs(ctx, service, lb)
+			}
 		}
 		backendIPv4Addresses, _ = iputil.ParseAddresses(backendIPv4List)
 		backendIPv6Addresses, _ = iputil.ParseAddresses(backendIPv6List)
@@ -3110,7 +3701,7 @@ func (az *Cloud) reconcileSecurityGroup(
 	)
 
 	if disableFloatingIP {
-		// use the backend node IPs
+		// use the backend node or pod IPs
 		dstIPv4Addresses = append(dstIPv4Addresses, backendIPv4Addresses...)
 		dstIPv6Addresses = append(dstIPv6Addresses, backendIPv6Addresses...)
 	} else {
@@ -3119,14 +3710,43 @@ func (az *Cloud) reconcileSecurityGroup(
 		dstIPv6Addresses = append(dstIPv6Addresses, lbIPv6Addresses...)
 	}
 
+	// The pod IPs removed from the backend pools are cleaned up as well in pod IP mode.
+	var removedPodIPs []string
+	cleanIPv4Addresses, cleanIPv6Addresses := dstIPv4Addresses, dstIPv6Addresses
+	if az.IsLBBackendPoolTypePodIP() {
+		backendAddresses := make(map[netip.Addr]bool)
+		for _, addr := range append(backendIPv4Addresses, backendIPv6Addresses...) {
+			backendAddresses[addr] = true
+		}
+		for _, ip := range az.getRemovedBackendPodIPs(getServiceName(service)) {
+			removedPodIPs = append(removedPodIPs, ip)
+			addr, err := netip.ParseAddr(ip)
+			if err != nil || backendAddresses[addr] {
+				// skip the pod IPs added back to the backend pools
+				continue
+			}
+			if addr.Is4() {
+				cleanIPv4Addresses = append(cleanIPv4Addresses, addr)
+			} else {
+				cleanIPv6Addresses = append(cleanIPv6Addresses, addr)
+			}
+		}
+	}
+
 	{
-		retainPortRanges, err := az.listSharedIPPortMapping(ctx, service, append(dstIPv4Addresses, dstIPv6Addresses...))
+		retainPortRanges, err := az.listSharedIPPortMapping(ctx, service, append(cleanIPv4Addresses, cleanIPv6Addresses...))
 		if err != nil {
 			logger.Error(err, "Failed to list retain port ranges")
 			return nil, err
 		}
 
-		if err := accessControl.CleanSecurityGroup(dstIPv4Addresses, dstIPv6Addresses, retainPortRanges); err != nil {
+		retainASGPortRanges, err := az.listSharedApplicationSecurityGroupPortMapping(ctx, service)
+		if err != nil {
+			logger.Error(err, "Failed to list retain port ranges of the destination application security groups")
+			return nil, err
+		}
+
+		if err := accessControl.CleanSecurityGroup(cleanIPv4Addresses, cleanIPv6Addresses, retainPortRanges, retainASGPortRanges); err != nil {
 			logger.Error(err, "Failed to clean security group")
 			return nil, err
 		}
@@ -3140,7 +3760,23 @@ func (az *Cloud) reconcileSecurityGroup(
 		}
 	}
 
+	{
+		// The rules of the per-node inbound NAT rules allow the traffic to the backend port on the nodes
+		// of the vmSet, and are removed along with the inbound NAT rules.
+		var natBackendPort int32
+		if natConfig != nil {
+			natBackendPort = natConfig.backendPort
+		}
+		if err := accessControl.ReconcileNodeInboundNATRules(
+			az.getNodeInboundNATSecurityRuleNamePrefix(service), natIPv4Addresses, natIPv6Addresses, natBackendPort,
+		); err != nil {
+			logger.Error(err, "Failed to reconcile the security rules of the inbound NAT rules")
+			return nil, err
+		}
+	}
+
 	rv, updated, err := accessControl.SecurityGroup()
+	az.reportSecurityGroupCapacity(service, accessControl.SecurityGroupCapacity())
 	if err != nil {
 		err = fmt.Errorf("unable to apply access control configuration to security group: %w", err)
 		logger.Error(err, "Failed to get security group after patching")
@@ -3153,13 +3789,14 @@ func (az *Cloud) reconcileSecurityGroup(
 	if updated {
 		logger.V(2).Info("Preparing to update security group")
 		logger.V(5).Info("CreateOrUpdateSecurityGroup begin")
-		err := az.nsgRepo.CreateOrUpdateSecurityGroup(ctx, rv)
+		err := az.createOrUpdateSecurityGroup(ctx, rv)
 		if err != nil {
 			logger.Error(err, "Failed to update security group")
 			return nil, err
 		}
 		logger.V(5).Info("CreateOrUpdateSecurityGroup end")
 	}
+	az.forgetRemovedBackendPodIPs(getServiceName(service), removedPodIPs)
 	return rv, nil
 }
 
@@ -3181,6 +3818,7 @@ func shouldReleaseExistingOwnedPublicIP(
 	lbShouldExist, lbIsInternal, isUserAssignedPIP bool,
 	desiredPipName string,
 	ipTagRequest serviceIPTagRequest,
+	pipSettings *publicIPSettings,
 ) bool {
 	// skip deleting user created pip
 	if isUserAssignedPIP {
@@ -3195,6 +3833,12 @@ func shouldReleaseExistingOwnedPublicIP(
 	if existingPip.Properties != nil {
 		currentIPTags = existingPip.Properties.IPTags
 	}
+	// The routing preference IP tag cannot be changed on an existing public IP, so it is not compared
+	// when the routing preference is set by the annotation or the cloud config.
+	requestedIPTags := ipTagRequest.IPTags
+	if pipSettings != nil && pipSettings.routingPreference != "" {
+		requestedIPTags = applyRoutingPreferenceToIPTags(requestedIPTags, getPublicIPRoutingPreference(existingPip))
+	}
 
 	// Check whether the public IP is being referenced by other service.
 	// The owned public IP can be released only when there is not other service using it.
@@ -3218,7 +3862,7 @@ func shouldReleaseExistingOwnedPublicIP(
 		// We need to recreate such PIP and current logic to delete needs no change.
 		(pipName != desiredPipName) ||
 		// #4 If the service annotations have specified the ip tags that the public ip must have, but they do not match the ip tags of the existing instance
-		(ipTagRequest.IPTagsRequestedByAnnotation && !areIPTagsEquivalent(currentIPTags, ipTagRequest.IPTags))
+		(ipTagRequest.IPTagsRequestedByAnnotation && !areIPTagsEquivalent(currentIPTags, requestedIPTags))
 }
 
 // ensurePIPTagged ensures the public IP of the service is tagged as configured
@@ -3311,16 +3955,26 @@ func (az *Cloud) reconcilePublicIPs(ctx context.Context, clusterName string, ser
 func (az *Cloud) reconcilePublicIP(ctx context.Context, pips []*armnetwork.PublicIPAddress, clusterName string, service *v1.Service, lbName string, wantLb, isIPv6 bool) (*armnetwork.PublicIPAddress, error) {
 	isInternal := requiresInternalLoadBalancer(service)
 	serviceName := getServiceName(service)
-	serviceIPTagRequest := getServiceIPTagRequestForPublicIP(service)
 	pipResourceGroup := az.getPublicIPAddressResourceGroup(service)
+	serviceIPTagRequest := getServiceIPTagRequestForPublicIP(service)
 
 	var (
 		lb               *armnetwork.LoadBalancer
 		desiredPipName   string
-		err              error
 		shouldPIPExisted bool
+		pipSettings      *publicIPSettings
+		err              error
 	)
 
+	// The public IP settings are only validated when the service wants a load balancer,
+	// so the invalid annotations do not block the deletion of the service.
+	if wantLb {
+		pipSettings, err = az.getServicePublicIPSettings(service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if !isInternal && wantLb {
 		desiredPipName, shouldPIPExisted, err = az.determinePublicIPName(ctx, clusterName, service, isIPv6)
 		if err != nil {
@@ -3336,7 +3990,7 @@ func (az *Cloud) reconcilePublicIP(ctx context.Context, pips []*armnetwork.Publi
 	}
 
 	discoveredDesiredPublicIP, pipsToBeDeleted, deletedDesiredPublicIP, pipsToBeUpdated, err := az.getPublicIPUpdates(
-		clusterName, service, pips, wantLb, isInternal, desiredPipName, serviceName, serviceIPTagRequest, shouldPIPExisted, isIPv6)
+		clusterName, service, pips, wantLb, isInternal, desiredPipName, serviceName, serviceIPTagRequest, pipSettings, shouldPIPExisted, isIPv6)
 	if err != nil {
 		return nil, err
 	}
@@ -3346,7 +4000,7 @@ func (az *Cloud) reconcilePublicIP(ctx context.Context, pips []*armnetwork.Publi
 		pipCopy := *pip
 		updateFuncs = append(updateFuncs, func() error {
 			klog.V(2).Infof("reconcilePublicIP for service(%s): pip(%s), isIPv6(%v) - updating", serviceName, *pip.Name, isIPv6)
-			return az.CreateOrUpdatePIP(service, pipResourceGroup, &pipCopy)
+			return az.CreateOrUpdatePIP(ctx, service, pipResourceGroup, &pipCopy)
 		})
 	}
 	errs := utilerrors.AggregateGoroutines(updateFuncs...)
@@ -3389,6 +4043,7 @@ func (az *Cloud) getPublicIPUpdates(
 	desiredPipName string,
 	serviceName string,
 	serviceIPTagRequest serviceIPTagRequest,
+	pipSettings *publicIPSettings,
 	serviceAnnotationRequestsNamedPublicIP,
 	isIPv6 bool,
 ) (bool, []*armnetwork.PublicIPAddress, bool, []*armnetwork.PublicIPAddress, error) {
@@ -3437,7 +4092,7 @@ func (az *Cloud) getPublicIPUpdates(
 					dirtyPIP = true
 				}
 			}
-			if shouldReleaseExistingOwnedPublicIP(pip, serviceReferences, wantLb, isInternal, isUserAssignedPIP, desiredPipName, serviceIPTagRequest) {
+			if shouldReleaseExistingOwnedPublicIP(pip, serviceReferences, wantLb, isInternal, isUserAssignedPIP, desiredPipName, serviceIPTagRequest, pipSettings) {
 				// Then, release the public ip
 				pipsToBeDeleted = append(pipsToBeDeleted, pip)
 
@@ -3546,7 +4201,7 @@ func (az *Cloud) safeDeletePublicIP(ctx context.Context, service *v1.Service, pi
 
 	pipName := ptr.Deref(pip.Name, "")
 	klog.V(10).Infof("DeletePublicIP(%s, %q): start", pipResourceGroup, pipName)
-	err := az.DeletePublicIP(service, pipResourceGroup, pipName)
+	err := az.DeletePublicIP(ctx, service, pipResourceGroup, pipName)
 	if err != nil {
 		return err
 	}
@@ -3948,6 +4603,12 @@ func (az *Cloud) getAzureLoadBalancerName(
 		}
 
 		currentLBName := az.getServiceCurrentLoadBalancerName(service)
+		// The service is being moved to another load balancer by the rebalancer.
+		if targetLBName := getServiceRebalanceTarget(service); targetLBName != "" &&
+			!strings.EqualFold(targetLBName, currentLBName) && StringInSlice(targetLBName, eligibleLBs) {
+			klog.V(2).Infof("getAzureLoadBalancerName: moving service %s from load balancer %s to the rebalance target %s", getServiceName(service), currentLBName, targetLBName)
+			currentLBName = targetLBName
+		}
 		lbNamePrefix = getMostEligibleLBForService(currentLBName, eligibleLBs, existingLBs, requiresInternalLoadBalancer(service))
 	}
 
@@ -4043,8 +4704,17 @@ func (az *Cloud) getEligibleLoadBalancersForService(ctx context.Context, service
 		WithValues("service", service.Name)
 
 	// 1. Service selects LBs defined in the annotation.
-	// If there is no annotation given, it selects all LBs.
+	// If there is no annotation given, it selects the LB mapped from its load balancer class.
+	// If the load balancer class is not mapped to any LB either, it selects all LBs.
 	lbsFromAnnotation := consts.GetLoadBalancerConfigurationsNames(service)
+	if len(lbsFromAnnotation) == 0 && service.Spec.LoadBalancerClass != nil {
+		if lbClass := az.GetLoadBalancerClassConfiguration(*service.Spec.LoadBalancerClass); lbClass != nil && lbClass.LoadBalancerConfigurationName != "" {
+			logger.V(4).Info("selects the load balancer by load balancer class",
+				"load balancer class", lbClass.Name,
+				"load balancer configuration name", lbClass.LoadBalancerConfigurationName)
+			lbsFromAnnotation = []string{strings.ToLower(lbClass.LoadBalancerConfigurationName)}
+		}
+	}
 	if len(lbsFromAnnotation) > 0 {
 		lbNamesSet := utilsets.NewString(lbsFromAnnotation...)
 		for i := range az.MultipleStandardLoadBalancerConfigurations {
@@ -20,17 +20,72 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"strings"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
 	"sigs.k8s.io/cloud-provider-azure/pkg/log"
 	"sigs.k8s.io/cloud-provider-azure/pkg/provider/loadbalancer"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/securitygroup"
 	fnutil "sigs.k8s.io/cloud-provider-azure/pkg/util/collectionutil"
 )
 
+// securityGroupCapacityWarningRatio is the usage ratio of the security group limits
+// above which a warning event is emitted on the reconciled service.
+const securityGroupCapacityWarningRatio = 0.9
+
+var (
+	securityGroupCapacityHeadroom = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "security_group_capacity_headroom",
+			Help:           "Number of rules, source IPs or destination IPs that can still be added to the security group",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"security_group", "resource"},
+	)
+	registerSecurityGroupCapacityMetricsOnce sync.Once
+)
+
+func registerSecurityGroupCapacityMetrics() {
+	registerSecurityGroupCapacityMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(securityGroupCapacityHeadroom)
+	})
+}
+
+// reportSecurityGroupCapacity records the headroom of the security group and emits a warning event
+// on the service when the security group usage crosses into the close-to-the-limits state.
+func (az *Cloud) reportSecurityGroupCapacity(service *v1.Service, capacity securitygroup.Capacity) {
+	registerSecurityGroupCapacityMetrics()
+
+	sgName := az.SecurityGroupName
+	headroom := capacity.Headroom()
+	securityGroupCapacityHeadroom.WithLabelValues(sgName, "rules").Set(float64(headroom.Rules))
+	securityGroupCapacityHeadroom.WithLabelValues(sgName, "source_ips").Set(float64(headroom.SourceIPs))
+	securityGroupCapacityHeadroom.WithLabelValues(sgName, "destination_ips").Set(float64(headroom.DestinationIPs))
+
+	if capacity.UsageRatio() < securityGroupCapacityWarningRatio {
+		az.securityGroupCapacityLow.Store(false)
+		return
+	}
+	if az.securityGroupCapacityLow.Swap(true) {
+		return
+	}
+	az.Event(service, v1.EventTypeWarning, "SecurityGroupCapacityLow", fmt.Sprintf(
+		"Security group %s is close to the limits: %d/%d rules, %d/%d source IPs, %d/%d destination IPs",
+		sgName,
+		capacity.Rules, securitygroup.MaxSecurityRulesPerGroup,
+		capacity.SourceIPs, securitygroup.MaxSecurityRuleSourceIPsPerGroup,
+		capacity.DestinationIPs, securitygroup.MaxSecurityRuleDestinationIPsPerGroup,
+	))
+}
+
 func filterServicesByIngressIPs(services []*v1.Service, ips []netip.Addr) []*v1.Service {
 	targetIPs := fnutil.Map(func(ip netip.Addr) string { return ip.String() }, ips)
 
@@ -74,8 +129,11 @@ func (az *Cloud) listSharedIPPortMapping(
 		}
 		logger.V(5).Info("Listed all services", "num-all-services", len(services))
 
-		// Filter services by ingress IPs or backend node pool IPs (when disable floating IP)
-		if consts.IsK8sServiceDisableLoadBalancerFloatingIP(svc) {
+		// Filter services by ingress IPs or backend node pool IPs (when disable floating IP).
+		// The backend pods may be shared by any service in pod IP mode, so no service is filtered out.
+		if az.IsLBBackendPoolTypePodIP() {
+			logger.V(5).Info("Skip filtering services in pod IP mode")
+		} else if consts.IsK8sServiceDisableLoadBalancerFloatingIP(svc) {
 			logger.V(5).Info("Filter service by disableFloatingIP")
 			services = filterServicesByDisableFloatingIP(services)
 		} else {
@@ -92,7 +150,7 @@ func (az *Cloud) listSharedIPPortMapping(
 			continue
 		}
 
-		portsByProtocol, err := loadbalancer.SecurityRuleDestinationPortsByProtocol(s)
+		portsByProtocol, err := az.getSecurityRuleDestinationPortsByProtocol(s)
 		if err != nil {
 			return nil, fmt.Errorf("fetch security rule dst ports for %s: %w", s.Name, err)
 		}
@@ -106,3 +164,71 @@ func (az *Cloud) listSharedIPPortMapping(
 
 	return rv, nil
 }
+
+// listSharedApplicationSecurityGroupPortMapping lists the port mapping of the services, excluding the service itself,
+// grouped by the lower-case resource ID of their destination application security groups.
+// The rules of an application security group are shared by the services referring to it,
+// and in order to clean up the security rules, we need to know the ports used by the other services.
+func (az *Cloud) listSharedApplicationSecurityGroupPortMapping(
+	ctx context.Context,
+	svc *v1.Service,
+) (map[string]map[armnetwork.SecurityRuleProtocol][]int32, error) {
+	var (
+		logger = log.FromContextOrBackground(ctx).WithName("listSharedApplicationSecurityGroupPortMapping")
+		rv     = make(map[string]map[armnetwork.SecurityRuleProtocol][]int32)
+	)
+
+	services, err := az.serviceLister.List(labels.Everything())
+	if err != nil {
+		logger.Error(err, "Failed to list all services")
+		return nil, fmt.Errorf("list all services: %w", err)
+	}
+
+	for _, s := range services {
+		if svc.Namespace == s.Namespace && svc.Name == s.Name {
+			// skip the service itself
+			continue
+		}
+		dstASGID := strings.ToLower(strings.TrimSpace(s.Annotations[consts.ServiceAnnotationDestinationApplicationSecurityGroup]))
+		if dstASGID == "" {
+			continue
+		}
+
+		portsByProtocol, err := az.getSecurityRuleDestinationPortsByProtocol(s)
+		if err != nil {
+			return nil, fmt.Errorf("fetch security rule dst ports for %s: %w", s.Name, err)
+		}
+		if rv[dstASGID] == nil {
+			rv[dstASGID] = make(map[armnetwork.SecurityRuleProtocol][]int32)
+		}
+		for protocol, ports := range portsByProtocol {
+			rv[dstASGID][protocol] = append(rv[dstASGID][protocol], ports...)
+		}
+	}
+
+	logger.V(5).Info("Retain port mapping", "port-mapping", rv)
+
+	return rv, nil
+}
+
+// getSecurityRuleDestinationPortsByProtocol returns the destination ports of the security rules of the service
+// grouped by protocol. The container ports are used in pod IP mode, as the traffic is delivered to the pods directly.
+func (az *Cloud) getSecurityRuleDestinationPortsByProtocol(svc *v1.Service) (map[armnetwork.SecurityRuleProtocol][]int32, error) {
+	if !az.IsLBBackendPoolTypePodIP() {
+		return loadbalancer.SecurityRuleDestinationPortsByProtocol(svc)
+	}
+
+	rv := make(map[armnetwork.SecurityRuleProtocol][]int32)
+	for _, port := range svc.Spec.Ports {
+		protocol, err := securitygroup.ProtocolFromKubernetes(port.Protocol)
+		if err != nil {
+			return nil, err
+		}
+		targetPort, err := az.getServicePortTargetPort(svc, port)
+		if err != nil {
+			return nil, err
+		}
+		rv[protocol] = append(rv[protocol], targetPort)
+	}
+	return rv, nil
+}
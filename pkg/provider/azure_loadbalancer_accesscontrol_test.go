@@ -32,6 +32,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/cloud-provider-azure/internal/testutil"
@@ -3100,3 +3102,76 @@ func TestCloud_reconcileSecurityGroup(t *testing.T) {
 		})
 	})
 }
+
+func TestListSharedApplicationSecurityGroupPortMapping(t *testing.T) {
+	const (
+		dstASGID   = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/dst"
+		otherASGID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/other"
+	)
+	var (
+		ctrl     = gomock.NewController(t)
+		az       = GetTestCloud(ctrl)
+		k8sFx    = fixture.NewFixture().Kubernetes()
+		svc      = k8sFx.Service().WithName("svc").WithDestinationApplicationSecurityGroup(dstASGID).Build()
+		sharedFx = k8sFx.Service().WithName("shared").WithDestinationApplicationSecurityGroup(strings.ToUpper(dstASGID))
+		shared   = sharedFx.Build()
+		other    = k8sFx.Service().WithName("other").WithDestinationApplicationSecurityGroup(otherASGID).Build()
+		plain    = k8sFx.Service().WithName("plain").Build()
+
+		kubeClient      = fake.NewSimpleClientset(&svc, &shared, &other, &plain)
+		informerFactory = informers.NewSharedInformerFactory(kubeClient, 0)
+	)
+	defer ctrl.Finish()
+
+	az.serviceLister = informerFactory.Core().V1().Services().Lister()
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+
+	rv, err := az.listSharedApplicationSecurityGroupPortMapping(context.Background(), &svc)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[armnetwork.SecurityRuleProtocol][]int32{
+		strings.ToLower(dstASGID): {
+			armnetwork.SecurityRuleProtocolTCP: sharedFx.TCPPorts(),
+			armnetwork.SecurityRuleProtocolUDP: sharedFx.UDPPorts(),
+		},
+		strings.ToLower(otherASGID): {
+			armnetwork.SecurityRuleProtocolTCP: sharedFx.TCPPorts(),
+			armnetwork.SecurityRuleProtocolUDP: sharedFx.UDPPorts(),
+		},
+	}, rv)
+
+	rv, err = az.listSharedApplicationSecurityGroupPortMapping(context.Background(), &plain)
+	assert.NoError(t, err)
+	assert.Len(t, rv, 2, "all the services referring to a destination application security group should be listed")
+}
+
+func TestReportSecurityGroupCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	recorder := record.NewFakeRecorder(10)
+	az.eventRecorder = recorder
+	svc := fixture.NewFixture().Kubernetes().Service().Build()
+
+	az.reportSecurityGroupCapacity(&svc, securitygroup.Capacity{Rules: 100, SourceIPs: 100, DestinationIPs: 100})
+	assert.Empty(t, recorder.Events)
+	headroom, err := metricstestutil.GetGaugeMetricValue(securityGroupCapacityHeadroom.WithLabelValues(az.SecurityGroupName, "rules"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(900), headroom)
+
+	az.reportSecurityGroupCapacity(&svc, securitygroup.Capacity{Rules: 950, SourceIPs: 100, DestinationIPs: 100})
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "SecurityGroupCapacityLow")
+	headroom, err = metricstestutil.GetGaugeMetricValue(securityGroupCapacityHeadroom.WithLabelValues(az.SecurityGroupName, "rules"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(50), headroom)
+
+	az.reportSecurityGroupCapacity(&svc, securitygroup.Capacity{Rules: 960, SourceIPs: 100, DestinationIPs: 100})
+	assert.Empty(t, recorder.Events, "the warning should only be emitted when the usage crosses the ratio")
+
+	az.reportSecurityGroupCapacity(&svc, securitygroup.Capacity{Rules: 100, SourceIPs: 100, DestinationIPs: 100})
+	az.reportSecurityGroupCapacity(&svc, securitygroup.Capacity{Rules: 950, SourceIPs: 100, DestinationIPs: 100})
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "SecurityGroupCapacityLow")
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/util/errutils"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+const (
+	// The results of the backend drains, which are used in the events and metrics.
+	backendDrainResultCompleted = "completed"
+	backendDrainResultCancelled = "cancelled"
+)
+
+var (
+	// loadBalancerBackendDrainCheckInterval is the interval for checking the timed out backend drains.
+	loadBalancerBackendDrainCheckInterval = 30 * time.Second
+
+	drainingBackendNodes = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "draining_backend_nodes",
+			Help:           "Number of nodes whose load balancer backend addresses are being drained",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	backendDrainsFinished = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "backend_drains_total",
+			Help:           "Number of finished load balancer backend drains of nodes",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+	registerBackendDrainMetricsOnce sync.Once
+)
+
+func registerBackendDrainMetrics() {
+	registerBackendDrainMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(drainingBackendNodes)
+		legacyregistry.MustRegister(backendDrainsFinished)
+	})
+}
+
+// nodeDrainState is the drain state of the load balancer backend addresses of a node.
+type nodeDrainState struct {
+	nodeName  string
+	startTime time.Time
+	// backendPools holds the IDs of the backend pools where the backend addresses
+	// of the node have been set to admin state Down.
+	backendPools *utilsets.IgnoreCaseSet
+	// completed is true after the backend addresses have been removed from the backend pools.
+	completed bool
+}
+
+// isLoadBalancerBackendDrainEnabled returns true if the backend addresses of the nodes
+// should be drained before they are removed from the IP-based backend pools.
+func (az *Cloud) isLoadBalancerBackendDrainEnabled() bool {
+	return az.LoadBalancerBackendDrainTimeoutInSeconds > 0 && az.IsLBBackendPoolTypeNodeIP()
+}
+
+func (az *Cloud) getLoadBalancerBackendDrainTimeout() time.Duration {
+	return time.Duration(az.LoadBalancerBackendDrainTimeoutInSeconds) * time.Second
+}
+
+// shouldDrainNode returns true if the node is unschedulable or carries the drain taint.
+func (az *Cloud) shouldDrainNode(node *v1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if strings.EqualFold(taint.Key, az.LoadBalancerBackendDrainTaintKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateNodeDrainState starts or cancels the backend drain of the node.
+// It is called by updateNodeCaches with the nodeCachesLock held.
+func (az *Cloud) updateNodeDrainState(prevNode, newNode *v1.Node) {
+	if !az.isLoadBalancerBackendDrainEnabled() {
+		return
+	}
+	if az.drainingNodes == nil {
+		az.drainingNodes = make(map[string]*nodeDrainState)
+	}
+	defer func() {
+		drainingBackendNodes.Set(float64(az.countDrainingNodes()))
+	}()
+
+	if newNode == nil {
+		// The drain of a deleted node goes on until it times out, unless it has finished.
+		key := strings.ToLower(prevNode.Name)
+		if state, ok := az.drainingNodes[key]; ok && state.completed {
+			delete(az.drainingNodes, key)
+		}
+		return
+	}
+
+	key := strings.ToLower(newNode.Name)
+	state, draining := az.drainingNodes[key]
+	shouldDrain := az.shouldDrainNode(newNode)
+	switch {
+	case shouldDrain && !draining:
+		klog.V(2).Infof("updateNodeDrainState: start draining the load balancer backend addresses of the node %s", newNode.Name)
+		az.drainingNodes[key] = &nodeDrainState{
+			nodeName:     newNode.Name,
+			startTime:    time.Now(),
+			backendPools: utilsets.NewString(),
+		}
+		az.Event(newNode, v1.EventTypeNormal, "DrainingLoadBalancerBackend",
+			fmt.Sprintf("Draining the load balancer backend addresses of the node, which will be removed in %s", az.getLoadBalancerBackendDrainTimeout()))
+	case !shouldDrain && draining:
+		klog.V(2).Infof("updateNodeDrainState: stop draining the load balancer backend addresses of the node %s", newNode.Name)
+		delete(az.drainingNodes, key)
+		if !state.completed {
+			backendDrainsFinished.WithLabelValues(backendDrainResultCancelled).Inc()
+		}
+		az.Event(newNode, v1.EventTypeNormal, "LoadBalancerBackendDrainCancelled",
+			"Stopped draining the load balancer backend addresses of the node because it is schedulable again")
+	}
+}
+
+// countDrainingNodes returns the number of nodes with unfinished drains. The caller must hold the nodeCachesLock.
+func (az *Cloud) countDrainingNodes() int {
+	var count int
+	for _, state := range az.drainingNodes {
+		if !state.completed {
+			count++
+		}
+	}
+	return count
+}
+
+// getNodeDrainState returns whether the backend addresses of the node are being drained, and whether the drain has timed out.
+func (az *Cloud) getNodeDrainState(nodeName string) (draining, timedOut bool) {
+	if nodeName == "" {
+		return false, false
+	}
+
+	az.nodeCachesLock.RLock()
+	defer az.nodeCachesLock.RUnlock()
+
+	state, ok := az.drainingNodes[strings.ToLower(nodeName)]
+	if !ok {
+		return false, false
+	}
+	return true, state.completed || time.Since(state.startTime) >= az.getLoadBalancerBackendDrainTimeout()
+}
+
+// recordNodeDrainBackendPool records the backend pool where the backend addresses of the node have been set to admin state Down,
+// so they can be removed from the backend pool once the drain times out.
+func (az *Cloud) recordNodeDrainBackendPool(nodeName, backendPoolID string) {
+	az.nodeCachesLock.Lock()
+	defer az.nodeCachesLock.Unlock()
+
+	if state, ok := az.drainingNodes[strings.ToLower(nodeName)]; ok {
+		state.backendPools.Insert(backendPoolID)
+	}
+}
+
+// getBackendAddressNodeName returns the name of the node which owns the backend address.
+func (az *Cloud) getBackendAddressNodeName(address *armnetwork.LoadBalancerBackendAddress) string {
+	if name := ptr.Deref(address.Name, ""); name != "" {
+		return name
+	}
+	if address.Properties == nil {
+		return ""
+	}
+
+	az.nodeCachesLock.RLock()
+	defer az.nodeCachesLock.RUnlock()
+	return az.nodePrivateIPToNodeNameMap[ptr.Deref(address.Properties.IPAddress, "")]
+}
+
+// setBackendAddressAdminState sets the admin state of the backend address and returns true if it is changed.
+func setBackendAddressAdminState(address *armnetwork.LoadBalancerBackendAddress, state armnetwork.LoadBalancerBackendAddressAdminState) bool {
+	if address.Properties == nil {
+		return false
+	}
+	current := ptr.Deref(address.Properties.AdminState, armnetwork.LoadBalancerBackendAddressAdminStateNone)
+	if current == state {
+		return false
+	}
+	address.Properties.AdminState = ptr.To(state)
+	return true
+}
+
+// runLoadBalancerBackendDrainer periodically removes the backend addresses
+// of the nodes whose drains have timed out from the backend pools.
+func (az *Cloud) runLoadBalancerBackendDrainer(ctx context.Context) {
+	registerBackendDrainMetrics()
+	klog.Infof("runLoadBalancerBackendDrainer: started with drain timeout %s", az.getLoadBalancerBackendDrainTimeout())
+	wait.UntilWithContext(ctx, az.removeTimedOutDrainingBackends, loadBalancerBackendDrainCheckInterval)
+}
+
+// removeTimedOutDrainingBackends removes the backend addresses of the nodes whose drains
+// have timed out from the backend pools where they have been set to admin state Down.
+func (az *Cloud) removeTimedOutDrainingBackends(ctx context.Context) {
+	var states []*nodeDrainState
+	az.nodeCachesLock.RLock()
+	for _, state := range az.drainingNodes {
+		if !state.completed && time.Since(state.startTime) >= az.getLoadBalancerBackendDrainTimeout() {
+			states = append(states, state)
+		}
+	}
+	az.nodeCachesLock.RUnlock()
+	if len(states) == 0 {
+		return
+	}
+
+	// The backend pools should not be updated by the service reconciliation at the same time.
+	az.serviceReconcileLock.Lock()
+	defer az.serviceReconcileLock.Unlock()
+
+	for _, state := range states {
+		if err := az.removeDrainingNodeFromBackendPools(ctx, state); err != nil {
+			klog.Errorf("removeTimedOutDrainingBackends: failed to remove the backend addresses of the node %s: %s", state.nodeName, err.Error())
+			continue
+		}
+
+		az.nodeCachesLock.Lock()
+		state.completed = true
+		if !az.nodeNames.Has(state.nodeName) {
+			delete(az.drainingNodes, strings.ToLower(state.nodeName))
+		}
+		drainingBackendNodes.Set(float64(az.countDrainingNodes()))
+		az.nodeCachesLock.Unlock()
+
+		backendDrainsFinished.WithLabelValues(backendDrainResultCompleted).Inc()
+		node, err := az.getCachedNode(state.nodeName)
+		if err != nil {
+			klog.V(4).Infof("removeTimedOutDrainingBackends: skip the event of the node %s: %s", state.nodeName, err.Error())
+			continue
+		}
+		az.Event(node, v1.EventTypeNormal, "LoadBalancerBackendDrained",
+			fmt.Sprintf("Removed the load balancer backend addresses of the node from %d backend pools after draining for %s", state.backendPools.Len(), az.getLoadBalancerBackendDrainTimeout()))
+	}
+}
+
+// getCachedNode gets the node from the node informer cache.
+func (az *Cloud) getCachedNode(nodeName string) (*v1.Node, error) {
+	if az.nodeLister == nil {
+		return nil, fmt.Errorf("the node informer is not initialized")
+	}
+	return az.nodeLister.Get(nodeName)
+}
+
+// removeDrainingNodeFromBackendPools removes the backend addresses of the draining node from the recorded backend pools.
+func (az *Cloud) removeDrainingNodeFromBackendPools(ctx context.Context, state *nodeDrainState) error {
+	for _, backendPoolID := range state.backendPools.UnsortedList() {
+		resourceID, err := arm.ParseResourceID(backendPoolID)
+		if err != nil || resourceID.Parent == nil {
+			klog.Warningf("removeDrainingNodeFromBackendPools: skipping invalid backend pool ID %s", backendPoolID)
+			continue
+		}
+		lbName, backendPoolName := resourceID.Parent.Name, resourceID.Name
+		backendPool, err := az.NetworkClientFactory.GetBackendAddressPoolClient().Get(ctx, resourceID.ResourceGroupName, lbName, backendPoolName)
+		if err != nil {
+			if exist, _ := errutils.CheckResourceExistsFromAzcoreError(err); !exist {
+				continue
+			}
+			return err
+		}
+		if backendPool == nil || backendPool.Properties == nil {
+			continue
+		}
+
+		var changed bool
+		addresses := make([]*armnetwork.LoadBalancerBackendAddress, 0, len(backendPool.Properties.LoadBalancerBackendAddresses))
+		for _, address := range backendPool.Properties.LoadBalancerBackendAddresses {
+			if strings.EqualFold(az.getBackendAddressNodeName(address), state.nodeName) {
+				klog.V(2).Infof("removeDrainingNodeFromBackendPools: removing IP %s of the drained node %s from the backend pool %s", ptr.Deref(address.Properties.IPAddress, ""), state.nodeName, backendPoolName)
+				changed = true
+				continue
+			}
+			addresses = append(addresses, address)
+		}
+		if !changed {
+			continue
+		}
+		backendPool.Properties.LoadBalancerBackendAddresses = addresses
+		if err := az.CreateOrUpdateLBBackendPool(ctx, lbName, backendPool); err != nil {
+			return fmt.Errorf("failed to update backend pool %s of load balancer %s: %w", backendPoolName, lbName, err)
+		}
+	}
+	return nil
+}
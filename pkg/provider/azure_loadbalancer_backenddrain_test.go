@@ -0,0 +1,259 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/backendaddresspoolclient/mock_backendaddresspoolclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+func getTestCloudWithBackendDrain(ctrl *gomock.Controller) *Cloud {
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	az.LoadBalancerBackendDrainTimeoutInSeconds = 60
+	az.LoadBalancerBackendDrainTaintKey = consts.ToBeDeletedByClusterAutoscalerTaintKey
+	return az
+}
+
+func getTestDrainNode(name, ip string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: ip,
+				},
+			},
+		},
+	}
+}
+
+func TestUpdateNodeDrainState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := getTestCloudWithBackendDrain(ctrl)
+	node := getTestDrainNode("node1", "10.0.0.1")
+	az.updateNodeCaches(nil, node)
+	assert.Empty(t, az.drainingNodes)
+
+	cordoned := node.DeepCopy()
+	cordoned.Spec.Unschedulable = true
+	az.updateNodeCaches(node, cordoned)
+	assert.Contains(t, az.drainingNodes, "node1")
+	draining, timedOut := az.getNodeDrainState("Node1")
+	assert.True(t, draining)
+	assert.False(t, timedOut)
+
+	az.updateNodeCaches(cordoned, node)
+	assert.Empty(t, az.drainingNodes)
+
+	tainted := node.DeepCopy()
+	tainted.Spec.Taints = []v1.Taint{{Key: consts.ToBeDeletedByClusterAutoscalerTaintKey, Effect: v1.TaintEffectNoSchedule}}
+	az.updateNodeCaches(node, tainted)
+	assert.Contains(t, az.drainingNodes, "node1")
+
+	// The drain of a deleted node goes on until it times out.
+	az.updateNodeCaches(tainted, nil)
+	assert.Contains(t, az.drainingNodes, "node1")
+	az.drainingNodes["node1"].startTime = time.Now().Add(-time.Hour)
+	draining, timedOut = az.getNodeDrainState("node1")
+	assert.True(t, draining)
+	assert.True(t, timedOut)
+
+	az.LoadBalancerBackendDrainTimeoutInSeconds = 0
+	az.drainingNodes = map[string]*nodeDrainState{}
+	az.updateNodeCaches(node, cordoned)
+	assert.Empty(t, az.drainingNodes)
+}
+
+func TestEnsureHostsInPoolNodeIPDrain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		description        string
+		drainStartTime     time.Time
+		existingAdminState armnetwork.LoadBalancerBackendAddressAdminState
+		cordoned           bool
+		expectedIPs        []string
+		expectedAdminState armnetwork.LoadBalancerBackendAddressAdminState
+		expectedRecorded   bool
+	}{
+		{
+			description:        "should set the admin state of the draining node to Down",
+			drainStartTime:     time.Now(),
+			cordoned:           true,
+			expectedIPs:        []string{"10.0.0.1", "10.0.0.2"},
+			expectedAdminState: armnetwork.LoadBalancerBackendAddressAdminStateDown,
+			expectedRecorded:   true,
+		},
+		{
+			description:    "should remove the draining node after the drain times out",
+			drainStartTime: time.Now().Add(-time.Hour),
+			cordoned:       true,
+			expectedIPs:    []string{"10.0.0.1"},
+		},
+		{
+			description:        "should reset the admin state after the drain is cancelled",
+			existingAdminState: armnetwork.LoadBalancerBackendAddressAdminStateDown,
+			expectedIPs:        []string{"10.0.0.1", "10.0.0.2"},
+			expectedAdminState: armnetwork.LoadBalancerBackendAddressAdminStateNone,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			az := getTestCloudWithBackendDrain(ctrl)
+			nodes := []*v1.Node{getTestDrainNode("node1", "10.0.0.1"), getTestDrainNode("node2", "10.0.0.2")}
+			for _, node := range nodes {
+				az.updateNodeCaches(nil, node)
+			}
+			if tc.cordoned {
+				cordoned := nodes[1].DeepCopy()
+				cordoned.Spec.Unschedulable = true
+				az.updateNodeCaches(nodes[1], cordoned)
+				az.drainingNodes["node2"].startTime = tc.drainStartTime
+			}
+
+			backendPool := getTestBackendAddressPoolWithIPs("kubernetes", "kubernetes", []string{"10.0.0.1", "10.0.0.2"})
+			backendPool.Properties.LoadBalancerBackendAddresses[0].Name = ptr.To("node1")
+			backendPool.Properties.LoadBalancerBackendAddresses[1].Name = ptr.To("node2")
+			if tc.existingAdminState != "" {
+				backendPool.Properties.LoadBalancerBackendAddresses[1].Properties.AdminState = ptr.To(tc.existingAdminState)
+			}
+
+			bpClient := az.NetworkClientFactory.GetBackendAddressPoolClient().(*mock_backendaddresspoolclient.MockInterface)
+			bpClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), "kubernetes", "kubernetes", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _, _ string, bp armnetwork.BackendAddressPool) (*armnetwork.BackendAddressPool, error) {
+					var ips []string
+					for _, address := range bp.Properties.LoadBalancerBackendAddresses {
+						ip := ptr.Deref(address.Properties.IPAddress, "")
+						ips = append(ips, ip)
+						if ip == "10.0.0.2" {
+							assert.Equal(t, tc.expectedAdminState, ptr.Deref(address.Properties.AdminState, ""))
+						}
+					}
+					assert.ElementsMatch(t, tc.expectedIPs, ips)
+					return &bp, nil
+				})
+
+			service := getTestService("svc1", v1.ProtocolTCP, nil, false, 80)
+			bi := newBackendPoolTypeNodeIP(az)
+			err := bi.EnsureHostsInPool(context.Background(), &service, nodes, "", "", "kubernetes", "kubernetes", backendPool)
+			assert.NoError(t, err)
+			if tc.expectedRecorded {
+				assert.True(t, az.drainingNodes["node2"].backendPools.Has(ptr.Deref(backendPool.ID, "")))
+			}
+		})
+	}
+}
+
+func TestRemoveTimedOutDrainingBackends(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := getTestCloudWithBackendDrain(ctrl)
+	backendPool := getTestBackendAddressPoolWithIPs("kubernetes", "kubernetes", []string{"10.0.0.1", "10.0.0.2"})
+	backendPool.Properties.LoadBalancerBackendAddresses[0].Name = ptr.To("node1")
+	backendPool.Properties.LoadBalancerBackendAddresses[1].Name = ptr.To("node2")
+	az.nodeNames = utilsets.NewString("node1", "node3")
+	az.drainingNodes = map[string]*nodeDrainState{
+		"node2": {
+			nodeName:     "node2",
+			startTime:    time.Now().Add(-time.Hour),
+			backendPools: utilsets.NewString(ptr.Deref(backendPool.ID, "")),
+		},
+		"node3": {
+			nodeName:     "node3",
+			startTime:    time.Now(),
+			backendPools: utilsets.NewString(ptr.Deref(backendPool.ID, "")),
+		},
+	}
+
+	bpClient := az.NetworkClientFactory.GetBackendAddressPoolClient().(*mock_backendaddresspoolclient.MockInterface)
+	bpClient.EXPECT().Get(gomock.Any(), "rg", "kubernetes", "kubernetes").Return(backendPool, nil)
+	bpClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), "kubernetes", "kubernetes", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, _ string, bp armnetwork.BackendAddressPool) (*armnetwork.BackendAddressPool, error) {
+			assert.Equal(t, 1, len(bp.Properties.LoadBalancerBackendAddresses))
+			assert.Equal(t, "10.0.0.1", ptr.Deref(bp.Properties.LoadBalancerBackendAddresses[0].Properties.IPAddress, ""))
+			return &bp, nil
+		})
+
+	az.removeTimedOutDrainingBackends(context.Background())
+	// The drain state of the deleted node is removed once the drain finishes.
+	assert.NotContains(t, az.drainingNodes, "node2")
+	assert.False(t, az.drainingNodes["node3"].completed)
+}
+
+func TestRemoveTimedOutDrainingBackendsEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := getTestCloudWithBackendDrain(ctrl)
+	recorder := record.NewFakeRecorder(10)
+	az.eventRecorder = recorder
+	kubeClient := fake.NewSimpleClientset(getTestDrainNode("node1", "10.0.0.1"))
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	az.nodeLister = informerFactory.Core().V1().Nodes().Lister()
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+
+	backendPool := getTestBackendAddressPoolWithIPs("kubernetes", "kubernetes", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	backendPool.Properties.LoadBalancerBackendAddresses[0].Name = ptr.To("node1")
+	backendPool.Properties.LoadBalancerBackendAddresses[1].Name = ptr.To("node2")
+	backendPool.Properties.LoadBalancerBackendAddresses[2].Name = ptr.To("node3")
+	az.nodeNames = utilsets.NewString("node1", "node3")
+	az.drainingNodes = map[string]*nodeDrainState{}
+	for _, nodeName := range []string{"node1", "node2"} {
+		az.drainingNodes[nodeName] = &nodeDrainState{
+			nodeName:     nodeName,
+			startTime:    time.Now().Add(-time.Hour),
+			backendPools: utilsets.NewString(ptr.Deref(backendPool.ID, "")),
+		}
+	}
+
+	bpClient := az.NetworkClientFactory.GetBackendAddressPoolClient().(*mock_backendaddresspoolclient.MockInterface)
+	bpClient.EXPECT().Get(gomock.Any(), "rg", "kubernetes", "kubernetes").Return(backendPool, nil).Times(2)
+	bpClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), "kubernetes", "kubernetes", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, _ string, bp armnetwork.BackendAddressPool) (*armnetwork.BackendAddressPool, error) {
+			return &bp, nil
+		}).Times(2)
+
+	az.removeTimedOutDrainingBackends(context.Background())
+	// Only the drain of the node in the informer cache is reported, the other node has been deleted.
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "LoadBalancerBackendDrained")
+}
@@ -66,10 +66,70 @@ func newBackendPoolTypeNodeIPConfig(c *Cloud) BackendPool {
 	return &backendPoolTypeNodeIPConfig{c}
 }
 
-func (bc *backendPoolTypeNodeIPConfig) EnsureHostsInPool(ctx context.Context, service *v1.Service, nodes []*v1.Node, backendPoolID, vmSetName, _, _ string, _ *armnetwork.BackendAddressPool) error {
+func (bc *backendPoolTypeNodeIPConfig) EnsureHostsInPool(ctx context.Context, service *v1.Service, nodes []*v1.Node, backendPoolID, vmSetName, _, _ string, backendPool *armnetwork.BackendAddressPool) error {
+	// The nodes of the backend pool of the per-node inbound NAT rules are selected by the caller.
+	isNATBackendPool := backendPool != nil && isNodeInboundNATBackendPool(service, ptr.Deref(backendPool.Name, ""))
+	if isServiceBackendNodesRestricted(service) || isNATBackendPool {
+		if !isNATBackendPool {
+			nodes = filterNodesByServiceBackendZones(service, nodes)
+		}
+		if err := bc.removeExcludedNodesFromServiceBackendPool(ctx, service, nodes, backendPoolID, vmSetName, backendPool); err != nil {
+			return err
+		}
+	}
 	return bc.VMSet.EnsureHostsInPool(ctx, service, nodes, backendPoolID, vmSetName)
 }
 
+// removeExcludedNodesFromServiceBackendPool decouples the nodes which are not in the backend zones of the
+// service, or in the vmSet of the per-node inbound NAT rules, from the backend pool of the service. The VMSS VMs are
+// decoupled on the instance level, so the backend zones of the service are not supported with vmType vmss, see
+// checkServiceBackendZones.
+func (bc *backendPoolTypeNodeIPConfig) removeExcludedNodesFromServiceBackendPool(
+	ctx context.Context,
+	service *v1.Service,
+	backendNodes []*v1.Node,
+	backendPoolID, vmSetName string,
+	backendPool *armnetwork.BackendAddressPool,
+) error {
+	if backendPool == nil || backendPool.Properties == nil || len(backendPool.Properties.BackendIPConfigurations) == 0 {
+		return nil
+	}
+
+	backendNodeNames := utilsets.NewString()
+	for _, node := range backendNodes {
+		backendNodeNames.Insert(node.Name)
+	}
+
+	var ipConfigsToBeDeleted []*armnetwork.InterfaceIPConfiguration
+	for _, ipConf := range backendPool.Properties.BackendIPConfigurations {
+		ipConfID := ptr.Deref(ipConf.ID, "")
+		nodeName, _, err := bc.VMSet.GetNodeNameByIPConfigurationID(ctx, ipConfID)
+		if err != nil {
+			if errors.Is(err, cloudprovider.InstanceNotFound) {
+				continue
+			}
+			return err
+		}
+		if !backendNodeNames.Has(nodeName) {
+			klog.V(2).Infof("bc.EnsureHostsInPool for service (%s): node %s is not a backend node, decouple it from the backend pool %s", getServiceName(service), nodeName, backendPoolID)
+			ipConfigsToBeDeleted = append(ipConfigsToBeDeleted, &armnetwork.InterfaceIPConfiguration{ID: ptr.To(ipConfID)})
+		}
+	}
+	if len(ipConfigsToBeDeleted) == 0 {
+		return nil
+	}
+
+	_, err := bc.ensureBackendPoolDeleted(ctx, service, []string{backendPoolID}, vmSetName, []*armnetwork.BackendAddressPool{
+		{
+			ID: ptr.To(backendPoolID),
+			Properties: &armnetwork.BackendAddressPoolPropertiesFormat{
+				BackendIPConfigurations: ipConfigsToBeDeleted,
+			},
+		},
+	}, false)
+	return err
+}
+
 func isLBBackendPoolsExisting(lbBackendPoolNames map[bool]string, bpName *string) (found, isIPv6 bool) {
 	if strings.EqualFold(ptr.Deref(bpName, ""), lbBackendPoolNames[consts.IPVersionIPv4]) {
 		isIPv6 = false
@@ -142,7 +202,7 @@ func (bc *backendPoolTypeNodeIPConfig) CleanupVMSetFromBackendPoolByCondition(ct
 			findBackendpoolToBeDeleted(consts.IPVersionIPv6)
 		}
 		// decouple the backendPool from the node
-		shouldRefreshLB, err := bc.VMSet.EnsureBackendPoolDeleted(ctx, service, lbBackendPoolIDsSlice, vmSetName, backendpoolToBeDeleted, true)
+		shouldRefreshLB, err := bc.ensureBackendPoolDeleted(ctx, service, lbBackendPoolIDsSlice, vmSetName, backendpoolToBeDeleted, true)
 		if err != nil {
 			return nil, err
 		}
@@ -260,7 +320,7 @@ func (bc *backendPoolTypeNodeIPConfig) ReconcileBackendPools(
 	}
 	if len(backendpoolToBeDeleted) > 0 {
 		// decouple the backendPool from the node
-		updated, err := bc.VMSet.EnsureBackendPoolDeleted(ctx, service, lbBackendPoolIDsSlice, vmSetName, backendpoolToBeDeleted, false)
+		updated, err := bc.ensureBackendPoolDeleted(ctx, service, lbBackendPoolIDsSlice, vmSetName, backendpoolToBeDeleted, false)
 		if err != nil {
 			return false, false, nil, err
 		}
@@ -287,6 +347,27 @@ func (bc *backendPoolTypeNodeIPConfig) ReconcileBackendPools(
 		backendPoolsCreated = true
 	}
 
+	// The service whose backend nodes are restricted to some zones or a vmSet has dedicated backend pools
+	// besides the ones of the cluster, which are still needed by the outbound rules.
+	if bc.hasServiceBackendPool(service) {
+		serviceBackendPoolNames := bc.getBackendPoolNamesForService(service, clusterName)
+		foundServiceBackendPools := map[bool]bool{}
+		for _, bp := range lb.Properties.BackendAddressPools {
+			if found, isIPv6 := isLBBackendPoolsExisting(serviceBackendPoolNames, bp.Name); found {
+				foundServiceBackendPools[isIPv6] = true
+			}
+		}
+		for _, ipFamily := range service.Spec.IPFamilies {
+			if foundServiceBackendPools[ipFamily == v1.IPv6Protocol] {
+				continue
+			}
+			serviceBackendPoolName := serviceBackendPoolNames[ipFamily == v1.IPv6Protocol]
+			klog.V(2).Infof("bc.ReconcileBackendPools for service (%s): creating the service backend pool %s", serviceName, serviceBackendPoolName)
+			newBackendPool(lb, false, bc.PreConfiguredBackendPoolLoadBalancerTypes, serviceName, serviceBackendPoolName)
+			backendPoolsCreated = true
+		}
+	}
+
 	if isMigration {
 		defer func() {
 			mc.ObserveOperationWithResult(isOperationSucceeded)
@@ -407,11 +488,17 @@ func (bi *backendPoolTypeNodeIP) EnsureHostsInPool(ctx context.Context, service
 		backendPool = &armnetwork.BackendAddressPool{}
 	}
 	isIPv6 := isBackendPoolIPv6(ptr.Deref(backendPool.Name, ""))
+	// The nodes out of the backend zones of the service are removed from its backend pool. The nodes
+	// of the backend pool of the per-node inbound NAT rules are selected by the caller.
+	isNATBackendPool := isNodeInboundNATBackendPool(service, ptr.Deref(backendPool.Name, ""))
+	if !isNATBackendPool {
+		nodes = filterNodesByServiceBackendZones(service, nodes)
+	}
 
 	var (
-		changed               bool
-		numOfAdd, numOfDelete int
-		activeNodes           *utilsets.IgnoreCaseSet
+		changed                           bool
+		numOfAdd, numOfDelete, numOfDrain int
+		activeNodes                       *utilsets.IgnoreCaseSet
 	)
 	if bi.UseMultipleStandardLoadBalancers() {
 		if !isLocalService(service) {
@@ -436,6 +523,9 @@ func (bi *backendPoolTypeNodeIP) EnsureHostsInPool(ctx context.Context, service
 	}
 
 	lbBackendPoolName := bi.getBackendPoolNameForService(service, clusterName, isIPv6)
+	if isNATBackendPool {
+		lbBackendPoolName = ptr.Deref(backendPool.Name, "")
+	}
 	if strings.EqualFold(ptr.Deref(backendPool.Name, ""), lbBackendPoolName) &&
 		backendPool.Properties != nil {
 		if backendPool.Properties.LoadBalancerBackendAddresses == nil {
@@ -472,6 +562,11 @@ func (bi *backendPoolTypeNodeIP) EnsureHostsInPool(ctx context.Context, service
 				}
 			}
 
+			if draining, _ := bi.getNodeDrainState(node.Name); draining {
+				klog.V(4).Infof("bi.EnsureHostsInPool: skipping draining node %s", node.Name)
+				continue
+			}
+
 			if !existingIPs.Has(privateIP) {
 				name := node.Name
 				klog.V(6).Infof("bi.EnsureHostsInPool: adding %s with ip address %s", name, privateIP)
@@ -484,6 +579,37 @@ func (bi *backendPoolTypeNodeIP) EnsureHostsInPool(ctx context.Context, service
 		var nodeIPsToBeDeleted []string
 		for _, loadBalancerBackendAddress := range backendPool.Properties.LoadBalancerBackendAddresses {
 			ip := ptr.Deref(loadBalancerBackendAddress.Properties.IPAddress, "")
+			if bi.isLoadBalancerBackendDrainEnabled() {
+				// The backend addresses of a draining node are set to admin state Down so that
+				// the existing flows can finish, and are removed after the drain times out.
+				nodeName := bi.getBackendAddressNodeName(loadBalancerBackendAddress)
+				if draining, timedOut := bi.getNodeDrainState(nodeName); draining {
+					if timedOut {
+						klog.V(4).Infof("bi.EnsureHostsInPool: removing IP %s because the drain of node %s has timed out", ip, nodeName)
+						nodeIPsToBeDeleted = append(nodeIPsToBeDeleted, ip)
+						changed = true
+						numOfDelete++
+						continue
+					}
+					if setBackendAddressAdminState(loadBalancerBackendAddress, armnetwork.LoadBalancerBackendAddressAdminStateDown) {
+						klog.V(4).Infof("bi.EnsureHostsInPool: draining IP %s of node %s", ip, nodeName)
+						changed = true
+						numOfDrain++
+					}
+					backendPoolID := ptr.Deref(backendPool.ID, "")
+					if backendPoolID == "" {
+						backendPoolID = bi.getBackendPoolID(lbName, ptr.Deref(backendPool.Name, ""))
+					}
+					bi.recordNodeDrainBackendPool(nodeName, backendPoolID)
+					continue
+				}
+				if ptr.Deref(loadBalancerBackendAddress.Properties.AdminState, "") == armnetwork.LoadBalancerBackendAddressAdminStateDown &&
+					nodePrivateIPsSet.Has(ip) &&
+					setBackendAddressAdminState(loadBalancerBackendAddress, armnetwork.LoadBalancerBackendAddressAdminStateNone) {
+					klog.V(4).Infof("bi.EnsureHostsInPool: resetting the admin state of IP %s because node %s is not draining", ip, nodeName)
+					changed = true
+				}
+			}
 			if !nodePrivateIPsSet.Has(ip) {
 				klog.V(4).Infof("bi.EnsureHostsInPool: removing IP %s because it is deleted or should be excluded", ip)
 				nodeIPsToBeDeleted = append(nodeIPsToBeDeleted, ip)
@@ -506,7 +632,7 @@ func (bi *backendPoolTypeNodeIP) EnsureHostsInPool(ctx context.Context, service
 		removeNodeIPAddressesFromBackendPool(backendPool, nodeIPsToBeDeleted, false, bi.UseMultipleStandardLoadBalancers(), true)
 	}
 	if changed {
-		klog.V(2).Infof("bi.EnsureHostsInPool: updating backend pool %s of load balancer %s to add %d nodes and remove %d nodes", lbBackendPoolName, lbName, numOfAdd, numOfDelete)
+		klog.V(2).Infof("bi.EnsureHostsInPool: updating backend pool %s of load balancer %s to add %d nodes, remove %d nodes and drain %d nodes", lbBackendPoolName, lbName, numOfAdd, numOfDelete, numOfDrain)
 		if err := bi.CreateOrUpdateLBBackendPool(ctx, lbName, backendPool); err != nil {
 			return fmt.Errorf("bi.EnsureHostsInPool: failed to update backend pool %s: %w", lbBackendPoolName, err)
 		}
@@ -661,7 +787,7 @@ func (bi *backendPoolTypeNodeIP) ReconcileBackendPools(ctx context.Context, clus
 		// 3. Decouple vmss from the lb if the backend pool is empty when using
 		// ip-based LB. Ref: https://github.com/kubernetes-sigs/cloud-provider-azure/pull/2829.
 		klog.V(2).Infof("bi.ReconcileBackendPools for service (%s) and vmSet (%s): ensuring the LB is decoupled from the VMSet", serviceName, vmSetName)
-		shouldRefreshLB, err = bi.VMSet.EnsureBackendPoolDeleted(ctx, service, lbBackendPoolIDsSlice, vmSetName, lb.Properties.BackendAddressPools, true)
+		shouldRefreshLB, err = bi.ensureBackendPoolDeleted(ctx, service, lbBackendPoolIDsSlice, vmSetName, lb.Properties.BackendAddressPools, true)
 		if err != nil {
 			klog.Errorf("bi.ReconcileBackendPools for service (%s): failed to EnsureBackendPoolDeleted: %s", serviceName, err.Error())
 			return false, false, nil, err
@@ -746,8 +872,13 @@ func (bi *backendPoolTypeNodeIP) ReconcileBackendPools(ctx context.Context, clus
 }
 
 func (bi *backendPoolTypeNodeIP) GetBackendPrivateIPs(_ context.Context, clusterName string, service *v1.Service, lb *armnetwork.LoadBalancer) ([]string, []string) {
+	return bi.getIPBasedBackendPrivateIPs(clusterName, service, lb)
+}
+
+// getIPBasedBackendPrivateIPs returns the IPs in the IP-based backend pools of the service.
+func (az *Cloud) getIPBasedBackendPrivateIPs(clusterName string, service *v1.Service, lb *armnetwork.LoadBalancer) ([]string, []string) {
 	serviceName := getServiceName(service)
-	lbBackendPoolNames := bi.getBackendPoolNamesForService(service, clusterName)
+	lbBackendPoolNames := az.getBackendPoolNamesForService(service, clusterName)
 	if lb.Properties == nil || lb.Properties.BackendAddressPools == nil {
 		return nil, nil
 	}
@@ -779,6 +910,170 @@ func (bi *backendPoolTypeNodeIP) GetBackendPrivateIPs(_ context.Context, cluster
 	return backendPrivateIPv4s.UnsortedList(), backendPrivateIPv6s.UnsortedList()
 }
 
+// backendPoolTypePodIP adds the IPs of the pods to the backend pools, which requires the pod IPs to be routable
+// in the virtual network, e.g., with Azure CNI. Each service has its own backend pools, which are
+// populated by the ready addresses of the EndpointSlices of the service.
+type backendPoolTypePodIP struct {
+	*Cloud
+}
+
+func newBackendPoolTypePodIP(c *Cloud) BackendPool {
+	return &backendPoolTypePodIP{c}
+}
+
+// EnsureHostsInPool ensures the ready endpoints of the service join its backend pool. The nodes
+// join the backend pool of the cluster, which is used by the outbound rules and the outbound SNAT of the nodes.
+func (bp *backendPoolTypePodIP) EnsureHostsInPool(ctx context.Context, service *v1.Service, nodes []*v1.Node, _, _, clusterName, lbName string, backendPool *armnetwork.BackendAddressPool) error {
+	if backendPool == nil || backendPool.Properties == nil {
+		return nil
+	}
+	isIPv6 := isBackendPoolIPv6(ptr.Deref(backendPool.Name, ""))
+	if strings.EqualFold(ptr.Deref(backendPool.Name, ""), getBackendPoolName(clusterName, isIPv6)) {
+		return bp.ensureNodesInClusterBackendPool(ctx, nodes, lbName, backendPool)
+	}
+	lbBackendPoolName := bp.getBackendPoolNameForService(service, clusterName, isIPv6)
+	if !strings.EqualFold(ptr.Deref(backendPool.Name, ""), lbBackendPoolName) {
+		return nil
+	}
+
+	key := strings.ToLower(getServiceName(service))
+	if si, found := bp.getLocalServiceInfo(key); found && !strings.EqualFold(si.lbName, lbName) {
+		klog.V(4).InfoS("bp.EnsureHostsInPool: the service is not on the load balancer",
+			"service", key,
+			"previous load balancer", lbName,
+			"current load balancer", si.lbName)
+		return nil
+	}
+
+	backendIPs := bp.getServiceBackendIPs(service)
+	if backendIPs == nil {
+		klog.V(2).Infof("bp.EnsureHostsInPool: skipping backend pool %s because the endpoints of service %s are unknown", lbBackendPoolName, key)
+		return nil
+	}
+	expectedIPs := utilsets.NewString()
+	for _, ip := range backendIPs {
+		if utilnet.IsIPv6String(ip) == isIPv6 {
+			expectedIPs.Insert(ip)
+		}
+	}
+
+	ipsToBeAdded, ipsToBeDeleted, changed := bp.updateBackendPoolIPAddresses(backendPool, expectedIPs, true)
+	if changed {
+		klog.V(2).Infof("bp.EnsureHostsInPool: updating backend pool %s of load balancer %s to add %d pod IPs and remove %d pod IPs", lbBackendPoolName, lbName, len(ipsToBeAdded), len(ipsToBeDeleted))
+		if err := bp.CreateOrUpdateLBBackendPool(ctx, lbName, backendPool); err != nil {
+			return fmt.Errorf("bp.EnsureHostsInPool: failed to update backend pool %s: %w", lbBackendPoolName, err)
+		}
+		bp.recordRemovedBackendPodIPs(key, ipsToBeDeleted)
+	}
+	return nil
+}
+
+// ensureNodesInClusterBackendPool ensures the private IPs of the nodes are in the backend pool of the cluster.
+// When using multiple standard load balancers, only the active nodes of the load balancer are kept.
+func (bp *backendPoolTypePodIP) ensureNodesInClusterBackendPool(ctx context.Context, nodes []*v1.Node, lbName string, backendPool *armnetwork.BackendAddressPool) error {
+	lbBackendPoolName := ptr.Deref(backendPool.Name, "")
+	if isNICPool(backendPool) {
+		klog.V(4).InfoS("bp.EnsureHostsInPool: skipping NIC-based backend pool", "backendPoolName", lbBackendPoolName)
+		return nil
+	}
+
+	var activeNodes *utilsets.IgnoreCaseSet
+	if bp.UseMultipleStandardLoadBalancers() {
+		activeNodes = bp.getActiveNodesByLoadBalancerName(lbName)
+	}
+	isIPv6 := isBackendPoolIPv6(lbBackendPoolName)
+	expectedIPs := utilsets.NewString()
+	for _, node := range nodes {
+		if isControlPlaneNode(node) {
+			klog.V(4).Infof("bp.EnsureHostsInPool: skipping control plane node %s", node.Name)
+			continue
+		}
+		if activeNodes != nil && !activeNodes.Has(node.Name) {
+			klog.V(4).Infof("bp.EnsureHostsInPool: node %s should not be in load balancer %q", node.Name, lbName)
+			continue
+		}
+		if privateIP := getNodePrivateIPAddress(node, isIPv6); privateIP != "" {
+			expectedIPs.Insert(privateIP)
+		}
+	}
+
+	nodeIPsToBeAdded, nodeIPsToBeDeleted, changed := bp.updateBackendPoolIPAddresses(backendPool, expectedIPs, bp.UseMultipleStandardLoadBalancers())
+	if changed {
+		klog.V(2).Infof("bp.EnsureHostsInPool: updating backend pool %s of load balancer %s to add %d nodes and remove %d nodes", lbBackendPoolName, lbName, len(nodeIPsToBeAdded), len(nodeIPsToBeDeleted))
+		if err := bp.CreateOrUpdateLBBackendPool(ctx, lbName, backendPool); err != nil {
+			return fmt.Errorf("bp.EnsureHostsInPool: failed to update backend pool %s: %w", lbBackendPoolName, err)
+		}
+	}
+	return nil
+}
+
+// updateBackendPoolIPAddresses adds the expected IPs missing in the backend pool and removes the unexpected ones.
+// The backend pool is allowed to be empty afterwards only if allowEmpty is true.
+func (bp *backendPoolTypePodIP) updateBackendPoolIPAddresses(backendPool *armnetwork.BackendAddressPool, expectedIPs *utilsets.IgnoreCaseSet, allowEmpty bool) ([]string, []string, bool) {
+	var ipsToBeAdded, ipsToBeDeleted []string
+	for _, ip := range expectedIPs.UnsortedList() {
+		if !hasIPAddressInBackendPool(backendPool, ip) {
+			ipsToBeAdded = append(ipsToBeAdded, ip)
+		}
+	}
+	for _, address := range backendPool.Properties.LoadBalancerBackendAddresses {
+		if address.Properties == nil {
+			continue
+		}
+		if ip := ptr.Deref(address.Properties.IPAddress, ""); !expectedIPs.Has(ip) {
+			ipsToBeDeleted = append(ipsToBeDeleted, ip)
+		}
+	}
+
+	changed := bp.addNodeIPAddressesToBackendPool(backendPool, ipsToBeAdded)
+	if removeNodeIPAddressesFromBackendPool(backendPool, ipsToBeDeleted, false, allowEmpty, true) {
+		changed = true
+	}
+	return ipsToBeAdded, ipsToBeDeleted, changed
+}
+
+// CleanupVMSetFromBackendPoolByCondition removes the nodes of the unwanted vmSet from the backend pool of the cluster.
+// The backend pools of the services are not changed because the pods do not belong to the vmSets.
+func (bp *backendPoolTypePodIP) CleanupVMSetFromBackendPoolByCondition(ctx context.Context, slb *armnetwork.LoadBalancer, service *v1.Service, nodes []*v1.Node, clusterName string, shouldRemoveVMSetFromSLB func(string) bool) (*armnetwork.LoadBalancer, error) {
+	return newBackendPoolTypeNodeIP(bp.Cloud).CleanupVMSetFromBackendPoolByCondition(ctx, slb, service, nodes, clusterName, shouldRemoveVMSetFromSLB)
+}
+
+// ReconcileBackendPools creates the backend pools of the service and the backend pools of the cluster,
+// which keep the nodes for the outbound connectivity, if they do not exist.
+// The pre-configured backend pools are not supported because the backend pools are owned by the service.
+func (bp *backendPoolTypePodIP) ReconcileBackendPools(_ context.Context, clusterName string, service *v1.Service, lb *armnetwork.LoadBalancer) (bool, bool, *armnetwork.LoadBalancer, error) {
+	serviceName := getServiceName(service)
+
+	var backendPoolsUpdated bool
+	for _, lbBackendPoolNames := range []map[bool]string{
+		bp.getBackendPoolNamesForService(service, clusterName),
+		getBackendPoolNames(clusterName),
+	} {
+		foundBackendPools := map[bool]bool{}
+		for _, backendPool := range lb.Properties.BackendAddressPools {
+			if found, isIPv6 := isLBBackendPoolsExisting(lbBackendPoolNames, backendPool.Name); found {
+				klog.V(10).Infof("bp.ReconcileBackendPools for service (%s): found wanted backendpool. Not adding anything", serviceName)
+				foundBackendPools[isIPv6] = true
+			}
+		}
+
+		for _, ipFamily := range service.Spec.IPFamilies {
+			isIPv6 := ipFamily == v1.IPv6Protocol
+			if foundBackendPools[isIPv6] {
+				continue
+			}
+			newBackendPool(lb, false, bp.PreConfiguredBackendPoolLoadBalancerTypes, serviceName, lbBackendPoolNames[isIPv6])
+			backendPoolsUpdated = true
+		}
+	}
+	return false, backendPoolsUpdated, lb, nil
+}
+
+// GetBackendPrivateIPs returns the pod IPs in the backend pools of the service.
+func (bp *backendPoolTypePodIP) GetBackendPrivateIPs(_ context.Context, clusterName string, service *v1.Service, lb *armnetwork.LoadBalancer) ([]string, []string) {
+	return bp.getIPBasedBackendPrivateIPs(clusterName, service, lb)
+}
+
 // getBackendPoolNameForService returns all node names in the backend pool.
 func (bi *backendPoolTypeNodeIP) getBackendPoolNodeNames(bp *armnetwork.BackendAddressPool) []string {
 	nodeNames := utilsets.NewString()
@@ -840,7 +1135,10 @@ func (az *Cloud) addNodeIPAddressesToBackendPool(backendPool *armnetwork.Backend
 	addresses := backendPool.Properties.LoadBalancerBackendAddresses
 	for _, ipAddress := range nodeIPAddresses {
 		if !hasIPAddressInBackendPool(backendPool, ipAddress) {
-			name := az.nodePrivateIPToNodeNameMap[ipAddress]
+			name, ok := az.nodePrivateIPToNodeNameMap[ipAddress]
+			if !ok && az.IsLBBackendPoolTypePodIP() {
+				name = getPodIPBackendAddressName(ipAddress)
+			}
 			klog.V(4).Infof("bi.addNodeIPAddressesToBackendPool: adding %s to the backend pool %s", ipAddress, ptr.Deref(backendPool.Name, ""))
 			addresses = append(addresses, &armnetwork.LoadBalancerBackendAddress{
 				Name: ptr.To(name),
@@ -855,6 +1153,12 @@ func (az *Cloud) addNodeIPAddressesToBackendPool(backendPool *armnetwork.Backend
 	return changed
 }
 
+// getPodIPBackendAddressName returns the name of the backend address of the pod IP,
+// which cannot contain the colons in the IPv6 addresses.
+func getPodIPBackendAddressName(ip string) string {
+	return strings.ReplaceAll(ip, ":", "-")
+}
+
 func hasIPAddressInBackendPool(backendPool *armnetwork.BackendAddressPool, ipAddress string) bool {
 	if backendPool.Properties.LoadBalancerBackendAddresses == nil {
 		return false
@@ -31,6 +31,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	v1 "k8s.io/api/core/v1"
+	discovery_v1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	cloudprovider "k8s.io/cloud-provider"
@@ -448,6 +449,147 @@ func TestEnsureHostsInPoolNodeIP(t *testing.T) {
 	}
 }
 
+func TestEnsureHostsInPoolPodIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	getPodIPEndpointSlice := func(endpoints ...discovery_v1.Endpoint) *discovery_v1.EndpointSlice {
+		return &discovery_v1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "eps1",
+				Namespace: "default",
+				Labels: map[string]string{
+					consts.ServiceNameLabel: "test",
+				},
+			},
+			Endpoints: endpoints,
+		}
+	}
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "master",
+				Labels: map[string]string{consts.ControlPlaneNodeRoleLabel: "true"},
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "vmss-0"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+					{Type: v1.NodeInternalIP, Address: "2001::2"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		description   string
+		backendPool   *armnetwork.BackendAddressPool
+		existingEPS   *discovery_v1.EndpointSlice
+		serviceLBName string
+		expectedIPs   []string
+		expectedNames map[string]string
+	}{
+		{
+			description: "should add the ready pod IPs and remove the stale ones",
+			backendPool: getTestBackendAddressPoolWithIPs("kubernetes", "default-test", []string{"10.244.0.1"}),
+			existingEPS: getPodIPEndpointSlice(
+				discovery_v1.Endpoint{Addresses: []string{"10.244.0.2"}},
+				discovery_v1.Endpoint{Addresses: []string{"10.244.0.3"}, Conditions: discovery_v1.EndpointConditions{Ready: ptr.To(true)}},
+				discovery_v1.Endpoint{Addresses: []string{"10.244.0.4"}, Conditions: discovery_v1.EndpointConditions{Ready: ptr.To(false)}},
+			),
+			expectedIPs: []string{"10.244.0.2", "10.244.0.3"},
+		},
+		{
+			description: "should only add the pod IPs of the same IP family",
+			backendPool: getTestBackendAddressPoolWithIPs("kubernetes", "default-test-ipv6", []string{}),
+			existingEPS: getPodIPEndpointSlice(
+				discovery_v1.Endpoint{Addresses: []string{"10.244.0.2"}},
+				discovery_v1.Endpoint{Addresses: []string{"fd00::2"}},
+			),
+			expectedIPs: []string{"fd00::2"},
+		},
+		{
+			description: "should not update the backend pool if the pod IPs are not changed",
+			backendPool: getTestBackendAddressPoolWithIPs("kubernetes", "default-test", []string{"10.244.0.2"}),
+			existingEPS: getPodIPEndpointSlice(discovery_v1.Endpoint{Addresses: []string{"10.244.0.2"}}),
+		},
+		{
+			description: "should not update the backend pool if the endpointslice is not found",
+			backendPool: getTestBackendAddressPoolWithIPs("kubernetes", "default-test", []string{"10.244.0.1"}),
+		},
+		{
+			description: "should not update the backend pool of other services",
+			backendPool: getTestBackendAddressPoolWithIPs("kubernetes", "default-other", []string{"10.244.0.1"}),
+			existingEPS: getPodIPEndpointSlice(discovery_v1.Endpoint{Addresses: []string{"10.244.0.2"}}),
+		},
+		{
+			description:   "should keep the nodes in the backend pool of the cluster",
+			backendPool:   getTestBackendAddressPoolWithIPs("kubernetes", "kubernetes", []string{"10.0.0.3"}),
+			existingEPS:   getPodIPEndpointSlice(discovery_v1.Endpoint{Addresses: []string{"10.244.0.2"}}),
+			expectedIPs:   []string{"10.0.0.2"},
+			expectedNames: map[string]string{"10.0.0.2": "vmss-0"},
+		},
+		{
+			description:   "should keep the nodes in the IPv6 backend pool of the cluster",
+			backendPool:   getTestBackendAddressPoolWithIPs("kubernetes", "kubernetes-IPv6", []string{}),
+			expectedIPs:   []string{"2001::2"},
+			expectedNames: map[string]string{"2001::2": "vmss-0"},
+		},
+		{
+			description: "should not update the backend pool of the cluster if the nodes are in it",
+			backendPool: getTestBackendAddressPoolWithIPs("kubernetes", "kubernetes", []string{"10.0.0.2"}),
+		},
+		{
+			description:   "should not update the backend pool if the service is on another load balancer",
+			backendPool:   getTestBackendAddressPoolWithIPs("kubernetes", "default-test", []string{"10.244.0.1"}),
+			existingEPS:   getPodIPEndpointSlice(discovery_v1.Endpoint{Addresses: []string{"10.244.0.2"}}),
+			serviceLBName: "another-lb",
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypePODIP
+			if tc.existingEPS != nil {
+				az.endpointSlicesCache.Store(fmt.Sprintf("%s/%s", tc.existingEPS.Namespace, tc.existingEPS.Name), tc.existingEPS)
+			}
+			if tc.serviceLBName != "" {
+				az.localServiceNameToServiceInfoMap.Store("default/test", newServiceInfo(consts.IPVersionIPv4String, tc.serviceLBName))
+			}
+			az.nodePrivateIPToNodeNameMap = map[string]string{"10.0.0.2": "vmss-0", "2001::2": "vmss-0"}
+			service := getTestService("test", v1.ProtocolTCP, nil, false, 80)
+
+			bpClient := az.NetworkClientFactory.GetBackendAddressPoolClient().(*mock_backendaddresspoolclient.MockInterface)
+			if tc.expectedIPs != nil {
+				bpClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), "kubernetes", ptr.Deref(tc.backendPool.Name, ""), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _, _, _ string, bp armnetwork.BackendAddressPool) (*armnetwork.BackendAddressPool, error) {
+						var ips []string
+						for _, address := range bp.Properties.LoadBalancerBackendAddresses {
+							ip := ptr.Deref(address.Properties.IPAddress, "")
+							ips = append(ips, ip)
+							expectedName, ok := tc.expectedNames[ip]
+							if !ok {
+								expectedName = getPodIPBackendAddressName(ip)
+							}
+							assert.Equal(t, expectedName, ptr.Deref(address.Name, ""))
+						}
+						assert.ElementsMatch(t, tc.expectedIPs, ips)
+						return &bp, nil
+					})
+			}
+
+			bp := newBackendPoolTypePodIP(az)
+			err := bp.EnsureHostsInPool(context.TODO(), &service, nodes, "", "", "kubernetes", "kubernetes", tc.backendPool)
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestIsLBBackendPoolsExisting(t *testing.T) {
 	testcases := []struct {
 		desc               string
@@ -843,6 +985,39 @@ func TestReconcileBackendPoolsNodeIP(t *testing.T) {
 	assert.True(t, changed)
 }
 
+func TestReconcileBackendPoolsPodIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypePODIP
+	bp := newBackendPoolTypePodIP(az)
+
+	service := getTestServiceDualStack("test", v1.ProtocolTCP, nil, 80)
+	lb := &armnetwork.LoadBalancer{
+		Name: ptr.To(testClusterName),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			BackendAddressPools: []*armnetwork.BackendAddressPool{
+				{Name: ptr.To(testClusterName)},
+				{Name: ptr.To("default-test")},
+			},
+		},
+	}
+	preConfigured, changed, updatedLB, err := bp.ReconcileBackendPools(context.TODO(), testClusterName, &service, lb)
+	assert.NoError(t, err)
+	assert.False(t, preConfigured)
+	assert.True(t, changed)
+	var backendPoolNames []string
+	for _, backendPool := range updatedLB.Properties.BackendAddressPools {
+		backendPoolNames = append(backendPoolNames, ptr.Deref(backendPool.Name, ""))
+	}
+	assert.Equal(t, []string{testClusterName, "default-test", "default-test-ipv6", testClusterName + "-IPv6"}, backendPoolNames)
+
+	_, changed, _, err = bp.ReconcileBackendPools(context.TODO(), testClusterName, &service, updatedLB)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
 func TestReconcileBackendPoolsNodeIPEmptyPool(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1174,3 +1349,156 @@ func TestGetBackendIPConfigurationsToBeDeleted(t *testing.T) {
 		assert.Equal(t, tc.expected, actual)
 	}
 }
+
+func getTestZonalNode(name, zone, ip string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1.LabelTopologyZone: zone},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: ip,
+				},
+			},
+		},
+	}
+}
+
+func TestEnsureHostsInPoolNodeIPBackendZones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	nodes := []*v1.Node{
+		getTestZonalNode("node1", "eastus-1", "10.0.0.1"),
+		getTestZonalNode("node2", "eastus-2", "10.0.0.2"),
+	}
+	service := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerBackendZones: "1",
+	}, false, 80)
+	backendPool := getTestBackendAddressPoolWithIPs("kubernetes", "default-test", []string{"10.0.0.2"})
+
+	bpClient := az.NetworkClientFactory.GetBackendAddressPoolClient().(*mock_backendaddresspoolclient.MockInterface)
+	bpClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), "kubernetes", "default-test", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, _ string, bp armnetwork.BackendAddressPool) (*armnetwork.BackendAddressPool, error) {
+			assert.Equal(t, 1, len(bp.Properties.LoadBalancerBackendAddresses))
+			assert.Equal(t, "10.0.0.1", ptr.Deref(bp.Properties.LoadBalancerBackendAddresses[0].Properties.IPAddress, ""))
+			return &bp, nil
+		})
+
+	bi := newBackendPoolTypeNodeIP(az)
+	err := bi.EnsureHostsInPool(context.Background(), &service, nodes, "", "", "kubernetes", "kubernetes", backendPool)
+	assert.NoError(t, err)
+}
+
+func TestEnsureHostsInPoolNodeIPNodeInboundNATBackendPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	nodes := []*v1.Node{
+		getTestZonalNode("node1", "eastus-1", "10.0.0.1"),
+		getTestZonalNode("node2", "eastus-2", "10.0.0.2"),
+	}
+	// The backend zones of the service do not apply to the backend pool of the inbound NAT rules.
+	service := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerBackendZones:                    "1",
+		consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50000-50099",
+		consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+		consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+	}, false, 80)
+	backendPool := getTestBackendAddressPoolWithIPs("kubernetes", "default-test_nat", []string{"10.0.0.2"})
+
+	bpClient := az.NetworkClientFactory.GetBackendAddressPoolClient().(*mock_backendaddresspoolclient.MockInterface)
+	bpClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), "kubernetes", "default-test_nat", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, _ string, bp armnetwork.BackendAddressPool) (*armnetwork.BackendAddressPool, error) {
+			assert.Equal(t, 2, len(bp.Properties.LoadBalancerBackendAddresses))
+			return &bp, nil
+		})
+
+	bi := newBackendPoolTypeNodeIP(az)
+	err := bi.EnsureHostsInPool(context.Background(), &service, nodes, "", "", "kubernetes", "kubernetes", backendPool)
+	assert.NoError(t, err)
+}
+
+func TestEnsureHostsInPoolNodeIPConfigBackendZones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	nodes := []*v1.Node{
+		getTestZonalNode("node1", "eastus-1", "10.0.0.1"),
+		getTestZonalNode("node2", "eastus-2", "10.0.0.2"),
+	}
+	service := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerBackendZones: "1",
+	}, false, 80)
+	ipConfigID1 := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/node1-nic/ipConfigurations/ipconfig1"
+	ipConfigID2 := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/node2-nic/ipConfigurations/ipconfig1"
+	backendPoolID := az.getBackendPoolID(testClusterName, "default-test")
+	backendPool := &armnetwork.BackendAddressPool{
+		ID:   ptr.To(backendPoolID),
+		Name: ptr.To("default-test"),
+		Properties: &armnetwork.BackendAddressPoolPropertiesFormat{
+			BackendIPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+				{ID: ptr.To(ipConfigID1)},
+				{ID: ptr.To(ipConfigID2)},
+			},
+		},
+	}
+
+	mockVMSet := NewMockVMSet(ctrl)
+	mockVMSet.EXPECT().GetNodeNameByIPConfigurationID(gomock.Any(), ipConfigID1).Return("node1", "", nil)
+	mockVMSet.EXPECT().GetNodeNameByIPConfigurationID(gomock.Any(), ipConfigID2).Return("node2", "", nil)
+	mockVMSet.EXPECT().EnsureBackendPoolDeleted(gomock.Any(), gomock.Any(), []string{backendPoolID}, "vmset", gomock.Any(), false).DoAndReturn(
+		func(_ context.Context, _ *v1.Service, _ []string, _ string, pools []*armnetwork.BackendAddressPool, _ bool) (bool, error) {
+			assert.Equal(t, 1, len(pools))
+			assert.Equal(t, []*armnetwork.InterfaceIPConfiguration{{ID: ptr.To(ipConfigID2)}}, pools[0].Properties.BackendIPConfigurations)
+			return true, nil
+		})
+	mockVMSet.EXPECT().EnsureHostsInPool(gomock.Any(), gomock.Any(), []*v1.Node{nodes[0]}, backendPoolID, "vmset").Return(nil)
+	az.VMSet = mockVMSet
+
+	bc := newBackendPoolTypeNodeIPConfig(az)
+	err := bc.EnsureHostsInPool(context.Background(), &service, nodes, backendPoolID, "vmset", testClusterName, testClusterName, backendPool)
+	assert.NoError(t, err)
+}
+
+func TestReconcileBackendPoolsNodeIPConfigBackendZones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	mockVMSet := NewMockVMSet(ctrl)
+	mockVMSet.EXPECT().GetPrimaryVMSetName().Return("vmset").AnyTimes()
+	az.VMSet = mockVMSet
+
+	lb := buildDefaultTestLB(testClusterName, []string{})
+	service := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerBackendZones: "1,2",
+	}, false, 80)
+
+	bc := newBackendPoolTypeNodeIPConfig(az)
+	_, created, updatedLB, err := bc.ReconcileBackendPools(context.TODO(), testClusterName, &service, &lb)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	var backendPoolNames []string
+	for _, bp := range updatedLB.Properties.BackendAddressPools {
+		backendPoolNames = append(backendPoolNames, ptr.Deref(bp.Name, ""))
+	}
+	assert.Equal(t, []string{testClusterName, "default-test"}, backendPoolNames)
+
+	// The existing zonal backend pool is not created again.
+	_, created, _, err = bc.ReconcileBackendPools(context.TODO(), testClusterName, &service, updatedLB)
+	assert.NoError(t, err)
+	assert.False(t, created)
+}
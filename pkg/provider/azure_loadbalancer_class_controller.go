@@ -0,0 +1,411 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	cloudprovider "k8s.io/cloud-provider"
+	servicehelper "k8s.io/cloud-provider/service/helpers"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+// LoadBalancerClassController reconciles the services whose `spec.loadBalancerClass` is one of the
+// load balancer classes in the cloud config.
+//
+// The service controller of the cloud provider framework skips every service that sets
+// `spec.loadBalancerClass`, so the classed services never reach EnsureLoadBalancer from there.
+// This controller takes over the same duty for the configured classes: it adds a finalizer, ensures
+// the load balancer on the nodes of the cluster, writes back the load balancer status, and releases
+// the Azure resources before the finalizer is removed. Like the service controller, the load balancer is
+// only ensured when the service changes, and the node changes only update the backend pools through
+// UpdateLoadBalancer. It uses its own finalizer because the service
+// controller deletes the load balancer of any classed service carrying the default cleanup finalizer.
+type LoadBalancerClassController struct {
+	balancer    cloudprovider.LoadBalancer
+	az          *Cloud
+	kubeClient  clientset.Interface
+	clusterName string
+
+	serviceLister  corelisters.ServiceLister
+	servicesSynced cache.InformerSynced
+	nodeLister     corelisters.NodeLister
+	nodesSynced    cache.InformerSynced
+
+	queue workqueue.TypedRateLimitingInterface[string]
+	// nodeSyncCh triggers the update of the backend pools after the nodes change.
+	nodeSyncCh chan struct{}
+}
+
+// NewLoadBalancerClassController creates a new LoadBalancerClassController.
+func NewLoadBalancerClassController(
+	az *Cloud,
+	kubeClient clientset.Interface,
+	serviceInformer coreinformers.ServiceInformer,
+	nodeInformer coreinformers.NodeInformer,
+	clusterName string,
+) *LoadBalancerClassController {
+	c := &LoadBalancerClassController{
+		balancer:       az,
+		az:             az,
+		kubeClient:     kubeClient,
+		clusterName:    clusterName,
+		serviceLister:  serviceInformer.Lister(),
+		servicesSynced: serviceInformer.Informer().HasSynced,
+		nodeLister:     nodeInformer.Lister(),
+		nodesSynced:    nodeInformer.Informer().HasSynced,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: consts.LoadBalancerClassControllerName},
+		),
+		nodeSyncCh: make(chan struct{}, 1),
+	}
+
+	_, _ = serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if svc, ok := obj.(*v1.Service); ok && c.isServiceInScope(svc) {
+				c.enqueueService(svc)
+			}
+		},
+		UpdateFunc: func(prev, obj interface{}) {
+			prevSvc, ok1 := prev.(*v1.Service)
+			svc, ok2 := obj.(*v1.Service)
+			if ok1 && ok2 && c.isServiceInScope(svc) && needsLoadBalancerClassUpdate(prevSvc, svc) {
+				c.enqueueService(svc)
+			}
+		},
+	})
+
+	_, _ = nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(_ interface{}) { c.triggerNodeSync() },
+		UpdateFunc: func(prev, obj interface{}) {
+			prevNode, ok1 := prev.(*v1.Node)
+			node, ok2 := obj.(*v1.Node)
+			if ok1 && ok2 && shouldSyncLoadBalancerClassNode(prevNode, node) {
+				c.triggerNodeSync()
+			}
+		},
+		DeleteFunc: func(_ interface{}) { c.triggerNodeSync() },
+	})
+
+	return c
+}
+
+// isServiceManaged returns true if the service wants a load balancer of a class managed by the cloud provider.
+func (c *LoadBalancerClassController) isServiceManaged(service *v1.Service) bool {
+	return service.Spec.Type == v1.ServiceTypeLoadBalancer &&
+		service.Spec.LoadBalancerClass != nil &&
+		c.az.IsLoadBalancerClassManaged(service.Spec.LoadBalancerClass)
+}
+
+// isServiceInScope returns true if the service is reconciled by the controller, including
+// the services whose resources are still to be released.
+func (c *LoadBalancerClassController) isServiceInScope(service *v1.Service) bool {
+	return c.isServiceManaged(service) || hasLoadBalancerClassFinalizer(service)
+}
+
+func (c *LoadBalancerClassController) enqueueService(service *v1.Service) {
+	key, err := cache.MetaNamespaceKeyFunc(service)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for service %s/%s: %w", service.Namespace, service.Name, err))
+		return
+	}
+	c.queue.Add(key)
+}
+
+// triggerNodeSync requests an update of the backend pools. The requests made
+// before the pending one is handled are merged into it.
+func (c *LoadBalancerClassController) triggerNodeSync() {
+	select {
+	case c.nodeSyncCh <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts the LoadBalancerClassController with the given number of workers, and stops if the context exits.
+func (c *LoadBalancerClassController) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	if len(c.az.LoadBalancerClasses) == 0 {
+		klog.V(2).Infof("LoadBalancerClassController.Run: no load balancer class is configured, will not reconcile the classed services")
+		return
+	}
+
+	if !cache.WaitForNamedCacheSync(consts.LoadBalancerClassControllerName, ctx.Done(), c.servicesSynced, c.nodesSynced) {
+		return
+	}
+
+	klog.V(2).Infof("LoadBalancerClassController.Run: started with %d workers", workers)
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, c.worker, time.Second)
+	}
+	go c.nodeSyncLoop(ctx)
+	<-ctx.Done()
+	klog.Infof("LoadBalancerClassController.Run: stopped due to %s", ctx.Err().Error())
+}
+
+func (c *LoadBalancerClassController) worker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *LoadBalancerClassController) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncService(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("error processing service %s (retrying with exponential backoff): %w", key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *LoadBalancerClassController) nodeSyncLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.nodeSyncCh:
+			c.syncNodes(ctx)
+		}
+	}
+}
+
+// syncNodes updates the backend pools of the load balancers of the classed services with the current nodes.
+// The services failing to be updated are requeued, so their load balancers are ensured again with the nodes.
+func (c *LoadBalancerClassController) syncNodes(ctx context.Context) {
+	nodes, err := c.listLoadBalancerNodes()
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list nodes: %w", err))
+		return
+	}
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list services: %w", err))
+		return
+	}
+
+	for _, svc := range services {
+		// The services without the finalizer have not been ensured yet, and are ensured with the nodes by syncService.
+		if !c.isServiceManaged(svc) || svc.DeletionTimestamp != nil || !hasLoadBalancerClassFinalizer(svc) {
+			continue
+		}
+		if err := c.balancer.UpdateLoadBalancer(ctx, c.clusterName, svc, nodes); err != nil {
+			c.az.Event(svc, v1.EventTypeWarning, "UpdateLoadBalancerFailed", fmt.Sprintf("Error updating load balancer with new hosts: %v", err))
+			utilruntime.HandleError(fmt.Errorf("failed to update the load balancer of service %s/%s with new hosts: %w", svc.Namespace, svc.Name, err))
+			key, err := cache.MetaNamespaceKeyFunc(svc)
+			if err == nil {
+				c.queue.AddRateLimited(key)
+			}
+			continue
+		}
+		c.az.Event(svc, v1.EventTypeNormal, "UpdatedLoadBalancer", "Updated load balancer with new hosts")
+	}
+}
+
+// syncService ensures or deletes the load balancer of the service with the given key.
+func (c *LoadBalancerClassController) syncService(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	service, err := c.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The finalizer makes sure the resources are released before the service is gone.
+			return nil
+		}
+		return err
+	}
+
+	if !c.isServiceManaged(service) || service.DeletionTimestamp != nil {
+		return c.deleteLoadBalancer(ctx, service)
+	}
+	return c.ensureLoadBalancer(ctx, service)
+}
+
+func (c *LoadBalancerClassController) ensureLoadBalancer(ctx context.Context, service *v1.Service) error {
+	// Add the finalizer before creating the resources, so the service
+	// cannot be deleted before the resources are released.
+	if err := c.patchFinalizer(service, true); err != nil {
+		return fmt.Errorf("failed to add the load balancer class cleanup finalizer: %w", err)
+	}
+
+	nodes, err := c.listLoadBalancerNodes()
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		c.az.Event(service, v1.EventTypeWarning, "UnAvailableLoadBalancer", "There are no available nodes for LoadBalancer")
+	}
+
+	c.az.Event(service, v1.EventTypeNormal, "EnsuringLoadBalancer", "Ensuring load balancer")
+	status, err := c.balancer.EnsureLoadBalancer(ctx, c.clusterName, service, nodes)
+	if err != nil {
+		c.az.Event(service, v1.EventTypeWarning, "SyncLoadBalancerFailed", fmt.Sprintf("Error syncing load balancer: %v", err))
+		return fmt.Errorf("failed to ensure load balancer: %w", err)
+	}
+	if status == nil {
+		status = &v1.LoadBalancerStatus{}
+	}
+	c.az.Event(service, v1.EventTypeNormal, "EnsuredLoadBalancer", "Ensured load balancer")
+
+	return c.patchStatus(service, status)
+}
+
+func (c *LoadBalancerClassController) deleteLoadBalancer(ctx context.Context, service *v1.Service) error {
+	if !hasLoadBalancerClassFinalizer(service) {
+		return nil
+	}
+
+	c.az.Event(service, v1.EventTypeNormal, "DeletingLoadBalancer", "Deleting load balancer")
+	if err := c.balancer.EnsureLoadBalancerDeleted(ctx, c.clusterName, service); err != nil && !errors.Is(err, cloudprovider.ImplementedElsewhere) {
+		c.az.Event(service, v1.EventTypeWarning, "DeleteLoadBalancerFailed", fmt.Sprintf("Error deleting load balancer: %v", err))
+		return fmt.Errorf("failed to delete load balancer: %w", err)
+	}
+	if err := c.patchStatus(service, &v1.LoadBalancerStatus{}); err != nil {
+		return err
+	}
+	if err := c.patchFinalizer(service, false); err != nil {
+		return fmt.Errorf("failed to remove the load balancer class cleanup finalizer: %w", err)
+	}
+	c.az.Event(service, v1.EventTypeNormal, "DeletedLoadBalancer", "Deleted load balancer")
+	return nil
+}
+
+// listLoadBalancerNodes lists the nodes in the same way as the service controller: the nodes being deleted,
+// excluded from external load balancers or tainted for deletion by the cluster autoscaler are left out.
+func (c *LoadBalancerClassController) listLoadBalancerNodes() ([]*v1.Node, error) {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var result []*v1.Node
+	for _, node := range nodes {
+		if isLoadBalancerClassNodeIncluded(node) {
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
+func (c *LoadBalancerClassController) patchFinalizer(service *v1.Service, add bool) error {
+	if hasLoadBalancerClassFinalizer(service) == add {
+		return nil
+	}
+	updated := service.DeepCopy()
+	if add {
+		updated.Finalizers = append(updated.Finalizers, consts.LoadBalancerClassCleanupFinalizer)
+	} else {
+		updated.Finalizers = nil
+		for _, finalizer := range service.Finalizers {
+			if finalizer != consts.LoadBalancerClassCleanupFinalizer {
+				updated.Finalizers = append(updated.Finalizers, finalizer)
+			}
+		}
+	}
+	klog.V(2).Infof("LoadBalancerClassController: updating finalizers of service %s/%s to %v", service.Namespace, service.Name, updated.Finalizers)
+	_, err := servicehelper.PatchService(c.kubeClient.CoreV1(), service, updated)
+	return err
+}
+
+func (c *LoadBalancerClassController) patchStatus(service *v1.Service, status *v1.LoadBalancerStatus) error {
+	if servicehelper.LoadBalancerStatusEqual(&service.Status.LoadBalancer, status) {
+		return nil
+	}
+	updated := service.DeepCopy()
+	updated.Status.LoadBalancer = *status
+	if _, err := servicehelper.PatchService(c.kubeClient.CoreV1(), service, updated); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to update the load balancer status: %w", err)
+	}
+	return nil
+}
+
+func hasLoadBalancerClassFinalizer(service *v1.Service) bool {
+	for _, finalizer := range service.Finalizers {
+		if finalizer == consts.LoadBalancerClassCleanupFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoadBalancerClassNodeIncluded(node *v1.Node) bool {
+	if !node.DeletionTimestamp.IsZero() {
+		return false
+	}
+	if _, ok := node.Labels[v1.LabelNodeExcludeBalancers]; ok {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == consts.ToBeDeletedByClusterAutoscalerTaintKey {
+			return false
+		}
+	}
+	return true
+}
+
+// needsLoadBalancerClassUpdate returns true if the change of the service requires its load balancer to be ensured
+// or deleted again. The updates of the status and the finalizer made by the controller itself are skipped.
+func needsLoadBalancerClassUpdate(prevService, service *v1.Service) bool {
+	return prevService.UID != service.UID ||
+		(prevService.DeletionTimestamp == nil) != (service.DeletionTimestamp == nil) ||
+		!reflect.DeepEqual(prevService.Spec, service.Spec) ||
+		!reflect.DeepEqual(prevService.Annotations, service.Annotations)
+}
+
+// shouldSyncLoadBalancerClassNode returns true if the change of the node affects the backends of the load balancers.
+func shouldSyncLoadBalancerClassNode(prevNode, node *v1.Node) bool {
+	if isLoadBalancerClassNodeIncluded(prevNode) != isLoadBalancerClassNodeIncluded(node) {
+		return true
+	}
+	return getNodeReadyStatus(prevNode) != getNodeReadyStatus(node)
+}
+
+func getNodeReadyStatus(node *v1.Node) v1.ConditionStatus {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status
+		}
+	}
+	return v1.ConditionUnknown
+}
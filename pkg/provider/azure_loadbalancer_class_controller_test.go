@@ -0,0 +1,263 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+)
+
+// fakeClassBalancer records the calls from the LoadBalancerClassController.
+type fakeClassBalancer struct {
+	ensured      []string
+	ensuredNodes []string
+	updated      []string
+	updatedNodes []string
+	deleted      []string
+}
+
+func (b *fakeClassBalancer) GetLoadBalancer(_ context.Context, _ string, _ *v1.Service) (*v1.LoadBalancerStatus, bool, error) {
+	return nil, false, nil
+}
+
+func (b *fakeClassBalancer) GetLoadBalancerName(_ context.Context, _ string, service *v1.Service) string {
+	return string(service.UID)
+}
+
+func (b *fakeClassBalancer) EnsureLoadBalancer(_ context.Context, _ string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+	b.ensured = append(b.ensured, service.Name)
+	for _, node := range nodes {
+		b.ensuredNodes = append(b.ensuredNodes, node.Name)
+	}
+	return &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}}, nil
+}
+
+func (b *fakeClassBalancer) UpdateLoadBalancer(_ context.Context, _ string, service *v1.Service, nodes []*v1.Node) error {
+	b.updated = append(b.updated, service.Name)
+	for _, node := range nodes {
+		b.updatedNodes = append(b.updatedNodes, node.Name)
+	}
+	return nil
+}
+
+func (b *fakeClassBalancer) EnsureLoadBalancerDeleted(_ context.Context, _ string, service *v1.Service) error {
+	b.deleted = append(b.deleted, service.Name)
+	return nil
+}
+
+func TestLoadBalancerClassControllerSyncService(t *testing.T) {
+	const managedClass = "example.com/azure"
+
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{v1.LabelNodeExcludeBalancers: "true"}}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node3"},
+			Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: consts.ToBeDeletedByClusterAutoscalerTaintKey}}},
+		},
+	}
+	newClassedService := func(class string) *v1.Service {
+		svc := getTestServiceWithLoadBalancerClass("svc", class, 80)
+		svc.Namespace = "default"
+		return &svc
+	}
+
+	for _, tc := range []struct {
+		desc               string
+		service            *v1.Service
+		expectedInScope    bool
+		expectedEnsured    []string
+		expectedDeleted    []string
+		expectedFinalizers []string
+		expectedIngressIP  string
+	}{
+		{
+			desc:               "a service of a managed class should be ensured with a finalizer and a status",
+			service:            newClassedService(managedClass),
+			expectedInScope:    true,
+			expectedEnsured:    []string{"svc"},
+			expectedFinalizers: []string{consts.LoadBalancerClassCleanupFinalizer},
+			expectedIngressIP:  "1.2.3.4",
+		},
+		{
+			desc:            "a service of another class should be left to other controllers",
+			service:         newClassedService("example.com/other"),
+			expectedInScope: false,
+		},
+		{
+			desc: "a service without a class should be left to the service controller",
+			service: func() *v1.Service {
+				svc := getTestService("svc", v1.ProtocolTCP, nil, false, 80)
+				svc.Namespace = "default"
+				return &svc
+			}(),
+			expectedInScope: false,
+		},
+		{
+			desc: "a deleted service of a managed class should be released and its finalizer removed",
+			service: func() *v1.Service {
+				svc := newClassedService(managedClass)
+				svc.DeletionTimestamp = &metav1.Time{}
+				svc.Finalizers = []string{consts.LoadBalancerClassCleanupFinalizer, "other"}
+				svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}
+				return svc
+			}(),
+			expectedInScope:    true,
+			expectedDeleted:    []string{"svc"},
+			expectedFinalizers: []string{"other"},
+		},
+		{
+			desc: "a service switched away from the load balancer type should be released",
+			service: func() *v1.Service {
+				svc := newClassedService(managedClass)
+				svc.Spec.Type = v1.ServiceTypeClusterIP
+				svc.Finalizers = []string{consts.LoadBalancerClassCleanupFinalizer}
+				return svc
+			}(),
+			expectedInScope: true,
+			expectedDeleted: []string{"svc"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: managedClass}}
+			az.eventRecorder = record.NewFakeRecorder(100)
+
+			kubeClient := fake.NewSimpleClientset(tc.service, nodes[0], nodes[1], nodes[2])
+			informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+			c := NewLoadBalancerClassController(az, kubeClient,
+				informerFactory.Core().V1().Services(), informerFactory.Core().V1().Nodes(), "kubernetes")
+			balancer := &fakeClassBalancer{}
+			c.balancer = balancer
+			informerFactory.Start(wait.NeverStop)
+			informerFactory.WaitForCacheSync(wait.NeverStop)
+
+			assert.Equal(t, tc.expectedInScope, c.isServiceInScope(tc.service))
+			if !tc.expectedInScope {
+				return
+			}
+
+			assert.NoError(t, c.syncService(context.Background(), "default/svc"))
+			assert.Equal(t, tc.expectedEnsured, balancer.ensured)
+			assert.Equal(t, tc.expectedDeleted, balancer.deleted)
+			if len(tc.expectedEnsured) > 0 {
+				assert.Equal(t, []string{"node1"}, balancer.ensuredNodes)
+			}
+
+			svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "svc", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedFinalizers, svc.Finalizers)
+			if tc.expectedIngressIP != "" {
+				assert.Equal(t, []v1.LoadBalancerIngress{{IP: tc.expectedIngressIP}}, svc.Status.LoadBalancer.Ingress)
+			} else {
+				assert.Empty(t, svc.Status.LoadBalancer.Ingress)
+			}
+		})
+	}
+}
+
+func TestLoadBalancerClassControllerSyncNodes(t *testing.T) {
+	const managedClass = "example.com/azure"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: managedClass}}
+	az.eventRecorder = record.NewFakeRecorder(100)
+
+	newClassedService := func(name string, ensured bool) *v1.Service {
+		svc := getTestServiceWithLoadBalancerClass(name, managedClass, 80)
+		svc.Namespace = "default"
+		if ensured {
+			svc.Finalizers = []string{consts.LoadBalancerClassCleanupFinalizer}
+		}
+		return &svc
+	}
+	kubeClient := fake.NewSimpleClientset(
+		newClassedService("ensured", true),
+		newClassedService("new", false),
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{v1.LabelNodeExcludeBalancers: "true"}}},
+	)
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	c := NewLoadBalancerClassController(az, kubeClient,
+		informerFactory.Core().V1().Services(), informerFactory.Core().V1().Nodes(), "kubernetes")
+	balancer := &fakeClassBalancer{}
+	c.balancer = balancer
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+
+	c.syncNodes(context.Background())
+	assert.Empty(t, balancer.ensured, "the node changes should not ensure the load balancers")
+	assert.Equal(t, []string{"ensured"}, balancer.updated, "only the ensured services should be updated")
+	assert.Equal(t, []string{"node1"}, balancer.updatedNodes)
+}
+
+func TestNeedsLoadBalancerClassUpdate(t *testing.T) {
+	svc := getTestServiceWithLoadBalancerClass("svc", "example.com/azure", 80)
+
+	statusUpdated := svc.DeepCopy()
+	statusUpdated.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}
+	statusUpdated.Finalizers = []string{consts.LoadBalancerClassCleanupFinalizer}
+	assert.False(t, needsLoadBalancerClassUpdate(&svc, statusUpdated))
+
+	specUpdated := svc.DeepCopy()
+	specUpdated.Spec.Ports[0].Port = 8080
+	assert.True(t, needsLoadBalancerClassUpdate(&svc, specUpdated))
+
+	annotationUpdated := svc.DeepCopy()
+	annotationUpdated.Annotations = map[string]string{consts.ServiceAnnotationLoadBalancerInternal: consts.TrueAnnotationValue}
+	assert.True(t, needsLoadBalancerClassUpdate(&svc, annotationUpdated))
+
+	deleted := svc.DeepCopy()
+	deleted.DeletionTimestamp = ptr.To(metav1.Now())
+	assert.True(t, needsLoadBalancerClassUpdate(&svc, deleted))
+}
+
+func TestShouldSyncLoadBalancerClassNode(t *testing.T) {
+	readyNode := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+	}
+	notReadyNode := readyNode.DeepCopy()
+	notReadyNode.Status.Conditions[0].Status = v1.ConditionFalse
+	excludedNode := readyNode.DeepCopy()
+	excludedNode.Labels = map[string]string{v1.LabelNodeExcludeBalancers: "true"}
+	labeledNode := readyNode.DeepCopy()
+	labeledNode.Labels = map[string]string{"foo": "bar"}
+
+	assert.True(t, shouldSyncLoadBalancerClassNode(readyNode, notReadyNode))
+	assert.True(t, shouldSyncLoadBalancerClassNode(readyNode, excludedNode))
+	assert.False(t, shouldSyncLoadBalancerClassNode(readyNode, labeledNode))
+}
@@ -43,6 +43,32 @@ func (az *Cloud) buildClusterServiceSharedProbe() *armnetwork.Probe {
 	}
 }
 
+// healthProbePortValidator validates the health probe port annotation, which is either the name or the number of
+// a service port, or a port number in range.
+func healthProbePortValidator(serviceManifest *v1.Service) consts.BusinessValidator {
+	return func(s *string) error {
+		if s == nil {
+			return nil
+		}
+		//not a integer
+		for _, item := range serviceManifest.Spec.Ports {
+			if strings.EqualFold(item.Name, *s) {
+				//found the port
+				return nil
+			}
+		}
+		//nolint:gosec
+		port, err := strconv.Atoi(*s)
+		if err != nil {
+			return fmt.Errorf("port %s not found in service", *s)
+		}
+		if port < 0 || port > 65535 {
+			return fmt.Errorf("port %d is out of range", port)
+		}
+		return nil
+	}
+}
+
 // buildHealthProbeRulesForPort
 // for following SKU: basic loadbalancer vs standard load balancer
 // for following protocols: TCP HTTP HTTPS(SLB only)
@@ -66,27 +92,7 @@ func (az *Cloud) buildHealthProbeRulesForPort(serviceManifest *v1.Service, port
 	// global annotation
 	// Lookup or Override Health Probe Port
 
-	probePort, err := consts.GetHealthProbeConfigOfPortFromK8sSvcAnnotation(serviceManifest.Annotations, port.Port, consts.HealthProbeParamsPort, func(s *string) error {
-		if s == nil {
-			return nil
-		}
-		//not a integer
-		for _, item := range serviceManifest.Spec.Ports {
-			if strings.EqualFold(item.Name, *s) {
-				//found the port
-				return nil
-			}
-		}
-		//nolint:gosec
-		port, err := strconv.Atoi(*s)
-		if err != nil {
-			return fmt.Errorf("port %s not found in service", *s)
-		}
-		if port < 0 || port > 65535 {
-			return fmt.Errorf("port %d is out of range", port)
-		}
-		return nil
-	})
+	probePort, err := consts.GetHealthProbeConfigOfPortFromK8sSvcAnnotation(serviceManifest.Annotations, port.Port, consts.HealthProbeParamsPort, healthProbePortValidator(serviceManifest))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse annotation %s: %w", consts.BuildHealthProbeAnnotationKeyForPort(port.Port, consts.HealthProbeParamsPort), err)
 	}
@@ -99,7 +105,11 @@ func (az *Cloud) buildHealthProbeRulesForPort(serviceManifest *v1.Service, port
 			for _, item := range serviceManifest.Spec.Ports {
 				if strings.EqualFold(item.Name, *probePort) {
 					//found the port
-					properties.Port = ptr.To(item.NodePort)
+					backendPort, err := az.getServicePortBackendPort(serviceManifest, item)
+					if err != nil {
+						return nil, err
+					}
+					properties.Port = ptr.To(backendPort)
 				}
 			}
 		} else {
@@ -109,7 +119,11 @@ func (az *Cloud) buildHealthProbeRulesForPort(serviceManifest *v1.Service, port
 				//nolint:gosec
 				if item.Port == int32(port) {
 					//found the port
-					properties.Port = ptr.To(item.NodePort)
+					backendPort, err := az.getServicePortBackendPort(serviceManifest, item)
+					if err != nil {
+						return nil, err
+					}
+					properties.Port = ptr.To(backendPort)
 					found = true
 					break
 				}
@@ -122,7 +136,11 @@ func (az *Cloud) buildHealthProbeRulesForPort(serviceManifest *v1.Service, port
 	} else if healthCheckNodePortProbe != nil {
 		return nil, nil
 	} else {
-		properties.Port = &port.NodePort
+		backendPort, err := az.getServicePortBackendPort(serviceManifest, port)
+		if err != nil {
+			return nil, err
+		}
+		properties.Port = ptr.To(backendPort)
 	}
 	// Select Protocol
 	//
@@ -190,7 +208,7 @@ func (az *Cloud) buildHealthProbeRulesForPort(serviceManifest *v1.Service, port
 		properties.RequestPath = path
 	}
 
-	properties.IntervalInSeconds, properties.ProbeThreshold, err = az.getHealthProbeConfigProbeIntervalAndNumOfProbe(serviceManifest, port.Port)
+	properties.IntervalInSeconds, properties.ProbeThreshold, err = getHealthProbeConfigProbeIntervalAndNumOfProbe(serviceManifest, port.Port)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse health probe config for port %d: %w", port.Port, err)
 	}
@@ -202,14 +220,14 @@ func (az *Cloud) buildHealthProbeRulesForPort(serviceManifest *v1.Service, port
 }
 
 // getHealthProbeConfigProbeIntervalAndNumOfProbe
-func (az *Cloud) getHealthProbeConfigProbeIntervalAndNumOfProbe(serviceManifest *v1.Service, port int32) (*int32, *int32, error) {
+func getHealthProbeConfigProbeIntervalAndNumOfProbe(serviceManifest *v1.Service, port int32) (*int32, *int32, error) {
 
-	numberOfProbes, err := az.getHealthProbeConfigNumOfProbe(serviceManifest, port)
+	numberOfProbes, err := getHealthProbeConfigNumOfProbe(serviceManifest, port)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	probeInterval, err := az.getHealthProbeConfigProbeInterval(serviceManifest, port)
+	probeInterval, err := getHealthProbeConfigProbeInterval(serviceManifest, port)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -223,7 +241,7 @@ func (az *Cloud) getHealthProbeConfigProbeIntervalAndNumOfProbe(serviceManifest
 // getHealthProbeConfigProbeInterval get probe interval in seconds
 // minimum probe interval in seconds is 5. ref: https://docs.microsoft.com/en-us/rest/api/load-balancer/load-balancers/create-or-update#probe
 // if probeInterval is not set, set it to default instead ref: https://docs.microsoft.com/en-us/rest/api/load-balancer/load-balancers/create-or-update#probe
-func (*Cloud) getHealthProbeConfigProbeInterval(serviceManifest *v1.Service, port int32) (*int32, error) {
+func getHealthProbeConfigProbeInterval(serviceManifest *v1.Service, port int32) (*int32, error) {
 	var probeIntervalValidator = func(val *int32) error {
 		const (
 			MinimumProbeIntervalInSecond = 5
@@ -252,7 +270,7 @@ func (*Cloud) getHealthProbeConfigProbeInterval(serviceManifest *v1.Service, por
 // getHealthProbeConfigNumOfProbe get number of probes
 // minimum number of unhealthy responses is 2. ref: https://docs.microsoft.com/en-us/rest/api/load-balancer/load-balancers/create-or-update#probe
 // if numberOfProbes is not set, set it to default instead ref: https://docs.microsoft.com/en-us/rest/api/load-balancer/load-balancers/create-or-update#probe
-func (*Cloud) getHealthProbeConfigNumOfProbe(serviceManifest *v1.Service, port int32) (*int32, error) {
+func getHealthProbeConfigNumOfProbe(serviceManifest *v1.Service, port int32) (*int32, error) {
 	var numOfProbeValidator = func(val *int32) error {
 		const (
 			MinimumNumOfProbe = 2
@@ -0,0 +1,454 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+// nodeInboundNATRuleConfig is the configuration of the per-node inbound NAT rules of a service.
+type nodeInboundNATRuleConfig struct {
+	frontendPortRangeStart int32
+	frontendPortRangeEnd   int32
+	backendPort            int32
+	vmSetName              string
+}
+
+// size returns the number of the frontend ports, which is the maximum number of nodes with inbound NAT rules.
+func (c *nodeInboundNATRuleConfig) size() int {
+	return int(c.frontendPortRangeEnd-c.frontendPortRangeStart) + 1
+}
+
+// hasNodeInboundNATRules returns true if the service asks for the per-node inbound NAT rules.
+func hasNodeInboundNATRules(service *v1.Service) bool {
+	return strings.TrimSpace(service.Annotations[consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange]) != ""
+}
+
+// getServiceNodeInboundNATRuleConfig parses the per-node inbound NAT rule annotations of the service.
+// It returns nil if the service does not need the inbound NAT rules.
+func getServiceNodeInboundNATRuleConfig(service *v1.Service) (*nodeInboundNATRuleConfig, error) {
+	portRange := strings.TrimSpace(service.Annotations[consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange])
+	if portRange == "" {
+		return nil, nil
+	}
+
+	start, end, found := strings.Cut(portRange, "-")
+	if !found {
+		return nil, fmt.Errorf("invalid value %q of annotation %s: the format should be <start>-<end>", portRange, consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange)
+	}
+	frontendPortRangeStart, err := parseInboundNATRulePort(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of annotation %s: %w", portRange, consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange, err)
+	}
+	frontendPortRangeEnd, err := parseInboundNATRulePort(end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of annotation %s: %w", portRange, consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange, err)
+	}
+	if frontendPortRangeStart > frontendPortRangeEnd {
+		return nil, fmt.Errorf("invalid value %q of annotation %s: the start port is greater than the end port", portRange, consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange)
+	}
+
+	backendPortStr, found := service.Annotations[consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort]
+	if !found {
+		return nil, fmt.Errorf("annotation %s is required with annotation %s", consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort, consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange)
+	}
+	backendPort, err := parseInboundNATRulePort(backendPortStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of annotation %s: %w", backendPortStr, consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort, err)
+	}
+
+	// The vmSet is required so that the backend port, e.g., SSH, is not exposed on every node by accident.
+	vmSetName := strings.TrimSpace(service.Annotations[consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet])
+	if vmSetName == "" {
+		return nil, fmt.Errorf("annotation %s is required with annotation %s", consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet, consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange)
+	}
+
+	for _, port := range service.Spec.Ports {
+		if port.Protocol == v1.ProtocolTCP && port.Port >= frontendPortRangeStart && port.Port <= frontendPortRangeEnd {
+			return nil, fmt.Errorf("the frontend port range %s of the inbound NAT rules conflicts with the service port %d", portRange, port.Port)
+		}
+	}
+
+	return &nodeInboundNATRuleConfig{
+		frontendPortRangeStart: frontendPortRangeStart,
+		frontendPortRangeEnd:   frontendPortRangeEnd,
+		backendPort:            backendPort,
+		vmSetName:              vmSetName,
+	}, nil
+}
+
+func parseInboundNATRulePort(s string) (int32, error) {
+	port, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("port %d is out of range [1, 65535]", port)
+	}
+	return int32(port), nil
+}
+
+// getNodeInboundNATRuleName returns the name of the per-node inbound NAT rule of the service.
+func (az *Cloud) getNodeInboundNATRuleName(service *v1.Service, backendPort int32, isIPv6 bool) string {
+	ruleName := fmt.Sprintf("%s-nat-%s-%d", az.getRulePrefix(service), v1.ProtocolTCP, backendPort)
+	return getResourceByIPFamily(ruleName, isServiceDualStack(service), isIPv6)
+}
+
+// serviceOwnsNodeInboundNATRule checks if the inbound NAT rule is one of the per-node inbound NAT rules of the service.
+func (az *Cloud) serviceOwnsNodeInboundNATRule(service *v1.Service, ruleName string) bool {
+	return strings.HasPrefix(strings.ToLower(ruleName), strings.ToLower(az.getRulePrefix(service)+"-nat-"))
+}
+
+// getNodeInboundNATBackendPoolName returns the name of the backend pool of the per-node inbound NAT rules of the service.
+// The underscore, which is not allowed in the names of the services, keeps it apart from the backend pools of the local services.
+func getNodeInboundNATBackendPoolName(service *v1.Service, isIPv6 bool) string {
+	name := getLocalServiceBackendPoolName(getServiceName(service), false) + "_nat"
+	if isIPv6 {
+		return fmt.Sprintf("%s-%s", name, consts.IPVersionIPv6StringLower)
+	}
+	return name
+}
+
+// isNodeInboundNATBackendPool checks if the backend pool is one of the backend pools of the per-node inbound NAT rules of the service.
+func isNodeInboundNATBackendPool(service *v1.Service, bpName string) bool {
+	return strings.EqualFold(bpName, getNodeInboundNATBackendPoolName(service, false)) ||
+		strings.EqualFold(bpName, getNodeInboundNATBackendPoolName(service, true))
+}
+
+// getExpectedNodeInboundNATRules returns the per-node inbound NAT rules of the service. The rules refer to the
+// dedicated backend pools with the nodes of the vmSet instead of the network interfaces, so Azure maps one
+// frontend port to each member of the backend pools, and the mappings are removed as the nodes leave them.
+func (az *Cloud) getExpectedNodeInboundNATRules(
+	service *v1.Service,
+	natConfig *nodeInboundNATRuleConfig,
+	lbName string,
+	lbFrontendIPConfigIDs map[bool]string,
+) []*armnetwork.InboundNatRule {
+	var rules []*armnetwork.InboundNatRule
+	v4Enabled, v6Enabled := getIPFamiliesEnabled(service)
+	for _, isIPv6 := range []bool{consts.IPVersionIPv4, consts.IPVersionIPv6} {
+		if (isIPv6 && !v6Enabled) || (!isIPv6 && !v4Enabled) {
+			continue
+		}
+		backendPoolID := az.getBackendPoolID(lbName, getNodeInboundNATBackendPoolName(service, isIPv6))
+		rules = append(rules, &armnetwork.InboundNatRule{
+			Name: ptr.To(az.getNodeInboundNATRuleName(service, natConfig.backendPort, isIPv6)),
+			Properties: &armnetwork.InboundNatRulePropertiesFormat{
+				FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(lbFrontendIPConfigIDs[isIPv6])},
+				BackendAddressPool:      &armnetwork.SubResource{ID: ptr.To(backendPoolID)},
+				Protocol:                to.Ptr(armnetwork.TransportProtocolTCP),
+				FrontendPortRangeStart:  ptr.To(natConfig.frontendPortRangeStart),
+				FrontendPortRangeEnd:    ptr.To(natConfig.frontendPortRangeEnd),
+				BackendPort:             ptr.To(natConfig.backendPort),
+				EnableFloatingIP:        ptr.To(false),
+			},
+		})
+	}
+	return rules
+}
+
+// checkNodeInboundNATRulesConflicts checks if the frontend port ranges of the inbound NAT rules
+// overlap with the load balancing rules or the inbound NAT rules of the other services.
+func (az *Cloud) checkNodeInboundNATRulesConflicts(lb *armnetwork.LoadBalancer, service *v1.Service, expectedRules []*armnetwork.InboundNatRule) error {
+	if lb.Properties == nil {
+		return nil
+	}
+	for _, expectedRule := range expectedRules {
+		fipConfigID := ptr.Deref(expectedRule.Properties.FrontendIPConfiguration.ID, "")
+		start, end := *expectedRule.Properties.FrontendPortRangeStart, *expectedRule.Properties.FrontendPortRangeEnd
+		for _, rule := range lb.Properties.LoadBalancingRules {
+			if rule.Properties == nil || rule.Properties.FrontendIPConfiguration == nil ||
+				!strings.EqualFold(ptr.Deref(rule.Properties.FrontendIPConfiguration.ID, ""), fipConfigID) ||
+				!strings.EqualFold(string(ptr.Deref(rule.Properties.Protocol, "")), string(armnetwork.TransportProtocolTCP)) {
+				continue
+			}
+			if port := ptr.Deref(rule.Properties.FrontendPort, 0); port >= start && port <= end {
+				return fmt.Errorf("checkNodeInboundNATRulesConflicts: the frontend port range %d-%d of the inbound NAT rule %s "+
+					"conflicts with the port %d of the existing loadBalancing rule %s", start, end, ptr.Deref(expectedRule.Name, ""), port, ptr.Deref(rule.Name, ""))
+			}
+		}
+		for _, rule := range lb.Properties.InboundNatRules {
+			if az.serviceOwnsNodeInboundNATRule(service, ptr.Deref(rule.Name, "")) {
+				continue
+			}
+			if inboundNatRuleConflictsWithPortRange(rule, fipConfigID, v1.ProtocolTCP, start, end) {
+				return fmt.Errorf("checkNodeInboundNATRulesConflicts: the frontend port range %d-%d of the inbound NAT rule %s "+
+					"conflicts with the existing inbound NAT rule %s", start, end, ptr.Deref(expectedRule.Name, ""), ptr.Deref(rule.Name, ""))
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileNodeInboundNATRules creates or updates the per-node inbound NAT rules of the service,
+// and removes the ones which are not expected. It returns true if the load balancer is changed.
+func (az *Cloud) reconcileNodeInboundNATRules(
+	lb *armnetwork.LoadBalancer,
+	service *v1.Service,
+	wantLb bool,
+	lbFrontendIPConfigIDs map[bool]string,
+) (bool, error) {
+	serviceName := getServiceName(service)
+	lbName := ptr.Deref(lb.Name, "")
+	var expectedRules []*armnetwork.InboundNatRule
+	if wantLb {
+		natConfig, err := getServiceNodeInboundNATRuleConfig(service)
+		if err != nil {
+			return false, err
+		}
+		if natConfig != nil {
+			if !az.UseStandardLoadBalancer() || az.IsLBBackendPoolTypePodIP() {
+				return false, fmt.Errorf("annotation %s can only be used with standard load balancer and node based backend pools", consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange)
+			}
+			expectedRules = az.getExpectedNodeInboundNATRules(service, natConfig, lbName, lbFrontendIPConfigIDs)
+			if err := az.checkNodeInboundNATRulesConflicts(lb, service, expectedRules); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if lb.Properties == nil {
+		lb.Properties = &armnetwork.LoadBalancerPropertiesFormat{}
+	}
+	var (
+		dirty        bool
+		updatedRules []*armnetwork.InboundNatRule
+	)
+	// The backend pools of the rules are created with the rules, and populated with the nodes of the vmSet
+	// after the load balancer is updated. They are deleted after the rules are removed from the load balancer.
+	for _, expectedRule := range expectedRules {
+		bpName := getNodeInboundNATBackendPoolName(service, managedResourceHasIPv6Suffix(ptr.Deref(expectedRule.Properties.BackendAddressPool.ID, "")))
+		if !slices.ContainsFunc(lb.Properties.BackendAddressPools, func(bp *armnetwork.BackendAddressPool) bool {
+			return strings.EqualFold(ptr.Deref(bp.Name, ""), bpName)
+		}) {
+			klog.V(2).Infof("reconcileNodeInboundNATRules for service (%s): lb backend pool(%s) - adding", serviceName, bpName)
+			newBackendPool(lb, false, "", serviceName, bpName)
+			dirty = true
+		}
+	}
+	foundRules := make(map[string]bool)
+	for _, rule := range lb.Properties.InboundNatRules {
+		ruleName := ptr.Deref(rule.Name, "")
+		if !az.serviceOwnsNodeInboundNATRule(service, ruleName) {
+			updatedRules = append(updatedRules, rule)
+			continue
+		}
+		var expectedRule *armnetwork.InboundNatRule
+		for _, r := range expectedRules {
+			if strings.EqualFold(ptr.Deref(r.Name, ""), ruleName) {
+				expectedRule = r
+				break
+			}
+		}
+		if expectedRule == nil {
+			klog.V(2).Infof("reconcileNodeInboundNATRules for service (%s): lb inbound NAT rule(%s) - dropping", serviceName, ruleName)
+			dirty = true
+			continue
+		}
+		foundRules[strings.ToLower(ruleName)] = true
+		if !equalNodeInboundNATRule(rule, expectedRule) {
+			klog.V(2).Infof("reconcileNodeInboundNATRules for service (%s): lb inbound NAT rule(%s) - updating", serviceName, ruleName)
+			updatedRules = append(updatedRules, expectedRule)
+			dirty = true
+			continue
+		}
+		updatedRules = append(updatedRules, rule)
+	}
+	for _, expectedRule := range expectedRules {
+		if foundRules[strings.ToLower(ptr.Deref(expectedRule.Name, ""))] {
+			continue
+		}
+		klog.V(2).Infof("reconcileNodeInboundNATRules for service (%s): lb inbound NAT rule(%s) - adding", serviceName, ptr.Deref(expectedRule.Name, ""))
+		updatedRules = append(updatedRules, expectedRule)
+		dirty = true
+	}
+
+	if dirty {
+		lb.Properties.InboundNatRules = updatedRules
+	}
+	return dirty, nil
+}
+
+// equalNodeInboundNATRule checks if the existing inbound NAT rule has the expected properties.
+func equalNodeInboundNATRule(rule, expectedRule *armnetwork.InboundNatRule) bool {
+	if rule.Properties == nil {
+		return false
+	}
+	p, e := rule.Properties, expectedRule.Properties
+	return p.FrontendIPConfiguration != nil &&
+		strings.EqualFold(ptr.Deref(p.FrontendIPConfiguration.ID, ""), ptr.Deref(e.FrontendIPConfiguration.ID, "")) &&
+		p.BackendAddressPool != nil &&
+		strings.EqualFold(ptr.Deref(p.BackendAddressPool.ID, ""), ptr.Deref(e.BackendAddressPool.ID, "")) &&
+		strings.EqualFold(string(ptr.Deref(p.Protocol, "")), string(ptr.Deref(e.Protocol, ""))) &&
+		ptr.Deref(p.FrontendPortRangeStart, 0) == ptr.Deref(e.FrontendPortRangeStart, 0) &&
+		ptr.Deref(p.FrontendPortRangeEnd, 0) == ptr.Deref(e.FrontendPortRangeEnd, 0) &&
+		ptr.Deref(p.BackendPort, 0) == ptr.Deref(e.BackendPort, 0)
+}
+
+// inboundNatRuleConflictsWithPortRange checks if the frontend port, or the frontend port range
+// of the backend pool based inbound NAT rule, overlaps with the given port range.
+func inboundNatRuleConflictsWithPortRange(inboundNatRule *armnetwork.InboundNatRule, frontendIPConfigID string, protocol v1.Protocol, start, end int32) bool {
+	if inboundNatRule.Properties == nil ||
+		inboundNatRule.Properties.FrontendIPConfiguration == nil ||
+		!strings.EqualFold(ptr.Deref(inboundNatRule.Properties.FrontendIPConfiguration.ID, ""), frontendIPConfigID) ||
+		!strings.EqualFold(string(ptr.Deref(inboundNatRule.Properties.Protocol, "")), string(protocol)) {
+		return false
+	}
+	if inboundNatRule.Properties.FrontendPortRangeStart != nil && inboundNatRule.Properties.FrontendPortRangeEnd != nil {
+		return *inboundNatRule.Properties.FrontendPortRangeStart <= end && *inboundNatRule.Properties.FrontendPortRangeEnd >= start
+	}
+	if inboundNatRule.Properties.FrontendPort != nil {
+		return *inboundNatRule.Properties.FrontendPort >= start && *inboundNatRule.Properties.FrontendPort <= end
+	}
+	return false
+}
+
+// filterNodesByVMSet returns the nodes in the given vmSet.
+func (az *Cloud) filterNodesByVMSet(ctx context.Context, vmSetName string, nodes []*v1.Node) ([]*v1.Node, error) {
+	var filtered []*v1.Node
+	for _, node := range nodes {
+		nodeVMSetName, err := az.VMSet.GetNodeVMSetName(ctx, node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the vmSet of node %s: %w", node.Name, err)
+		}
+		if strings.EqualFold(nodeVMSetName, vmSetName) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered, nil
+}
+
+// getNodeInboundNATBackendNodes returns the nodes of the vmSet of the per-node inbound NAT rules of the service.
+// It returns false if the frontend port range is too small for the nodes, in which case Azure would reject the
+// backend pool membership, so the backend pool is left unchanged and a warning event is emitted instead.
+func (az *Cloud) getNodeInboundNATBackendNodes(ctx context.Context, service *v1.Service, nodes []*v1.Node) ([]*v1.Node, bool, error) {
+	natConfig, err := getServiceNodeInboundNATRuleConfig(service)
+	if err != nil || natConfig == nil {
+		return nil, false, err
+	}
+	natNodes, err := az.filterNodesByVMSet(ctx, natConfig.vmSetName, nodes)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(natNodes) > natConfig.size() {
+		klog.Warningf("getNodeInboundNATBackendNodes for service (%s): %d nodes in vmSet %s exceed the %d frontend ports of the inbound NAT rules",
+			getServiceName(service), len(natNodes), natConfig.vmSetName, natConfig.size())
+		az.Event(service, v1.EventTypeWarning, "NodeInboundNATFrontendPortRangeExhausted", fmt.Sprintf(
+			"The frontend port range %d-%d has %d ports, but vmSet %s has %d nodes. "+
+				"The nodes joining the vmSet will not get an inbound NAT rule until the range is extended.",
+			natConfig.frontendPortRangeStart, natConfig.frontendPortRangeEnd, natConfig.size(), natConfig.vmSetName, len(natNodes)))
+		return nil, false, nil
+	}
+	return natNodes, true, nil
+}
+
+// cleanupNodeInboundNATBackendPools deletes the backend pools of the per-node inbound NAT rules of the service,
+// which are no longer referenced once the rules are removed from the load balancer. It returns true if any
+// backend pool is deleted.
+func (az *Cloud) cleanupNodeInboundNATBackendPools(ctx context.Context, service *v1.Service, lb *armnetwork.LoadBalancer, clusterName string) (bool, error) {
+	if lb.Properties == nil {
+		return false, nil
+	}
+	var (
+		lbName  = ptr.Deref(lb.Name, "")
+		changed bool
+	)
+	for _, bp := range lb.Properties.BackendAddressPools {
+		bpName := ptr.Deref(bp.Name, "")
+		if !isNodeInboundNATBackendPool(service, bpName) {
+			continue
+		}
+		klog.V(2).Infof("cleanupNodeInboundNATBackendPools for service (%s): lb backend pool(%s) - deleting", getServiceName(service), bpName)
+		// The NICs or VMSS referencing the backend pool in the nodeIPConfiguration mode must be decoupled first.
+		if bp.Properties != nil && len(bp.Properties.BackendIPConfigurations) > 0 {
+			vmSetName := az.mapLoadBalancerNameToVMSet(lbName, clusterName)
+			if _, err := az.ensureBackendPoolDeleted(ctx, service, []string{az.getBackendPoolID(lbName, bpName)}, vmSetName, lb.Properties.BackendAddressPools, true); err != nil {
+				return false, err
+			}
+		}
+		if err := az.DeleteLBBackendPool(ctx, lbName, bpName); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// getNodeInboundNATBackendPrivateIPs returns the private IPs of the nodes in the backend pools of the
+// per-node inbound NAT rules of the service, which are the destinations of their security rules.
+func (az *Cloud) getNodeInboundNATBackendPrivateIPs(ctx context.Context, service *v1.Service, lb *armnetwork.LoadBalancer) ([]netip.Addr, []netip.Addr) {
+	if lb.Properties == nil {
+		return nil, nil
+	}
+	var ipv4Addresses, ipv6Addresses []netip.Addr
+	addAddress := func(ip string, isIPv6 bool) {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil || addr.Is6() != isIPv6 {
+			return
+		}
+		if isIPv6 {
+			ipv6Addresses = append(ipv6Addresses, addr)
+		} else {
+			ipv4Addresses = append(ipv4Addresses, addr)
+		}
+	}
+	for _, bp := range lb.Properties.BackendAddressPools {
+		bpName := ptr.Deref(bp.Name, "")
+		if !isNodeInboundNATBackendPool(service, bpName) || bp.Properties == nil {
+			continue
+		}
+		isIPv6 := isBackendPoolIPv6(bpName)
+		for _, ipConfig := range bp.Properties.BackendIPConfigurations {
+			nodeName, _, err := az.VMSet.GetNodeNameByIPConfigurationID(ctx, ptr.Deref(ipConfig.ID, ""))
+			if err != nil {
+				klog.Errorf("getNodeInboundNATBackendPrivateIPs for service (%s): GetNodeNameByIPConfigurationID failed with error: %v", getServiceName(service), err)
+				continue
+			}
+			if privateIPs, ok := az.nodePrivateIPs[strings.ToLower(nodeName)]; ok {
+				for _, ip := range privateIPs.UnsortedList() {
+					addAddress(ip, isIPv6)
+				}
+			}
+		}
+		for _, address := range bp.Properties.LoadBalancerBackendAddresses {
+			if address.Properties != nil {
+				addAddress(ptr.Deref(address.Properties.IPAddress, ""), isIPv6)
+			}
+		}
+	}
+	slices.SortFunc(ipv4Addresses, func(a, b netip.Addr) int { return a.Compare(b) })
+	slices.SortFunc(ipv6Addresses, func(a, b netip.Addr) int { return a.Compare(b) })
+	return slices.Compact(ipv4Addresses), slices.Compact(ipv6Addresses)
+}
+
+// getNodeInboundNATSecurityRuleNamePrefix returns the name prefix of the security rules of the per-node inbound NAT rules of the service.
+func (az *Cloud) getNodeInboundNATSecurityRuleNamePrefix(service *v1.Service) string {
+	return az.getRulePrefix(service) + "-nat"
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+func TestGetServiceNodeInboundNATRuleConfig(t *testing.T) {
+	for _, tc := range []struct {
+		desc           string
+		annotations    map[string]string
+		expectedConfig *nodeInboundNATRuleConfig
+		expectedErr    bool
+	}{
+		{
+			desc: "should return nil without the annotation",
+		},
+		{
+			desc: "should parse the annotations",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50000-50099",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+			},
+			expectedConfig: &nodeInboundNATRuleConfig{frontendPortRangeStart: 50000, frontendPortRangeEnd: 50099, backendPort: 22, vmSetName: "vmss1"},
+		},
+		{
+			desc: "should report an error if the backend port is missing",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50000-50099",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the vmSet is missing",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50000-50099",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the range is invalid",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50099-50000",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the port is out of range",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50000-70000",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the range conflicts with the service port",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "1-100",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+			},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			service := getTestService("test", v1.ProtocolTCP, tc.annotations, false, 80)
+			natConfig, err := getServiceNodeInboundNATRuleConfig(&service)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedConfig, natConfig)
+		})
+	}
+}
+
+func TestReconcileNodeInboundNATRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fipID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb/frontendIPConfigurations/atest"
+	annotations := map[string]string{
+		consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50000-50099",
+		consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+		consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+	}
+	natBackendPool := &armnetwork.BackendAddressPool{Name: ptr.To("default-test_nat")}
+	otherNATRule := &armnetwork.InboundNatRule{
+		Name: ptr.To("user-nat-rule"),
+		Properties: &armnetwork.InboundNatRulePropertiesFormat{
+			FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(fipID)},
+			FrontendPort:            ptr.To(int32(40000)),
+			Protocol:                to.Ptr(armnetwork.TransportProtocolTCP),
+		},
+	}
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	service := getTestService("test", v1.ProtocolTCP, annotations, false, 80)
+	natConfig, err := getServiceNodeInboundNATRuleConfig(&service)
+	assert.NoError(t, err)
+	expectedRules := az.getExpectedNodeInboundNATRules(&service, natConfig, "lb", map[bool]string{false: fipID})
+	assert.Equal(t, 1, len(expectedRules))
+	assert.Equal(t, "atest-nat-TCP-22", ptr.Deref(expectedRules[0].Name, ""))
+	assert.Equal(t, "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb/backendAddressPools/default-test_nat",
+		ptr.Deref(expectedRules[0].Properties.BackendAddressPool.ID, ""))
+
+	for _, tc := range []struct {
+		desc          string
+		annotations   map[string]string
+		wantLb        bool
+		existingRules []*armnetwork.InboundNatRule
+		lbRules       []*armnetwork.LoadBalancingRule
+		noBackendPool bool
+		expectedDirty bool
+		expectedRules []*armnetwork.InboundNatRule
+		expectedErr   bool
+	}{
+		{
+			desc:          "should add the inbound NAT rule",
+			annotations:   annotations,
+			wantLb:        true,
+			existingRules: []*armnetwork.InboundNatRule{otherNATRule},
+			expectedDirty: true,
+			expectedRules: []*armnetwork.InboundNatRule{otherNATRule, expectedRules[0]},
+		},
+		{
+			desc:          "should add the backend pool of the inbound NAT rule",
+			annotations:   annotations,
+			wantLb:        true,
+			existingRules: []*armnetwork.InboundNatRule{otherNATRule, expectedRules[0]},
+			noBackendPool: true,
+			expectedDirty: true,
+			expectedRules: []*armnetwork.InboundNatRule{otherNATRule, expectedRules[0]},
+		},
+		{
+			desc:          "should not change the up-to-date inbound NAT rule",
+			annotations:   annotations,
+			wantLb:        true,
+			existingRules: []*armnetwork.InboundNatRule{otherNATRule, expectedRules[0]},
+			expectedRules: []*armnetwork.InboundNatRule{otherNATRule, expectedRules[0]},
+		},
+		{
+			desc: "should update the inbound NAT rule with a different port range",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: "50000-50199",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+			},
+			wantLb:        true,
+			existingRules: []*armnetwork.InboundNatRule{expectedRules[0]},
+			expectedDirty: true,
+		},
+		{
+			desc:          "should remove the inbound NAT rule when the annotation is removed",
+			wantLb:        true,
+			existingRules: []*armnetwork.InboundNatRule{otherNATRule, expectedRules[0]},
+			expectedDirty: true,
+			expectedRules: []*armnetwork.InboundNatRule{otherNATRule},
+		},
+		{
+			desc:          "should remove the inbound NAT rule when the service is deleted",
+			annotations:   annotations,
+			existingRules: []*armnetwork.InboundNatRule{expectedRules[0]},
+			expectedDirty: true,
+		},
+		{
+			desc:        "should report an error if the port range conflicts with a load balancing rule",
+			annotations: annotations,
+			wantLb:      true,
+			lbRules: []*armnetwork.LoadBalancingRule{
+				{
+					Name: ptr.To("another-service-TCP-50050"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(fipID)},
+						FrontendPort:            ptr.To(int32(50050)),
+						Protocol:                to.Ptr(armnetwork.TransportProtocolTCP),
+					},
+				},
+			},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			service := getTestService("test", v1.ProtocolTCP, tc.annotations, false, 80)
+			lb := &armnetwork.LoadBalancer{
+				Name: ptr.To("lb"),
+				Properties: &armnetwork.LoadBalancerPropertiesFormat{
+					InboundNatRules:    append([]*armnetwork.InboundNatRule{}, tc.existingRules...),
+					LoadBalancingRules: tc.lbRules,
+				},
+			}
+			if !tc.noBackendPool {
+				lb.Properties.BackendAddressPools = []*armnetwork.BackendAddressPool{natBackendPool}
+			}
+
+			dirty, err := az.reconcileNodeInboundNATRules(lb, &service, tc.wantLb, map[bool]string{false: fipID})
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedDirty, dirty)
+			if tc.wantLb && tc.annotations != nil {
+				assert.Equal(t, 1, len(lb.Properties.BackendAddressPools))
+				assert.Equal(t, "default-test_nat", ptr.Deref(lb.Properties.BackendAddressPools[0].Name, ""))
+			}
+			if tc.expectedRules != nil || !tc.wantLb {
+				assert.Equal(t, tc.expectedRules, lb.Properties.InboundNatRules)
+			} else {
+				assert.Equal(t, 1, len(lb.Properties.InboundNatRules))
+				assert.Equal(t, int32(50199), ptr.Deref(lb.Properties.InboundNatRules[0].Properties.FrontendPortRangeEnd, 0))
+			}
+		})
+	}
+}
+
+func TestGetNodeInboundNATBackendNodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nodes := []*v1.Node{
+		getTestZonalNode("node1", "eastus-1", "10.0.0.1"),
+		getTestZonalNode("node2", "eastus-1", "10.0.0.2"),
+		getTestZonalNode("node3", "eastus-1", "10.0.0.3"),
+	}
+	for _, tc := range []struct {
+		desc          string
+		portRange     string
+		expectedNodes []*v1.Node
+		expectedOK    bool
+		expectedEvent bool
+	}{
+		{
+			desc:          "should return the nodes of the vmSet",
+			portRange:     "50000-50099",
+			expectedNodes: []*v1.Node{nodes[0], nodes[2]},
+			expectedOK:    true,
+		},
+		{
+			desc:          "should not return the nodes if the frontend port range is too small",
+			portRange:     "50000-50000",
+			expectedEvent: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			recorder := record.NewFakeRecorder(10)
+			az.eventRecorder = recorder
+			mockVMSet := NewMockVMSet(ctrl)
+			mockVMSet.EXPECT().GetNodeVMSetName(gomock.Any(), nodes[0]).Return("vmss1", nil)
+			mockVMSet.EXPECT().GetNodeVMSetName(gomock.Any(), nodes[1]).Return("vmss2", nil)
+			mockVMSet.EXPECT().GetNodeVMSetName(gomock.Any(), nodes[2]).Return("VMSS1", nil)
+			az.VMSet = mockVMSet
+
+			service := getTestService("test", v1.ProtocolTCP, map[string]string{
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATFrontendPortRange: tc.portRange,
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATBackendPort:       "22",
+				consts.ServiceAnnotationLoadBalancerNodeInboundNATVMSet:             "vmss1",
+			}, false, 80)
+			natNodes, ok, err := az.getNodeInboundNATBackendNodes(context.Background(), &service, nodes)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedNodes, natNodes)
+			assert.Equal(t, tc.expectedEvent, len(recorder.Events) > 0)
+		})
+	}
+}
+
+func TestGetNodeInboundNATBackendPrivateIPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	service := getTestService("test", v1.ProtocolTCP, nil, false, 80)
+	lb := &armnetwork.LoadBalancer{
+		Name: ptr.To("lb"),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			BackendAddressPools: []*armnetwork.BackendAddressPool{
+				getTestBackendAddressPoolWithIPs("lb", "kubernetes", []string{"10.0.0.1", "10.0.0.2"}),
+				getTestBackendAddressPoolWithIPs("lb", "default-test_nat", []string{"10.0.0.3", "10.0.0.2"}),
+				getTestBackendAddressPoolWithIPs("lb", "default-test_nat-ipv6", []string{"fd00::3"}),
+			},
+		},
+	}
+	ipv4Addresses, ipv6Addresses := az.getNodeInboundNATBackendPrivateIPs(context.Background(), &service, lb)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.0.3")}, ipv4Addresses)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("fd00::3")}, ipv6Addresses)
+}
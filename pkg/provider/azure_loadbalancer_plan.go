@@ -0,0 +1,677 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/util/deepcopy"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+// LoadBalancerPlanAction is the kind of change the cloud provider would make to an Azure resource.
+type LoadBalancerPlanAction string
+
+const (
+	// LoadBalancerPlanActionAdd means the resource would be created.
+	LoadBalancerPlanActionAdd LoadBalancerPlanAction = "Add"
+	// LoadBalancerPlanActionUpdate means the resource would be updated.
+	LoadBalancerPlanActionUpdate LoadBalancerPlanAction = "Update"
+	// LoadBalancerPlanActionDelete means the resource would be deleted.
+	LoadBalancerPlanActionDelete LoadBalancerPlanAction = "Delete"
+)
+
+// LoadBalancerPlanResourceType is the type of the Azure resource in a load balancer plan.
+type LoadBalancerPlanResourceType string
+
+const (
+	LoadBalancerPlanResourceTypeLoadBalancer       LoadBalancerPlanResourceType = "LoadBalancer"
+	LoadBalancerPlanResourceTypePublicIPAddress    LoadBalancerPlanResourceType = "PublicIPAddress"
+	LoadBalancerPlanResourceTypeSecurityGroup      LoadBalancerPlanResourceType = "NetworkSecurityGroup"
+	LoadBalancerPlanResourceTypePrivateLinkService LoadBalancerPlanResourceType = "PrivateLinkService"
+)
+
+const (
+	// Azure allocates the address of a public IP when it is created, so the public IPs
+	// that would be created are given an address reserved for documentation (RFC 5737
+	// and RFC 3849) to let the security rules referring to them be planned.
+	planPlaceholderIPv4Address = "192.0.2.1"
+	planPlaceholderIPv6Address = "2001:db8::1"
+)
+
+// planSubResourceField is a collection of sub-resources in the properties of an Azure resource.
+type planSubResourceField struct {
+	// jsonName is the name of the collection in the JSON representation of the properties.
+	jsonName string
+	// subResourceType is the type reported in the plan for the items of the collection.
+	subResourceType string
+}
+
+// planSubResourceFields lists the sub-resources compared per item for each resource type.
+// Other differences are reported as an update of the resource itself.
+var planSubResourceFields = map[LoadBalancerPlanResourceType][]planSubResourceField{
+	LoadBalancerPlanResourceTypeLoadBalancer: {
+		{jsonName: "frontendIPConfigurations", subResourceType: "FrontendIPConfiguration"},
+		{jsonName: "backendAddressPools", subResourceType: "BackendAddressPool"},
+		{jsonName: "loadBalancingRules", subResourceType: "LoadBalancingRule"},
+		{jsonName: "probes", subResourceType: "Probe"},
+		{jsonName: "inboundNatRules", subResourceType: "InboundNatRule"},
+		{jsonName: "outboundRules", subResourceType: "OutboundRule"},
+	},
+	LoadBalancerPlanResourceTypeSecurityGroup: {
+		{jsonName: "securityRules", subResourceType: "SecurityRule"},
+	},
+	LoadBalancerPlanResourceTypePrivateLinkService: {
+		{jsonName: "ipConfigurations", subResourceType: "IPConfiguration"},
+	},
+}
+
+// LoadBalancerPlanSubResourceChange is a change of a sub-resource, e.g. a load balancing rule.
+type LoadBalancerPlanSubResourceChange struct {
+	Type   string                 `json:"type"`
+	Name   string                 `json:"name"`
+	Action LoadBalancerPlanAction `json:"action"`
+}
+
+// LoadBalancerPlanResourceChange is a change of an Azure resource.
+type LoadBalancerPlanResourceChange struct {
+	Type          LoadBalancerPlanResourceType        `json:"type"`
+	ResourceGroup string                              `json:"resourceGroup"`
+	Name          string                              `json:"name"`
+	Action        LoadBalancerPlanAction              `json:"action"`
+	SubResources  []LoadBalancerPlanSubResourceChange `json:"subResources,omitempty"`
+}
+
+// LoadBalancerPlan is the set of changes the cloud provider would make to the Azure
+// load balancers, public IPs, security group and private link services to reconcile a service.
+type LoadBalancerPlan struct {
+	// Service is the namespaced name of the service.
+	Service string `json:"service"`
+	// Changes are listed in the order the cloud provider would make them.
+	Changes []LoadBalancerPlanResourceChange `json:"changes,omitempty"`
+}
+
+// IsEmpty returns true if the plan has no change.
+func (p *LoadBalancerPlan) IsEmpty() bool {
+	return p == nil || len(p.Changes) == 0
+}
+
+// String returns a one-line summary of the plan, which is used as the message of the plan event.
+func (p *LoadBalancerPlan) String() string {
+	if p.IsEmpty() {
+		return "no changes"
+	}
+
+	changes := make([]string, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		s := fmt.Sprintf("%s %s %s/%s", strings.ToLower(string(change.Action)), change.Type, change.ResourceGroup, change.Name)
+		if len(change.SubResources) > 0 {
+			subResources := make([]string, 0, len(change.SubResources))
+			for _, subResource := range change.SubResources {
+				subResources = append(subResources, fmt.Sprintf("%s %s %s", strings.ToLower(string(subResource.Action)), subResource.Type, subResource.Name))
+			}
+			s = fmt.Sprintf("%s (%s)", s, strings.Join(subResources, ", "))
+		}
+		changes = append(changes, s)
+	}
+	return strings.Join(changes, "; ")
+}
+
+// loadBalancerPlanEventMaxLength is the maximum length of the message of the plan event,
+// which keeps the event of a plan with many changes within the size accepted by the API server.
+const loadBalancerPlanEventMaxLength = 1024
+
+// eventMessage returns the summary of the plan truncated to loadBalancerPlanEventMaxLength.
+// The full summary is logged.
+func (p *LoadBalancerPlan) eventMessage() string {
+	const suffix = " ... (truncated)"
+	message := p.String()
+	if len(message) <= loadBalancerPlanEventMaxLength {
+		return message
+	}
+	return message[:loadBalancerPlanEventMaxLength-len(suffix)] + suffix
+}
+
+// PlanLoadBalancer computes the changes EnsureLoadBalancer would make to the Azure resources
+// of the service and returns them without sending any create, update or delete request to Azure.
+// The membership of the nodes in the backend pools is not part of the plan.
+// Parameter 'clusterName' is the name of the cluster as presented to kube-controller-manager.
+func (az *Cloud) PlanLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*LoadBalancerPlan, error) {
+	// Serialize service reconcile process
+	az.serviceReconcileLock.Lock()
+	defer az.serviceReconcileLock.Unlock()
+
+	return az.planLoadBalancer(ctx, clusterName, service, nodes)
+}
+
+// planLoadBalancer runs the service reconciliation in plan mode: the writes are recorded instead
+// of being sent to Azure, and the reads of the recorded resources return their planned state.
+func (az *Cloud) planLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*LoadBalancerPlan, error) {
+	ctx, recorder := newLoadBalancerPlanContext(ctx)
+
+	// The multiple standard load balancer status is updated during the reconciliation,
+	// which must not be kept after planning.
+	defer az.restoreMultipleStandardLoadBalancerStatus(az.snapshotMultipleStandardLoadBalancerStatus())
+
+	var err error
+	if az.isServiceLoadBalancerClassManaged(service) {
+		_, err = az.reconcileService(ctx, clusterName, service, nodes)
+	} else {
+		err = az.reconcileServiceDeletion(ctx, clusterName, service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := recorder.plan(getServiceName(service))
+	if err != nil {
+		return nil, err
+	}
+	klog.V(2).Infof("planLoadBalancer(%s): %s", getServiceName(service), plan.String())
+	return plan, nil
+}
+
+// multipleStandardLoadBalancerStatusSnapshot is a copy of the in-memory state of the multiple standard load balancers.
+type multipleStandardLoadBalancerStatusSnapshot struct {
+	activeServices                             []*utilsets.IgnoreCaseSet
+	activeNodes                                []*utilsets.IgnoreCaseSet
+	nodesWithCorrectLoadBalancerByPrimaryVMSet []interface{}
+}
+
+func copyIgnoreCaseSet(s *utilsets.IgnoreCaseSet) *utilsets.IgnoreCaseSet {
+	if !s.Initialized() {
+		return s
+	}
+	return utilsets.NewString(s.UnsortedList()...)
+}
+
+func (az *Cloud) snapshotMultipleStandardLoadBalancerStatus() *multipleStandardLoadBalancerStatusSnapshot {
+	snapshot := &multipleStandardLoadBalancerStatusSnapshot{}
+	for _, multiSLBConfig := range az.MultipleStandardLoadBalancerConfigurations {
+		snapshot.activeServices = append(snapshot.activeServices, copyIgnoreCaseSet(multiSLBConfig.ActiveServices))
+		snapshot.activeNodes = append(snapshot.activeNodes, copyIgnoreCaseSet(multiSLBConfig.ActiveNodes))
+	}
+	az.nodesWithCorrectLoadBalancerByPrimaryVMSet.Range(func(key, _ interface{}) bool {
+		snapshot.nodesWithCorrectLoadBalancerByPrimaryVMSet = append(snapshot.nodesWithCorrectLoadBalancerByPrimaryVMSet, key)
+		return true
+	})
+	return snapshot
+}
+
+func (az *Cloud) restoreMultipleStandardLoadBalancerStatus(snapshot *multipleStandardLoadBalancerStatusSnapshot) {
+	for i := range az.MultipleStandardLoadBalancerConfigurations {
+		if i >= len(snapshot.activeServices) {
+			break
+		}
+		az.MultipleStandardLoadBalancerConfigurations[i].ActiveServices = snapshot.activeServices[i]
+		az.MultipleStandardLoadBalancerConfigurations[i].ActiveNodes = snapshot.activeNodes[i]
+	}
+	az.nodesWithCorrectLoadBalancerByPrimaryVMSet.Clear()
+	for _, key := range snapshot.nodesWithCorrectLoadBalancerByPrimaryVMSet {
+		az.nodesWithCorrectLoadBalancerByPrimaryVMSet.Store(key, struct{}{})
+	}
+}
+
+type loadBalancerPlanContextKey struct{}
+
+// loadBalancerPlanRecorder records the desired state of the Azure resources written in plan mode.
+type loadBalancerPlanRecorder struct {
+	mu        sync.Mutex
+	resources map[string]*plannedResource
+	// keys are the keys of the resources in the order they are first written.
+	keys []string
+}
+
+// plannedResource is an Azure resource written in plan mode.
+type plannedResource struct {
+	resourceType  LoadBalancerPlanResourceType
+	resourceGroup string
+	name          string
+	// original is the resource before planning, nil if it does not exist.
+	original interface{}
+	// desired is the planned resource, nil if it would be deleted.
+	desired interface{}
+}
+
+// newLoadBalancerPlanContext returns a context which turns the writes to the Azure
+// load balancer resources into records of the returned recorder.
+func newLoadBalancerPlanContext(ctx context.Context) (context.Context, *loadBalancerPlanRecorder) {
+	recorder := &loadBalancerPlanRecorder{
+		resources: make(map[string]*plannedResource),
+	}
+	return context.WithValue(ctx, loadBalancerPlanContextKey{}, recorder), recorder
+}
+
+func loadBalancerPlanRecorderFromContext(ctx context.Context) *loadBalancerPlanRecorder {
+	recorder, _ := ctx.Value(loadBalancerPlanContextKey{}).(*loadBalancerPlanRecorder)
+	return recorder
+}
+
+// isLoadBalancerPlanContext returns true if the reconciliation only plans the changes.
+func isLoadBalancerPlanContext(ctx context.Context) bool {
+	return loadBalancerPlanRecorderFromContext(ctx) != nil
+}
+
+// withoutLoadBalancerPlan returns a context which reads the actual state of the Azure resources.
+func withoutLoadBalancerPlan(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadBalancerPlanContextKey{}, (*loadBalancerPlanRecorder)(nil))
+}
+
+func plannedResourceKey(resourceType LoadBalancerPlanResourceType, resourceGroup, name string) string {
+	return strings.ToLower(fmt.Sprintf("%s/%s/%s", resourceType, resourceGroup, name))
+}
+
+// record stores the desired state of the resource, nil if the resource would be deleted.
+// getOriginal is only called when the resource is written for the first time.
+func (r *loadBalancerPlanRecorder) record(
+	resourceType LoadBalancerPlanResourceType,
+	resourceGroup, name string,
+	desired interface{},
+	getOriginal func() (interface{}, error),
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := plannedResourceKey(resourceType, resourceGroup, name)
+	res, ok := r.resources[key]
+	if !ok {
+		original, err := getOriginal()
+		if err != nil {
+			return err
+		}
+		res = &plannedResource{
+			resourceType:  resourceType,
+			resourceGroup: resourceGroup,
+			name:          name,
+			original:      original,
+		}
+		r.resources[key] = res
+		r.keys = append(r.keys, key)
+	}
+	res.desired = deepcopy.Copy(desired)
+	klog.V(4).Infof("loadBalancerPlanRecorder: recorded %s %s/%s, deleted: %t", resourceType, resourceGroup, name, desired == nil)
+	return nil
+}
+
+// get returns a copy of the planned resource. The second return value is false if the resource is not recorded.
+func (r *loadBalancerPlanRecorder) get(resourceType LoadBalancerPlanResourceType, resourceGroup, name string) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, ok := r.resources[plannedResourceKey(resourceType, resourceGroup, name)]
+	if !ok {
+		return nil, false
+	}
+	return deepcopy.Copy(res.desired), true
+}
+
+// find returns a copy of the first planned resource of the given type and resource group
+// which matches, and whether the matched resource is recorded.
+func (r *loadBalancerPlanRecorder) find(resourceType LoadBalancerPlanResourceType, resourceGroup string, match func(interface{}) bool) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range r.keys {
+		res := r.resources[key]
+		if res.resourceType != resourceType || !strings.EqualFold(res.resourceGroup, resourceGroup) {
+			continue
+		}
+		if (res.desired != nil && match(res.desired)) || (res.original != nil && match(res.original)) {
+			return deepcopy.Copy(res.desired), true
+		}
+	}
+	return nil, false
+}
+
+// overlayPlannedResources replaces the resources in the list with their planned state,
+// removes the ones which would be deleted and appends the ones which would be created.
+func overlayPlannedResources[T any](
+	r *loadBalancerPlanRecorder,
+	resourceType LoadBalancerPlanResourceType,
+	resourceGroup string,
+	resources []*T,
+	nameOf func(*T) string,
+) []*T {
+	if r == nil {
+		return resources
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	overlaid := utilsets.NewString()
+	var ret []*T
+	for _, resource := range resources {
+		key := plannedResourceKey(resourceType, resourceGroup, nameOf(resource))
+		res, ok := r.resources[key]
+		if !ok {
+			ret = append(ret, resource)
+			continue
+		}
+		overlaid.Insert(key)
+		if res.desired != nil {
+			ret = append(ret, deepcopy.Copy(res.desired).(*T))
+		}
+	}
+	for _, key := range r.keys {
+		res := r.resources[key]
+		if overlaid.Has(key) || res.desired == nil ||
+			res.resourceType != resourceType || !strings.EqualFold(res.resourceGroup, resourceGroup) {
+			continue
+		}
+		ret = append(ret, deepcopy.Copy(res.desired).(*T))
+	}
+	return ret
+}
+
+// plan diffs the planned resources against their original state.
+func (r *loadBalancerPlanRecorder) plan(serviceName string) (*LoadBalancerPlan, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan := &LoadBalancerPlan{Service: serviceName}
+	for _, key := range r.keys {
+		change, changed, err := r.resources[key].diff()
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+	return plan, nil
+}
+
+// diff returns the change from the original state of the resource to the planned one,
+// and false if there is no change.
+func (res *plannedResource) diff() (LoadBalancerPlanResourceChange, bool, error) {
+	change := LoadBalancerPlanResourceChange{
+		Type:          res.resourceType,
+		ResourceGroup: res.resourceGroup,
+		Name:          res.name,
+	}
+	switch {
+	case res.original == nil && res.desired == nil:
+		return change, false, nil
+	case res.original == nil:
+		change.Action = LoadBalancerPlanActionAdd
+	case res.desired == nil:
+		change.Action = LoadBalancerPlanActionDelete
+		return change, true, nil
+	default:
+		change.Action = LoadBalancerPlanActionUpdate
+	}
+
+	original, err := toPlanObject(res.original)
+	if err != nil {
+		return change, false, err
+	}
+	desired, err := toPlanObject(res.desired)
+	if err != nil {
+		return change, false, err
+	}
+
+	originalProperties, _ := original["properties"].(map[string]interface{})
+	desiredProperties, _ := desired["properties"].(map[string]interface{})
+	for _, field := range planSubResourceFields[res.resourceType] {
+		change.SubResources = append(change.SubResources, diffPlanSubResources(field, originalProperties[field.jsonName], desiredProperties[field.jsonName])...)
+		delete(originalProperties, field.jsonName)
+		delete(desiredProperties, field.jsonName)
+	}
+	if change.Action == LoadBalancerPlanActionAdd {
+		return change, true, nil
+	}
+
+	// The etag changes with every write, which is not a change of the resource.
+	delete(original, "etag")
+	delete(desired, "etag")
+	if len(change.SubResources) == 0 && reflect.DeepEqual(original, desired) {
+		return change, false, nil
+	}
+	return change, true, nil
+}
+
+// toPlanObject converts the Azure resource to its JSON representation to compare the fields
+// the same way as Azure does.
+func toPlanObject(resource interface{}) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	if resource == nil {
+		return obj, nil
+	}
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the planned resource: %w", err)
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the planned resource: %w", err)
+	}
+	return obj, nil
+}
+
+type planSubResource struct {
+	name  string
+	value interface{}
+}
+
+// planSubResourcesByName indexes the items of a sub-resource collection by their lower case name.
+func planSubResourcesByName(collection interface{}) map[string]planSubResource {
+	items, _ := collection.([]interface{})
+	ret := make(map[string]planSubResource, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := obj["name"].(string)
+		delete(obj, "etag")
+		ret[strings.ToLower(name)] = planSubResource{name: name, value: obj}
+	}
+	return ret
+}
+
+func diffPlanSubResources(field planSubResourceField, original, desired interface{}) []LoadBalancerPlanSubResourceChange {
+	originalItems, desiredItems := planSubResourcesByName(original), planSubResourcesByName(desired)
+	keys := utilsets.NewString()
+	for key := range originalItems {
+		keys.Insert(key)
+	}
+	for key := range desiredItems {
+		keys.Insert(key)
+	}
+	sortedKeys := keys.UnsortedList()
+	sort.Strings(sortedKeys)
+
+	var changes []LoadBalancerPlanSubResourceChange
+	for _, key := range sortedKeys {
+		originalItem, inOriginal := originalItems[key]
+		desiredItem, inDesired := desiredItems[key]
+		switch {
+		case !inOriginal:
+			changes = append(changes, LoadBalancerPlanSubResourceChange{Type: field.subResourceType, Name: desiredItem.name, Action: LoadBalancerPlanActionAdd})
+		case !inDesired:
+			changes = append(changes, LoadBalancerPlanSubResourceChange{Type: field.subResourceType, Name: originalItem.name, Action: LoadBalancerPlanActionDelete})
+		case !reflect.DeepEqual(originalItem.value, desiredItem.value):
+			changes = append(changes, LoadBalancerPlanSubResourceChange{Type: field.subResourceType, Name: desiredItem.name, Action: LoadBalancerPlanActionUpdate})
+		}
+	}
+	return changes
+}
+
+// ensureBackendPoolDeleted decouples the backend pools from the VM set. The VM sets
+// are not part of the plan, so it is a no-op in plan mode.
+func (az *Cloud) ensureBackendPoolDeleted(ctx context.Context, service *v1.Service, backendPoolIDs []string, vmSetName string, backendAddressPools []*armnetwork.BackendAddressPool, deleteFromVMSet bool) (bool, error) {
+	if isLoadBalancerPlanContext(ctx) {
+		klog.V(4).Infof("ensureBackendPoolDeleted: skip decoupling backend pools %q from vmSet %s in plan mode", backendPoolIDs, vmSetName)
+		return false, nil
+	}
+	return az.VMSet.EnsureBackendPoolDeleted(ctx, service, backendPoolIDs, vmSetName, backendAddressPools, deleteFromVMSet)
+}
+
+// getOriginalLoadBalancerFunc returns a function getting the load balancer before planning.
+func (az *Cloud) getOriginalLoadBalancerFunc(ctx context.Context, lbName string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		lb, exists, err := az.getAzureLoadBalancer(withoutLoadBalancerPlan(ctx), lbName, azcache.CacheReadTypeDefault)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return lb, nil
+	}
+}
+
+// planLBBackendPoolUpdate records the load balancer with the backend pool updated, or deleted if backendPool is nil.
+func (az *Cloud) planLBBackendPoolUpdate(ctx context.Context, recorder *loadBalancerPlanRecorder, lbName, backendPoolName string, backendPool *armnetwork.BackendAddressPool) error {
+	lb, exists, err := az.getAzureLoadBalancer(ctx, lbName, azcache.CacheReadTypeDefault)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("load balancer %q not found", lbName)
+	}
+	if lb.Properties == nil {
+		lb.Properties = &armnetwork.LoadBalancerPropertiesFormat{}
+	}
+
+	backendPools := make([]*armnetwork.BackendAddressPool, 0, len(lb.Properties.BackendAddressPools)+1)
+	for _, bp := range lb.Properties.BackendAddressPools {
+		if !strings.EqualFold(ptr.Deref(bp.Name, ""), backendPoolName) {
+			backendPools = append(backendPools, bp)
+		}
+	}
+	if backendPool != nil {
+		backendPools = append(backendPools, backendPool)
+	}
+	lb.Properties.BackendAddressPools = backendPools
+
+	return recorder.record(LoadBalancerPlanResourceTypeLoadBalancer, az.getLoadBalancerResourceGroup(), lbName, lb, az.getOriginalLoadBalancerFunc(ctx, lbName))
+}
+
+// getOriginalPublicIPAddressFunc returns a function getting the public IP before planning.
+func (az *Cloud) getOriginalPublicIPAddressFunc(ctx context.Context, pipResourceGroup, pipName string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		pip, exists, err := az.getPublicIPAddress(withoutLoadBalancerPlan(ctx), pipResourceGroup, pipName, azcache.CacheReadTypeDefault)
+		if err != nil || !exists {
+			return nil, err
+		}
+		return pip, nil
+	}
+}
+
+// planPublicIPUpdate records the public IP. The public IPs which would be created
+// are given an ID and a placeholder address so that they can be referenced.
+func (az *Cloud) planPublicIPUpdate(ctx context.Context, recorder *loadBalancerPlanRecorder, pipResourceGroup string, pip *armnetwork.PublicIPAddress) error {
+	pipName := ptr.Deref(pip.Name, "")
+	planned := deepcopy.Copy(pip).(*armnetwork.PublicIPAddress)
+	if planned.ID == nil {
+		planned.ID = ptr.To(fmt.Sprintf(consts.PublicIPAddressIDTemplate, az.getNetworkResourceSubscriptionID(), pipResourceGroup, pipName))
+		if planned.Properties == nil {
+			planned.Properties = &armnetwork.PublicIPAddressPropertiesFormat{}
+		}
+		if planned.Properties.IPAddress == nil {
+			planned.Properties.IPAddress = ptr.To(planPlaceholderIPv4Address)
+			if ptr.Deref(planned.Properties.PublicIPAddressVersion, "") == armnetwork.IPVersionIPv6 {
+				planned.Properties.IPAddress = ptr.To(planPlaceholderIPv6Address)
+			}
+		}
+	}
+	return recorder.record(LoadBalancerPlanResourceTypePublicIPAddress, pipResourceGroup, pipName, planned, az.getOriginalPublicIPAddressFunc(ctx, pipResourceGroup, pipName))
+}
+
+// getSecurityGroup gets the security group of the cluster, or its planned state in plan mode.
+func (az *Cloud) getSecurityGroup(ctx context.Context) (*armnetwork.SecurityGroup, error) {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		if planned, ok := recorder.get(LoadBalancerPlanResourceTypeSecurityGroup, az.SecurityGroupResourceGroup, az.SecurityGroupName); ok && planned != nil {
+			return planned.(*armnetwork.SecurityGroup), nil
+		}
+	}
+	return az.nsgRepo.GetSecurityGroup(ctx)
+}
+
+// createOrUpdateSecurityGroup updates the security group of the cluster, or records it in plan mode.
+func (az *Cloud) createOrUpdateSecurityGroup(ctx context.Context, sg *armnetwork.SecurityGroup) error {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return recorder.record(LoadBalancerPlanResourceTypeSecurityGroup, az.SecurityGroupResourceGroup, ptr.Deref(sg.Name, ""), sg, func() (interface{}, error) {
+			return az.nsgRepo.GetSecurityGroup(withoutLoadBalancerPlan(ctx))
+		})
+	}
+	return az.nsgRepo.CreateOrUpdateSecurityGroup(ctx, sg)
+}
+
+// isPrivateLinkServiceOnFrontendIPConfig returns true if the private link service is attached to the frontend IP configuration.
+func isPrivateLinkServiceOnFrontendIPConfig(pls *armnetwork.PrivateLinkService, fipConfigID string) bool {
+	if pls == nil || pls.Properties == nil {
+		return false
+	}
+	for _, fipConfig := range pls.Properties.LoadBalancerFrontendIPConfigurations {
+		if fipConfig != nil && strings.EqualFold(ptr.Deref(fipConfig.ID, ""), fipConfigID) {
+			return true
+		}
+	}
+	return false
+}
+
+// getPrivateLinkService gets the private link service attached to the frontend IP configuration,
+// or its planned state in plan mode.
+func (az *Cloud) getPrivateLinkService(ctx context.Context, resourceGroup, fipConfigID string, crt azcache.AzureCacheReadType) (*armnetwork.PrivateLinkService, error) {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		planned, ok := recorder.find(LoadBalancerPlanResourceTypePrivateLinkService, resourceGroup, func(obj interface{}) bool {
+			return isPrivateLinkServiceOnFrontendIPConfig(obj.(*armnetwork.PrivateLinkService), fipConfigID)
+		})
+		if ok {
+			if planned == nil {
+				return &armnetwork.PrivateLinkService{ID: ptr.To(consts.PrivateLinkServiceNotExistID)}, nil
+			}
+			return planned.(*armnetwork.PrivateLinkService), nil
+		}
+	}
+	return az.plsRepo.Get(ctx, resourceGroup, fipConfigID, crt)
+}
+
+// createOrUpdatePrivateLinkService updates the private link service, or records it in plan mode.
+func (az *Cloud) createOrUpdatePrivateLinkService(ctx context.Context, resourceGroup string, pls *armnetwork.PrivateLinkService) error {
+	recorder := loadBalancerPlanRecorderFromContext(ctx)
+	if recorder == nil {
+		_, err := az.plsRepo.CreateOrUpdate(ctx, resourceGroup, *pls)
+		return err
+	}
+
+	return recorder.record(LoadBalancerPlanResourceTypePrivateLinkService, resourceGroup, ptr.Deref(pls.Name, ""), pls, func() (interface{}, error) {
+		if pls.Properties == nil || len(pls.Properties.LoadBalancerFrontendIPConfigurations) == 0 {
+			return nil, nil
+		}
+		fipConfigID := ptr.Deref(pls.Properties.LoadBalancerFrontendIPConfigurations[0].ID, "")
+		existingPLS, err := az.plsRepo.Get(withoutLoadBalancerPlan(ctx), resourceGroup, fipConfigID, azcache.CacheReadTypeDefault)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(ptr.Deref(existingPLS.ID, ""), consts.PrivateLinkServiceNotExistID) {
+			return nil, nil
+		}
+		return existingPLS, nil
+	})
+}
@@ -0,0 +1,372 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/loadbalancerclient/mock_loadbalancerclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/publicipaddressclient/mock_publicipaddressclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/securitygroupclient/mock_securitygroupclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+// setMockPlanEnv mocks the reads of an empty resource group, and fails the test on any write.
+func setMockPlanEnv(az *Cloud) {
+	mockLBBackendPool := az.LoadBalancerBackendPool.(*MockBackendPool)
+	mockLBBackendPool.EXPECT().ReconcileBackendPools(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string, _ *v1.Service, lb *armnetwork.LoadBalancer) (bool, bool, *armnetwork.LoadBalancer, error) {
+		return false, false, lb, nil
+	}).AnyTimes()
+	mockLBBackendPool.EXPECT().GetBackendPrivateIPs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockLBBackendPool.EXPECT().EnsureHostsInPool(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	notFound := &azcore.ResponseError{StatusCode: http.StatusNotFound}
+	mockLBClient := az.NetworkClientFactory.GetLoadBalancerClient().(*mock_loadbalancerclient.MockInterface)
+	mockLBClient.EXPECT().List(gomock.Any(), az.ResourceGroup).Return(nil, nil).AnyTimes()
+	mockLBClient.EXPECT().Get(gomock.Any(), az.ResourceGroup, gomock.Any(), gomock.Any()).Return(nil, notFound).AnyTimes()
+	mockLBClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockLBClient.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	mockPIPClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+	mockPIPClient.EXPECT().List(gomock.Any(), az.ResourceGroup).Return(nil, nil).AnyTimes()
+	mockPIPClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockPIPClient.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	mockSGClient := az.NetworkClientFactory.GetSecurityGroupClient().(*mock_securitygroupclient.MockInterface)
+	mockSGClient.EXPECT().Get(gomock.Any(), az.SecurityGroupResourceGroup, az.SecurityGroupName).Return(getTestSecurityGroup(az), nil).AnyTimes()
+	mockSGClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+}
+
+func TestPlanLoadBalancer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	setMockPlanEnv(az)
+
+	clusterResources, _, _ := getClusterResources(az, 1, 1)
+	service := getTestService("service1", v1.ProtocolTCP, nil, false, 80)
+	plan, err := az.PlanLoadBalancer(context.TODO(), testClusterName, &service, clusterResources.nodes)
+	assert.NoError(t, err)
+
+	expected := []LoadBalancerPlanResourceChange{
+		{
+			Type:          LoadBalancerPlanResourceTypePublicIPAddress,
+			ResourceGroup: "rg",
+			Name:          "testCluster-aservice1",
+			Action:        LoadBalancerPlanActionAdd,
+		},
+		{
+			Type:          LoadBalancerPlanResourceTypeLoadBalancer,
+			ResourceGroup: "rg",
+			Name:          testClusterName,
+			Action:        LoadBalancerPlanActionAdd,
+			SubResources: []LoadBalancerPlanSubResourceChange{
+				{Type: "FrontendIPConfiguration", Name: "aservice1", Action: LoadBalancerPlanActionAdd},
+				{Type: "LoadBalancingRule", Name: "aservice1-TCP-80", Action: LoadBalancerPlanActionAdd},
+				{Type: "Probe", Name: "aservice1-TCP-80", Action: LoadBalancerPlanActionAdd},
+			},
+		},
+	}
+	assert.Equal(t, "default/service1", plan.Service)
+	assert.Len(t, plan.Changes, 3)
+	assert.Equal(t, expected, plan.Changes[:2])
+
+	sgChange := plan.Changes[2]
+	assert.Equal(t, LoadBalancerPlanResourceTypeSecurityGroup, sgChange.Type)
+	assert.Equal(t, az.SecurityGroupName, sgChange.Name)
+	assert.Equal(t, LoadBalancerPlanActionUpdate, sgChange.Action)
+	assert.NotEmpty(t, sgChange.SubResources)
+	for _, rule := range sgChange.SubResources {
+		assert.Equal(t, "SecurityRule", rule.Type)
+		assert.Equal(t, LoadBalancerPlanActionAdd, rule.Action)
+	}
+}
+
+func TestEnsureLoadBalancerPlanOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	recorder := record.NewFakeRecorder(10)
+	az.eventRecorder = recorder
+	setMockPlanEnv(az)
+
+	clusterResources, _, _ := getClusterResources(az, 1, 1)
+	service := getTestService("service1", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerPlanOnly: consts.TrueAnnotationValue,
+	}, false, 80)
+	service.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}
+
+	lbStatus, err := az.EnsureLoadBalancer(context.TODO(), testClusterName, &service, clusterResources.nodes)
+	assert.NoError(t, err)
+	assert.Equal(t, &service.Status.LoadBalancer, lbStatus)
+
+	assert.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, "LoadBalancerPlan")
+	assert.Contains(t, event, "add PublicIPAddress rg/testCluster-aservice1")
+	assert.Contains(t, event, "add LoadBalancer rg/testCluster (add FrontendIPConfiguration aservice1")
+}
+
+func TestEnsureLoadBalancerPlanOnlyWithUnmanagedClass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	recorder := record.NewFakeRecorder(10)
+	az.eventRecorder = recorder
+	setMockPlanEnv(az)
+
+	clusterResources, _, _ := getClusterResources(az, 1, 1)
+	service := getTestServiceWithLoadBalancerClass("service1", "example.com/other", 80)
+	service.Annotations = map[string]string{consts.ServiceAnnotationLoadBalancerPlanOnly: consts.TrueAnnotationValue}
+
+	_, err := az.EnsureLoadBalancer(context.TODO(), testClusterName, &service, clusterResources.nodes)
+	assert.ErrorIs(t, err, cloudprovider.ImplementedElsewhere)
+	assert.Empty(t, recorder.Events, "no plan should be reported for a service of another load balancer class")
+}
+
+func TestPlannedResourceDiff(t *testing.T) {
+	existingLB := &armnetwork.LoadBalancer{
+		Name: ptr.To("lb"),
+		Etag: ptr.To("etag1"),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			LoadBalancingRules: []*armnetwork.LoadBalancingRule{
+				{Name: ptr.To("rule1"), Properties: &armnetwork.LoadBalancingRulePropertiesFormat{FrontendPort: ptr.To(int32(80))}},
+				{Name: ptr.To("rule2"), Properties: &armnetwork.LoadBalancingRulePropertiesFormat{FrontendPort: ptr.To(int32(443))}},
+			},
+			Probes: []*armnetwork.Probe{
+				{Name: ptr.To("probe1")},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		description     string
+		resource        *plannedResource
+		expectedChanged bool
+		expected        LoadBalancerPlanResourceChange
+	}{
+		{
+			description: "should report no change if only the etag changes",
+			resource: &plannedResource{
+				resourceType: LoadBalancerPlanResourceTypeLoadBalancer,
+				original:     existingLB,
+				desired: func() *armnetwork.LoadBalancer {
+					lb := cloneLoadBalancer(existingLB)
+					lb.Etag = ptr.To("etag2")
+					return lb
+				}(),
+			},
+		},
+		{
+			description: "should report the changed sub-resources",
+			resource: &plannedResource{
+				resourceType:  LoadBalancerPlanResourceTypeLoadBalancer,
+				resourceGroup: "rg",
+				name:          "lb",
+				original:      existingLB,
+				desired: func() *armnetwork.LoadBalancer {
+					lb := cloneLoadBalancer(existingLB)
+					lb.Properties.LoadBalancingRules = []*armnetwork.LoadBalancingRule{
+						{Name: ptr.To("rule1"), Properties: &armnetwork.LoadBalancingRulePropertiesFormat{FrontendPort: ptr.To(int32(8080))}},
+						{Name: ptr.To("rule3"), Properties: &armnetwork.LoadBalancingRulePropertiesFormat{FrontendPort: ptr.To(int32(443))}},
+					}
+					return lb
+				}(),
+			},
+			expectedChanged: true,
+			expected: LoadBalancerPlanResourceChange{
+				Type:          LoadBalancerPlanResourceTypeLoadBalancer,
+				ResourceGroup: "rg",
+				Name:          "lb",
+				Action:        LoadBalancerPlanActionUpdate,
+				SubResources: []LoadBalancerPlanSubResourceChange{
+					{Type: "LoadBalancingRule", Name: "rule1", Action: LoadBalancerPlanActionUpdate},
+					{Type: "LoadBalancingRule", Name: "rule2", Action: LoadBalancerPlanActionDelete},
+					{Type: "LoadBalancingRule", Name: "rule3", Action: LoadBalancerPlanActionAdd},
+				},
+			},
+		},
+		{
+			description: "should report an update of the resource itself",
+			resource: &plannedResource{
+				resourceType:  LoadBalancerPlanResourceTypeLoadBalancer,
+				resourceGroup: "rg",
+				name:          "lb",
+				original:      existingLB,
+				desired: func() *armnetwork.LoadBalancer {
+					lb := cloneLoadBalancer(existingLB)
+					lb.Tags = map[string]*string{"foo": ptr.To("bar")}
+					return lb
+				}(),
+			},
+			expectedChanged: true,
+			expected: LoadBalancerPlanResourceChange{
+				Type:          LoadBalancerPlanResourceTypeLoadBalancer,
+				ResourceGroup: "rg",
+				Name:          "lb",
+				Action:        LoadBalancerPlanActionUpdate,
+			},
+		},
+		{
+			description: "should report the deletion of the resource",
+			resource: &plannedResource{
+				resourceType:  LoadBalancerPlanResourceTypePublicIPAddress,
+				resourceGroup: "rg",
+				name:          "pip",
+				original:      &armnetwork.PublicIPAddress{Name: ptr.To("pip")},
+			},
+			expectedChanged: true,
+			expected: LoadBalancerPlanResourceChange{
+				Type:          LoadBalancerPlanResourceTypePublicIPAddress,
+				ResourceGroup: "rg",
+				Name:          "pip",
+				Action:        LoadBalancerPlanActionDelete,
+			},
+		},
+		{
+			description: "should not report a resource created and deleted during planning",
+			resource: &plannedResource{
+				resourceType: LoadBalancerPlanResourceTypePublicIPAddress,
+			},
+		},
+		{
+			description: "should report the security rules of the security group",
+			resource: &plannedResource{
+				resourceType:  LoadBalancerPlanResourceTypeSecurityGroup,
+				resourceGroup: "rg",
+				name:          "nsg",
+				original:      &armnetwork.SecurityGroup{Name: ptr.To("nsg")},
+				desired: &armnetwork.SecurityGroup{
+					Name: ptr.To("nsg"),
+					Properties: &armnetwork.SecurityGroupPropertiesFormat{
+						SecurityRules: []*armnetwork.SecurityRule{
+							{Name: ptr.To("rule"), Properties: &armnetwork.SecurityRulePropertiesFormat{Access: to.Ptr(armnetwork.SecurityRuleAccessAllow)}},
+						},
+					},
+				},
+			},
+			expectedChanged: true,
+			expected: LoadBalancerPlanResourceChange{
+				Type:          LoadBalancerPlanResourceTypeSecurityGroup,
+				ResourceGroup: "rg",
+				Name:          "nsg",
+				Action:        LoadBalancerPlanActionUpdate,
+				SubResources: []LoadBalancerPlanSubResourceChange{
+					{Type: "SecurityRule", Name: "rule", Action: LoadBalancerPlanActionAdd},
+				},
+			},
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			change, changed, err := tc.resource.diff()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedChanged, changed)
+			if tc.expectedChanged {
+				assert.Equal(t, tc.expected, change)
+			}
+		})
+	}
+}
+
+func TestLoadBalancerPlanRecorder(t *testing.T) {
+	ctx, recorder := newLoadBalancerPlanContext(context.Background())
+	assert.True(t, isLoadBalancerPlanContext(ctx))
+	assert.False(t, isLoadBalancerPlanContext(withoutLoadBalancerPlan(ctx)))
+	assert.False(t, isLoadBalancerPlanContext(context.Background()))
+
+	existing := []*armnetwork.PublicIPAddress{
+		{Name: ptr.To("pip1")},
+		{Name: ptr.To("pip2")},
+		{Name: ptr.To("pip3")},
+	}
+	getOriginal := func(pip *armnetwork.PublicIPAddress) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			if pip == nil {
+				return nil, nil
+			}
+			return pip, nil
+		}
+	}
+	assert.NoError(t, recorder.record(LoadBalancerPlanResourceTypePublicIPAddress, "rg", "pip1", nil, getOriginal(existing[0])))
+	assert.NoError(t, recorder.record(LoadBalancerPlanResourceTypePublicIPAddress, "rg", "pip2",
+		&armnetwork.PublicIPAddress{Name: ptr.To("pip2"), Tags: map[string]*string{"foo": ptr.To("bar")}}, getOriginal(existing[1])))
+	assert.NoError(t, recorder.record(LoadBalancerPlanResourceTypePublicIPAddress, "rg", "pip4",
+		&armnetwork.PublicIPAddress{Name: ptr.To("pip4")}, getOriginal(nil)))
+	assert.NoError(t, recorder.record(LoadBalancerPlanResourceTypePublicIPAddress, "other-rg", "pip5",
+		&armnetwork.PublicIPAddress{Name: ptr.To("pip5")}, getOriginal(nil)))
+
+	overlaid := overlayPlannedResources(recorder, LoadBalancerPlanResourceTypePublicIPAddress, "rg", existing, func(pip *armnetwork.PublicIPAddress) string {
+		return ptr.Deref(pip.Name, "")
+	})
+	var names []string
+	for _, pip := range overlaid {
+		names = append(names, ptr.Deref(pip.Name, ""))
+	}
+	assert.Equal(t, []string{"pip2", "pip3", "pip4"}, names)
+	assert.Equal(t, "bar", ptr.Deref(overlaid[0].Tags["foo"], ""))
+
+	planned, ok := recorder.get(LoadBalancerPlanResourceTypePublicIPAddress, "RG", "PIP1")
+	assert.True(t, ok)
+	assert.Nil(t, planned)
+	_, ok = recorder.get(LoadBalancerPlanResourceTypePublicIPAddress, "rg", "pip3")
+	assert.False(t, ok)
+
+	plan, err := recorder.plan("default/service")
+	assert.NoError(t, err)
+	assert.Equal(t, "delete PublicIPAddress rg/pip1; update PublicIPAddress rg/pip2; add PublicIPAddress rg/pip4; add PublicIPAddress other-rg/pip5", plan.String())
+	assert.Equal(t, "no changes", (&LoadBalancerPlan{}).String())
+	assert.Equal(t, plan.String(), plan.eventMessage())
+
+	for i := 0; i < 100; i++ {
+		plan.Changes = append(plan.Changes, LoadBalancerPlanResourceChange{
+			Action:        LoadBalancerPlanActionAdd,
+			Type:          LoadBalancerPlanResourceTypePublicIPAddress,
+			ResourceGroup: "rg",
+			Name:          fmt.Sprintf("pip-%d", i),
+		})
+	}
+	message := plan.eventMessage()
+	assert.Len(t, message, loadBalancerPlanEventMaxLength)
+	assert.True(t, strings.HasSuffix(message, " ... (truncated)"))
+}
+
+func cloneLoadBalancer(lb *armnetwork.LoadBalancer) *armnetwork.LoadBalancer {
+	clone := *lb
+	properties := *lb.Properties
+	clone.Properties = &properties
+	return &clone
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+// azureReservedIPCount is the number of addresses at the beginning of each subnet
+// reserved by Azure (network address, default gateway and two DNS addresses).
+// The last address of the subnet is reserved as well.
+const azureReservedIPCount = 4
+
+// isInternalIPAutoAssignEnabled returns true if the service asks the provider to pick
+// an unused private IP from the subnet for the internal frontend.
+func isInternalIPAutoAssignEnabled(service *v1.Service) bool {
+	return requiresInternalLoadBalancer(service) &&
+		strings.EqualFold(service.Annotations[consts.ServiceAnnotationLoadBalancerInternalIPAutoAssign], consts.TrueAnnotationValue)
+}
+
+// parsePrivateIPRange parses the range in the CIDR form (10.0.0.64/26)
+// or the start-end form (10.0.0.10-10.0.0.20).
+func parsePrivateIPRange(value string) (netip.Addr, netip.Addr, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid IP range %q: %w", value, err)
+		}
+		return prefix.Masked().Addr(), lastAddrOfPrefix(prefix), nil
+	}
+
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid IP range %q, expected a CIDR or the start-end form", value)
+	}
+	start, err := netip.ParseAddr(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid IP range %q: %w", value, err)
+	}
+	end, err := netip.ParseAddr(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid IP range %q: %w", value, err)
+	}
+	if start.Is4() != end.Is4() || start.Compare(end) > 0 {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid IP range %q, the start IP should not be greater than the end IP of the same family", value)
+	}
+	return start, end, nil
+}
+
+// getServiceInternalIPAutoAssignRange returns the sub-range of the subnet the private IP of the
+// given family should be picked from. Invalid addresses are returned if the range is not set.
+func getServiceInternalIPAutoAssignRange(service *v1.Service, isIPv6 bool) (netip.Addr, netip.Addr, error) {
+	value := strings.TrimSpace(service.Annotations[consts.ServiceAnnotationLoadBalancerInternalIPAutoAssignRangeDualStack[isIPv6]])
+	if value == "" {
+		return netip.Addr{}, netip.Addr{}, nil
+	}
+	start, end, err := parsePrivateIPRange(value)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, err
+	}
+	if start.Is6() != isIPv6 {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("IP range %q does not match the IP family (isIPv6=%t)", value, isIPv6)
+	}
+	return start, end, nil
+}
+
+// lastAddrOfPrefix returns the last address in the prefix.
+func lastAddrOfPrefix(prefix netip.Prefix) netip.Addr {
+	b := prefix.Masked().Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// getUsedPrivateIPs collects the private IPs used by the IP configurations of the subnet,
+// which include the NICs and the frontends of all load balancers in the subnet, and the
+// private IPs of the frontends of the given load balancer.
+func getUsedPrivateIPs(subnet *armnetwork.Subnet, lb *armnetwork.LoadBalancer) map[netip.Addr]bool {
+	used := make(map[netip.Addr]bool)
+	addIP := func(ip *string) {
+		if addr, err := netip.ParseAddr(ptr.Deref(ip, "")); err == nil {
+			used[addr] = true
+		}
+	}
+	if subnet != nil && subnet.Properties != nil {
+		for _, ipConfig := range subnet.Properties.IPConfigurations {
+			if ipConfig != nil && ipConfig.Properties != nil {
+				addIP(ipConfig.Properties.PrivateIPAddress)
+			}
+		}
+	}
+	if lb != nil && lb.Properties != nil {
+		for _, fip := range lb.Properties.FrontendIPConfigurations {
+			if fip != nil && fip.Properties != nil {
+				addIP(fip.Properties.PrivateIPAddress)
+			}
+		}
+	}
+	return used
+}
+
+// selectFreePrivateIP returns the first address of the given family in the subnet that is
+// neither reserved by Azure nor used, optionally restricted to [rangeStart, rangeEnd].
+func selectFreePrivateIP(subnet *armnetwork.Subnet, isIPv6 bool, rangeStart, rangeEnd netip.Addr, used map[netip.Addr]bool) (string, error) {
+	if subnet == nil || subnet.Properties == nil {
+		return "", fmt.Errorf("subnet is empty")
+	}
+	cidrs := make([]*string, 0)
+	if subnet.Properties.AddressPrefix != nil {
+		cidrs = append(cidrs, subnet.Properties.AddressPrefix)
+	}
+	cidrs = append(cidrs, subnet.Properties.AddressPrefixes...)
+
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(ptr.Deref(cidr, ""))
+		if err != nil {
+			klog.Errorf("selectFreePrivateIP: failed to parse ip cidr %s: %v", ptr.Deref(cidr, ""), err)
+			continue
+		}
+		if prefix.Addr().Is6() != isIPv6 {
+			continue
+		}
+
+		start := prefix.Masked().Addr()
+		for i := 0; i < azureReservedIPCount && start.IsValid(); i++ {
+			start = start.Next()
+		}
+		end := lastAddrOfPrefix(prefix).Prev()
+		if rangeStart.IsValid() && rangeStart.Compare(start) > 0 {
+			start = rangeStart
+		}
+		if rangeEnd.IsValid() && rangeEnd.Compare(end) < 0 {
+			end = rangeEnd
+		}
+		for ip := start; ip.IsValid() && ip.Compare(end) <= 0; ip = ip.Next() {
+			if !used[ip] {
+				return ip.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no free private IP (isIPv6=%t) left in subnet %s", isIPv6, ptr.Deref(subnet.Name, ""))
+}
+
+// getAutoAssignedInternalIP picks an unused private IP of the given family for the internal service.
+// The subnet is fetched with its IP configurations expanded to detect the collisions with the
+// existing NICs and frontends.
+func (az *Cloud) getAutoAssignedInternalIP(ctx context.Context, service *v1.Service, subnet *armnetwork.Subnet, lb *armnetwork.LoadBalancer, isIPv6 bool) (string, error) {
+	rangeStart, rangeEnd, err := getServiceInternalIPAutoAssignRange(service, isIPv6)
+	if err != nil {
+		return "", err
+	}
+
+	subnetID, err := arm.ParseResourceID(ptr.Deref(subnet.ID, ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the subnet ID %q: %w", ptr.Deref(subnet.ID, ""), err)
+	}
+	expandedSubnet, err := az.subnetRepo.GetWithIPConfigurations(ctx, subnetID.ResourceGroupName, subnetID.Parent.Name, subnetID.Name)
+	if err != nil {
+		return "", err
+	}
+
+	privateIP, err := selectFreePrivateIP(expandedSubnet, isIPv6, rangeStart, rangeEnd, getUsedPrivateIPs(expandedSubnet, lb))
+	if err != nil {
+		return "", err
+	}
+	klog.V(2).Infof("getAutoAssignedInternalIP: selected private IP %s for service %s (isIPv6=%t)", privateIP, getServiceName(service), isIPv6)
+	return privateIP, nil
+}
+
+// persistInternalIPs records the private IPs of the internal frontends owned by the service in the
+// azure-load-balancer-ipv4/ipv6 annotations. It is called after the load balancer is updated, since
+// an IP recorded for a frontend that failed to be created may be taken by another resource meanwhile.
+func (az *Cloud) persistInternalIPs(ctx context.Context, service *v1.Service, lb *armnetwork.LoadBalancer) error {
+	if !isInternalIPAutoAssignEnabled(service) || lb == nil || lb.Properties == nil {
+		return nil
+	}
+	for _, fip := range lb.Properties.FrontendIPConfigurations {
+		if fip.Properties == nil || ptr.Deref(fip.Properties.PrivateIPAddress, "") == "" {
+			continue
+		}
+		// The secondary services share the frontend through the IP in their annotations already.
+		if owns, isPrimary, _ := az.serviceOwnsFrontendIP(ctx, fip, service); !owns || !isPrimary {
+			continue
+		}
+		ip := *fip.Properties.PrivateIPAddress
+		if err := az.persistServiceLoadBalancerIP(ctx, service, ip, net.ParseIP(ip).To4() == nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persistServiceLoadBalancerIP records the private IP in the azure-load-balancer-ipv4/ipv6
+// annotation so that the same IP is used when the frontend is recreated.
+func (az *Cloud) persistServiceLoadBalancerIP(ctx context.Context, service *v1.Service, ip string, isIPv6 bool) error {
+	annotation := consts.ServiceAnnotationLoadBalancerIPDualStack[isIPv6]
+	if service.Annotations[annotation] == ip || loadBalancerPlanRecorderFromContext(ctx) != nil {
+		return nil
+	}
+	if az.KubeClient == nil {
+		klog.Warningf("persistServiceLoadBalancerIP: az.KubeClient is nil, skip persisting private IP %s for service %s", ip, getServiceName(service))
+		return nil
+	}
+
+	if err := az.patchServiceAnnotations(ctx, service, map[string]*string{annotation: ptr.To(ip)}); err != nil {
+		return err
+	}
+
+	az.Event(service, v1.EventTypeNormal, "InternalIPAutoAssigned", fmt.Sprintf("Assigned private IP %s to the internal load balancer frontend", ip))
+	return nil
+}
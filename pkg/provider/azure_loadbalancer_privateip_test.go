@@ -0,0 +1,260 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/subnet"
+)
+
+func TestParsePrivateIPRange(t *testing.T) {
+	for _, tc := range []struct {
+		desc          string
+		value         string
+		expectedStart string
+		expectedEnd   string
+		expectedErr   bool
+	}{
+		{
+			desc:          "should parse the CIDR form",
+			value:         "10.0.0.64/26",
+			expectedStart: "10.0.0.64",
+			expectedEnd:   "10.0.0.127",
+		},
+		{
+			desc:          "should parse the start-end form",
+			value:         "10.0.0.10 - 10.0.0.20",
+			expectedStart: "10.0.0.10",
+			expectedEnd:   "10.0.0.20",
+		},
+		{
+			desc:          "should parse the IPv6 CIDR form",
+			value:         "fd00::100/120",
+			expectedStart: "fd00::100",
+			expectedEnd:   "fd00::1ff",
+		},
+		{
+			desc:        "should report an error if the start is greater than the end",
+			value:       "10.0.0.20-10.0.0.10",
+			expectedErr: true,
+		},
+		{
+			desc:        "should report an error if the families are different",
+			value:       "10.0.0.10-fd00::1",
+			expectedErr: true,
+		},
+		{
+			desc:        "should report an error for an invalid value",
+			value:       "10.0.0.10",
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			start, end, err := parsePrivateIPRange(tc.value)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			if !tc.expectedErr {
+				assert.Equal(t, tc.expectedStart, start.String())
+				assert.Equal(t, tc.expectedEnd, end.String())
+			}
+		})
+	}
+}
+
+func TestSelectFreePrivateIP(t *testing.T) {
+	dualStackSubnet := &armnetwork.Subnet{
+		Name: ptr.To("subnet"),
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			AddressPrefixes: []*string{ptr.To("10.0.0.0/29"), ptr.To("fd00::/124")},
+		},
+	}
+	for _, tc := range []struct {
+		desc        string
+		isIPv6      bool
+		rangeValue  string
+		used        []string
+		expectedIP  string
+		expectedErr bool
+	}{
+		{
+			desc:       "should skip the addresses reserved by Azure",
+			expectedIP: "10.0.0.4",
+		},
+		{
+			desc:       "should skip the used addresses",
+			used:       []string{"10.0.0.4", "10.0.0.5"},
+			expectedIP: "10.0.0.6",
+		},
+		{
+			desc:        "should not use the last address of the subnet",
+			used:        []string{"10.0.0.4", "10.0.0.5", "10.0.0.6"},
+			expectedErr: true,
+		},
+		{
+			desc:       "should pick the address in the range",
+			rangeValue: "10.0.0.5-10.0.0.6",
+			used:       []string{"10.0.0.5"},
+			expectedIP: "10.0.0.6",
+		},
+		{
+			desc:        "should report an error if the range is outside of the subnet",
+			rangeValue:  "10.0.1.0/24",
+			expectedErr: true,
+		},
+		{
+			desc:       "should pick the IPv6 address",
+			isIPv6:     true,
+			used:       []string{"fd00::4"},
+			expectedIP: "fd00::5",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var rangeStart, rangeEnd netip.Addr
+			if tc.rangeValue != "" {
+				var err error
+				rangeStart, rangeEnd, err = parsePrivateIPRange(tc.rangeValue)
+				assert.NoError(t, err)
+			}
+			used := make(map[netip.Addr]bool)
+			for _, ip := range tc.used {
+				used[netip.MustParseAddr(ip)] = true
+			}
+			ip, err := selectFreePrivateIP(dualStackSubnet, tc.isIPv6, rangeStart, rangeEnd, used)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedIP, ip)
+		})
+	}
+}
+
+func TestGetUsedPrivateIPs(t *testing.T) {
+	subnet := &armnetwork.Subnet{
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			IPConfigurations: []*armnetwork.IPConfiguration{
+				{Properties: &armnetwork.IPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To("10.0.0.4")}},
+				{ID: ptr.To("nic-without-properties")},
+			},
+		},
+	}
+	lb := &armnetwork.LoadBalancer{
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To("10.0.0.5")}},
+			},
+		},
+	}
+	assert.Equal(t, map[netip.Addr]bool{
+		netip.MustParseAddr("10.0.0.4"): true,
+		netip.MustParseAddr("10.0.0.5"): true,
+	}, getUsedPrivateIPs(subnet, lb))
+}
+
+func TestAutoAssignInternalIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.eventRecorder = record.NewFakeRecorder(10)
+	service := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerInternal:                                  consts.TrueAnnotationValue,
+		consts.ServiceAnnotationLoadBalancerInternalIPAutoAssign:                      consts.TrueAnnotationValue,
+		consts.ServiceAnnotationLoadBalancerInternalIPAutoAssignRangeDualStack[false]: "10.0.0.10-10.0.0.20",
+	}, false, 80)
+	assert.True(t, isInternalIPAutoAssignEnabled(&service))
+	az.KubeClient = fake.NewSimpleClientset(&service)
+
+	subnetID := "/subscriptions/subscription/resourceGroups/vnet-rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet"
+	existingSubnet := &armnetwork.Subnet{
+		ID:   ptr.To(subnetID),
+		Name: ptr.To("subnet"),
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			AddressPrefix: ptr.To("10.0.0.0/24"),
+		},
+	}
+	expandedSubnet := &armnetwork.Subnet{
+		ID:   ptr.To(subnetID),
+		Name: ptr.To("subnet"),
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			AddressPrefix: ptr.To("10.0.0.0/24"),
+			IPConfigurations: []*armnetwork.IPConfiguration{
+				{Properties: &armnetwork.IPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To("10.0.0.10")}},
+			},
+		},
+	}
+	lb := &armnetwork.LoadBalancer{
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To("10.0.0.11")}},
+			},
+		},
+	}
+	az.subnetRepo.(*subnet.MockRepository).EXPECT().GetWithIPConfigurations(gomock.Any(), "vnet-rg", "vnet", "subnet").Return(expandedSubnet, nil)
+
+	ip, err := az.getAutoAssignedInternalIP(context.Background(), &service, existingSubnet, lb, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.12", ip)
+
+	assert.NoError(t, az.persistServiceLoadBalancerIP(context.Background(), &service, ip, false))
+	updated, err := az.KubeClient.CoreV1().Services(service.Namespace).Get(context.Background(), service.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.12", updated.Annotations[consts.ServiceAnnotationLoadBalancerIPDualStack[false]])
+	assert.Equal(t, ip, getServiceLoadBalancerIP(updated, false))
+}
+
+func TestPersistInternalIPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.eventRecorder = record.NewFakeRecorder(10)
+	service := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerInternal:             consts.TrueAnnotationValue,
+		consts.ServiceAnnotationLoadBalancerInternalIPAutoAssign: consts.TrueAnnotationValue,
+	}, false, 80)
+	az.KubeClient = fake.NewSimpleClientset(&service)
+
+	lb := &armnetwork.LoadBalancer{
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{
+				{
+					Name:       ptr.To("other"),
+					Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To("10.0.0.11")},
+				},
+				{
+					Name:       ptr.To(az.getDefaultFrontendIPConfigName(&service)),
+					Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To("10.0.0.12")},
+				},
+			},
+		},
+	}
+	assert.NoError(t, az.persistInternalIPs(context.Background(), &service, lb))
+	updated, err := az.KubeClient.CoreV1().Services(service.Namespace).Get(context.Background(), service.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.12", updated.Annotations[consts.ServiceAnnotationLoadBalancerIPDualStack[false]])
+	assert.Empty(t, updated.Annotations[consts.ServiceAnnotationLoadBalancerIPDualStack[true]])
+}
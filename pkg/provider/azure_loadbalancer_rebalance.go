@@ -0,0 +1,458 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/util/errutils"
+)
+
+const (
+	// The reasons of the rebalance moves, which are used in the events and metrics.
+	loadBalancerRebalanceReasonDrain   = "drain"
+	loadBalancerRebalanceReasonBalance = "balance"
+)
+
+var (
+	loadBalancerRebalanceMoves = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "load_balancer_rebalance_moves_total",
+			Help:           "Number of services moved between the multiple standard load balancers by the rebalancer",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"reason"},
+	)
+	registerLoadBalancerRebalanceMetricsOnce sync.Once
+)
+
+func registerLoadBalancerRebalanceMetrics() {
+	registerLoadBalancerRebalanceMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(loadBalancerRebalanceMoves)
+	})
+}
+
+// rebalanceLoadBalancer is a load balancer of a multiple standard load balancer configuration.
+type rebalanceLoadBalancer struct {
+	configName string
+	// draining is true if the configuration does not allow service placement.
+	draining  bool
+	ruleCount int
+}
+
+// rebalanceCandidate is a service which can be moved to another load balancer.
+type rebalanceCandidate struct {
+	service   *v1.Service
+	lb        *rebalanceLoadBalancer
+	ruleCount int
+	// targets are the load balancers the service can be moved to.
+	targets []*rebalanceLoadBalancer
+}
+
+// loadBalancerRebalanceMove moves a service from one load balancer configuration to another.
+type loadBalancerRebalanceMove struct {
+	service *v1.Service
+	from    string
+	to      string
+	reason  string
+}
+
+// finishedLoadBalancerRebalanceMove is a move whose rebalance target annotation should be removed from the service,
+// because the service has been moved to the target, or the move has timed out.
+type finishedLoadBalancerRebalanceMove struct {
+	service  *v1.Service
+	to       string
+	timedOut bool
+}
+
+// isLoadBalancerRebalanceEnabled returns true if the services should be rebalanced across the multiple standard load balancers.
+func (az *Cloud) isLoadBalancerRebalanceEnabled() bool {
+	return az.LoadBalancerRebalanceIntervalInSeconds > 0 && az.UseMultipleStandardLoadBalancers()
+}
+
+// getServiceRebalanceTarget returns the name of the load balancer configuration the service is moved to by the rebalancer.
+func getServiceRebalanceTarget(service *v1.Service) string {
+	return strings.TrimSpace(service.Annotations[consts.ServiceAnnotationLoadBalancerRebalanceTarget])
+}
+
+// runLoadBalancerRebalancer periodically rebalances the services across the multiple standard load balancers.
+func (az *Cloud) runLoadBalancerRebalancer(ctx context.Context) {
+	registerLoadBalancerRebalanceMetrics()
+	interval := time.Duration(az.LoadBalancerRebalanceIntervalInSeconds) * time.Second
+	klog.Infof("runLoadBalancerRebalancer: started with interval %s", interval)
+	wait.UntilWithContext(ctx, az.rebalanceLoadBalancers, interval)
+}
+
+// rebalanceLoadBalancers moves at most LoadBalancerRebalanceMaxMoves services to other load balancers.
+// The services are not moved by the rebalancer itself. Instead, the rebalance target annotation is set on
+// them, which triggers the service reconciliation to move their frontend IP configurations, so the public IPs,
+// or the private IPs if they are in the same subnet, are kept.
+func (az *Cloud) rebalanceLoadBalancers(ctx context.Context) {
+	moves, finished, err := az.planLoadBalancerRebalance(ctx)
+	if err != nil {
+		klog.Errorf("rebalanceLoadBalancers: failed to plan the rebalance: %s", err.Error())
+		return
+	}
+
+	for _, move := range finished {
+		if err := az.finishLoadBalancerRebalanceMove(ctx, move); err != nil {
+			klog.Errorf("rebalanceLoadBalancers: failed to finish moving service %s to load balancer %s: %s", getServiceName(move.service), move.to, err.Error())
+		}
+	}
+	for _, move := range moves {
+		if err := az.applyLoadBalancerRebalanceMove(ctx, move); err != nil {
+			klog.Errorf("rebalanceLoadBalancers: failed to move service %s from load balancer %s to %s: %s", getServiceName(move.service), move.from, move.to, err.Error())
+		}
+	}
+}
+
+// planLoadBalancerRebalance decides which services should be moved. The services on the load balancers
+// which do not allow service placement are moved first. Then the services are moved from the load balancers
+// with the most rules to the ones with the fewest if the difference exceeds LoadBalancerRebalanceRuleCountThreshold.
+// Nothing is moved until the previous moves are finished, and the finished moves are returned so that their
+// rebalance target annotations are removed. The planning only reads the load balancers, so the service
+// reconciliation lock is not held, and the moves are applied by the service reconciliation later.
+func (az *Cloud) planLoadBalancerRebalance(ctx context.Context) ([]loadBalancerRebalanceMove, []finishedLoadBalancerRebalanceMove, error) {
+	// The active services of the load balancers are not known until the first service reconciliation.
+	if !az.multipleStandardLoadBalancerConfigurationsSynced {
+		klog.V(4).Info("planLoadBalancerRebalance: skipping because the multiple standard load balancer configurations are not synced")
+		return nil, nil, nil
+	}
+
+	rgName := az.getLoadBalancerResourceGroup()
+	existingLBs, err := az.NetworkClientFactory.GetLoadBalancerClient().List(ctx, rgName)
+	if err != nil {
+		if exist, _ := errutils.CheckResourceExistsFromAzcoreError(err); exist {
+			return nil, nil, fmt.Errorf("failed to list load balancers in resource group %s: %w", rgName, err)
+		}
+	}
+	existingLBsByName := make(map[string]*armnetwork.LoadBalancer)
+	for _, lb := range existingLBs {
+		existingLBsByName[strings.ToLower(ptr.Deref(lb.Name, ""))] = lb
+	}
+
+	lbs := make(map[string]*rebalanceLoadBalancer)
+	for _, multiSLBConfig := range az.getMultipleStandardLoadBalancerConfigurations() {
+		for _, isInternal := range []bool{false, true} {
+			lbName := getRebalanceLoadBalancerName(multiSLBConfig.Name, isInternal)
+			rlb := &rebalanceLoadBalancer{
+				configName: multiSLBConfig.Name,
+				draining:   !ptr.Deref(multiSLBConfig.AllowServicePlacement, true),
+			}
+			if lb := existingLBsByName[strings.ToLower(lbName)]; lb != nil && lb.Properties != nil {
+				rlb.ruleCount = len(lb.Properties.LoadBalancingRules)
+			}
+			lbs[strings.ToLower(lbName)] = rlb
+		}
+	}
+
+	candidates, finished, pending := az.getRebalanceCandidates(ctx, lbs, existingLBsByName)
+	if pending {
+		klog.V(2).Info("planLoadBalancerRebalance: waiting for the previous moves to finish")
+		return nil, finished, nil
+	}
+
+	var moves []loadBalancerRebalanceMove
+	moved := make(map[string]bool)
+	move := func(candidate *rebalanceCandidate, target *rebalanceLoadBalancer, reason string) {
+		moves = append(moves, loadBalancerRebalanceMove{
+			service: candidate.service,
+			from:    candidate.lb.configName,
+			to:      target.configName,
+			reason:  reason,
+		})
+		moved[getServiceName(candidate.service)] = true
+		candidate.lb.ruleCount -= candidate.ruleCount
+		target.ruleCount += candidate.ruleCount
+	}
+
+	// 1. Move the services off the draining load balancers.
+	for _, candidate := range candidates {
+		if len(moves) >= az.LoadBalancerRebalanceMaxMoves {
+			return moves, finished, nil
+		}
+		if !candidate.lb.draining {
+			continue
+		}
+		if target := az.getRebalanceTarget(candidate); target != nil {
+			move(candidate, target, loadBalancerRebalanceReasonDrain)
+		} else {
+			klog.Warningf("planLoadBalancerRebalance: no load balancer can take the %d rules of service %s on the draining load balancer %s", candidate.ruleCount, getServiceName(candidate.service), candidate.lb.configName)
+		}
+	}
+
+	// 2. Even out the rule counts, starting from the load balancer with the most rules and the service with the fewest rules.
+	for len(moves) < az.LoadBalancerRebalanceMaxMoves {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].lb.ruleCount != candidates[j].lb.ruleCount {
+				return candidates[i].lb.ruleCount > candidates[j].lb.ruleCount
+			}
+			return candidates[i].ruleCount < candidates[j].ruleCount
+		})
+
+		var found bool
+		for _, candidate := range candidates {
+			if moved[getServiceName(candidate.service)] || candidate.lb.draining {
+				continue
+			}
+			target := az.getRebalanceTarget(candidate)
+			if target == nil {
+				continue
+			}
+			// The move must make the difference smaller.
+			diff := candidate.lb.ruleCount - target.ruleCount
+			if diff < az.LoadBalancerRebalanceRuleCountThreshold || candidate.ruleCount >= diff {
+				continue
+			}
+			move(candidate, target, loadBalancerRebalanceReasonBalance)
+			found = true
+			break
+		}
+		if !found {
+			break
+		}
+	}
+
+	return moves, finished, nil
+}
+
+// getRebalanceCandidates returns the services which can be moved to other load balancers, the moves which
+// have finished or timed out, and whether there are services being moved. A move is finished when the service
+// is only active on the target load balancer. The services sharing the frontend IP configurations with other
+// services are not candidates because their IPs cannot be moved without affecting the others.
+func (az *Cloud) getRebalanceCandidates(
+	ctx context.Context,
+	lbs map[string]*rebalanceLoadBalancer,
+	existingLBsByName map[string]*armnetwork.LoadBalancer,
+) (candidates []*rebalanceCandidate, finished []finishedLoadBalancerRebalanceMove, pending bool) {
+	activeServices := make(map[string][]string)
+	activeConfigCounts := make(map[string]int)
+	az.multipleStandardLoadBalancersActiveServicesLock.Lock()
+	for _, multiSLBConfig := range az.getMultipleStandardLoadBalancerConfigurations() {
+		if multiSLBConfig.ActiveServices != nil {
+			activeServices[multiSLBConfig.Name] = multiSLBConfig.ActiveServices.UnsortedList()
+			for _, serviceName := range activeServices[multiSLBConfig.Name] {
+				activeConfigCounts[strings.ToLower(serviceName)]++
+			}
+		}
+	}
+	az.multipleStandardLoadBalancersActiveServicesLock.Unlock()
+
+	finishedServices := make(map[string]bool)
+	finish := func(service *v1.Service, targetLBName string, timedOut bool) {
+		if serviceName := getServiceName(service); !finishedServices[serviceName] {
+			finishedServices[serviceName] = true
+			finished = append(finished, finishedLoadBalancerRebalanceMove{service: service, to: targetLBName, timedOut: timedOut})
+		}
+	}
+
+	for configName, serviceNames := range activeServices {
+		sort.Strings(serviceNames)
+		for _, serviceName := range serviceNames {
+			parts := strings.SplitN(serviceName, "/", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			service, err := az.serviceLister.Services(parts[0]).Get(parts[1])
+			if err != nil || service.DeletionTimestamp != nil || service.Spec.Type != v1.ServiceTypeLoadBalancer {
+				continue
+			}
+			eligibleLBs, err := az.getEligibleLoadBalancersForService(ctx, service)
+			if err != nil {
+				klog.Errorf("getRebalanceCandidates: failed to get the eligible load balancers of service %s: %s", serviceName, err.Error())
+				continue
+			}
+			if targetLBName := getServiceRebalanceTarget(service); targetLBName != "" && StringInSlice(targetLBName, eligibleLBs) {
+				switch {
+				case strings.EqualFold(targetLBName, configName) && activeConfigCounts[strings.ToLower(serviceName)] == 1:
+					finish(service, targetLBName, false)
+				case az.isLoadBalancerRebalanceMoveTimedOut(serviceName):
+					finish(service, targetLBName, true)
+				default:
+					klog.V(4).Infof("getRebalanceCandidates: service %s is being moved from load balancer %s to %s", serviceName, configName, targetLBName)
+					pending = true
+				}
+				continue
+			}
+
+			isInternal := requiresInternalLoadBalancer(service)
+			lbName := strings.ToLower(getRebalanceLoadBalancerName(configName, isInternal))
+			lb := existingLBsByName[lbName]
+			if lb == nil || lb.Properties == nil || lbs[lbName] == nil {
+				continue
+			}
+			ruleCount, shared, err := az.getServiceRuleCountOnLoadBalancer(ctx, service, lb)
+			if err != nil {
+				klog.Errorf("getRebalanceCandidates: failed to count the rules of service %s on load balancer %s: %s", serviceName, lbName, err.Error())
+				continue
+			}
+			if shared {
+				klog.V(4).Infof("getRebalanceCandidates: skipping service %s because it shares the frontend IP configurations with other services", serviceName)
+				continue
+			}
+			if ruleCount == 0 {
+				continue
+			}
+
+			var targets []*rebalanceLoadBalancer
+			for _, eligibleLB := range eligibleLBs {
+				target := lbs[strings.ToLower(getRebalanceLoadBalancerName(eligibleLB, isInternal))]
+				if target == nil || target.draining || strings.EqualFold(eligibleLB, configName) {
+					continue
+				}
+				targets = append(targets, target)
+			}
+			if len(targets) == 0 {
+				continue
+			}
+
+			candidates = append(candidates, &rebalanceCandidate{
+				service:   service,
+				lb:        lbs[lbName],
+				ruleCount: ruleCount,
+				targets:   targets,
+			})
+		}
+	}
+
+	// Keep the order stable across the runs.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return getServiceName(candidates[i].service) < getServiceName(candidates[j].service)
+	})
+	return candidates, finished, pending
+}
+
+// isLoadBalancerRebalanceMoveTimedOut returns true if the service has been moved for longer than
+// LoadBalancerRebalanceMoveTimeoutInSeconds. The moves found after a restart are timed from then on.
+func (az *Cloud) isLoadBalancerRebalanceMoveTimedOut(serviceName string) bool {
+	if az.loadBalancerRebalanceMoveStartTimes == nil {
+		az.loadBalancerRebalanceMoveStartTimes = make(map[string]time.Time)
+	}
+	startTime, found := az.loadBalancerRebalanceMoveStartTimes[serviceName]
+	if !found {
+		az.loadBalancerRebalanceMoveStartTimes[serviceName] = time.Now()
+		return false
+	}
+	return time.Since(startTime) > time.Duration(az.LoadBalancerRebalanceMoveTimeoutInSeconds)*time.Second
+}
+
+// getServiceRuleCountOnLoadBalancer returns the number of load balancing rules of the service on the load balancer,
+// and whether its frontend IP configurations are shared with other services.
+func (az *Cloud) getServiceRuleCountOnLoadBalancer(ctx context.Context, service *v1.Service, lb *armnetwork.LoadBalancer) (int, bool, error) {
+	fipConfigs, err := az.findFrontendIPConfigsOfService(ctx, lb.Properties.FrontendIPConfigurations, service)
+	if err != nil {
+		return 0, false, err
+	}
+	fipConfigIDs := make(map[string]bool)
+	for _, fipConfig := range fipConfigs {
+		fipConfigIDs[strings.ToLower(ptr.Deref(fipConfig.ID, ""))] = true
+	}
+
+	var ruleCount int
+	for _, rule := range lb.Properties.LoadBalancingRules {
+		if rule.Properties == nil || rule.Properties.FrontendIPConfiguration == nil ||
+			!fipConfigIDs[strings.ToLower(ptr.Deref(rule.Properties.FrontendIPConfiguration.ID, ""))] {
+			continue
+		}
+		if !az.serviceOwnsRule(service, ptr.Deref(rule.Name, "")) {
+			return 0, true, nil
+		}
+		ruleCount++
+	}
+	return ruleCount, false, nil
+}
+
+// getRebalanceTarget returns the target load balancer with the fewest rules which can take the rules of the service.
+func (az *Cloud) getRebalanceTarget(candidate *rebalanceCandidate) *rebalanceLoadBalancer {
+	var target *rebalanceLoadBalancer
+	for _, lb := range candidate.targets {
+		if lb.ruleCount+candidate.ruleCount > az.MaximumLoadBalancerRuleCount {
+			continue
+		}
+		if target == nil || lb.ruleCount < target.ruleCount {
+			target = lb
+		}
+	}
+	return target
+}
+
+// applyLoadBalancerRebalanceMove sets the rebalance target annotation on the service, which triggers the
+// service reconciliation to move the service to the target load balancer.
+func (az *Cloud) applyLoadBalancerRebalanceMove(ctx context.Context, move loadBalancerRebalanceMove) error {
+	serviceName := getServiceName(move.service)
+	klog.V(2).Infof("applyLoadBalancerRebalanceMove: moving service %s from load balancer %s to %s (reason: %s)", serviceName, move.from, move.to, move.reason)
+	if err := az.patchServiceAnnotations(ctx, move.service, map[string]*string{
+		consts.ServiceAnnotationLoadBalancerRebalanceTarget: ptr.To(move.to),
+	}); err != nil {
+		return err
+	}
+
+	if az.loadBalancerRebalanceMoveStartTimes == nil {
+		az.loadBalancerRebalanceMoveStartTimes = make(map[string]time.Time)
+	}
+	az.loadBalancerRebalanceMoveStartTimes[serviceName] = time.Now()
+	loadBalancerRebalanceMoves.WithLabelValues(move.reason).Inc()
+	message := fmt.Sprintf("Moving the service from load balancer %s to %s to even out the load balancing rules", move.from, move.to)
+	if move.reason == loadBalancerRebalanceReasonDrain {
+		message = fmt.Sprintf("Moving the service from load balancer %s to %s because %s does not allow service placement", move.from, move.to, move.from)
+	}
+	az.Event(move.service, v1.EventTypeNormal, "LoadBalancerRebalance", message)
+	return nil
+}
+
+// finishLoadBalancerRebalanceMove removes the rebalance target annotation from the service. The service stays on the
+// load balancer it is active on, which is the target unless the move has timed out.
+func (az *Cloud) finishLoadBalancerRebalanceMove(ctx context.Context, move finishedLoadBalancerRebalanceMove) error {
+	serviceName := getServiceName(move.service)
+	if err := az.patchServiceAnnotations(ctx, move.service, map[string]*string{
+		consts.ServiceAnnotationLoadBalancerRebalanceTarget: nil,
+	}); err != nil {
+		return err
+	}
+	delete(az.loadBalancerRebalanceMoveStartTimes, serviceName)
+
+	if move.timedOut {
+		klog.Warningf("finishLoadBalancerRebalanceMove: abandoned moving service %s to load balancer %s after %d seconds", serviceName, move.to, az.LoadBalancerRebalanceMoveTimeoutInSeconds)
+		az.Event(move.service, v1.EventTypeWarning, "LoadBalancerRebalanceTimeout",
+			fmt.Sprintf("Abandoned moving the service to load balancer %s because it is not finished in %d seconds", move.to, az.LoadBalancerRebalanceMoveTimeoutInSeconds))
+		return nil
+	}
+	klog.V(2).Infof("finishLoadBalancerRebalanceMove: moved service %s to load balancer %s", serviceName, move.to)
+	return nil
+}
+
+func getRebalanceLoadBalancerName(configName string, isInternal bool) string {
+	if isInternal {
+		return configName + consts.InternalLoadBalancerNameSuffix
+	}
+	return configName
+}
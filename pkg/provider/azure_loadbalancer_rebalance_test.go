@@ -0,0 +1,300 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/loadbalancerclient/mock_loadbalancerclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+// getRebalanceTestLB returns a load balancer with the given number of rules for each service.
+func getRebalanceTestLB(az *Cloud, name string, services []*v1.Service, ruleCounts []int) *armnetwork.LoadBalancer {
+	lb := &armnetwork.LoadBalancer{
+		Name:       ptr.To(name),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{},
+	}
+	for i, service := range services {
+		prefix := az.GetLoadBalancerName(context.Background(), "", service)
+		fipID := az.getFrontendIPConfigID(name, prefix)
+		lb.Properties.FrontendIPConfigurations = append(lb.Properties.FrontendIPConfigurations, &armnetwork.FrontendIPConfiguration{
+			Name: ptr.To(prefix),
+			ID:   ptr.To(fipID),
+		})
+		for j := 0; j < ruleCounts[i]; j++ {
+			lb.Properties.LoadBalancingRules = append(lb.Properties.LoadBalancingRules, &armnetwork.LoadBalancingRule{
+				Name: ptr.To(fmt.Sprintf("%s-TCP-%d", prefix, 1000+j)),
+				Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+					FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To(fipID)},
+				},
+			})
+		}
+	}
+	return lb
+}
+
+func TestPlanLoadBalancerRebalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	getService := func(name string, annotations map[string]string) *v1.Service {
+		svc := getTestService(name, v1.ProtocolTCP, annotations, false, 80)
+		svc.UID = types.UID(fmt.Sprintf("%s-0000-0000-0000-000000000000", name))
+		return &svc
+	}
+
+	for _, tc := range []struct {
+		description     string
+		lb2Draining     bool
+		ruleCounts      []int
+		svc1Annotations map[string]string
+		sharedFIP       bool
+		notSynced       bool
+		svc1MovedSince  time.Duration
+		expectedMoves   []loadBalancerRebalanceMove
+		expectedFinish  []finishedLoadBalancerRebalanceMove
+	}{
+		{
+			description:   "should move the service to even out the rules",
+			ruleCounts:    []int{4, 5, 15},
+			expectedMoves: []loadBalancerRebalanceMove{{service: getService("svc2", nil), from: "lb2", to: "lb1", reason: loadBalancerRebalanceReasonBalance}},
+		},
+		{
+			description: "should not move the service if the difference is under the threshold",
+			ruleCounts:  []int{4, 5, 8},
+		},
+		{
+			description: "should not move the service if the move does not reduce the difference",
+			ruleCounts:  []int{0, 12, 0},
+		},
+		{
+			description:   "should move the services off the draining load balancer",
+			lb2Draining:   true,
+			ruleCounts:    []int{4, 2, 3},
+			expectedMoves: []loadBalancerRebalanceMove{{service: getService("svc2", nil), from: "lb2", to: "lb1", reason: loadBalancerRebalanceReasonDrain}},
+		},
+		{
+			description:     "should wait for the previous move to finish",
+			ruleCounts:      []int{4, 5, 15},
+			svc1Annotations: map[string]string{consts.ServiceAnnotationLoadBalancerRebalanceTarget: "lb2"},
+		},
+		{
+			description:     "should finish the move when the service is on the target",
+			ruleCounts:      []int{4, 5, 15},
+			svc1Annotations: map[string]string{consts.ServiceAnnotationLoadBalancerRebalanceTarget: "lb1"},
+			expectedMoves:   []loadBalancerRebalanceMove{{service: getService("svc2", nil), from: "lb2", to: "lb1", reason: loadBalancerRebalanceReasonBalance}},
+			expectedFinish:  []finishedLoadBalancerRebalanceMove{{service: getService("svc1", nil), to: "lb1"}},
+		},
+		{
+			description:     "should abandon the move which has timed out",
+			ruleCounts:      []int{4, 5, 15},
+			svc1Annotations: map[string]string{consts.ServiceAnnotationLoadBalancerRebalanceTarget: "lb2"},
+			svc1MovedSince:  time.Hour,
+			expectedMoves:   []loadBalancerRebalanceMove{{service: getService("svc2", nil), from: "lb2", to: "lb1", reason: loadBalancerRebalanceReasonBalance}},
+			expectedFinish:  []finishedLoadBalancerRebalanceMove{{service: getService("svc1", nil), to: "lb2", timedOut: true}},
+		},
+		{
+			description:   "should not move the service sharing the frontend IP configuration",
+			ruleCounts:    []int{4, 5, 15},
+			sharedFIP:     true,
+			expectedMoves: []loadBalancerRebalanceMove{{service: getService("svc3", nil), from: "lb2", to: "lb1", reason: loadBalancerRebalanceReasonBalance}},
+		},
+		{
+			description: "should not move the services before the configurations are synced",
+			ruleCounts:  []int{4, 5, 15},
+			notSynced:   true,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+			az.LoadBalancerRebalanceMaxMoves = 1
+			az.LoadBalancerRebalanceRuleCountThreshold = 10
+			az.LoadBalancerRebalanceMoveTimeoutInSeconds = 1800
+			if tc.svc1MovedSince > 0 {
+				az.loadBalancerRebalanceMoveStartTimes = map[string]time.Time{"default/svc1": time.Now().Add(-tc.svc1MovedSince)}
+			}
+			az.multipleStandardLoadBalancerConfigurationsSynced = !tc.notSynced
+			az.MultipleStandardLoadBalancerConfigurations = []config.MultipleStandardLoadBalancerConfiguration{
+				{
+					Name: "lb1",
+					MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+						ActiveServices: utilsets.NewString("default/svc1"),
+					},
+				},
+				{
+					Name: "lb2",
+					MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{
+						AllowServicePlacement: ptr.To(!tc.lb2Draining),
+					},
+					MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+						ActiveServices: utilsets.NewString("default/svc2", "default/svc3"),
+					},
+				},
+			}
+
+			svc1 := getService("svc1", tc.svc1Annotations)
+			svc2 := getService("svc2", nil)
+			svc3 := getService("svc3", nil)
+			kubeClient := fake.NewSimpleClientset(svc1, svc2, svc3)
+			informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+			az.serviceLister = informerFactory.Core().V1().Services().Lister()
+			informerFactory.Start(wait.NeverStop)
+			informerFactory.WaitForCacheSync(wait.NeverStop)
+
+			lb1 := getRebalanceTestLB(az, "lb1", []*v1.Service{svc1}, tc.ruleCounts[:1])
+			lb2 := getRebalanceTestLB(az, "lb2", []*v1.Service{svc2, svc3}, tc.ruleCounts[1:])
+			if tc.sharedFIP {
+				lb2.Properties.LoadBalancingRules = append(lb2.Properties.LoadBalancingRules, &armnetwork.LoadBalancingRule{
+					Name: ptr.To("another-service-TCP-80"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: lb2.Properties.FrontendIPConfigurations[0].ID},
+					},
+				})
+			}
+			lbClient := az.NetworkClientFactory.GetLoadBalancerClient().(*mock_loadbalancerclient.MockInterface)
+			lbClient.EXPECT().List(gomock.Any(), "rg").Return([]*armnetwork.LoadBalancer{lb1, lb2}, nil).MaxTimes(1)
+
+			moves, finished, err := az.planLoadBalancerRebalance(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, len(tc.expectedFinish), len(finished))
+			for i, expectedFinish := range tc.expectedFinish {
+				assert.Equal(t, expectedFinish.service.Name, finished[i].service.Name)
+				assert.Equal(t, expectedFinish.to, finished[i].to)
+				assert.Equal(t, expectedFinish.timedOut, finished[i].timedOut)
+			}
+			assert.Equal(t, len(tc.expectedMoves), len(moves))
+			for i, expectedMove := range tc.expectedMoves {
+				assert.Equal(t, expectedMove.service.Name, moves[i].service.Name)
+				assert.Equal(t, expectedMove.from, moves[i].from)
+				assert.Equal(t, expectedMove.to, moves[i].to)
+				assert.Equal(t, expectedMove.reason, moves[i].reason)
+			}
+		})
+	}
+}
+
+func TestApplyLoadBalancerRebalanceMove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	az.eventRecorder = recorder
+	svc := getTestService("svc1", v1.ProtocolTCP, nil, false, 80)
+	az.KubeClient = fake.NewSimpleClientset(&svc)
+
+	err := az.applyLoadBalancerRebalanceMove(context.Background(), loadBalancerRebalanceMove{
+		service: &svc,
+		from:    "lb1",
+		to:      "lb2",
+		reason:  loadBalancerRebalanceReasonDrain,
+	})
+	assert.NoError(t, err)
+
+	updated, err := az.KubeClient.CoreV1().Services("default").Get(context.Background(), "svc1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "lb2", getServiceRebalanceTarget(updated))
+	assert.Contains(t, <-recorder.Events, "LoadBalancerRebalance")
+}
+
+func TestFinishLoadBalancerRebalanceMove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	az.eventRecorder = recorder
+	az.LoadBalancerRebalanceMoveTimeoutInSeconds = 1800
+	az.loadBalancerRebalanceMoveStartTimes = map[string]time.Time{"default/svc1": time.Now()}
+	svc := getTestService("svc1", v1.ProtocolTCP, map[string]string{consts.ServiceAnnotationLoadBalancerRebalanceTarget: "lb2"}, false, 80)
+	az.KubeClient = fake.NewSimpleClientset(&svc)
+
+	err := az.finishLoadBalancerRebalanceMove(context.Background(), finishedLoadBalancerRebalanceMove{
+		service:  &svc,
+		to:       "lb2",
+		timedOut: true,
+	})
+	assert.NoError(t, err)
+
+	updated, err := az.KubeClient.CoreV1().Services("default").Get(context.Background(), "svc1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotContains(t, updated.Annotations, consts.ServiceAnnotationLoadBalancerRebalanceTarget)
+	assert.Empty(t, az.loadBalancerRebalanceMoveStartTimes)
+	assert.Contains(t, <-recorder.Events, "LoadBalancerRebalanceTimeout")
+}
+
+func TestGetAzureLoadBalancerNameWithRebalanceTarget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.MultipleStandardLoadBalancerConfigurations = []config.MultipleStandardLoadBalancerConfiguration{
+		{
+			Name: "lb1",
+			MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+				ActiveServices: utilsets.NewString("default/svc1"),
+			},
+		},
+		{Name: "lb2"},
+		{
+			Name: "lb3",
+			MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{
+				AllowServicePlacement: ptr.To(false),
+			},
+		},
+	}
+	mockVMSet := NewMockVMSet(ctrl)
+	mockVMSet.EXPECT().GetPrimaryVMSetName().Return("vmss").AnyTimes()
+	az.VMSet = mockVMSet
+
+	svc := getTestService("svc1", v1.ProtocolTCP, nil, false, 80)
+	lbName, err := az.getAzureLoadBalancerName(context.Background(), &svc, nil, testClusterName, "vmss", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "lb1", lbName)
+
+	svc.Annotations = map[string]string{consts.ServiceAnnotationLoadBalancerRebalanceTarget: "lb2"}
+	lbName, err = az.getAzureLoadBalancerName(context.Background(), &svc, nil, testClusterName, "vmss", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "lb2", lbName)
+
+	// The target which is not eligible is ignored.
+	svc.Annotations = map[string]string{consts.ServiceAnnotationLoadBalancerRebalanceTarget: "lb3"}
+	lbName, err = az.getAzureLoadBalancerName(context.Background(), &svc, nil, testClusterName, "vmss", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "lb1", lbName)
+}
@@ -42,6 +42,9 @@ import (
 // DeleteLB invokes az.NetworkClientFactory.GetLoadBalancerClient().Delete with exponential backoff retry
 func (az *Cloud) DeleteLB(ctx context.Context, service *v1.Service, lbName string) error {
 	rgName := az.getLoadBalancerResourceGroup()
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return recorder.record(LoadBalancerPlanResourceTypeLoadBalancer, rgName, lbName, nil, az.getOriginalLoadBalancerFunc(ctx, lbName))
+	}
 	rerr := az.NetworkClientFactory.GetLoadBalancerClient().Delete(ctx, rgName, lbName)
 	if rerr == nil {
 		// Invalidate the cache right after updating
@@ -67,6 +70,9 @@ func (az *Cloud) ListLB(ctx context.Context, service *v1.Service) ([]*armnetwork
 		return nil, rerr
 	}
 	klog.V(2).Infof("LoadbalancerClient.List(%v) success", rgName)
+	allLBs = overlayPlannedResources(loadBalancerPlanRecorderFromContext(ctx), LoadBalancerPlanResourceTypeLoadBalancer, rgName, allLBs, func(lb *armnetwork.LoadBalancer) string {
+		return ptr.Deref(lb.Name, "")
+	})
 	return allLBs, nil
 }
 
@@ -126,11 +132,15 @@ func (az *Cloud) ListManagedLBs(ctx context.Context, service *v1.Service, nodes
 	return managedLBs, nil
 }
 
-// CreateOrUpdateLB invokes az.NetworkClientFactory.GetLoadBalancerClient().CreateOrUpdate with exponential backoff retry
+// CreateOrUpdateLB invokes az.NetworkClientFactory.GetLoadBalancerClient().CreateOrUpdate with exponential backoff retry.
+// The service is nil if the load balancer is not updated for a service, e.g. by the garbage collector.
 func (az *Cloud) CreateOrUpdateLB(ctx context.Context, service *v1.Service, lb armnetwork.LoadBalancer) error {
 	lb = cleanupSubnetInFrontendIPConfigurations(&lb)
 
 	rgName := az.getLoadBalancerResourceGroup()
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return recorder.record(LoadBalancerPlanResourceTypeLoadBalancer, rgName, ptr.Deref(lb.Name, ""), &lb, az.getOriginalLoadBalancerFunc(ctx, ptr.Deref(lb.Name, "")))
+	}
 	_, err := az.NetworkClientFactory.GetLoadBalancerClient().CreateOrUpdate(ctx, rgName, ptr.Deref(lb.Name, ""), lb)
 	klog.V(10).Infof("LoadbalancerClient.CreateOrUpdate(%s): end", *lb.Name)
 	if err == nil {
@@ -173,7 +183,7 @@ func (az *Cloud) CreateOrUpdateLB(ctx context.Context, service *v1.Service, lb a
 			return rerr
 		}
 		// Perform a dummy update to fix the provisioning state
-		err = az.CreateOrUpdatePIP(service, pipRG, pip)
+		err = az.CreateOrUpdatePIP(ctx, service, pipRG, pip)
 		if err != nil {
 			klog.Errorf("Failed to update the public IP %s in resource group %s: %v", pipName, pipRG, err)
 			return rerr
@@ -188,6 +198,9 @@ func (az *Cloud) CreateOrUpdateLB(ctx context.Context, service *v1.Service, lb a
 
 func (az *Cloud) CreateOrUpdateLBBackendPool(ctx context.Context, lbName string, backendPool *armnetwork.BackendAddressPool) error {
 	klog.V(4).Infof("CreateOrUpdateLBBackendPool: updating backend pool %s in LB %s", ptr.Deref(backendPool.Name, ""), lbName)
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return az.planLBBackendPoolUpdate(ctx, recorder, lbName, ptr.Deref(backendPool.Name, ""), backendPool)
+	}
 	_, err := az.NetworkClientFactory.GetBackendAddressPoolClient().CreateOrUpdate(ctx, az.getLoadBalancerResourceGroup(), lbName, ptr.Deref(backendPool.Name, ""), *backendPool)
 	if err == nil {
 		// Invalidate the cache right after updating
@@ -217,6 +230,9 @@ func (az *Cloud) CreateOrUpdateLBBackendPool(ctx context.Context, lbName string,
 
 func (az *Cloud) DeleteLBBackendPool(ctx context.Context, lbName, backendPoolName string) error {
 	klog.V(4).Infof("DeleteLBBackendPool: deleting backend pool %s in LB %s", backendPoolName, lbName)
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return az.planLBBackendPoolUpdate(ctx, recorder, lbName, backendPoolName, nil)
+	}
 	err := az.NetworkClientFactory.GetBackendAddressPoolClient().Delete(ctx, az.getLoadBalancerResourceGroup(), lbName, backendPoolName)
 	if err == nil {
 		// Invalidate the cache right after updating
@@ -278,6 +294,11 @@ func (az *Cloud) MigrateToIPBasedBackendPoolAndWaitForCompletion(
 	ctx context.Context,
 	lbName string, backendPoolNames []string, nicsCountMap map[string]int,
 ) error {
+	if isLoadBalancerPlanContext(ctx) {
+		klog.V(2).Infof("MigrateToIPBasedBackendPoolAndWaitForCompletion: skip migrating backend pools of lb %s in plan mode", lbName)
+		return nil
+	}
+
 	if _, rerr := az.NetworkClientFactory.GetLoadBalancerClient().MigrateToIPBased(ctx, az.ResourceGroup, lbName, &armnetwork.LoadBalancersClientMigrateToIPBasedOptions{
 		Parameters: &armnetwork.MigrateLoadBalancerToIPBasedRequest{
 			Pools: to.SliceOfPtrs(backendPoolNames...),
@@ -349,6 +370,15 @@ func (az *Cloud) newLBCache() (azcache.Resource, error) {
 }
 
 func (az *Cloud) getAzureLoadBalancer(ctx context.Context, name string, crt azcache.AzureCacheReadType) (lb *armnetwork.LoadBalancer, exists bool, err error) {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		if planned, ok := recorder.get(LoadBalancerPlanResourceTypeLoadBalancer, az.getLoadBalancerResourceGroup(), name); ok {
+			if planned == nil {
+				return nil, false, nil
+			}
+			return planned.(*armnetwork.LoadBalancer), true, nil
+		}
+	}
+
 	cachedLB, err := az.lbCache.GetWithDeepCopy(ctx, name, crt)
 	if err != nil {
 		return lb, false, err
@@ -31,6 +31,8 @@ import (
 	"go.uber.org/mock/gomock"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/backendaddresspoolclient/mock_backendaddresspoolclient"
@@ -210,6 +212,27 @@ func TestCreateOrUpdateLB(t *testing.T) {
 	}
 }
 
+func TestCreateOrUpdateLBWithoutService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const referencedResourceNotProvisionedRawErrorString = `Code="ReferencedResourceNotProvisioned" Message="Cannot proceed with operation because resource /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip used by resource /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb is not in Succeeded state. Resource is in Failed state and the last operation that updated/is updating the resource is PutPublicIpAddressOperation."`
+
+	az := GetTestCloud(ctrl)
+	// The recorder of the controller manager fails on a nil service, unlike the fake one.
+	az.eventRecorder = record.NewBroadcaster().NewRecorder(scheme.Scheme, v1.EventSource{Component: "test"})
+	mockLBClient := az.NetworkClientFactory.GetLoadBalancerClient().(*mock_loadbalancerclient.MockInterface)
+	mockLBClient.EXPECT().CreateOrUpdate(gomock.Any(), az.ResourceGroup, "lb", gomock.Any()).Return(nil, &azcore.ResponseError{ErrorCode: referencedResourceNotProvisionedRawErrorString})
+	mockPIPClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+	mockPIPClient.EXPECT().List(gomock.Any(), az.ResourceGroup).Return([]*armnetwork.PublicIPAddress{{Name: ptr.To("pip")}}, nil)
+	mockPIPClient.EXPECT().CreateOrUpdate(gomock.Any(), az.ResourceGroup, "pip", gomock.Any()).Return(nil, &azcore.ResponseError{StatusCode: http.StatusInternalServerError})
+
+	assert.NotPanics(t, func() {
+		err := az.CreateOrUpdateLB(context.TODO(), nil, armnetwork.LoadBalancer{Name: ptr.To("lb")})
+		assert.ErrorContains(t, err, "ReferencedResourceNotProvisioned")
+	})
+}
+
 func TestCreateOrUpdateLBBackendPool(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
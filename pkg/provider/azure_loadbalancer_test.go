@@ -41,9 +41,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	cloudprovider "k8s.io/cloud-provider"
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/backendaddresspoolclient/mock_backendaddresspoolclient"
@@ -255,7 +258,7 @@ func TestGetLoadBalancer(t *testing.T) {
 			expectedGotLB: true,
 			expectedStatus: &v1.LoadBalancerStatus{
 				Ingress: []v1.LoadBalancerIngress{
-					{IP: "1.2.3.4"},
+					{IP: "1.2.3.4", IPMode: ptr.To(v1.LoadBalancerIPModeVIP)},
 				},
 			},
 		},
@@ -266,7 +269,7 @@ func TestGetLoadBalancer(t *testing.T) {
 			expectedGotLB: true,
 			expectedStatus: &v1.LoadBalancerStatus{
 				Ingress: []v1.LoadBalancerIngress{
-					{IP: "10.0.0.6"},
+					{IP: "10.0.0.6", IPMode: ptr.To(v1.LoadBalancerIPModeVIP)},
 				},
 			},
 		},
@@ -277,7 +280,7 @@ func TestGetLoadBalancer(t *testing.T) {
 			expectedGotLB: true,
 			expectedStatus: &v1.LoadBalancerStatus{
 				Ingress: []v1.LoadBalancerIngress{
-					{IP: "10.0.0.6"},
+					{IP: "10.0.0.6", IPMode: ptr.To(v1.LoadBalancerIPModeVIP)},
 				},
 			},
 		},
@@ -289,7 +292,7 @@ func TestGetLoadBalancer(t *testing.T) {
 			expectedGotLB: true,
 			expectedStatus: &v1.LoadBalancerStatus{
 				Ingress: []v1.LoadBalancerIngress{
-					{IP: "1.2.3.4"},
+					{IP: "1.2.3.4", IPMode: ptr.To(v1.LoadBalancerIPModeVIP)},
 				},
 			},
 		},
@@ -906,6 +909,86 @@ func TestEnsureLoadBalancerDeletedLock(t *testing.T) {
 	assert.Contains(t, err.Error(), "list lb failed")
 }
 
+func TestEnsureLoadBalancerWithUnmanagedLoadBalancerClass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: "example.com/managed"}}
+	mockLBBackendPool := az.LoadBalancerBackendPool.(*MockBackendPool)
+	mockLBBackendPool.EXPECT().ReconcileBackendPools(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string, _ *v1.Service, lb *armnetwork.LoadBalancer) (bool, bool, *armnetwork.LoadBalancer, error) {
+		return false, false, lb, nil
+	}).AnyTimes()
+	mockLBBackendPool.EXPECT().EnsureHostsInPool(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockLBBackendPool.EXPECT().GetBackendPrivateIPs(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+	clusterResources, expectedInterfaces, expectedVirtualMachines := getClusterResources(az, 1, 1)
+	setMockEnv(az, expectedInterfaces, expectedVirtualMachines, 1)
+
+	mockPLSRepo := privatelinkservice.NewMockRepository(ctrl)
+	mockPLSRepo.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&armnetwork.PrivateLinkService{ID: to.Ptr(consts.PrivateLinkServiceNotExistID)}, nil).AnyTimes()
+	az.plsRepo = mockPLSRepo
+
+	expectedLBs := make([]*armnetwork.LoadBalancer, 0)
+	setMockLBs(az, &expectedLBs, "service", 1, 1, false)
+
+	// the service is created with a managed load balancer class first.
+	service := getTestServiceWithLoadBalancerClass("service1", "example.com/managed", 80)
+	lbStatus, err := az.EnsureLoadBalancer(context.TODO(), testClusterName, &service, clusterResources.nodes)
+	assert.NoError(t, err)
+	assert.NotNil(t, lbStatus)
+
+	// then the load balancer class is no longer managed, the owned resources should be released.
+	az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: "example.com/other"}}
+	expectedLBs = make([]*armnetwork.LoadBalancer, 0)
+	setMockLBs(az, &expectedLBs, "service", 1, 1, false)
+	lbStatus, err = az.EnsureLoadBalancer(context.TODO(), testClusterName, &service, clusterResources.nodes)
+	assert.Equal(t, cloudprovider.ImplementedElsewhere, err)
+	assert.Nil(t, lbStatus)
+}
+
+func TestIsServiceLoadBalancerClassManaged(t *testing.T) {
+	for _, tc := range []struct {
+		description         string
+		lbClasses           []config.LoadBalancerClassConfiguration
+		reconcileWithoutLBC *bool
+		lbClass             *string
+		expected            bool
+	}{
+		{
+			description: "should reconcile services without load balancer class by default",
+			expected:    true,
+		},
+		{
+			description:         "should not reconcile services without load balancer class if disabled",
+			reconcileWithoutLBC: ptr.To(false),
+			expected:            false,
+		},
+		{
+			description: "should not reconcile services with an unknown load balancer class",
+			lbClass:     ptr.To("example.com/unknown"),
+			expected:    false,
+		},
+		{
+			description: "should reconcile services with a configured load balancer class",
+			lbClasses:   []config.LoadBalancerClassConfiguration{{Name: "example.com/managed"}},
+			lbClass:     ptr.To("example.com/managed"),
+			expected:    true,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerClasses = tc.lbClasses
+			az.ReconcileServicesWithoutLoadBalancerClass = tc.reconcileWithoutLBC
+			svc := getTestService("test", v1.ProtocolTCP, nil, false, 80)
+			svc.Spec.LoadBalancerClass = tc.lbClass
+			assert.Equal(t, tc.expected, az.isServiceLoadBalancerClassManaged(&svc))
+		})
+	}
+}
+
 func TestServiceOwnsPublicIP(t *testing.T) {
 	tests := []struct {
 		desc                    string
@@ -1371,7 +1454,7 @@ func TestShouldReleaseExistingOwnedPublicIP(t *testing.T) {
 	for _, c := range tests {
 		t.Run(c.desc, func(t *testing.T) {
 			existingPip := c.existingPip
-			actualShouldRelease := shouldReleaseExistingOwnedPublicIP(&existingPip, c.serviceReferences, c.lbShouldExist, c.lbIsInternal, c.isUserAssignedPIP, c.desiredPipName, c.ipTagRequest)
+			actualShouldRelease := shouldReleaseExistingOwnedPublicIP(&existingPip, c.serviceReferences, c.lbShouldExist, c.lbIsInternal, c.isUserAssignedPIP, c.desiredPipName, c.ipTagRequest, nil)
 			assert.Equal(t, c.expectedShouldRelease, actualShouldRelease)
 		})
 	}
@@ -1958,7 +2041,7 @@ func TestGetServiceLoadBalancerCommon(t *testing.T) {
 					},
 				},
 			},
-			expectedStatus: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4", Hostname: ""}}},
+			expectedStatus: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4", Hostname: "", IPMode: ptr.To(v1.LoadBalancerIPModeVIP)}}},
 			expectedExists: true,
 			expectedError:  false,
 		},
@@ -3099,6 +3182,39 @@ func TestGetExpectedLBRulesSharedProbe(t *testing.T) {
 	}
 }
 
+func TestGetExpectedLBRulesPodIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypePODIP
+	az.ClusterServiceLoadBalancerHealthProbeMode = consts.ClusterServiceLoadBalancerHealthProbeModeShared
+	svc := getTestService("test1", v1.ProtocolTCP, nil, false, 80, 81)
+	svc.Spec.AllocateLoadBalancerNodePorts = ptr.To(false)
+	svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyLocal
+	svc.Spec.HealthCheckNodePort = 32000
+	for i := range svc.Spec.Ports {
+		svc.Spec.Ports[i].NodePort = 0
+	}
+	svc.Spec.Ports[0].TargetPort = intstr.FromInt32(8080)
+
+	probes, rules, err := az.getExpectedLBRules(&svc, "frontendIPConfigID", "backendPoolID", "lbname", consts.IPVersionIPv4)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(rules))
+	assert.Equal(t, int32(8080), *rules[0].Properties.BackendPort)
+	assert.Equal(t, int32(81), *rules[1].Properties.BackendPort)
+	assert.Equal(t, 2, len(probes))
+	assert.Equal(t, int32(8080), *probes[0].Properties.Port)
+	assert.Equal(t, int32(81), *probes[1].Properties.Port)
+	for _, rule := range rules {
+		assert.False(t, *rule.Properties.EnableFloatingIP)
+	}
+
+	svc.Spec.Ports[0].TargetPort = intstr.FromString("http")
+	_, _, err = az.getExpectedLBRules(&svc, "frontendIPConfigID", "backendPoolID", "lbname", consts.IPVersionIPv4)
+	assert.Error(t, err)
+}
+
 // getDefaultTestRules returns dualstack rules.
 func getDefaultTestRules(enableTCPReset bool) map[bool][]*armnetwork.LoadBalancingRule {
 	return map[bool][]*armnetwork.LoadBalancingRule{
@@ -4103,7 +4219,7 @@ func TestGetServiceLoadBalancerStatus(t *testing.T) {
 			desc:           "getServiceLoadBalancerStatus shall return private ip if service is internal",
 			service:        &internalService,
 			lb:             lb2,
-			expectedStatus: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "private"}}},
+			expectedStatus: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "private", IPMode: ptr.To(v1.LoadBalancerIPModeVIP)}}},
 		},
 		{
 			desc: "getServiceLoadBalancerStatus shall return nil if lb.Properties.FrontendIPConfigurations.name != " +
@@ -4135,7 +4251,7 @@ func TestGetServiceLoadBalancerStatus(t *testing.T) {
 				"lb status if everything is good",
 			service:        &service,
 			lb:             lb2,
-			expectedStatus: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}},
+			expectedStatus: &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4", IPMode: ptr.To(v1.LoadBalancerIPModeVIP)}}},
 		},
 	}
 
@@ -5892,6 +6008,28 @@ func TestCheckLoadBalancerResourcesConflicted(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			desc: "checkLoadBalancerResourcesConflicts should report the conflict error if " +
+				"there is a backend pool based inbound NAT rule with a conflicted frontend port range",
+			fipID: "fip",
+			existingLB: &armnetwork.LoadBalancer{
+				Name: ptr.To("lb"),
+				Properties: &armnetwork.LoadBalancerPropertiesFormat{
+					InboundNatRules: []*armnetwork.InboundNatRule{
+						{
+							Name: ptr.To("aservice2-nat-TCP-22"),
+							Properties: &armnetwork.InboundNatRulePropertiesFormat{
+								FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To("fip")},
+								FrontendPortRangeStart:  ptr.To(int32(70)),
+								FrontendPortRangeEnd:    ptr.To(int32(90)),
+								Protocol:                to.Ptr(armnetwork.TransportProtocolTCP),
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
 		{
 			desc: "checkLoadBalancerResourcesConflicts should report the conflict error if " +
 				"there is a conflicted inbound NAT pool",
@@ -6202,9 +6340,25 @@ func TestRemoveFrontendIPConfigurationFromLoadBalancerUpdate(t *testing.T) {
 		}
 		service := getTestService("svc1", v1.ProtocolTCP, nil, false, 80)
 		lb := getTestLoadBalancer(ptr.To("lb"), ptr.To("rg"), ptr.To("testCluster"), ptr.To("testCluster"), service, "standard")
-		lb.Properties.FrontendIPConfigurations = append(lb.Properties.FrontendIPConfigurations, &armnetwork.FrontendIPConfiguration{Name: ptr.To("fip1")})
+		lb.Properties.FrontendIPConfigurations = append(lb.Properties.FrontendIPConfigurations, &armnetwork.FrontendIPConfiguration{Name: ptr.To("fip1"), ID: ptr.To("fip1-id")})
+		// The inbound NAT rules are matched by the frontend IP configuration they refer to, not by their names.
+		lb.Properties.InboundNatRules = []*armnetwork.InboundNatRule{
+			{
+				Name:       ptr.To("asvc1-nat-TCP-22"),
+				Properties: &armnetwork.InboundNatRulePropertiesFormat{FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To("testCluster-fip")}},
+			},
+			{
+				Name:       ptr.To("testCluster-user-nat-rule"),
+				Properties: &armnetwork.InboundNatRulePropertiesFormat{FrontendIPConfiguration: &armnetwork.SubResource{ID: ptr.To("fip1-id")}},
+			},
+		}
 		mockLBClient := cloud.NetworkClientFactory.GetLoadBalancerClient().(*mock_loadbalancerclient.MockInterface)
-		mockLBClient.EXPECT().CreateOrUpdate(gomock.Any(), "rg", "lb", gomock.Any()).Return(nil, nil)
+		mockLBClient.EXPECT().CreateOrUpdate(gomock.Any(), "rg", "lb", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, lb armnetwork.LoadBalancer) (*armnetwork.LoadBalancer, error) {
+				assert.Equal(t, 1, len(lb.Properties.InboundNatRules))
+				assert.Equal(t, "testCluster-user-nat-rule", ptr.Deref(lb.Properties.InboundNatRules[0].Name, ""))
+				return nil, nil
+			})
 		mockPLSRepo := cloud.plsRepo.(*privatelinkservice.MockRepository)
 		mockPLSRepo.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&armnetwork.PrivateLinkService{ID: to.Ptr(consts.PrivateLinkServiceNotExistID)}, nil)
 		_, err := cloud.removeFrontendIPConfigurationFromLoadBalancer(context.TODO(), lb, []*armnetwork.LoadBalancer{}, []*armnetwork.FrontendIPConfiguration{fip}, "testCluster", &service)
@@ -6713,6 +6867,116 @@ func TestReconcileFrontendIPConfigs(t *testing.T) {
 	}
 }
 
+func TestReconcileFrontendIPConfigsGatewayLoadBalancer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gatewayLBFIPID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/gwlb/frontendIPConfigurations/fip"
+	defaultGatewayLBFIPID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/gwlb/frontendIPConfigurations/default"
+	testcases := []struct {
+		desc                  string
+		annotations           map[string]string
+		defaultGatewayLBFIPID string
+		existingGatewayLBID   string
+		expectedDirty         bool
+		expectedGatewayLBID   string
+		expectedErr           bool
+	}{
+		{
+			desc:                "should chain the frontend to the gateway load balancer in the annotation",
+			annotations:         map[string]string{consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID: gatewayLBFIPID},
+			expectedDirty:       true,
+			expectedGatewayLBID: gatewayLBFIPID,
+		},
+		{
+			desc:                "should not update the frontend already chained to the gateway load balancer",
+			annotations:         map[string]string{consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID: gatewayLBFIPID},
+			existingGatewayLBID: gatewayLBFIPID,
+			expectedGatewayLBID: gatewayLBFIPID,
+		},
+		{
+			desc:                "should clear the gateway load balancer when the annotation is removed",
+			existingGatewayLBID: gatewayLBFIPID,
+			expectedDirty:       true,
+		},
+		{
+			desc:                  "should chain the frontend to the default gateway load balancer",
+			defaultGatewayLBFIPID: defaultGatewayLBFIPID,
+			expectedDirty:         true,
+			expectedGatewayLBID:   defaultGatewayLBFIPID,
+		},
+		{
+			desc:                  "should not chain the frontend if the annotation is empty",
+			annotations:           map[string]string{consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID: ""},
+			defaultGatewayLBFIPID: defaultGatewayLBFIPID,
+			existingGatewayLBID:   defaultGatewayLBFIPID,
+			expectedDirty:         true,
+		},
+		{
+			desc:        "should report an error if the ID is not a frontend IP configuration ID",
+			annotations: map[string]string{consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID: "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/gwlb"},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the service is internal",
+			annotations: map[string]string{
+				consts.ServiceAnnotationLoadBalancerInternal:                         "true",
+				consts.ServiceAnnotationLoadBalancerGatewayFrontendIPConfigurationID: gatewayLBFIPID,
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cloud := GetTestCloud(ctrl)
+			cloud.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+			cloud.GatewayLoadBalancerFrontendIPConfigurationID = tc.defaultGatewayLBFIPID
+
+			service := getTestService("test", v1.ProtocolTCP, tc.annotations, false, 80)
+			fip := &armnetwork.FrontendIPConfiguration{
+				Name: ptr.To("atest"),
+				ID:   ptr.To("/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb/frontendIPConfigurations/atest"),
+				Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+					PublicIPAddress: &armnetwork.PublicIPAddress{ID: ptr.To("testCluster-atest-id")},
+				},
+			}
+			if tc.existingGatewayLBID != "" {
+				fip.Properties.GatewayLoadBalancer = &armnetwork.SubResource{ID: ptr.To(tc.existingGatewayLBID)}
+			}
+			lb := getTestLoadBalancer(ptr.To("lb"), ptr.To("rg"), ptr.To("testCluster"), ptr.To("testCluster"), service, "standard")
+			lb.Properties.FrontendIPConfigurations = []*armnetwork.FrontendIPConfiguration{fip}
+
+			pip := &armnetwork.PublicIPAddress{
+				Name: ptr.To("testCluster-atest"),
+				ID:   ptr.To("testCluster-atest-id"),
+				Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+					PublicIPAddressVersion:   to.Ptr(armnetwork.IPVersionIPv4),
+					PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
+					IPAddress:                ptr.To("1.2.3.4"),
+				},
+			}
+			mockPIPClient := cloud.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+			mockPIPClient.EXPECT().List(gomock.Any(), "rg").Return([]*armnetwork.PublicIPAddress{pip}, nil).MaxTimes(2)
+			mockPIPClient.EXPECT().Get(gomock.Any(), "rg", "testCluster-atest", gomock.Any()).Return(pip, nil).MaxTimes(1)
+
+			lbFrontendIPConfigNames := map[bool]string{false: "atest"}
+			_, _, dirty, err := cloud.reconcileFrontendIPConfigs(context.TODO(), "testCluster", &service, lb, nil, true, lbFrontendIPConfigNames)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedDirty, dirty)
+			if tc.expectedGatewayLBID == "" {
+				assert.Nil(t, fip.Properties.GatewayLoadBalancer)
+			} else {
+				assert.Equal(t, tc.expectedGatewayLBID, ptr.Deref(fip.Properties.GatewayLoadBalancer.ID, ""))
+			}
+		})
+	}
+}
+
 func TestReconcileIPSettings(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -6795,7 +7059,7 @@ func TestReconcileIPSettings(t *testing.T) {
 			pip := tc.pip
 			pip.Name = ptr.To("pip")
 			service := tc.service
-			changed := az.reconcileIPSettings(pip, &service, tc.isIPv6)
+			changed := az.reconcileIPSettings(context.TODO(), pip, &service, &publicIPSettings{}, false, tc.isIPv6)
 			assert.Equal(t, tc.expectedChanged, changed)
 			assert.NotNil(t, pip.Properties)
 			assert.Equal(t, *pip.Properties.PublicIPAddressVersion, tc.expectedIPVersion)
@@ -7044,6 +7308,7 @@ func TestGetEligibleLoadBalancers(t *testing.T) {
 		description string
 		lbConfigs   []config.MultipleStandardLoadBalancerConfiguration
 		svc         v1.Service
+		lbClasses   []config.LoadBalancerClassConfiguration
 		namespace   *v1.Namespace
 		labels      map[string]string
 		expectedLBs []string
@@ -7355,11 +7620,54 @@ func TestGetEligibleLoadBalancers(t *testing.T) {
 			},
 			expectedLBs: []string{"a", "c"},
 		},
+		{
+			description: "should select the load balancer mapped from the load balancer class",
+			svc:         getTestServiceWithLoadBalancerClass("test", "example.com/lb-class"),
+			lbClasses: []config.LoadBalancerClassConfiguration{
+				{
+					Name:                          "example.com/lb-class",
+					LoadBalancerConfigurationName: "b",
+				},
+			},
+			lbConfigs: []config.MultipleStandardLoadBalancerConfiguration{
+				{
+					Name: "a",
+				},
+				{
+					Name: "b",
+				},
+			},
+			expectedLBs: []string{"b"},
+		},
+		{
+			description: "should respect the annotation over the load balancer class",
+			svc: func() v1.Service {
+				svc := getTestServiceWithLoadBalancerClass("test", "example.com/lb-class")
+				svc.Annotations = map[string]string{consts.ServiceAnnotationLoadBalancerConfigurations: "a"}
+				return svc
+			}(),
+			lbClasses: []config.LoadBalancerClassConfiguration{
+				{
+					Name:                          "example.com/lb-class",
+					LoadBalancerConfigurationName: "b",
+				},
+			},
+			lbConfigs: []config.MultipleStandardLoadBalancerConfiguration{
+				{
+					Name: "a",
+				},
+				{
+					Name: "b",
+				},
+			},
+			expectedLBs: []string{"a"},
+		},
 	} {
 		tc := tc
 		t.Run(tc.description, func(t *testing.T) {
 			az := GetTestCloud(ctrl)
 			az.MultipleStandardLoadBalancerConfigurations = tc.lbConfigs
+			az.LoadBalancerClasses = tc.lbClasses
 			if tc.namespace != nil {
 				az.KubeClient = fake.NewSimpleClientset(tc.namespace)
 			}
@@ -8931,3 +9239,297 @@ func fakeEnsureHostsInPool() func(context.Context, *v1.Service, []*v1.Node, stri
 		return nil
 	}
 }
+
+func TestGetServicePublicIPSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	planID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/ddosProtectionPlans/plan"
+	for _, tc := range []struct {
+		desc             string
+		sku              string
+		config           publicIPSettings
+		annotations      map[string]string
+		expectedSettings *publicIPSettings
+		expectedErr      bool
+	}{
+		{
+			desc:             "should return empty settings by default",
+			expectedSettings: &publicIPSettings{},
+		},
+		{
+			desc:             "should use the defaults in the cloud config",
+			sku:              consts.LoadBalancerSKUStandard,
+			config:           publicIPSettings{ddosProtectionMode: "enabled", ddosProtectionPlanID: planID, routingPreference: "internet", tier: "regional"},
+			expectedSettings: &publicIPSettings{ddosProtectionMode: "Enabled", ddosProtectionPlanID: planID, routingPreference: "Internet", tier: "Regional"},
+		},
+		{
+			desc:   "should override the defaults with the annotations",
+			sku:    consts.LoadBalancerSKUStandard,
+			config: publicIPSettings{ddosProtectionMode: "Enabled", ddosProtectionPlanID: planID, routingPreference: "Internet"},
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPDDoSProtectionMode: "Disabled",
+				consts.ServiceAnnotationPIPRoutingPreference:  "MicrosoftNetwork",
+				consts.ServiceAnnotationPIPTier:               "regional",
+			},
+			expectedSettings: &publicIPSettings{
+				ddosProtectionMode:              "Disabled",
+				routingPreference:               "MicrosoftNetwork",
+				tier:                            "Regional",
+				routingPreferenceFromAnnotation: true,
+				tierFromAnnotation:              true,
+			},
+		},
+		{
+			desc: "should report an error if the DDoS protection mode is not supported",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPDDoSProtectionMode: "Basic",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the DDoS protection plan is set without the enabled mode",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPDDoSProtectionMode:   "VirtualNetworkInherited",
+				consts.ServiceAnnotationPIPDDoSProtectionPlanID: planID,
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the DDoS protection plan ID is invalid",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPDDoSProtectionMode:   "Enabled",
+				consts.ServiceAnnotationPIPDDoSProtectionPlanID: "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the global tier is used since it cannot be attached to a regional load balancer",
+			sku:  consts.LoadBalancerSKUStandard,
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPTier: "Global",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "should report an error if the internet routing preference is used with basic load balancer",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPRoutingPreference: "Internet",
+			},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerSKU = tc.sku
+			az.PublicIPDDoSProtectionMode = tc.config.ddosProtectionMode
+			az.PublicIPDDoSProtectionPlanID = tc.config.ddosProtectionPlanID
+			az.PublicIPRoutingPreference = tc.config.routingPreference
+			az.PublicIPTier = tc.config.tier
+			service := getTestService("test", v1.ProtocolTCP, tc.annotations, false, 80)
+
+			settings, err := az.getServicePublicIPSettings(&service)
+			assert.Equal(t, tc.expectedErr, err != nil, "unexpected error: %v", err)
+			assert.Equal(t, tc.expectedSettings, settings)
+		})
+	}
+}
+
+func TestApplyRoutingPreferenceToIPTags(t *testing.T) {
+	routingPreferenceTag := &armnetwork.IPTag{IPTagType: ptr.To(consts.IPTagTypeRoutingPreference), Tag: ptr.To(consts.PIPRoutingPreferenceInternet)}
+	otherTag := &armnetwork.IPTag{IPTagType: ptr.To("FirstPartyUsage"), Tag: ptr.To("/Sql")}
+
+	assert.Equal(t, []*armnetwork.IPTag{otherTag}, applyRoutingPreferenceToIPTags([]*armnetwork.IPTag{otherTag}, ""))
+	assert.Equal(t, []*armnetwork.IPTag{otherTag, routingPreferenceTag}, applyRoutingPreferenceToIPTags([]*armnetwork.IPTag{otherTag}, consts.PIPRoutingPreferenceInternet))
+	assert.Equal(t, []*armnetwork.IPTag{otherTag}, applyRoutingPreferenceToIPTags([]*armnetwork.IPTag{otherTag, routingPreferenceTag}, consts.PIPRoutingPreferenceMicrosoftNetwork))
+	assert.Equal(t, []*armnetwork.IPTag{}, applyRoutingPreferenceToIPTags(nil, consts.PIPRoutingPreferenceMicrosoftNetwork))
+}
+
+func TestReconcileIPSettingsWithPublicIPSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	planID := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/ddosProtectionPlans/plan"
+	settings := &publicIPSettings{ddosProtectionMode: "Enabled", ddosProtectionPlanID: planID, tier: "Regional", tierFromAnnotation: true}
+	expectedDDoSSettings := &armnetwork.DdosSettings{
+		ProtectionMode:     to.Ptr(armnetwork.DdosSettingsProtectionModeEnabled),
+		DdosProtectionPlan: &armnetwork.SubResource{ID: ptr.To(planID)},
+	}
+	getTestPIP := func() *armnetwork.PublicIPAddress {
+		return &armnetwork.PublicIPAddress{
+			Name: ptr.To("pip"),
+			SKU: &armnetwork.PublicIPAddressSKU{
+				Name: to.Ptr(armnetwork.PublicIPAddressSKUNameStandard),
+				Tier: to.Ptr(armnetwork.PublicIPAddressSKUTierRegional),
+			},
+			Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+				PublicIPAddressVersion:   to.Ptr(armnetwork.IPVersionIPv4),
+				PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
+				DdosSettings: &armnetwork.DdosSettings{
+					ProtectionMode: to.Ptr(armnetwork.DdosSettingsProtectionModeVirtualNetworkInherited),
+				},
+			},
+		}
+	}
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	recorder := record.NewFakeRecorder(10)
+	az.eventRecorder = recorder
+	service := getTestService("test", v1.ProtocolTCP, nil, false, 80)
+
+	// The DDoS settings of the managed public IP are updated.
+	pip := getTestPIP()
+	assert.True(t, az.reconcileIPSettings(context.TODO(), pip, &service, settings, false, false))
+	assert.Equal(t, expectedDDoSSettings, pip.Properties.DdosSettings)
+	assert.False(t, az.reconcileIPSettings(context.TODO(), pip, &service, settings, false, false))
+	assert.Empty(t, recorder.Events)
+
+	// The DDoS settings of the user-assigned public IP are not changed.
+	pip = getTestPIP()
+	assert.False(t, az.reconcileIPSettings(context.TODO(), pip, &service, settings, true, false))
+	assert.Equal(t, to.Ptr(armnetwork.DdosSettingsProtectionModeVirtualNetworkInherited), pip.Properties.DdosSettings.ProtectionMode)
+	assert.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	// The immutable settings cannot be changed.
+	pip = getTestPIP()
+	pip.SKU.Tier = to.Ptr(armnetwork.PublicIPAddressSKUTierGlobal)
+	assert.True(t, az.reconcileIPSettings(context.TODO(), pip, &service, settings, false, false))
+	assert.Equal(t, to.Ptr(armnetwork.PublicIPAddressSKUTierGlobal), pip.SKU.Tier)
+	assert.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	// No event is emitted when only the plan is computed.
+	planCtx, _ := newLoadBalancerPlanContext(context.TODO())
+	pip = getTestPIP()
+	pip.SKU.Tier = to.Ptr(armnetwork.PublicIPAddressSKUTierGlobal)
+	az.reconcileIPSettings(planCtx, pip, &service, settings, false, false)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestGetPublicIPImmutableSettingsDrift(t *testing.T) {
+	pip := &armnetwork.PublicIPAddress{
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			IPTags: []*armnetwork.IPTag{
+				{IPTagType: ptr.To(consts.IPTagTypeRoutingPreference), Tag: ptr.To(consts.PIPRoutingPreferenceInternet)},
+			},
+		},
+	}
+
+	fromAnnotation := func(settings publicIPSettings) *publicIPSettings {
+		settings.routingPreferenceFromAnnotation = true
+		settings.tierFromAnnotation = true
+		return &settings
+	}
+
+	assert.Empty(t, getPublicIPImmutableSettingsDrift(pip, nil))
+	assert.Empty(t, getPublicIPImmutableSettingsDrift(pip, fromAnnotation(publicIPSettings{})))
+	assert.Empty(t, getPublicIPImmutableSettingsDrift(pip, fromAnnotation(publicIPSettings{routingPreference: consts.PIPRoutingPreferenceInternet, tier: "Regional"})))
+	assert.Len(t, getPublicIPImmutableSettingsDrift(pip, fromAnnotation(publicIPSettings{routingPreference: consts.PIPRoutingPreferenceMicrosoftNetwork})), 1)
+	globalPIP := &armnetwork.PublicIPAddress{
+		SKU:        &armnetwork.PublicIPAddressSKU{Tier: to.Ptr(armnetwork.PublicIPAddressSKUTierGlobal)},
+		Properties: pip.Properties,
+	}
+	assert.Len(t, getPublicIPImmutableSettingsDrift(globalPIP, fromAnnotation(publicIPSettings{routingPreference: consts.PIPRoutingPreferenceMicrosoftNetwork, tier: "Regional"})), 2)
+	// The defaults in the cloud config do not apply to the existing public IPs.
+	assert.Empty(t, getPublicIPImmutableSettingsDrift(globalPIP, &publicIPSettings{routingPreference: consts.PIPRoutingPreferenceMicrosoftNetwork, tier: "Regional"}))
+
+	// The managed public IP with different immutable settings is kept.
+	globalPIP.Name = ptr.To("pip")
+	assert.False(t, shouldReleaseExistingOwnedPublicIP(globalPIP,
+		nil, true, false, false, "pip", serviceIPTagRequest{}, fromAnnotation(publicIPSettings{tier: "Regional"})))
+	assert.False(t, shouldReleaseExistingOwnedPublicIP(globalPIP,
+		nil, true, false, false, "pip", serviceIPTagRequest{}, fromAnnotation(publicIPSettings{routingPreference: consts.PIPRoutingPreferenceMicrosoftNetwork})))
+
+	// The routing preference IP tag is not compared with the IP tags from the annotation,
+	// but the changes of the other IP tags still release the managed public IP.
+	otherTag := &armnetwork.IPTag{IPTagType: ptr.To("FirstPartyUsage"), Tag: ptr.To("/Sql")}
+	pip.Name = ptr.To("pip")
+	pip.Properties.IPTags = append(pip.Properties.IPTags, otherTag)
+	assert.False(t, shouldReleaseExistingOwnedPublicIP(pip,
+		nil, true, false, false, "pip", serviceIPTagRequest{IPTagsRequestedByAnnotation: true, IPTags: []*armnetwork.IPTag{otherTag}},
+		fromAnnotation(publicIPSettings{routingPreference: consts.PIPRoutingPreferenceMicrosoftNetwork})))
+	assert.True(t, shouldReleaseExistingOwnedPublicIP(pip,
+		nil, true, false, false, "pip", serviceIPTagRequest{IPTagsRequestedByAnnotation: true, IPTags: []*armnetwork.IPTag{}},
+		fromAnnotation(publicIPSettings{routingPreference: consts.PIPRoutingPreferenceMicrosoftNetwork})))
+}
+
+func TestReconcilePublicIPWithInvalidPublicIPSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	service := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationPIPDDoSProtectionMode: "Basic",
+		consts.ServiceAnnotationPIPTier:               "Global",
+	}, false, 80)
+
+	// The invalid settings do not block the deletion of the service.
+	pip, err := az.reconcilePublicIP(context.TODO(), []*armnetwork.PublicIPAddress{}, testClusterName, &service, "", false, false)
+	assert.NoError(t, err)
+	assert.Nil(t, pip)
+
+	_, err = az.reconcilePublicIP(context.TODO(), []*armnetwork.PublicIPAddress{}, testClusterName, &service, "", true, false)
+	assert.Error(t, err)
+}
+
+func TestEnsurePublicIPExistsWithPublicIPSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.regionZonesMap = map[string][]string{az.Location: {"1", "2", "3"}}
+	az.PublicIPDDoSProtectionMode = string(armnetwork.DdosSettingsProtectionModeDisabled)
+
+	for _, tc := range []struct {
+		desc         string
+		annotations  map[string]string
+		expectedTier *armnetwork.PublicIPAddressSKUTier
+		expectedTags []*armnetwork.IPTag
+		expectZones  bool
+	}{
+		{
+			desc: "should create the pip with the internet routing preference",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPRoutingPreference: consts.PIPRoutingPreferenceInternet,
+			},
+			expectedTags: []*armnetwork.IPTag{
+				{IPTagType: ptr.To(consts.IPTagTypeRoutingPreference), Tag: ptr.To(consts.PIPRoutingPreferenceInternet)},
+			},
+			expectZones: true,
+		},
+		{
+			desc: "should create the regional pip with zones",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPIPTier: "Regional",
+			},
+			expectedTier: to.Ptr(armnetwork.PublicIPAddressSKUTierRegional),
+			expectZones:  true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			service := getTestService("test", v1.ProtocolTCP, tc.annotations, false, 80)
+			mockPIPsClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+			var createdPIP *armnetwork.PublicIPAddress
+			first := mockPIPsClient.EXPECT().List(gomock.Any(), "rg").Return([]*armnetwork.PublicIPAddress{}, nil).Times(2)
+			mockPIPsClient.EXPECT().CreateOrUpdate(gomock.Any(), "rg", "pip", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ string, parameters armnetwork.PublicIPAddress) (*armnetwork.PublicIPAddress, error) {
+					createdPIP = &parameters
+					return nil, nil
+				})
+			mockPIPsClient.EXPECT().Get(gomock.Any(), "rg", "pip", gomock.Any()).DoAndReturn(func(_ context.Context, _ string, _ string, _ *string) (*armnetwork.PublicIPAddress, error) {
+				return createdPIP, nil
+			}).After(first)
+
+			_, err := az.ensurePublicIPExists(context.TODO(), &service, "pip", "", "", false, false, false)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTier, createdPIP.SKU.Tier)
+			assert.Equal(t, tc.expectedTags, createdPIP.Properties.IPTags)
+			assert.Equal(t, tc.expectZones, len(createdPIP.Zones) > 0)
+			assert.Equal(t, to.Ptr(armnetwork.DdosSettingsProtectionModeDisabled), createdPIP.Properties.DdosSettings.ProtectionMode)
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
 	v1 "k8s.io/api/core/v1"
 	discovery_v1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
@@ -156,17 +157,36 @@ func (updater *loadBalancerBackendPoolUpdater) removeOperation(serviceName strin
 }
 
 // process processes all operations in the loadBalancerBackendPoolUpdater.
+// In pod IP mode, the security rules of the services whose backend pools are changed
+// are updated afterwards, because their destination addresses are the pod IPs.
+func (updater *loadBalancerBackendPoolUpdater) process(ctx context.Context) {
+	updatedServiceNames := updater.processBackendPools(ctx)
+	if !updater.az.IsLBBackendPoolTypePodIP() {
+		return
+	}
+	for _, serviceName := range updatedServiceNames {
+		if err := updater.az.reconcileServiceBackendSecurityRules(ctx, serviceName); err != nil {
+			klog.Errorf("loadBalancerBackendPoolUpdater.process: failed to update the security rules of service %s: %v", serviceName, err)
+			if svc, found, _ := updater.az.getLatestService(serviceName, false); found {
+				updater.az.Event(svc, v1.EventTypeWarning, "SyncSecurityGroupFailed", err.Error())
+			}
+		}
+	}
+}
+
+// processBackendPools processes all operations in the loadBalancerBackendPoolUpdater
+// and returns the names of the services whose backend pools are changed.
 // It merges operations that have the same loadBalancerName and backendPoolName,
 // and then processes them in batches. If an operation fails, it will be retried
 // if it is retriable, otherwise all operations in the batch targeting to
 // this backend pool will fail.
-func (updater *loadBalancerBackendPoolUpdater) process(ctx context.Context) {
+func (updater *loadBalancerBackendPoolUpdater) processBackendPools(ctx context.Context) []string {
 	updater.lock.Lock()
 	defer updater.lock.Unlock()
 
 	if len(updater.operations) == 0 {
 		klog.V(4).Infof("loadBalancerBackendPoolUpdater.process: no operations to process")
-		return
+		return nil
 	}
 
 	// Group operations by loadBalancerName:backendPoolName
@@ -193,6 +213,7 @@ func (updater *loadBalancerBackendPoolUpdater) process(ctx context.Context) {
 	// Clear all jobs.
 	updater.operations = make([]batchOperation, 0)
 
+	updatedServiceNames := utilsets.NewString()
 	for key, ops := range groups {
 		parts := strings.Split(key, ":")
 		lbName, poolName := parts[0], parts[1]
@@ -226,9 +247,92 @@ func (updater *loadBalancerBackendPoolUpdater) process(ctx context.Context) {
 				updater.processError(err, operationName, ops...)
 				continue
 			}
+			_ = updater.az.lbCache.Delete(lbName)
+			for _, op := range ops {
+				lbOp := op.(*loadBalancerBackendPoolUpdateOperation)
+				updatedServiceNames.Insert(lbOp.serviceName)
+				if lbOp.kind == consts.LoadBalancerBackendPoolUpdateOperationRemove && updater.az.IsLBBackendPoolTypePodIP() {
+					updater.az.recordRemovedBackendPodIPs(lbOp.serviceName, lbOp.nodeIPs)
+				}
+			}
 		}
 		updater.notify(newBatchOperationResult(operationName, true, nil), ops...)
 	}
+	return updatedServiceNames.UnsortedList()
+}
+
+// reconcileServiceBackendSecurityRules updates the destination addresses of the security rules of the service
+// to the pod IPs in its backend pools. It is serialized with the service reconciliation, which updates the security group as well.
+func (az *Cloud) reconcileServiceBackendSecurityRules(ctx context.Context, serviceName string) error {
+	az.serviceReconcileLock.Lock()
+	defer az.serviceReconcileLock.Unlock()
+
+	svc, found, err := az.getLatestService(serviceName, false)
+	if err != nil {
+		return err
+	}
+	if !found || svc.DeletionTimestamp != nil || svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		klog.V(4).Infof("reconcileServiceBackendSecurityRules: service %s is not a load balancer service, skip updating the security rules", serviceName)
+		return nil
+	}
+	si, found := az.getLocalServiceInfo(strings.ToLower(serviceName))
+	if !found {
+		return nil
+	}
+	var lbIPs []string
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			lbIPs = append(lbIPs, ingress.IP)
+		}
+	}
+	if len(lbIPs) == 0 {
+		klog.V(4).Infof("reconcileServiceBackendSecurityRules: service %s has no load balancer IP yet, skip updating the security rules", serviceName)
+		return nil
+	}
+
+	_, err = az.reconcileSecurityGroup(ctx, si.clusterName, svc, si.lbName, lbIPs, true)
+	return err
+}
+
+// recordRemovedBackendPodIPs records the pod IPs removed from the backend pools of the service.
+func (az *Cloud) recordRemovedBackendPodIPs(serviceName string, ips []string) {
+	if len(ips) == 0 {
+		return
+	}
+	az.removedBackendPodIPsLock.Lock()
+	defer az.removedBackendPodIPsLock.Unlock()
+
+	if az.removedBackendPodIPs == nil {
+		az.removedBackendPodIPs = make(map[string]*utilsets.IgnoreCaseSet)
+	}
+	key := strings.ToLower(serviceName)
+	az.removedBackendPodIPs[key] = utilsets.SafeInsert(az.removedBackendPodIPs[key], ips...)
+}
+
+// getRemovedBackendPodIPs returns the recorded pod IPs removed from the backend pools of the service.
+func (az *Cloud) getRemovedBackendPodIPs(serviceName string) []string {
+	az.removedBackendPodIPsLock.Lock()
+	defer az.removedBackendPodIPsLock.Unlock()
+
+	return az.removedBackendPodIPs[strings.ToLower(serviceName)].UnsortedList()
+}
+
+// forgetRemovedBackendPodIPs forgets the recorded pod IPs after they are removed from the security rules.
+func (az *Cloud) forgetRemovedBackendPodIPs(serviceName string, ips []string) {
+	az.removedBackendPodIPsLock.Lock()
+	defer az.removedBackendPodIPsLock.Unlock()
+
+	key := strings.ToLower(serviceName)
+	removedIPs, ok := az.removedBackendPodIPs[key]
+	if !ok {
+		return
+	}
+	for _, ip := range ips {
+		removedIPs.Delete(ip)
+	}
+	if removedIPs.Len() == 0 {
+		delete(az.removedBackendPodIPs, key)
+	}
 }
 
 // processError mark the operations as retriable if the error is retriable,
@@ -311,24 +415,12 @@ func (az *Cloud) setUpEndpointSlicesInformer(informerFactory informers.SharedInf
 				}
 				lbName, ipFamily := si.lbName, si.ipFamily
 
-				var previousIPs, currentIPs, previousNodeNames, currentNodeNames []string
+				var previousIPs, currentIPs []string
 				if previousES != nil {
-					for _, ep := range previousES.Endpoints {
-						previousNodeNames = append(previousNodeNames, ptr.Deref(ep.NodeName, ""))
-					}
+					previousIPs = az.getEndpointSliceBackendIPs(previousES, si.backendZones)
 				}
 				if newES != nil {
-					for _, ep := range newES.Endpoints {
-						currentNodeNames = append(currentNodeNames, ptr.Deref(ep.NodeName, ""))
-					}
-				}
-				for _, previousNodeName := range previousNodeNames {
-					nodeIPsSet := az.nodePrivateIPs[strings.ToLower(previousNodeName)]
-					previousIPs = append(previousIPs, nodeIPsSet.UnsortedList()...)
-				}
-				for _, currentNodeName := range currentNodeNames {
-					nodeIPsSet := az.nodePrivateIPs[strings.ToLower(currentNodeName)]
-					currentIPs = append(currentIPs, nodeIPsSet.UnsortedList()...)
+					currentIPs = az.getEndpointSliceBackendIPs(newES, si.backendZones)
 				}
 
 				if az.backendPoolUpdater != nil {
@@ -416,10 +508,111 @@ func getLocalServiceBackendPoolName(serviceName string, ipv6 bool) string {
 	return serviceName
 }
 
+// useServiceBackendPool returns true if the service has its own backend pools, which are
+// populated according to the EndpointSlices of the service. This is the case for all services
+// in the pod IP backend pool mode, and for the local services when using multiple standard load balancers.
+func (az *Cloud) useServiceBackendPool(service *v1.Service) bool {
+	if az.IsLBBackendPoolTypePodIP() {
+		return true
+	}
+	return isLocalService(service) && az.UseMultipleStandardLoadBalancers()
+}
+
+// hasServiceBackendPool returns true if the service has its own backend pools instead of sharing the
+// ones of the cluster, either because they are populated according to the EndpointSlices of the service,
+// or because the backend nodes of the service are restricted to some availability zones.
+func (az *Cloud) hasServiceBackendPool(service *v1.Service) bool {
+	if az.useServiceBackendPool(service) {
+		return true
+	}
+	return az.UseStandardLoadBalancer() && isServiceBackendNodesRestricted(service)
+}
+
+// isServiceBackendNodesRestricted returns true if the backend nodes of the service are restricted
+// to some availability zones.
+func isServiceBackendNodesRestricted(service *v1.Service) bool {
+	return len(getServiceBackendZones(service)) > 0
+}
+
+// checkServiceBackendZones checks if the backend nodes of the service can be restricted to some availability zones.
+// With the backend pool type nodeIPConfiguration, the VMSS VMs join the backend pools through the VMSS model shared
+// by the instances in all zones, so the restriction is only supported if the VMs join the backend pools on the
+// instance level, i.e., the availability sets and the VMSS Flex.
+func (az *Cloud) checkServiceBackendZones(service *v1.Service) error {
+	if !isServiceBackendNodesRestricted(service) || !az.IsLBBackendPoolTypeNodeIPConfig() {
+		return nil
+	}
+	if strings.EqualFold(az.VMType, consts.VMTypeVMSS) {
+		return fmt.Errorf("annotation %s is not supported with backend pool type %s and vmType %s, please use backend pool type %s",
+			consts.ServiceAnnotationLoadBalancerBackendZones,
+			consts.LoadBalancerBackendPoolConfigurationTypeNodeIPConfiguration,
+			consts.VMTypeVMSS,
+			consts.LoadBalancerBackendPoolConfigurationTypeNodeIP,
+		)
+	}
+	return nil
+}
+
+// getServiceBackendZones returns the availability zones which the backend nodes of the service are restricted to.
+func getServiceBackendZones(service *v1.Service) []string {
+	var zones []string
+	for _, zone := range strings.Split(service.Annotations[consts.ServiceAnnotationLoadBalancerBackendZones], ",") {
+		if zone = strings.ToLower(strings.TrimSpace(zone)); zone != "" {
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
+// isZoneInBackendZones checks if the zone, e.g., "eastus-1", is one of the backend zones,
+// which can be given either with or without the region prefix.
+func isZoneInBackendZones(zone string, backendZones []string) bool {
+	zone = strings.ToLower(zone)
+	if zone == "" {
+		return false
+	}
+	for _, backendZone := range backendZones {
+		if zone == backendZone || strings.HasSuffix(zone, "-"+backendZone) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNodeInBackendZones checks if the node is in one of the backend zones according to the node zones cache.
+func (az *Cloud) isNodeInBackendZones(nodeName string, backendZones []string) bool {
+	az.nodeCachesLock.RLock()
+	defer az.nodeCachesLock.RUnlock()
+
+	for zone, nodeNames := range az.nodeZones {
+		if nodeNames != nil && nodeNames.Has(nodeName) {
+			return isZoneInBackendZones(zone, backendZones)
+		}
+	}
+	return false
+}
+
+// filterNodesByServiceBackendZones returns the nodes in the backend zones of the service.
+// All nodes are returned if the service has no backend zones.
+func filterNodesByServiceBackendZones(service *v1.Service, nodes []*v1.Node) []*v1.Node {
+	backendZones := getServiceBackendZones(service)
+	if len(backendZones) == 0 {
+		return nodes
+	}
+
+	var filtered []*v1.Node
+	for _, node := range nodes {
+		if isZoneInBackendZones(node.Labels[v1.LabelTopologyZone], backendZones) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
 // getBackendPoolNameForService determine the expected backend pool name
 // by checking the external traffic policy of the service.
 func (az *Cloud) getBackendPoolNameForService(service *v1.Service, clusterName string, ipv6 bool) string {
-	if !isLocalService(service) || !az.UseMultipleStandardLoadBalancers() {
+	if !az.hasServiceBackendPool(service) {
 		return getBackendPoolName(clusterName, ipv6)
 	}
 	return getLocalServiceBackendPoolName(getServiceName(service), ipv6)
@@ -428,7 +621,7 @@ func (az *Cloud) getBackendPoolNameForService(service *v1.Service, clusterName s
 // getBackendPoolNamesForService determine the expected backend pool names
 // by checking the external traffic policy of the service.
 func (az *Cloud) getBackendPoolNamesForService(service *v1.Service, clusterName string) map[bool]string {
-	if !isLocalService(service) || !az.UseMultipleStandardLoadBalancers() {
+	if !az.hasServiceBackendPool(service) {
 		return getBackendPoolNames(clusterName)
 	}
 	return map[bool]string{
@@ -440,7 +633,7 @@ func (az *Cloud) getBackendPoolNamesForService(service *v1.Service, clusterName
 // getBackendPoolIDsForService determine the expected backend pool IDs
 // by checking the external traffic policy of the service.
 func (az *Cloud) getBackendPoolIDsForService(service *v1.Service, clusterName, lbName string) map[bool]string {
-	if !isLocalService(service) || !az.UseMultipleStandardLoadBalancers() {
+	if !az.hasServiceBackendPool(service) {
 		return az.getBackendPoolIDs(clusterName, lbName)
 	}
 	return map[bool]string{
@@ -463,8 +656,10 @@ func localServiceOwnsBackendPool(serviceName, bpName string) bool {
 }
 
 type serviceInfo struct {
-	ipFamily string
-	lbName   string
+	ipFamily     string
+	lbName       string
+	backendZones []string
+	clusterName  string
 }
 
 func newServiceInfo(ipFamily, lbName string) *serviceInfo {
@@ -474,8 +669,8 @@ func newServiceInfo(ipFamily, lbName string) *serviceInfo {
 	}
 }
 
-// getLocalServiceEndpointsNodeNames gets the node names that host all endpoints of the local service.
-func (az *Cloud) getLocalServiceEndpointsNodeNames(service *v1.Service) *utilsets.IgnoreCaseSet {
+// getServiceEndpointSlices gets the cached EndpointSlices of the service.
+func (az *Cloud) getServiceEndpointSlices(service *v1.Service) []*discovery_v1.EndpointSlice {
 	var eps []*discovery_v1.EndpointSlice
 	az.endpointSlicesCache.Range(func(_, value interface{}) bool {
 		endpointSlice := value.(*discovery_v1.EndpointSlice)
@@ -485,6 +680,12 @@ func (az *Cloud) getLocalServiceEndpointsNodeNames(service *v1.Service) *utilset
 		}
 		return true
 	})
+	return eps
+}
+
+// getLocalServiceEndpointsNodeNames gets the node names that host all endpoints of the local service.
+func (az *Cloud) getLocalServiceEndpointsNodeNames(service *v1.Service) *utilsets.IgnoreCaseSet {
+	eps := az.getServiceEndpointSlices(service)
 	if len(eps) == 0 {
 		klog.Warningf("getLocalServiceEndpointsNodeNames: failed to find EndpointSlice for service %s/%s", service.Namespace, service.Name)
 		return nil
@@ -501,6 +702,84 @@ func (az *Cloud) getLocalServiceEndpointsNodeNames(service *v1.Service) *utilset
 	return utilsets.NewString(nodeNames...)
 }
 
+// getEndpointSliceBackendIPs gets the IPs of the EndpointSlice which should be in the backend pool of the service.
+// In the pod IP backend pool mode, they are the addresses of the ready endpoints. Otherwise,
+// they are the private IPs of the nodes hosting the endpoints. The endpoints out of the
+// backend zones of the service are ignored if there are any.
+func (az *Cloud) getEndpointSliceBackendIPs(es *discovery_v1.EndpointSlice, backendZones []string) []string {
+	var ips []string
+	for _, ep := range es.Endpoints {
+		if len(backendZones) > 0 {
+			inBackendZones := isZoneInBackendZones(ptr.Deref(ep.Zone, ""), backendZones)
+			if !inBackendZones && ep.NodeName != nil {
+				inBackendZones = az.isNodeInBackendZones(*ep.NodeName, backendZones)
+			}
+			if !inBackendZones {
+				continue
+			}
+		}
+		if az.IsLBBackendPoolTypePodIP() {
+			// A nil ready condition should be interpreted as ready.
+			if ptr.Deref(ep.Conditions.Ready, true) {
+				ips = append(ips, ep.Addresses...)
+			}
+			continue
+		}
+		nodeIPsSet := az.nodePrivateIPs[strings.ToLower(ptr.Deref(ep.NodeName, ""))]
+		ips = append(ips, nodeIPsSet.UnsortedList()...)
+	}
+	return ips
+}
+
+// getServiceBackendIPs gets the IPs which should be in the backend pools of the service according to its EndpointSlices.
+// It returns nil if the EndpointSlices of the service are not found, which means the
+// informer cache has not been synced, so the backend pools should be left as is.
+func (az *Cloud) getServiceBackendIPs(service *v1.Service) []string {
+	eps := az.getServiceEndpointSlices(service)
+	if len(eps) == 0 {
+		klog.Warningf("getServiceBackendIPs: failed to find EndpointSlice for service %s/%s", service.Namespace, service.Name)
+		return nil
+	}
+
+	ips := utilsets.NewString()
+	backendZones := getServiceBackendZones(service)
+	for _, es := range eps {
+		ips.Insert(az.getEndpointSliceBackendIPs(es, backendZones)...)
+	}
+	return ips.UnsortedList()
+}
+
+// getServicePortTargetPort gets the container port of the service port. A named target port is resolved
+// by the ports of the EndpointSlices of the service, which are named after the service ports.
+func (az *Cloud) getServicePortTargetPort(service *v1.Service, port v1.ServicePort) (int32, error) {
+	if port.TargetPort.Type == intstr.Int {
+		if port.TargetPort.IntVal == 0 {
+			return port.Port, nil
+		}
+		return port.TargetPort.IntVal, nil
+	}
+
+	for _, es := range az.getServiceEndpointSlices(service) {
+		for _, esPort := range es.Ports {
+			if ptr.Deref(esPort.Name, "") == port.Name &&
+				ptr.Deref(esPort.Protocol, v1.ProtocolTCP) == port.Protocol &&
+				ptr.Deref(esPort.Port, 0) != 0 {
+				return *esPort.Port, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("failed to resolve the target port %q of service port %d: no EndpointSlice port named %q found", port.TargetPort.StrVal, port.Port, port.Name)
+}
+
+// getServicePortBackendPort gets the port the backends listen on for the service port,
+// which is the container port in pod IP mode and the node port otherwise.
+func (az *Cloud) getServicePortBackendPort(service *v1.Service, port v1.ServicePort) (int32, error) {
+	if az.IsLBBackendPoolTypePodIP() {
+		return az.getServicePortTargetPort(service, port)
+	}
+	return port.NodePort, nil
+}
+
 // cleanupLocalServiceBackendPool cleans up the backend pool of
 // a local service among given load balancers.
 func (az *Cloud) cleanupLocalServiceBackendPool(
@@ -517,6 +796,15 @@ func (az *Cloud) cleanupLocalServiceBackendPool(
 			for _, bp := range lb.Properties.BackendAddressPools {
 				bpName := ptr.Deref(bp.Name, "")
 				if localServiceOwnsBackendPool(getServiceName(svc), bpName) {
+					// The NICs or VMSS referencing the zonal backend pools of the service
+					// in the nodeIPConfiguration mode must be decoupled first.
+					if bp.Properties != nil && len(bp.Properties.BackendIPConfigurations) > 0 {
+						bpID := az.getBackendPoolID(lbName, bpName)
+						vmSetName := az.mapLoadBalancerNameToVMSet(lbName, clusterName)
+						if _, err := az.ensureBackendPoolDeleted(ctx, svc, []string{bpID}, vmSetName, lb.Properties.BackendAddressPools, true); err != nil {
+							return nil, err
+						}
+					}
 					if err := az.DeleteLBBackendPool(ctx, lbName, bpName); err != nil {
 						return nil, err
 					}
@@ -541,16 +829,10 @@ func (az *Cloud) cleanupLocalServiceBackendPool(
 // with the corresponding endpointslice, and update the backend pool if necessary.
 func (az *Cloud) checkAndApplyLocalServiceBackendPoolUpdates(lb armnetwork.LoadBalancer, service *v1.Service) error {
 	serviceName := getServiceName(service)
-	endpointsNodeNames := az.getLocalServiceEndpointsNodeNames(service)
-	if endpointsNodeNames == nil {
+	expectedIPs := az.getServiceBackendIPs(service)
+	if expectedIPs == nil {
 		return nil
 	}
-
-	var expectedIPs []string
-	for _, nodeName := range endpointsNodeNames.UnsortedList() {
-		ips := az.nodePrivateIPs[strings.ToLower(nodeName)]
-		expectedIPs = append(expectedIPs, ips.UnsortedList()...)
-	}
 	currentIPsInBackendPools := make(map[string][]string)
 	for _, bp := range lb.Properties.BackendAddressPools {
 		bpName := ptr.Deref(bp.Name, "")
@@ -33,13 +33,21 @@ import (
 	v1 "k8s.io/api/core/v1"
 	discovery_v1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/ptr"
 
+	"sigs.k8s.io/cloud-provider-azure/internal/testutil"
+	"sigs.k8s.io/cloud-provider-azure/internal/testutil/fixture"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/backendaddresspoolclient/mock_backendaddresspoolclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/loadbalancerclient/mock_loadbalancerclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/securitygroupclient/mock_securitygroupclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
 	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/securitygroup"
+	"sigs.k8s.io/cloud-provider-azure/pkg/util/iputil"
 	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
 )
 
@@ -553,3 +561,218 @@ func TestCheckAndApplyLocalServiceBackendPoolUpdates(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEndpointSliceBackendIPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eps := getTestEndpointSlice("eps1", "default", "svc1", "node1", "node2")
+	eps.Endpoints[0].Addresses = []string{"10.244.0.1"}
+	eps.Endpoints[1].Addresses = []string{"10.244.1.1"}
+	eps.Endpoints[1].Conditions.Ready = ptr.To(false)
+
+	cloud := GetTestCloud(ctrl)
+	cloud.nodePrivateIPs = map[string]*utilsets.IgnoreCaseSet{
+		"node1": utilsets.NewString("10.0.0.1"),
+		"node2": utilsets.NewString("10.0.0.2"),
+	}
+	assert.ElementsMatch(t, []string{"10.0.0.1", "10.0.0.2"}, cloud.getEndpointSliceBackendIPs(eps, nil))
+
+	cloud.nodeZones = map[string]*utilsets.IgnoreCaseSet{
+		"eastus-1": utilsets.NewString("node1"),
+		"eastus-2": utilsets.NewString("node2"),
+	}
+	assert.Equal(t, []string{"10.0.0.2"}, cloud.getEndpointSliceBackendIPs(eps, []string{"2"}))
+
+	cloud.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypePODIP
+	assert.Equal(t, []string{"10.244.0.1"}, cloud.getEndpointSliceBackendIPs(eps, nil))
+	eps.Endpoints[0].Zone = ptr.To("eastus-1")
+	assert.Equal(t, []string{"10.244.0.1"}, cloud.getEndpointSliceBackendIPs(eps, []string{"eastus-1"}))
+	assert.Empty(t, cloud.getEndpointSliceBackendIPs(eps, []string{"eastus-3"}))
+}
+
+func TestServiceBackendZones(t *testing.T) {
+	svc := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerBackendZones: " 1, EastUS-2,,",
+	}, false, 80)
+	zones := getServiceBackendZones(&svc)
+	assert.Equal(t, []string{"1", "eastus-2"}, zones)
+
+	assert.True(t, isZoneInBackendZones("eastus-1", zones))
+	assert.True(t, isZoneInBackendZones("EastUS-2", zones))
+	assert.False(t, isZoneInBackendZones("eastus-3", zones))
+	assert.False(t, isZoneInBackendZones("westus-11", zones))
+	assert.False(t, isZoneInBackendZones("", zones))
+
+	getZonalNode := func(name, zone string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}}}
+	}
+	nodes := []*v1.Node{getZonalNode("node1", "eastus-1"), getZonalNode("node2", "eastus-2"), getZonalNode("node3", "eastus-3"), getZonalNode("node4", "")}
+	filtered := filterNodesByServiceBackendZones(&svc, nodes)
+	assert.Equal(t, []*v1.Node{nodes[0], nodes[1]}, filtered)
+
+	delete(svc.Annotations, consts.ServiceAnnotationLoadBalancerBackendZones)
+	assert.Empty(t, getServiceBackendZones(&svc))
+	assert.Equal(t, nodes, filterNodesByServiceBackendZones(&svc, nodes))
+}
+
+func TestGetBackendPoolNamesForServiceWithBackendZones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cloud := GetTestCloud(ctrl)
+	cloud.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	svc := getTestServiceDualStack("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerBackendZones: "1",
+	}, 80)
+	assert.True(t, cloud.hasServiceBackendPool(&svc))
+	assert.Equal(t, map[bool]string{
+		consts.IPVersionIPv4: "default-test",
+		consts.IPVersionIPv6: "default-test-ipv6",
+	}, cloud.getBackendPoolNamesForService(&svc, testClusterName))
+
+	cloud.LoadBalancerSKU = consts.LoadBalancerSKUBasic
+	assert.False(t, cloud.hasServiceBackendPool(&svc))
+	assert.Equal(t, testClusterName, cloud.getBackendPoolNameForService(&svc, testClusterName, false))
+}
+
+func TestCheckServiceBackendZones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cloud := GetTestCloud(ctrl)
+	svc := getTestService("test", v1.ProtocolTCP, map[string]string{
+		consts.ServiceAnnotationLoadBalancerBackendZones: "1",
+	}, false, 80)
+
+	// The VMSS model is shared by the instances in all zones.
+	cloud.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIPConfiguration
+	cloud.VMType = consts.VMTypeVMSS
+	assert.Error(t, cloud.checkServiceBackendZones(&svc))
+
+	cloud.VMType = consts.VMTypeStandard
+	assert.NoError(t, cloud.checkServiceBackendZones(&svc))
+	cloud.VMType = consts.VMTypeVmssFlex
+	assert.NoError(t, cloud.checkServiceBackendZones(&svc))
+
+	cloud.VMType = consts.VMTypeVMSS
+	cloud.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	assert.NoError(t, cloud.checkServiceBackendZones(&svc))
+
+	cloud.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIPConfiguration
+	delete(svc.Annotations, consts.ServiceAnnotationLoadBalancerBackendZones)
+	assert.NoError(t, cloud.checkServiceBackendZones(&svc))
+}
+
+func TestGetServicePortTargetPort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		description  string
+		port         v1.ServicePort
+		expectedPort int32
+		expectedErr  bool
+	}{
+		{
+			description:  "should use the service port if the target port is not set",
+			port:         v1.ServicePort{Name: "http", Port: 80, Protocol: v1.ProtocolTCP},
+			expectedPort: 80,
+		},
+		{
+			description:  "should use the numeric target port",
+			port:         v1.ServicePort{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromInt32(8080)},
+			expectedPort: 8080,
+		},
+		{
+			description:  "should resolve the named target port by the endpointslice",
+			port:         v1.ServicePort{Name: "http", Port: 80, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromString("web")},
+			expectedPort: 8081,
+		},
+		{
+			description: "should report an error if the named target port cannot be resolved",
+			port:        v1.ServicePort{Name: "dns", Port: 53, Protocol: v1.ProtocolUDP, TargetPort: intstr.FromString("dns")},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			cloud := GetTestCloud(ctrl)
+			eps := getTestEndpointSlice("eps1", "default", "svc1", "node1")
+			eps.Ports = []discovery_v1.EndpointPort{
+				{Name: ptr.To("http"), Protocol: ptr.To(v1.ProtocolTCP), Port: ptr.To(int32(8081))},
+				{Name: ptr.To("dns"), Protocol: ptr.To(v1.ProtocolTCP), Port: ptr.To(int32(5353))},
+			}
+			cloud.endpointSlicesCache.Store("default/eps1", eps)
+			svc := getTestService("svc1", v1.ProtocolTCP, nil, false)
+
+			port, err := cloud.getServicePortTargetPort(&svc, tc.port)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedPort, port)
+		})
+	}
+}
+
+func TestLoadBalancerBackendPoolUpdaterUpdatesSecurityRulesOnPodChurn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	azureFx := fixture.NewFixture().Azure()
+	cloud := GetTestCloud(ctrl)
+	cloud.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypePODIP
+	cloud.LoadBalancerBackendPool = newBackendPoolTypePodIP(cloud)
+
+	svc := getTestService("svc1", v1.ProtocolTCP, nil, false, 80)
+	svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}
+	client := fake.NewSimpleClientset(&svc)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	cloud.serviceLister = informerFactory.Core().V1().Services().Lister()
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+	cloud.localServiceNameToServiceInfoMap.Store("default/svc1", &serviceInfo{lbName: "lb1", clusterName: testClusterName})
+
+	// the pod 10.244.0.1 is replaced by the pod 10.244.0.2
+	bpName := getLocalServiceBackendPoolName("default/svc1", false)
+	mockbpClient := cloud.NetworkClientFactory.GetBackendAddressPoolClient().(*mock_backendaddresspoolclient.MockInterface)
+	mockbpClient.EXPECT().Get(gomock.Any(), gomock.Any(), "lb1", bpName).
+		Return(getTestBackendAddressPoolWithIPs("lb1", bpName, []string{"10.244.0.1"}), nil)
+	mockbpClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), "lb1", bpName, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, _ string, bp armnetwork.BackendAddressPool) (*armnetwork.BackendAddressPool, error) {
+			assert.Len(t, bp.Properties.LoadBalancerBackendAddresses, 1)
+			assert.Equal(t, "10.244.0.2", ptr.Deref(bp.Properties.LoadBalancerBackendAddresses[0].Properties.IPAddress, ""))
+			return &bp, nil
+		})
+
+	mockLBClient := cloud.NetworkClientFactory.GetLoadBalancerClient().(*mock_loadbalancerclient.MockInterface)
+	mockLBClient.EXPECT().Get(gomock.Any(), gomock.Any(), "lb1", gomock.Any()).Return(&armnetwork.LoadBalancer{
+		Name: ptr.To("lb1"),
+		Properties: &armnetwork.LoadBalancerPropertiesFormat{
+			BackendAddressPools: []*armnetwork.BackendAddressPool{
+				getTestBackendAddressPoolWithIPs("lb1", bpName, []string{"10.244.0.2"}),
+			},
+		},
+	}, nil)
+
+	newRule := func(dstIP string) *armnetwork.SecurityRule {
+		return azureFx.AllowSecurityRule(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, []string{securitygroup.ServiceTagInternet}, []int32{80}).
+			WithPriority(500).
+			WithDestination(dstIP).
+			Build()
+	}
+	mockSGClient := cloud.NetworkClientFactory.GetSecurityGroupClient().(*mock_securitygroupclient.MockInterface)
+	mockSGClient.EXPECT().Get(gomock.Any(), cloud.ResourceGroup, cloud.SecurityGroupName).
+		Return(azureFx.SecurityGroup().WithRules([]*armnetwork.SecurityRule{newRule("10.244.0.1")}).Build(), nil)
+	var updatedRules []*armnetwork.SecurityRule
+	mockSGClient.EXPECT().CreateOrUpdate(gomock.Any(), cloud.ResourceGroup, cloud.SecurityGroupName, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, sg armnetwork.SecurityGroup) (*armnetwork.SecurityGroup, error) {
+			updatedRules = sg.Properties.SecurityRules
+			return &sg, nil
+		})
+
+	u := newLoadBalancerBackendPoolUpdater(cloud, time.Second)
+	u.addOperation(getRemoveIPsFromBackendPoolOperation("default/svc1", "lb1", bpName, []string{"10.244.0.1"}))
+	u.addOperation(getAddIPsToBackendPoolOperation("default/svc1", "lb1", bpName, []string{"10.244.0.2"}))
+	u.process(context.Background())
+
+	testutil.ExpectEqualInJSON(t, []*armnetwork.SecurityRule{newRule("10.244.0.2")}, updatedRules)
+	assert.Empty(t, cloud.getRemovedBackendPodIPs("default/svc1"), "the removed pod IPs should be forgotten once cleaned up")
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/dynamiclister"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+)
+
+// MultipleStandardLoadBalancerConfigurationController applies the MultipleStandardLoadBalancerConfiguration
+// custom resources to the multiple standard load balancer configurations of the cloud provider, and writes
+// the services and nodes on each load balancer back to the status of the custom resources.
+//
+// The configurations in the cloud config file are kept. A custom resource with the same name overrides the
+// spec of the configuration in the file, and the other custom resources add new configurations. A configuration
+// removed from the custom resources stays as a draining one until there is no service on it.
+type MultipleStandardLoadBalancerConfigurationController struct {
+	az              *Cloud
+	client          dynamic.Interface
+	informerFactory dynamicinformer.DynamicSharedInformerFactory
+	lister          dynamiclister.Lister
+	// fileConfigurations are the configurations in the cloud config file.
+	fileConfigurations []config.MultipleStandardLoadBalancerConfiguration
+	// syncCh triggers a sync of the configurations when the custom resources change.
+	syncCh   chan struct{}
+	interval time.Duration
+}
+
+// NewMultipleStandardLoadBalancerConfigurationController creates a new MultipleStandardLoadBalancerConfigurationController.
+func NewMultipleStandardLoadBalancerConfigurationController(az *Cloud, client dynamic.Interface) *MultipleStandardLoadBalancerConfigurationController {
+	informerFactory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	informer := informerFactory.ForResource(config.MultipleStandardLoadBalancerConfigurationGVR)
+
+	c := &MultipleStandardLoadBalancerConfigurationController{
+		az:              az,
+		client:          client,
+		informerFactory: informerFactory,
+		lister:          dynamiclister.New(informer.Informer().GetIndexer(), config.MultipleStandardLoadBalancerConfigurationGVR),
+		syncCh:          make(chan struct{}, 1),
+		interval:        time.Duration(consts.DefaultMultipleStandardLoadBalancerConfigurationSyncIntervalInSeconds) * time.Second,
+	}
+	for _, multiSLBConfig := range az.MultipleStandardLoadBalancerConfigurations {
+		c.fileConfigurations = append(c.fileConfigurations, config.MultipleStandardLoadBalancerConfiguration{
+			Name: multiSLBConfig.Name,
+			MultipleStandardLoadBalancerConfigurationSpec: multiSLBConfig.MultipleStandardLoadBalancerConfigurationSpec,
+		})
+	}
+
+	_, _ = informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(_ interface{}) { c.enqueue() },
+		UpdateFunc: func(_, _ interface{}) { c.enqueue() },
+		DeleteFunc: func(_ interface{}) { c.enqueue() },
+	})
+
+	return c
+}
+
+func (c *MultipleStandardLoadBalancerConfigurationController) enqueue() {
+	select {
+	case c.syncCh <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts the MultipleStandardLoadBalancerConfigurationController, and stops if the context exits.
+// The configurations can be added by the custom resources even if there is none in the cloud config file,
+// so it only requires the standard load balancer and an IP-based backend pool type.
+func (c *MultipleStandardLoadBalancerConfigurationController) Run(ctx context.Context) {
+	if !c.az.UseStandardLoadBalancer() {
+		klog.Warningf("MultipleStandardLoadBalancerConfigurationController.Run: multiple standard load balancers require the standard load balancer sku, will not watch the configurations")
+		return
+	}
+	if c.az.IsLBBackendPoolTypeNodeIPConfig() {
+		klog.Warningf("MultipleStandardLoadBalancerConfigurationController.Run: multiple standard load balancers cannot be used with backend pool type %s, will not watch the configurations", consts.LoadBalancerBackendPoolConfigurationTypeNodeIPConfiguration)
+		return
+	}
+	c.ensureBackendPoolUpdater(ctx)
+
+	c.informerFactory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.informerFactory.ForResource(config.MultipleStandardLoadBalancerConfigurationGVR).Informer().HasSynced) {
+		klog.Errorf("MultipleStandardLoadBalancerConfigurationController.Run: failed to wait for the cache to sync")
+		return
+	}
+
+	klog.V(2).Infof("MultipleStandardLoadBalancerConfigurationController.Run: started with interval %s", c.interval)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	c.enqueue()
+	for {
+		select {
+		case <-ctx.Done():
+			klog.Infof("MultipleStandardLoadBalancerConfigurationController.Run: stopped due to %s", ctx.Err().Error())
+			return
+		case <-c.syncCh:
+			if err := c.sync(); err != nil {
+				klog.Errorf("MultipleStandardLoadBalancerConfigurationController.Run: failed to sync the configurations: %s", err.Error())
+			}
+		case <-ticker.C:
+			if err := c.sync(); err != nil {
+				klog.Errorf("MultipleStandardLoadBalancerConfigurationController.Run: failed to sync the configurations: %s", err.Error())
+			}
+			if err := c.updateStatus(ctx); err != nil {
+				klog.Errorf("MultipleStandardLoadBalancerConfigurationController.Run: failed to update the status: %s", err.Error())
+			}
+		}
+	}
+}
+
+// ensureBackendPoolUpdater starts the backend pool updater used by the multiple standard load balancers
+// if it was not started at initialization because there was no configuration in the cloud config file.
+func (c *MultipleStandardLoadBalancerConfigurationController) ensureBackendPoolUpdater(ctx context.Context) {
+	c.az.serviceReconcileLock.Lock()
+	defer c.az.serviceReconcileLock.Unlock()
+
+	if c.az.backendPoolUpdater != nil {
+		return
+	}
+	if c.az.LoadBalancerBackendPoolUpdateIntervalInSeconds == 0 {
+		c.az.LoadBalancerBackendPoolUpdateIntervalInSeconds = consts.DefaultLoadBalancerBackendPoolUpdateIntervalInSeconds
+	}
+	c.az.backendPoolUpdater = newLoadBalancerBackendPoolUpdater(c.az, time.Duration(c.az.LoadBalancerBackendPoolUpdateIntervalInSeconds)*time.Second)
+	go c.az.backendPoolUpdater.run(ctx)
+}
+
+// listResources lists the MultipleStandardLoadBalancerConfiguration custom resources from the cache.
+func (c *MultipleStandardLoadBalancerConfigurationController) listResources() ([]*config.MultipleStandardLoadBalancerConfigurationResource, error) {
+	objs, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*config.MultipleStandardLoadBalancerConfigurationResource, 0, len(objs))
+	for _, obj := range objs {
+		resource := &config.MultipleStandardLoadBalancerConfigurationResource{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), resource); err != nil {
+			klog.Errorf("MultipleStandardLoadBalancerConfigurationController: failed to convert the configuration %s: %s", obj.GetName(), err.Error())
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Name < resources[j].Name
+	})
+
+	return resources, nil
+}
+
+// sync applies the custom resources to the multiple standard load balancer configurations of the cloud provider.
+func (c *MultipleStandardLoadBalancerConfigurationController) sync() error {
+	resources, err := c.listResources()
+	if err != nil {
+		return fmt.Errorf("failed to list the configurations: %w", err)
+	}
+
+	// Serialize with the service reconciliation so that a service does not see the configurations change halfway.
+	c.az.serviceReconcileLock.Lock()
+	defer c.az.serviceReconcileLock.Unlock()
+	c.az.multipleStandardLoadBalancersActiveServicesLock.Lock()
+	defer c.az.multipleStandardLoadBalancersActiveServicesLock.Unlock()
+	c.az.multipleStandardLoadBalancersActiveNodesLock.Lock()
+	defer c.az.multipleStandardLoadBalancersActiveNodesLock.Unlock()
+
+	configs := mergeMultipleStandardLoadBalancerConfigurations(c.fileConfigurations, resources, c.az.MultipleStandardLoadBalancerConfigurations)
+	// The configurations added by the custom resources are checked in the same way as the ones in the cloud config file.
+	if len(configs) > 0 {
+		if err := c.az.checkEnableMultipleStandardLoadBalancers(configs); err != nil {
+			return fmt.Errorf("invalid configurations: %w", err)
+		}
+	}
+	if equalMultipleStandardLoadBalancerConfigurationSpecs(configs, c.az.MultipleStandardLoadBalancerConfigurations) {
+		return nil
+	}
+
+	names := make([]string, 0, len(configs))
+	for _, multiSLBConfig := range configs {
+		names = append(names, multiSLBConfig.Name)
+	}
+	klog.Infof("MultipleStandardLoadBalancerConfigurationController: applying the configurations %s", strings.Join(names, ","))
+	c.az.setMultipleStandardLoadBalancerConfigurations(configs)
+	// The nodes are matched with the load balancers by primary vmSet again, as the primary vmSets may have changed.
+	c.az.nodesWithCorrectLoadBalancerByPrimaryVMSet.Clear()
+
+	return nil
+}
+
+// mergeMultipleStandardLoadBalancerConfigurations merges the configurations in the cloud config file and the
+// custom resources, and keeps the status of the current configurations.
+func mergeMultipleStandardLoadBalancerConfigurations(
+	fileConfigs []config.MultipleStandardLoadBalancerConfiguration,
+	resources []*config.MultipleStandardLoadBalancerConfigurationResource,
+	currentConfigs []config.MultipleStandardLoadBalancerConfiguration,
+) []config.MultipleStandardLoadBalancerConfiguration {
+	resourceByName := make(map[string]*config.MultipleStandardLoadBalancerConfigurationResource)
+	for _, resource := range resources {
+		resourceByName[strings.ToLower(resource.Name)] = resource
+	}
+
+	configs := make([]config.MultipleStandardLoadBalancerConfiguration, 0, len(fileConfigs)+len(resources))
+	found := make(map[string]bool)
+	for _, fileConfig := range fileConfigs {
+		multiSLBConfig := fileConfig
+		if resource, ok := resourceByName[strings.ToLower(fileConfig.Name)]; ok {
+			multiSLBConfig.MultipleStandardLoadBalancerConfigurationSpec = resource.Spec
+		}
+		configs = append(configs, multiSLBConfig)
+		found[strings.ToLower(multiSLBConfig.Name)] = true
+	}
+	for _, resource := range resources {
+		if found[strings.ToLower(resource.Name)] {
+			continue
+		}
+		configs = append(configs, config.MultipleStandardLoadBalancerConfiguration{
+			Name: resource.Name,
+			MultipleStandardLoadBalancerConfigurationSpec: resource.Spec,
+		})
+		found[strings.ToLower(resource.Name)] = true
+	}
+
+	currentByName := make(map[string]config.MultipleStandardLoadBalancerConfiguration)
+	for _, currentConfig := range currentConfigs {
+		currentByName[strings.ToLower(currentConfig.Name)] = currentConfig
+		if found[strings.ToLower(currentConfig.Name)] || currentConfig.ActiveServices.Len() == 0 {
+			continue
+		}
+		// Keep the removed configuration until the services are moved away from the load balancer.
+		klog.V(2).Infof("mergeMultipleStandardLoadBalancerConfigurations: configuration %s is removed but still has %d services, will drain it", currentConfig.Name, currentConfig.ActiveServices.Len())
+		draining := currentConfig
+		draining.AllowServicePlacement = ptr.To(false)
+		configs = append(configs, draining)
+	}
+
+	for i := range configs {
+		if currentConfig, ok := currentByName[strings.ToLower(configs[i].Name)]; ok {
+			configs[i].MultipleStandardLoadBalancerConfigurationStatus = currentConfig.MultipleStandardLoadBalancerConfigurationStatus
+		}
+	}
+
+	return configs
+}
+
+// equalMultipleStandardLoadBalancerConfigurationSpecs returns true if the names and specs of the configurations are the same.
+func equalMultipleStandardLoadBalancerConfigurationSpecs(a, b []config.MultipleStandardLoadBalancerConfiguration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i].Name, b[i].Name) ||
+			!reflect.DeepEqual(a[i].MultipleStandardLoadBalancerConfigurationSpec, b[i].MultipleStandardLoadBalancerConfigurationSpec) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateStatus writes the services and nodes on each load balancer to the status of the custom resources.
+func (c *MultipleStandardLoadBalancerConfigurationController) updateStatus(ctx context.Context) error {
+	objs, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list the configurations: %w", err)
+	}
+
+	statuses := c.getMultipleStandardLoadBalancerConfigurationStatuses()
+	for _, obj := range objs {
+		status, ok := statuses[strings.ToLower(obj.GetName())]
+		if !ok {
+			continue
+		}
+		current := config.MultipleStandardLoadBalancerConfigurationResourceStatus{}
+		if content, ok := obj.UnstructuredContent()["status"].(map[string]interface{}); ok {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &current); err != nil {
+				klog.Warningf("MultipleStandardLoadBalancerConfigurationController.updateStatus: failed to convert the status of %s: %s", obj.GetName(), err.Error())
+			}
+		}
+		if reflect.DeepEqual(current, status) {
+			continue
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+		if err != nil {
+			return err
+		}
+		updated := obj.DeepCopy()
+		if err := unstructured.SetNestedMap(updated.Object, content, "status"); err != nil {
+			return err
+		}
+		klog.V(2).Infof("MultipleStandardLoadBalancerConfigurationController.updateStatus: updating the status of %s with %d services and %d nodes", obj.GetName(), len(status.ActiveServices), len(status.ActiveNodes))
+		if _, err := c.client.Resource(config.MultipleStandardLoadBalancerConfigurationGVR).UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update the status of %s: %w", obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// getMultipleStandardLoadBalancerConfigurationStatuses returns the sorted services and nodes of the configurations by lower-cased name.
+func (c *MultipleStandardLoadBalancerConfigurationController) getMultipleStandardLoadBalancerConfigurationStatuses() map[string]config.MultipleStandardLoadBalancerConfigurationResourceStatus {
+	c.az.multipleStandardLoadBalancersActiveServicesLock.Lock()
+	defer c.az.multipleStandardLoadBalancersActiveServicesLock.Unlock()
+	c.az.multipleStandardLoadBalancersActiveNodesLock.Lock()
+	defer c.az.multipleStandardLoadBalancersActiveNodesLock.Unlock()
+
+	statuses := make(map[string]config.MultipleStandardLoadBalancerConfigurationResourceStatus)
+	for _, multiSLBConfig := range c.az.getMultipleStandardLoadBalancerConfigurations() {
+		var status config.MultipleStandardLoadBalancerConfigurationResourceStatus
+		if multiSLBConfig.ActiveServices.Len() > 0 {
+			status.ActiveServices = multiSLBConfig.ActiveServices.UnsortedList()
+			sort.Strings(status.ActiveServices)
+		}
+		if multiSLBConfig.ActiveNodes.Len() > 0 {
+			status.ActiveNodes = multiSLBConfig.ActiveNodes.UnsortedList()
+			sort.Strings(status.ActiveNodes)
+		}
+		statuses[strings.ToLower(multiSLBConfig.Name)] = status
+	}
+	return statuses
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+func getTestMultipleStandardLoadBalancerConfigurationResource(t *testing.T, name, primaryVMSet string, status *config.MultipleStandardLoadBalancerConfigurationResourceStatus) *unstructured.Unstructured {
+	resource := &config.MultipleStandardLoadBalancerConfigurationResource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: config.MultipleStandardLoadBalancerConfigurationGVR.GroupVersion().String(),
+			Kind:       config.MultipleStandardLoadBalancerConfigurationKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: config.MultipleStandardLoadBalancerConfigurationSpec{
+			PrimaryVMSet: primaryVMSet,
+		},
+	}
+	if status != nil {
+		resource.Status = *status
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	assert.NoError(t, err)
+	return &unstructured.Unstructured{Object: content}
+}
+
+func newTestMultipleStandardLoadBalancerConfigurationController(t *testing.T, az *Cloud, objs ...runtime.Object) (*MultipleStandardLoadBalancerConfigurationController, *dynamicfake.FakeDynamicClient) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			config.MultipleStandardLoadBalancerConfigurationGVR: config.MultipleStandardLoadBalancerConfigurationKind + "List",
+		},
+		objs...,
+	)
+	c := NewMultipleStandardLoadBalancerConfigurationController(az, client)
+	c.informerFactory.Start(wait.NeverStop)
+	c.informerFactory.WaitForCacheSync(wait.NeverStop)
+	return c, client
+}
+
+func TestMergeMultipleStandardLoadBalancerConfigurations(t *testing.T) {
+	fileConfigs := []config.MultipleStandardLoadBalancerConfiguration{
+		{Name: "lb1", MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{PrimaryVMSet: "vmss1"}},
+	}
+	currentConfigs := []config.MultipleStandardLoadBalancerConfiguration{
+		{
+			Name: "lb1",
+			MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{PrimaryVMSet: "vmss1"},
+			MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+				ActiveServices: utilsets.NewString("default/svc1"),
+			},
+		},
+		{
+			Name: "lb2",
+			MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{PrimaryVMSet: "vmss2"},
+			MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+				ActiveServices: utilsets.NewString("default/svc2"),
+			},
+		},
+		{
+			Name: "lb3",
+			MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{PrimaryVMSet: "vmss3"},
+		},
+	}
+	resources := []*config.MultipleStandardLoadBalancerConfigurationResource{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "lb1"},
+			Spec: config.MultipleStandardLoadBalancerConfigurationSpec{
+				PrimaryVMSet:          "vmss1",
+				AllowServicePlacement: ptr.To(false),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "lb4"},
+			Spec:       config.MultipleStandardLoadBalancerConfigurationSpec{PrimaryVMSet: "vmss4"},
+		},
+	}
+
+	configs := mergeMultipleStandardLoadBalancerConfigurations(fileConfigs, resources, currentConfigs)
+	assert.Len(t, configs, 3)
+
+	// The resource overrides the spec of the configuration in the file, and the status is kept.
+	assert.Equal(t, "lb1", configs[0].Name)
+	assert.Equal(t, ptr.To(false), configs[0].AllowServicePlacement)
+	assert.True(t, configs[0].ActiveServices.Has("default/svc1"))
+	// The new resource is added.
+	assert.Equal(t, "lb4", configs[1].Name)
+	assert.Equal(t, "vmss4", configs[1].PrimaryVMSet)
+	// The removed configuration with services is drained, and the one without services is removed.
+	assert.Equal(t, "lb2", configs[2].Name)
+	assert.Equal(t, ptr.To(false), configs[2].AllowServicePlacement)
+	assert.True(t, configs[2].ActiveServices.Has("default/svc2"))
+}
+
+func TestMultipleStandardLoadBalancerConfigurationControllerSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	az.MultipleStandardLoadBalancerConfigurations = []config.MultipleStandardLoadBalancerConfiguration{
+		{
+			Name: testClusterName,
+			MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{PrimaryVMSet: "vmss1"},
+			MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+				ActiveServices: utilsets.NewString("default/svc1"),
+				ActiveNodes:    utilsets.NewString("node1"),
+			},
+		},
+	}
+	az.nodesWithCorrectLoadBalancerByPrimaryVMSet.Store("node1", struct{}{})
+
+	c, _ := newTestMultipleStandardLoadBalancerConfigurationController(t, az,
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb2", "vmss2", nil),
+	)
+	assert.NoError(t, c.sync())
+	assert.Len(t, az.MultipleStandardLoadBalancerConfigurations, 2)
+	assert.Equal(t, testClusterName, az.MultipleStandardLoadBalancerConfigurations[0].Name)
+	assert.True(t, az.MultipleStandardLoadBalancerConfigurations[0].ActiveNodes.Has("node1"))
+	assert.Equal(t, "lb2", az.MultipleStandardLoadBalancerConfigurations[1].Name)
+	_, ok := az.nodesWithCorrectLoadBalancerByPrimaryVMSet.Load("node1")
+	assert.False(t, ok)
+
+	// The configurations with duplicated primary vmSets are rejected.
+	c, _ = newTestMultipleStandardLoadBalancerConfigurationController(t, az,
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb2", "vmss2", nil),
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb3", "vmss2", nil),
+	)
+	c.fileConfigurations = c.fileConfigurations[:1]
+	assert.Error(t, c.sync())
+	assert.Len(t, az.MultipleStandardLoadBalancerConfigurations, 2)
+}
+
+func TestMultipleStandardLoadBalancerConfigurationControllerSyncWithoutFileConfigurations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	az.MultipleStandardLoadBalancerConfigurations = nil
+	assert.False(t, az.UseMultipleStandardLoadBalancers())
+
+	// The multiple standard load balancers are enabled by the custom resources.
+	c, _ := newTestMultipleStandardLoadBalancerConfigurationController(t, az,
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb1", "vmss1", nil),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.ensureBackendPoolUpdater(ctx)
+	assert.NotNil(t, az.backendPoolUpdater)
+	assert.Equal(t, consts.DefaultLoadBalancerBackendPoolUpdateIntervalInSeconds, az.LoadBalancerBackendPoolUpdateIntervalInSeconds)
+	assert.NoError(t, c.sync())
+	assert.True(t, az.UseMultipleStandardLoadBalancers())
+	assert.Len(t, az.getMultipleStandardLoadBalancerConfigurations(), 1)
+
+	// The configurations without primary vmSet are rejected as the ones in the cloud config file.
+	c, _ = newTestMultipleStandardLoadBalancerConfigurationController(t, az,
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb1", "vmss1", nil),
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb2", "", nil),
+	)
+	assert.Error(t, c.sync())
+	assert.Len(t, az.getMultipleStandardLoadBalancerConfigurations(), 1)
+
+	// The backend pool type nodeIPConfiguration is not supported.
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIPConfiguration
+	c, _ = newTestMultipleStandardLoadBalancerConfigurationController(t, az,
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb1", "vmss1", nil),
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb2", "vmss2", nil),
+	)
+	assert.Error(t, c.sync())
+	assert.Len(t, az.getMultipleStandardLoadBalancerConfigurations(), 1)
+}
+
+func TestMultipleStandardLoadBalancerConfigurationControllerUpdateStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	az.MultipleStandardLoadBalancerConfigurations = []config.MultipleStandardLoadBalancerConfiguration{
+		{
+			Name: "lb1",
+			MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+				ActiveServices: utilsets.NewString("default/svc2", "default/svc1"),
+				ActiveNodes:    utilsets.NewString("node1"),
+			},
+		},
+		{
+			Name: "lb2",
+			MultipleStandardLoadBalancerConfigurationStatus: config.MultipleStandardLoadBalancerConfigurationStatus{
+				ActiveNodes: utilsets.NewString("node2"),
+			},
+		},
+	}
+
+	c, client := newTestMultipleStandardLoadBalancerConfigurationController(t, az,
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb1", "vmss1", nil),
+		getTestMultipleStandardLoadBalancerConfigurationResource(t, "lb2", "vmss2", &config.MultipleStandardLoadBalancerConfigurationResourceStatus{
+			ActiveNodes: []string{"node2"},
+		}),
+	)
+	assert.NoError(t, c.updateStatus(context.Background()))
+
+	obj, err := client.Resource(config.MultipleStandardLoadBalancerConfigurationGVR).Get(context.Background(), "lb1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	services, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "activeServices")
+	assert.Equal(t, []string{"default/svc1", "default/svc2"}, services)
+	nodes, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "activeNodes")
+	assert.Equal(t, []string{"node1"}, nodes)
+
+	// The resource with the up-to-date status is not updated.
+	updates := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			updates++
+		}
+	}
+	assert.Equal(t, 1, updates)
+}
@@ -22,6 +22,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -91,7 +94,7 @@ func (az *Cloud) reconcilePrivateLinkService(
 		}
 
 		// Secondly, check if there is a private link service already created
-		existingPLS, err := az.plsRepo.Get(ctx, az.getPLSResourceGroup(service), *fipConfigID, azcache.CacheReadTypeDefault)
+		existingPLS, err := az.getPrivateLinkService(ctx, az.getPLSResourceGroup(service), *fipConfigID, azcache.CacheReadTypeDefault)
 		if err != nil {
 			klog.Errorf("reconcilePrivateLinkService for service(%s): getPrivateLinkService(%s) failed: %v", serviceName, ptr.Deref(fipConfigID, ""), err)
 			return err
@@ -158,14 +161,26 @@ func (az *Cloud) reconcilePrivateLinkService(
 				return err
 			}
 			existingPLS.Etag = ptr.To("")
-			_, err = az.plsRepo.CreateOrUpdate(ctx, az.getPLSResourceGroup(service), *existingPLS)
+			err = az.createOrUpdatePrivateLinkService(ctx, az.getPLSResourceGroup(service), existingPLS)
 			if err != nil {
 				klog.Errorf("reconcilePrivateLinkService for service(%s) abort backoff: pls(%s) - updating: %s", serviceName, plsName, err.Error())
 				return err
 			}
 		}
+
+		// The private endpoint connections are retried by the resync, so the failures do not fail the service.
+		if err := az.reconcilePLSConnections(ctx, service, existingPLS, ptr.Deref(fipConfigID, "")); err != nil {
+			klog.Errorf("reconcilePrivateLinkService for service(%s): pls(%s) - reconciling private endpoint connections: %s", serviceName, plsName, err.Error())
+			az.Event(service, v1.EventTypeWarning, "ReconcilePrivateEndpointConnectionsFailed", err.Error())
+		}
+		az.plsConnectionTargets.Store(serviceName, &plsConnectionTarget{
+			resourceGroup: az.getPLSResourceGroup(service),
+			fipConfigID:   ptr.Deref(fipConfigID, ""),
+		})
 	} else if !wantPLS {
-		existingPLS, err := az.plsRepo.Get(ctx, az.getPLSResourceGroup(service), *fipConfigID, azcache.CacheReadTypeDefault)
+		az.plsConnectionTargets.Delete(serviceName)
+
+		existingPLS, err := az.getPrivateLinkService(ctx, az.getPLSResourceGroup(service), *fipConfigID, azcache.CacheReadTypeDefault)
 		if err != nil {
 			klog.Errorf("reconcilePrivateLinkService for service(%s): getPrivateLinkService(%s) failed: %v", serviceName, ptr.Deref(fipConfigID, ""), err)
 			return err
@@ -178,6 +193,11 @@ func (az *Cloud) reconcilePrivateLinkService(
 				klog.Errorf("reconcilePrivateLinkService for service(%s): deletePLS for frontEnd(%s) failed: %v", serviceName, ptr.Deref(fipConfigID, ""), err)
 				return deleteErr
 			}
+			if !isLoadBalancerPlanContext(ctx) {
+				if err := az.removePLSAnnotations(ctx, service); err != nil {
+					klog.Warningf("reconcilePrivateLinkService for service(%s): failed to remove the private link service annotations: %v", serviceName, err)
+				}
+			}
 		}
 	}
 
@@ -198,23 +218,36 @@ func (az *Cloud) getPLSResourceGroup(service *v1.Service) string {
 }
 
 func (az *Cloud) disablePLSNetworkPolicy(ctx context.Context, service *v1.Service) error {
-	serviceName := getServiceName(service)
-	subnetName := getPLSSubnetName(service)
-	if subnetName == nil {
-		subnetName = &az.SubnetName
+	// The subnet is not part of the plan.
+	if isLoadBalancerPlanContext(ctx) {
+		return nil
 	}
 
+	subnetNames := getPLSSubnetNames(service)
+	if len(subnetNames) == 0 {
+		subnetNames = []string{az.SubnetName}
+	}
+	for _, subnetName := range subnetNames {
+		if err := az.disablePLSSubnetNetworkPolicy(ctx, service, subnetName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (az *Cloud) disablePLSSubnetNetworkPolicy(ctx context.Context, service *v1.Service, subnetName string) error {
+	serviceName := getServiceName(service)
 	rg := az.VnetResourceGroup
 	if rg == "" {
 		rg = az.ResourceGroup
 	}
 
-	subnet, err := az.subnetRepo.Get(ctx, rg, az.VnetName, *subnetName)
+	subnet, err := az.subnetRepo.Get(ctx, rg, az.VnetName, subnetName)
 	if err != nil {
 		var respErr *azcore.ResponseError
 		if errors.As(err, &respErr) {
 			if respErr != nil && respErr.StatusCode == http.StatusNotFound {
-				return fmt.Errorf("disablePLSNetworkPolicy: failed to get private link service subnet(%s) for service(%s)", *subnetName, serviceName)
+				return fmt.Errorf("disablePLSNetworkPolicy: failed to get private link service subnet(%s) for service(%s)", subnetName, serviceName)
 			}
 		}
 		return err
@@ -229,7 +262,7 @@ func (az *Cloud) disablePLSNetworkPolicy(ctx context.Context, service *v1.Servic
 	}
 
 	subnet.Properties.PrivateLinkServiceNetworkPolicies = to.Ptr(armnetwork.VirtualNetworkPrivateLinkServiceNetworkPoliciesDisabled)
-	err = az.subnetRepo.CreateOrUpdate(ctx, rg, az.VnetName, *subnetName, *subnet)
+	err = az.subnetRepo.CreateOrUpdate(ctx, rg, az.VnetName, subnetName, *subnet)
 	if err != nil {
 		return err
 	}
@@ -241,6 +274,12 @@ func (az *Cloud) safeDeletePLS(ctx context.Context, pls *armnetwork.PrivateLinkS
 		return nil
 	}
 
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return recorder.record(LoadBalancerPlanResourceTypePrivateLinkService, az.getPLSResourceGroup(service), ptr.Deref(pls.Name, ""), nil, func() (interface{}, error) {
+			return pls, nil
+		})
+	}
+
 	peConns := pls.Properties.PrivateEndpointConnections
 	for _, peConn := range peConns {
 		klog.V(2).Infof("deletePLS: deleting PEConnection %s", ptr.Deref(peConn.Name, ""))
@@ -364,138 +403,230 @@ func (az *Cloud) reconcilePLSIpConfigs(
 	existingPLS *armnetwork.PrivateLinkService,
 	service *v1.Service,
 ) (bool, error) {
-	changed := false
 	serviceName := getServiceName(service)
 
-	subnetName := getPLSSubnetName(service)
-	if subnetName == nil {
-		subnetName = &az.SubnetName
+	subnetNames := getPLSSubnetNames(service)
+	if len(subnetNames) == 0 {
+		subnetNames = []string{az.SubnetName}
 	}
 	rg := az.VnetResourceGroup
 	if rg == "" {
 		rg = az.ResourceGroup
 	}
-	subnet, err := az.subnetRepo.Get(ctx, rg, az.VnetName, *subnetName)
-	if err != nil {
-		var runtimError *azcore.ResponseError
-		if errors.As(err, &runtimError) {
-			if runtimError != nil && runtimError.StatusCode == http.StatusNotFound {
-				return false, fmt.Errorf("checkAndUpdatePLSIPConfigs: failed to get private link service subnet(%s) for service(%s)", *subnetName, serviceName)
+	subnets := make([]*armnetwork.Subnet, 0, len(subnetNames))
+	for _, subnetName := range subnetNames {
+		subnet, err := az.subnetRepo.Get(ctx, rg, az.VnetName, subnetName)
+		if err != nil {
+			var runtimError *azcore.ResponseError
+			if errors.As(err, &runtimError) {
+				if runtimError != nil && runtimError.StatusCode == http.StatusNotFound {
+					return false, fmt.Errorf("checkAndUpdatePLSIPConfigs: failed to get private link service subnet(%s) for service(%s)", subnetName, serviceName)
+				}
 			}
+			return false, err
 		}
-		return false, err
+		subnets = append(subnets, subnet)
 	}
 
 	ipConfigCount, err := getPLSIPConfigCount(service)
 	if err != nil {
 		return false, err
 	}
-
-	staticIps, primaryIP, err := getPLSStaticIPs(service)
+	ipv6ConfigCount, err := getPLSIPv6ConfigCount(service)
 	if err != nil {
 		return false, err
 	}
-
-	if int(ipConfigCount) < len(staticIps) {
-		return false, fmt.Errorf("checkAndUpdatePLSIPConfigs: ipConfigCount(%d) must be no smaller than number of static IPs specified(%d)", ipConfigCount, len(staticIps))
+	staticIps, primaryIP, err := getPLSStaticIPs(service)
+	if err != nil {
+		return false, err
 	}
-
-	if existingPLS.Properties.IPConfigurations == nil {
-		existingPLS.Properties.IPConfigurations = []*armnetwork.PrivateLinkServiceIPConfiguration{}
-		changed = true
+	staticIPv4s, staticIPv6s := splitPLSStaticIPs(staticIps)
+	if err := validatePLSIPConfigCounts(ipConfigCount, ipv6ConfigCount, len(staticIPv4s), len(staticIPv6s)); err != nil {
+		return false, fmt.Errorf("checkAndUpdatePLSIPConfigs: %w", err)
 	}
 
-	if int32(len(existingPLS.Properties.IPConfigurations)) != ipConfigCount {
-		changed = true
+	expectedIPConfigs, err := getExpectedPLSIpConfigs(ptr.Deref(existingPLS.Name, ""), subnets, ipConfigCount, ipv6ConfigCount, staticIPv4s, staticIPv6s, primaryIP)
+	if err != nil {
+		return false, fmt.Errorf("checkAndUpdatePLSIPConfigs: %w", err)
 	}
 
-	existingStaticIps := make([]string, 0)
+	// The names of the existing IP configurations are ignored, so that the configurations are only
+	// recreated if the subnets, the allocation methods or the addresses drift.
+	existingKeys := make(map[string]int)
 	for _, ipConfig := range existingPLS.Properties.IPConfigurations {
-		if !strings.EqualFold(ptr.Deref(subnet.ID, ""), ptr.Deref(ipConfig.Properties.Subnet.ID, "")) {
+		existingKeys[getPLSIpConfigKey(ipConfig)]++
+	}
+	changed := existingPLS.Properties.IPConfigurations == nil || len(existingPLS.Properties.IPConfigurations) != len(expectedIPConfigs)
+	for _, ipConfig := range expectedIPConfigs {
+		key := getPLSIpConfigKey(ipConfig)
+		if existingKeys[key] == 0 {
+			klog.V(10).Infof("reconcilePLSIpConfigs for service(%s): ipConfig %s not found", serviceName, key)
 			changed = true
+			break
 		}
-		if *ipConfig.Properties.PrivateIPAllocationMethod == armnetwork.IPAllocationMethodStatic {
-			klog.V(10).Infof("Found static IP: %s", ptr.Deref(ipConfig.Properties.PrivateIPAddress, ""))
-			if _, found := staticIps[ptr.Deref(ipConfig.Properties.PrivateIPAddress, "")]; !found {
-				changed = true
+		existingKeys[key]--
+	}
+
+	if changed {
+		existingPLS.Properties.IPConfigurations = expectedIPConfigs
+	}
+	return changed, nil
+}
+
+// getExpectedPLSIpConfigs returns the IP configurations of the PLS. The static IPs are put in the subnets containing
+// them, and the dynamic IPs are spread across the subnets of the same IP family in a round-robin manner.
+func getExpectedPLSIpConfigs(
+	plsName string,
+	subnets []*armnetwork.Subnet,
+	ipConfigCount, ipv6ConfigCount int32,
+	staticIPv4s, staticIPv6s []string,
+	primaryIP string,
+) ([]*armnetwork.PrivateLinkServiceIPConfiguration, error) {
+	getFrontendIPConfigName := func(subnet *armnetwork.Subnet, suffix string) (string, error) {
+		// frontend ipConfig name length cannot exceed 80
+		maxPrefixLen := consts.FrontendIPConfigNameMaxLength - len(suffix)
+		if maxPrefixLen <= 0 {
+			return "", fmt.Errorf("reconcilePLSIpConfigs: frontend ipConfig suffix %s is too long (not likely to happen)", suffix)
+		}
+		prefix := fmt.Sprintf("%s-%s", ptr.Deref(subnet.Name, ""), plsName)
+		if len(prefix) > maxPrefixLen {
+			prefix = prefix[:maxPrefixLen]
+		}
+		return prefix + suffix, nil
+	}
+	newIPConfig := func(subnet *armnetwork.Subnet, suffix, ip string, isIPv6, isPrimary bool) (*armnetwork.PrivateLinkServiceIPConfiguration, error) {
+		configName, err := getFrontendIPConfigName(subnet, suffix)
+		if err != nil {
+			return nil, err
+		}
+		ipConfig := &armnetwork.PrivateLinkServiceIPConfiguration{
+			Name: &configName,
+			Properties: &armnetwork.PrivateLinkServiceIPConfigurationProperties{
+				PrivateIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodDynamic),
+				Subnet: &armnetwork.Subnet{
+					ID: subnet.ID,
+				},
+				Primary:                 ptr.To(isPrimary),
+				PrivateIPAddressVersion: to.Ptr(armnetwork.IPVersionIPv4),
+			},
+		}
+		if ip != "" {
+			ipConfig.Properties.PrivateIPAddress = ptr.To(ip)
+			ipConfig.Properties.PrivateIPAllocationMethod = to.Ptr(armnetwork.IPAllocationMethodStatic)
+		}
+		if isIPv6 {
+			ipConfig.Properties.PrivateIPAddressVersion = to.Ptr(armnetwork.IPVersionIPv6)
+		}
+		return ipConfig, nil
+	}
+
+	var ipConfigs []*armnetwork.PrivateLinkServiceIPConfiguration
+	for _, family := range []struct {
+		isIPv6    bool
+		count     int32
+		staticIPs []string
+	}{
+		{isIPv6: false, count: ipConfigCount, staticIPs: staticIPv4s},
+		{isIPv6: true, count: ipv6ConfigCount, staticIPs: staticIPv6s},
+	} {
+		if family.count == 0 {
+			continue
+		}
+		familySubnets := make([]*armnetwork.Subnet, 0, len(subnets))
+		for _, subnet := range subnets {
+			if subnetHasIPFamily(subnet, family.isIPv6) {
+				familySubnets = append(familySubnets, subnet)
 			}
-			existingStaticIps = append(existingStaticIps, ptr.Deref(ipConfig.Properties.PrivateIPAddress, ""))
 		}
-		if *ipConfig.Properties.Primary {
-			if *ipConfig.Properties.PrivateIPAllocationMethod == armnetwork.IPAllocationMethodStatic {
-				if !strings.EqualFold(primaryIP, ptr.Deref(ipConfig.Properties.PrivateIPAddress, "")) {
-					changed = true
+		if len(familySubnets) == 0 {
+			return nil, fmt.Errorf("none of the private link service subnets has an address prefix of IP family (isIPv6=%t)", family.isIPv6)
+		}
+
+		for _, ip := range family.staticIPs {
+			subnet := familySubnets[0]
+			if len(familySubnets) > 1 {
+				subnet = nil
+				for _, candidate := range familySubnets {
+					if ipInSubnet(ip, candidate) {
+						subnet = candidate
+						break
+					}
 				}
-			} else {
-				// Dynamic
-				if primaryIP != "" {
-					changed = true
+				if subnet == nil {
+					return nil, fmt.Errorf("static IP %s is not in any of the private link service subnets", ip)
 				}
 			}
+			suffix := fmt.Sprintf("-static-%s", strings.ReplaceAll(ip, ":", "-"))
+			ipConfig, err := newIPConfig(subnet, suffix, ip, family.isIPv6, strings.EqualFold(ip, primaryIP))
+			if err != nil {
+				return nil, err
+			}
+			ipConfigs = append(ipConfigs, ipConfig)
 		}
-	}
-	if len(existingStaticIps) != len(staticIps) {
-		changed = true
-	}
 
-	if changed {
-		getFrontendIPConfigName := func(suffix string) (string, error) {
-			// frontend ipConfig name length cannot exceed 80
-			maxPrefixLen := consts.FrontendIPConfigNameMaxLength - len(suffix)
-			if maxPrefixLen <= 0 {
-				return "", fmt.Errorf("reconcilePLSIpConfigs: frontend ipConfig suffix %s is too long (not likely to happen)", suffix)
+		dynamicIndexes := make([]int, len(familySubnets))
+		for i := 0; i < int(family.count)-len(family.staticIPs); i++ {
+			subnetIndex := i % len(familySubnets)
+			isPrimary := !family.isIPv6 && primaryIP == "" && i == 0
+			suffix := fmt.Sprintf("-dynamic-%d", dynamicIndexes[subnetIndex])
+			if family.isIPv6 {
+				suffix = fmt.Sprintf("-dynamic-ipv6-%d", dynamicIndexes[subnetIndex])
 			}
-			prefix := fmt.Sprintf("%s-%s", ptr.Deref(subnet.Name, ""), ptr.Deref(existingPLS.Name, ""))
-			if len(prefix) > maxPrefixLen {
-				prefix = prefix[:maxPrefixLen]
+			dynamicIndexes[subnetIndex]++
+			ipConfig, err := newIPConfig(familySubnets[subnetIndex], suffix, "", family.isIPv6, isPrimary)
+			if err != nil {
+				return nil, err
 			}
-			return prefix + suffix, nil
+			ipConfigs = append(ipConfigs, ipConfig)
 		}
+	}
+	return ipConfigs, nil
+}
 
-		var ipConfigs []*armnetwork.PrivateLinkServiceIPConfiguration
-		for k := range staticIps {
-			ip := k
-			isPrimary := strings.EqualFold(ip, primaryIP)
-			suffix := fmt.Sprintf("-static-%s", ip)
-			configName, err := getFrontendIPConfigName(suffix)
-			if err != nil {
-				return false, err
-			}
-			ipConfigs = append(ipConfigs, &armnetwork.PrivateLinkServiceIPConfiguration{
-				Name: &configName,
-				Properties: &armnetwork.PrivateLinkServiceIPConfigurationProperties{
-					PrivateIPAddress:          &ip,
-					PrivateIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
-					Subnet: &armnetwork.Subnet{
-						ID: subnet.ID,
-					},
-					Primary:                 &isPrimary,
-					PrivateIPAddressVersion: to.Ptr(armnetwork.IPVersionIPv4),
-				},
-			})
+// getPLSIpConfigKey returns the properties of the IP configuration which are compared to detect the drift.
+func getPLSIpConfigKey(ipConfig *armnetwork.PrivateLinkServiceIPConfiguration) string {
+	if ipConfig == nil || ipConfig.Properties == nil {
+		return ""
+	}
+	props := ipConfig.Properties
+	subnetID := ""
+	if props.Subnet != nil {
+		subnetID = strings.ToLower(ptr.Deref(props.Subnet.ID, ""))
+	}
+	method := ptr.Deref(props.PrivateIPAllocationMethod, armnetwork.IPAllocationMethodDynamic)
+	ip := ""
+	if method == armnetwork.IPAllocationMethodStatic {
+		ip = strings.ToLower(ptr.Deref(props.PrivateIPAddress, ""))
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%t",
+		subnetID,
+		method,
+		ip,
+		ptr.Deref(props.PrivateIPAddressVersion, armnetwork.IPVersionIPv4),
+		ptr.Deref(props.Primary, false),
+	)
+}
+
+// subnetHasIPFamily returns true if the subnet has an address prefix of the IP family. The subnet
+// without known address prefixes is considered as an IPv4 subnet.
+func subnetHasIPFamily(subnet *armnetwork.Subnet, isIPv6 bool) bool {
+	if subnet.Properties == nil || (subnet.Properties.AddressPrefix == nil && len(subnet.Properties.AddressPrefixes) == 0) {
+		return !isIPv6
+	}
+	cidrs := append([]*string{subnet.Properties.AddressPrefix}, subnet.Properties.AddressPrefixes...)
+	for _, cidr := range cidrs {
+		if cidr == nil {
+			continue
 		}
-		for i := 0; i < int(ipConfigCount)-len(staticIps); i++ {
-			isPrimary := primaryIP == "" && i == 0
-			suffix := fmt.Sprintf("-dynamic-%d", i)
-			configName, err := getFrontendIPConfigName(suffix)
-			if err != nil {
-				return false, err
-			}
-			ipConfigs = append(ipConfigs, &armnetwork.PrivateLinkServiceIPConfiguration{
-				Name: &configName,
-				Properties: &armnetwork.PrivateLinkServiceIPConfigurationProperties{
-					PrivateIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodDynamic),
-					Subnet: &armnetwork.Subnet{
-						ID: subnet.ID,
-					},
-					Primary:                 &isPrimary,
-					PrivateIPAddressVersion: to.Ptr(armnetwork.IPVersionIPv4),
-				},
-			})
+		prefix, err := netip.ParsePrefix(*cidr)
+		if err != nil {
+			continue
+		}
+		if prefix.Addr().Is6() == isIPv6 {
+			return true
 		}
-		existingPLS.Properties.IPConfigurations = ipConfigs
 	}
-	return changed, nil
+	return false
 }
 
 func serviceRequiresPLS(service *v1.Service) bool {
@@ -612,14 +743,21 @@ func (az *Cloud) reconcilePLSTags(
 	return changed
 }
 
-func getPLSSubnetName(service *v1.Service) *string {
+// getPLSSubnetNames returns the subnets of the NAT IP configurations of the PLS, or nil if the default subnet is used.
+func getPLSSubnetNames(service *v1.Service) []string {
 	if l, found := service.Annotations[consts.ServiceAnnotationPLSIpConfigurationSubnet]; found && strings.TrimSpace(l) != "" {
-		return &l
+		var subnetNames []string
+		for _, name := range strings.Fields(l) {
+			if !slices.Contains(subnetNames, name) {
+				subnetNames = append(subnetNames, name)
+			}
+		}
+		return subnetNames
 	}
 
 	if requiresInternalLoadBalancer(service) {
 		if l, found := service.Annotations[consts.ServiceAnnotationLoadBalancerInternalSubnet]; found && strings.TrimSpace(l) != "" {
-			return &l
+			return []string{l}
 		}
 	}
 
@@ -631,15 +769,12 @@ func getPLSIPConfigCount(service *v1.Service) (int32, error) {
 		service.Annotations,
 		consts.ServiceAnnotationPLSIpConfigurationIPAddressCount,
 		func(val *int32) error {
-			const (
-				MinimumNumOfIPConfig = 1
-				MaximumNumOfIPConfig = 8
-			)
+			const MinimumNumOfIPConfig = 1
 			if *val < MinimumNumOfIPConfig {
 				return fmt.Errorf("minimum number of private link service ipConfig is %d, %d provided", MinimumNumOfIPConfig, *val)
 			}
-			if *val > MaximumNumOfIPConfig {
-				return fmt.Errorf("maximum number of private link service ipConfig is %d, %d provided", MaximumNumOfIPConfig, *val)
+			if *val > consts.PLSMaximumNumOfIPConfig {
+				return fmt.Errorf("maximum number of private link service ipConfig is %d, %d provided", consts.PLSMaximumNumOfIPConfig, *val)
 			}
 			return nil
 		},
@@ -653,6 +788,41 @@ func getPLSIPConfigCount(service *v1.Service) (int32, error) {
 	return consts.PLSDefaultNumOfIPConfig, nil
 }
 
+// getPLSIPv6ConfigCount returns the number of IPv6 NAT IP configurations of the PLS, which is 0 by default.
+func getPLSIPv6ConfigCount(service *v1.Service) (int32, error) {
+	ipConfigCnt, err := consts.Getint32ValueFromK8sSvcAnnotation(
+		service.Annotations,
+		consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount,
+		func(val *int32) error {
+			if *val < 0 {
+				return fmt.Errorf("number of private link service IPv6 ipConfig must not be negative, %d provided", *val)
+			}
+			if *val > consts.PLSMaximumNumOfIPConfig {
+				return fmt.Errorf("maximum number of private link service ipConfig is %d, %d provided", consts.PLSMaximumNumOfIPConfig, *val)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return ptr.Deref(ipConfigCnt, 0), nil
+}
+
+// validatePLSIPConfigCounts checks the numbers of the NAT IP configurations against the static IPs and the PLS limit.
+func validatePLSIPConfigCounts(ipConfigCount, ipv6ConfigCount int32, staticIPv4Count, staticIPv6Count int) error {
+	if int(ipConfigCount) < staticIPv4Count {
+		return fmt.Errorf("ipConfigCount(%d) must be no smaller than number of static IPs specified(%d)", ipConfigCount, staticIPv4Count)
+	}
+	if int(ipv6ConfigCount) < staticIPv6Count {
+		return fmt.Errorf("ipv6IPConfigCount(%d) must be no smaller than number of static IPv6 IPs specified(%d)", ipv6ConfigCount, staticIPv6Count)
+	}
+	if ipConfigCount+ipv6ConfigCount > consts.PLSMaximumNumOfIPConfig {
+		return fmt.Errorf("maximum number of private link service ipConfig is %d, %d IPv4 and %d IPv6 provided", consts.PLSMaximumNumOfIPConfig, ipConfigCount, ipv6ConfigCount)
+	}
+	return nil
+}
+
 func getPLSFqdns(service *v1.Service) []string {
 	fqdns := make([]string, 0)
 	if v, ok := service.Annotations[consts.ServiceAnnotationPLSFqdns]; ok {
@@ -718,12 +888,9 @@ func getPLSStaticIPs(service *v1.Service) (map[string]bool, string, error) {
 				return nil, "", fmt.Errorf("getPLSStaticIPs: %s is not a valid IP address", ip)
 			}
 
-			if parsedIP.To4() == nil {
-				return nil, "", fmt.Errorf("getPLSStaticIPs: private link service ip config only supports IPv4, %s provided", ip)
-			}
-
 			result[ip] = true
-			if primaryIP == "" {
+			// the primary ip config must be IPv4
+			if primaryIP == "" && parsedIP.To4() != nil {
 				primaryIP = ip
 			}
 		}
@@ -732,6 +899,21 @@ func getPLSStaticIPs(service *v1.Service) (map[string]bool, string, error) {
 	return result, primaryIP, nil
 }
 
+// splitPLSStaticIPs returns the sorted static IPv4 and IPv6 addresses.
+func splitPLSStaticIPs(staticIPs map[string]bool) ([]string, []string) {
+	var ipv4s, ipv6s []string
+	for ip := range staticIPs {
+		if net.ParseIP(ip).To4() != nil {
+			ipv4s = append(ipv4s, ip)
+		} else {
+			ipv6s = append(ipv6s, ip)
+		}
+	}
+	sort.Strings(ipv4s)
+	sort.Strings(ipv6s)
+	return ipv4s, ipv6s
+}
+
 func isManagedPrivateLinkSerivce(existingPLS *armnetwork.PrivateLinkService, clusterName string) bool {
 	tags := existingPLS.Tags
 	v, ok := tags[consts.ClusterNameTagKey]
@@ -754,11 +936,15 @@ func serviceHasAdditionalConfigs(service *v1.Service) bool {
 		consts.ServiceAnnotationPLSName,
 		consts.ServiceAnnotationPLSIpConfigurationSubnet,
 		consts.ServiceAnnotationPLSIpConfigurationIPAddressCount,
+		consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount,
 		consts.ServiceAnnotationPLSIpConfigurationIPAddress,
 		consts.ServiceAnnotationPLSFqdns,
 		consts.ServiceAnnotationPLSProxyProtocol,
 		consts.ServiceAnnotationPLSVisibility,
-		consts.ServiceAnnotationPLSAutoApproval}
+		consts.ServiceAnnotationPLSAutoApproval,
+		consts.ServiceAnnotationPLSConnectionApprovalSubscriptions,
+		consts.ServiceAnnotationPLSConnectionRejectionMessage,
+		consts.ServiceAnnotationPLSMaxConnections}
 	for _, k := range tagKeyList {
 		if _, found := service.Annotations[k]; found {
 			return true
@@ -0,0 +1,342 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+// The states of the private endpoint connections.
+const (
+	plsConnectionStatusPending  = "Pending"
+	plsConnectionStatusApproved = "Approved"
+	plsConnectionStatusRejected = "Rejected"
+)
+
+// plsConnectionApprovalPolicy approves or rejects the pending private endpoint connections of a PLS.
+type plsConnectionApprovalPolicy struct {
+	// subscriptions allowed to connect to the PLS, all subscriptions are allowed if empty.
+	subscriptions map[string]bool
+	// maxConnections is the maximum number of approved connections, unlimited if nil.
+	maxConnections   *int32
+	rejectionMessage string
+}
+
+// plsConnection is the state of a private endpoint connection recorded on the service.
+type plsConnection struct {
+	Name            string `json:"name"`
+	PrivateEndpoint string `json:"privateEndpoint,omitempty"`
+	Status          string `json:"status"`
+	Description     string `json:"description,omitempty"`
+}
+
+// plsConnectionTarget is the PLS whose private endpoint connections are checked periodically.
+type plsConnectionTarget struct {
+	resourceGroup string
+	fipConfigID   string
+}
+
+// getPLSConnectionApprovalPolicy returns the approval policy defined by the service annotations,
+// or nil if the pending connections are left to the service owner.
+func getPLSConnectionApprovalPolicy(service *v1.Service) (*plsConnectionApprovalPolicy, error) {
+	subscriptions, hasSubscriptions := service.Annotations[consts.ServiceAnnotationPLSConnectionApprovalSubscriptions]
+	maxConnections, err := consts.Getint32ValueFromK8sSvcAnnotation(
+		service.Annotations,
+		consts.ServiceAnnotationPLSMaxConnections,
+		func(val *int32) error {
+			if *val < 0 {
+				return fmt.Errorf("maximum number of private endpoint connections must not be negative, %d provided", *val)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !hasSubscriptions && maxConnections == nil {
+		return nil, nil
+	}
+
+	policy := &plsConnectionApprovalPolicy{
+		subscriptions:    make(map[string]bool),
+		maxConnections:   maxConnections,
+		rejectionMessage: strings.TrimSpace(service.Annotations[consts.ServiceAnnotationPLSConnectionRejectionMessage]),
+	}
+	for _, sub := range strings.Fields(subscriptions) {
+		policy.subscriptions[strings.ToLower(sub)] = true
+	}
+	if policy.rejectionMessage == "" {
+		policy.rejectionMessage = fmt.Sprintf("Rejected by the connection approval policy of service %s", getServiceName(service))
+	}
+	return policy, nil
+}
+
+// review returns the status and the description of a pending connection from the private endpoint.
+func (p *plsConnectionApprovalPolicy) review(privateEndpointID string, approvedCount int) (string, string) {
+	if len(p.subscriptions) > 0 {
+		resourceID, err := arm.ParseResourceID(privateEndpointID)
+		if err != nil || !p.subscriptions[strings.ToLower(resourceID.SubscriptionID)] {
+			return plsConnectionStatusRejected, p.rejectionMessage
+		}
+	}
+	if p.maxConnections != nil && approvedCount >= int(*p.maxConnections) {
+		return plsConnectionStatusRejected, fmt.Sprintf("%s: the private link service has reached the maximum number of connections %d", p.rejectionMessage, *p.maxConnections)
+	}
+	return plsConnectionStatusApproved, "Approved by the connection approval policy"
+}
+
+// reconcilePLSConnections approves or rejects the pending private endpoint connections of the PLS owned by
+// the service according to its approval policy, and publishes the PLS and its connections on the service.
+func (az *Cloud) reconcilePLSConnections(ctx context.Context, service *v1.Service, pls *armnetwork.PrivateLinkService, fipConfigID string) error {
+	if isLoadBalancerPlanContext(ctx) || pls == nil || pls.Properties == nil {
+		return nil
+	}
+	serviceName := getServiceName(service)
+	resourceGroup := az.getPLSResourceGroup(service)
+	plsName := ptr.Deref(pls.Name, "")
+
+	policy, err := getPLSConnectionApprovalPolicy(service)
+	if err != nil {
+		return err
+	}
+
+	connections := make([]*armnetwork.PrivateEndpointConnection, 0, len(pls.Properties.PrivateEndpointConnections))
+	for _, conn := range pls.Properties.PrivateEndpointConnections {
+		if conn != nil && conn.Name != nil && conn.Properties != nil {
+			connections = append(connections, conn)
+		}
+	}
+	sort.Slice(connections, func(i, j int) bool {
+		return ptr.Deref(connections[i].Name, "") < ptr.Deref(connections[j].Name, "")
+	})
+
+	states := make([]plsConnection, 0, len(connections))
+	for _, conn := range connections {
+		state := plsConnection{Name: *conn.Name}
+		if conn.Properties.PrivateEndpoint != nil {
+			state.PrivateEndpoint = ptr.Deref(conn.Properties.PrivateEndpoint.ID, "")
+		}
+		if conn.Properties.PrivateLinkServiceConnectionState != nil {
+			state.Status = ptr.Deref(conn.Properties.PrivateLinkServiceConnectionState.Status, "")
+			state.Description = ptr.Deref(conn.Properties.PrivateLinkServiceConnectionState.Description, "")
+		}
+		states = append(states, state)
+	}
+
+	var errs []error
+	if policy != nil {
+		approvedCount := 0
+		for _, state := range states {
+			if strings.EqualFold(state.Status, plsConnectionStatusApproved) {
+				approvedCount++
+			}
+		}
+		for i := range states {
+			if !strings.EqualFold(states[i].Status, plsConnectionStatusPending) {
+				continue
+			}
+			status, description := policy.review(states[i].PrivateEndpoint, approvedCount)
+			klog.V(2).Infof("reconcilePLSConnections for service(%s): pls(%s) - %s connection %s from %s", serviceName, plsName, strings.ToLower(status), states[i].Name, states[i].PrivateEndpoint)
+			if _, err := az.plsRepo.UpdatePEConnection(ctx, resourceGroup, plsName, fipConfigID, armnetwork.PrivateEndpointConnection{
+				Name: ptr.To(states[i].Name),
+				Properties: &armnetwork.PrivateEndpointConnectionProperties{
+					PrivateLinkServiceConnectionState: &armnetwork.PrivateLinkServiceConnectionState{
+						Status:          ptr.To(status),
+						Description:     ptr.To(description),
+						ActionsRequired: ptr.To("None"),
+					},
+				},
+			}); err != nil {
+				klog.Errorf("reconcilePLSConnections for service(%s): pls(%s) - failed to update connection %s: %v", serviceName, plsName, states[i].Name, err)
+				errs = append(errs, err)
+				continue
+			}
+
+			states[i].Status = status
+			states[i].Description = description
+			if status == plsConnectionStatusApproved {
+				approvedCount++
+				az.Event(service, v1.EventTypeNormal, "PrivateEndpointConnectionApproved",
+					fmt.Sprintf("Approved private endpoint connection %s from %s", states[i].Name, states[i].PrivateEndpoint))
+			} else {
+				az.Event(service, v1.EventTypeWarning, "PrivateEndpointConnectionRejected",
+					fmt.Sprintf("Rejected private endpoint connection %s from %s: %s", states[i].Name, states[i].PrivateEndpoint, description))
+			}
+		}
+	}
+
+	annotations, err := getPLSAnnotations(pls, states)
+	if err == nil {
+		err = az.patchPLSAnnotations(ctx, service, annotations)
+	}
+	if err != nil {
+		klog.Errorf("reconcilePLSConnections for service(%s): failed to publish the private link service: %v", serviceName, err)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// getPLSAnnotations returns the annotations publishing the PLS and its private endpoint connections on the service.
+func getPLSAnnotations(pls *armnetwork.PrivateLinkService, states []plsConnection) (map[string]*string, error) {
+	var approved, pending int
+	for _, state := range states {
+		switch {
+		case strings.EqualFold(state.Status, plsConnectionStatusApproved):
+			approved++
+		case strings.EqualFold(state.Status, plsConnectionStatusPending):
+			pending++
+		}
+	}
+
+	annotations := map[string]*string{
+		consts.ServiceAnnotationPLSID:                      pls.ID,
+		consts.ServiceAnnotationPLSApprovedConnectionCount: ptr.To(strconv.Itoa(approved)),
+		consts.ServiceAnnotationPLSPendingConnectionCount:  ptr.To(strconv.Itoa(pending)),
+		consts.ServiceAnnotationPLSConnections:             nil,
+	}
+	if pls.Properties != nil {
+		annotations[consts.ServiceAnnotationPLSAlias] = pls.Properties.Alias
+	}
+	if len(states) > 0 {
+		b, err := json.Marshal(summarizePLSConnections(states))
+		if err != nil {
+			return nil, err
+		}
+		annotations[consts.ServiceAnnotationPLSConnections] = ptr.To(string(b))
+	}
+	return annotations, nil
+}
+
+// summarizePLSConnections returns at most PLSConnectionsAnnotationMaxEntries connections to be recorded on the
+// service, the pending ones first as they may need the action of the service owner.
+func summarizePLSConnections(states []plsConnection) []plsConnection {
+	if len(states) <= consts.PLSConnectionsAnnotationMaxEntries {
+		return states
+	}
+	summary := make([]plsConnection, 0, len(states))
+	for _, state := range states {
+		if strings.EqualFold(state.Status, plsConnectionStatusPending) {
+			summary = append(summary, state)
+		}
+	}
+	for _, state := range states {
+		if !strings.EqualFold(state.Status, plsConnectionStatusPending) {
+			summary = append(summary, state)
+		}
+	}
+	return summary[:consts.PLSConnectionsAnnotationMaxEntries]
+}
+
+// patchPLSAnnotations updates the annotations published by the cloud provider on the service if they changed.
+// The annotations with nil values are removed.
+func (az *Cloud) patchPLSAnnotations(ctx context.Context, service *v1.Service, annotations map[string]*string) error {
+	if az.KubeClient == nil {
+		return nil
+	}
+	changed := false
+	for key, value := range annotations {
+		current, found := service.Annotations[key]
+		if (value == nil && found) || (value != nil && (!found || current != *value)) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return az.patchServiceAnnotations(ctx, service, annotations)
+}
+
+// removePLSAnnotations removes the annotations publishing the PLS from the service.
+func (az *Cloud) removePLSAnnotations(ctx context.Context, service *v1.Service) error {
+	return az.patchPLSAnnotations(ctx, service, map[string]*string{
+		consts.ServiceAnnotationPLSAlias:                   nil,
+		consts.ServiceAnnotationPLSID:                      nil,
+		consts.ServiceAnnotationPLSApprovedConnectionCount: nil,
+		consts.ServiceAnnotationPLSPendingConnectionCount:  nil,
+		consts.ServiceAnnotationPLSConnections:             nil,
+	})
+}
+
+// runPLSConnectionResync periodically checks the private endpoint connections of the private link services.
+func (az *Cloud) runPLSConnectionResync(ctx context.Context) {
+	interval := time.Duration(az.PLSConnectionResyncIntervalInSeconds) * time.Second
+	klog.Infof("runPLSConnectionResync: started with interval %s", interval)
+	wait.UntilWithContext(ctx, az.resyncPLSConnections, interval)
+}
+
+// resyncPLSConnections reconciles the private endpoint connections of the private link services reconciled
+// by the services, so that the new connections are reviewed and published without waiting for the next
+// service update. The private link services are read from the cache to keep the refresh lightweight.
+func (az *Cloud) resyncPLSConnections(ctx context.Context) {
+	if az.serviceLister == nil {
+		return
+	}
+	az.plsConnectionTargets.Range(func(key, value interface{}) bool {
+		serviceName := key.(string)
+		target := value.(*plsConnectionTarget)
+		namespace, name, err := cache.SplitMetaNamespaceKey(serviceName)
+		if err != nil {
+			az.plsConnectionTargets.Delete(key)
+			return true
+		}
+		service, err := az.serviceLister.Services(namespace).Get(name)
+		if err != nil || !serviceRequiresPLS(service) {
+			az.plsConnectionTargets.Delete(key)
+			return true
+		}
+
+		// The pending connections are reviewed on the latest private link service. The connections of the services
+		// without an approval policy are only published, which can wait for the cache of the private link services to expire.
+		crt := azcache.CacheReadTypeDefault
+		if policy, err := getPLSConnectionApprovalPolicy(service); err == nil && policy != nil {
+			crt = azcache.CacheReadTypeForceRefresh
+		}
+		pls, err := az.plsRepo.Get(ctx, target.resourceGroup, target.fipConfigID, crt)
+		if err != nil {
+			klog.Errorf("resyncPLSConnections: failed to get the private link service of service %s: %v", serviceName, err)
+			return true
+		}
+		if strings.EqualFold(ptr.Deref(pls.ID, ""), consts.PrivateLinkServiceNotExistID) ||
+			!strings.EqualFold(getPrivateLinkServiceOwner(pls), serviceName) {
+			az.plsConnectionTargets.Delete(key)
+			return true
+		}
+		if err := az.reconcilePLSConnections(ctx, service, pls, target.fipConfigID); err != nil {
+			klog.Errorf("resyncPLSConnections: failed to reconcile the private endpoint connections of service %s: %v", serviceName, err)
+		}
+		return true
+	})
+}
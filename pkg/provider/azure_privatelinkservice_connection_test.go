@@ -0,0 +1,273 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/privatelinkservice"
+)
+
+const testPLSFrontendIPConfigID = "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb/frontendIPConfigurations/fip"
+
+func newTestPEConnection(name, subscription, status string) *armnetwork.PrivateEndpointConnection {
+	return &armnetwork.PrivateEndpointConnection{
+		Name: ptr.To(name),
+		Properties: &armnetwork.PrivateEndpointConnectionProperties{
+			PrivateEndpoint: &armnetwork.PrivateEndpoint{
+				ID: ptr.To("/subscriptions/" + subscription + "/resourceGroups/pe-rg/providers/Microsoft.Network/privateEndpoints/" + name),
+			},
+			PrivateLinkServiceConnectionState: &armnetwork.PrivateLinkServiceConnectionState{
+				Status: ptr.To(status),
+			},
+		},
+	}
+}
+
+func newTestPLSConnectionService(annotations map[string]string) *v1.Service {
+	annotations[consts.ServiceAnnotationPLSCreation] = "true"
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default", Annotations: annotations},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+}
+
+func TestGetPLSConnectionApprovalPolicy(t *testing.T) {
+	policy, err := getPLSConnectionApprovalPolicy(newTestPLSConnectionService(map[string]string{}))
+	assert.NoError(t, err)
+	assert.Nil(t, policy, "pending connections should be left to the owner without a policy")
+
+	_, err = getPLSConnectionApprovalPolicy(newTestPLSConnectionService(map[string]string{
+		consts.ServiceAnnotationPLSMaxConnections: "-1",
+	}))
+	assert.Error(t, err)
+
+	policy, err = getPLSConnectionApprovalPolicy(newTestPLSConnectionService(map[string]string{
+		consts.ServiceAnnotationPLSConnectionApprovalSubscriptions: " SUB1  sub2 ",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"sub1": true, "sub2": true}, policy.subscriptions)
+	assert.Nil(t, policy.maxConnections)
+	assert.Equal(t, "Rejected by the connection approval policy of service default/svc", policy.rejectionMessage)
+
+	status, _ := policy.review("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/privateEndpoints/pe", 0)
+	assert.Equal(t, plsConnectionStatusApproved, status)
+	status, _ = policy.review("/subscriptions/sub3/resourceGroups/rg/providers/Microsoft.Network/privateEndpoints/pe", 0)
+	assert.Equal(t, plsConnectionStatusRejected, status)
+	status, _ = policy.review("invalid", 0)
+	assert.Equal(t, plsConnectionStatusRejected, status)
+}
+
+func TestReconcilePLSConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	recorder := record.NewFakeRecorder(10)
+	az.eventRecorder = recorder
+	service := newTestPLSConnectionService(map[string]string{
+		consts.ServiceAnnotationPLSConnectionApprovalSubscriptions: "sub1",
+		consts.ServiceAnnotationPLSConnectionRejectionMessage:      "not allowed",
+		consts.ServiceAnnotationPLSMaxConnections:                  "2",
+	})
+	kubeClient := fake.NewSimpleClientset(service)
+	az.KubeClient = kubeClient
+
+	pls := &armnetwork.PrivateLinkService{
+		Name: ptr.To("pls"),
+		Properties: &armnetwork.PrivateLinkServiceProperties{
+			PrivateEndpointConnections: []*armnetwork.PrivateEndpointConnection{
+				newTestPEConnection("pe-d", "sub1", plsConnectionStatusPending),
+				newTestPEConnection("pe-a", "sub1", plsConnectionStatusApproved),
+				newTestPEConnection("pe-b", "sub2", plsConnectionStatusPending),
+				newTestPEConnection("pe-c", "sub1", plsConnectionStatusPending),
+			},
+		},
+	}
+
+	mockPLSRepo := az.plsRepo.(*privatelinkservice.MockRepository)
+	var updated []string
+	mockPLSRepo.EXPECT().UpdatePEConnection(gomock.Any(), az.PrivateLinkServiceResourceGroup, "pls", testPLSFrontendIPConfigID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, _ string, peConn armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error) {
+			updated = append(updated, *peConn.Name+"="+*peConn.Properties.PrivateLinkServiceConnectionState.Status)
+			return &peConn, nil
+		}).Times(3)
+
+	err := az.reconcilePLSConnections(context.Background(), service, pls, testPLSFrontendIPConfigID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pe-b=Rejected", "pe-c=Approved", "pe-d=Rejected"}, updated,
+		"connections from other subscriptions and beyond the limit should be rejected")
+	assert.Len(t, recorder.Events, 3)
+
+	svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "svc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	var states []plsConnection
+	assert.NoError(t, json.Unmarshal([]byte(svc.Annotations[consts.ServiceAnnotationPLSConnections]), &states))
+	assert.Len(t, states, 4)
+	for i, expected := range []string{"Approved", "Rejected", "Approved", "Rejected"} {
+		assert.Equal(t, expected, states[i].Status)
+	}
+	assert.Equal(t, "not allowed", states[1].Description)
+}
+
+func TestResyncPLSConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	service := newTestPLSConnectionService(map[string]string{})
+	kubeClient := fake.NewSimpleClientset(service)
+	az.KubeClient = kubeClient
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	az.serviceLister = informerFactory.Core().V1().Services().Lister()
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+
+	az.plsConnectionTargets.Store("default/svc", &plsConnectionTarget{resourceGroup: "rg", fipConfigID: testPLSFrontendIPConfigID})
+	az.plsConnectionTargets.Store("default/deleted", &plsConnectionTarget{resourceGroup: "rg", fipConfigID: testPLSFrontendIPConfigID})
+
+	mockPLSRepo := az.plsRepo.(*privatelinkservice.MockRepository)
+	mockPLSRepo.EXPECT().Get(gomock.Any(), "rg", testPLSFrontendIPConfigID, azcache.CacheReadTypeDefault).Return(&armnetwork.PrivateLinkService{
+		ID:   ptr.To("pls-id"),
+		Name: ptr.To("pls"),
+		Tags: map[string]*string{consts.OwnerServiceTagKey: ptr.To("default/svc")},
+		Properties: &armnetwork.PrivateLinkServiceProperties{
+			Alias: ptr.To("pls.guid.eastus.azure.privatelinkservice"),
+			PrivateEndpointConnections: []*armnetwork.PrivateEndpointConnection{
+				newTestPEConnection("pe", "sub1", plsConnectionStatusPending),
+				newTestPEConnection("pe-approved", "sub1", plsConnectionStatusApproved),
+			},
+		},
+	}, nil).Times(1)
+
+	az.resyncPLSConnections(context.Background())
+
+	svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "svc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, svc.Annotations[consts.ServiceAnnotationPLSConnections], `"status":"Pending"`,
+		"pending connections should be recorded but left to the owner without a policy")
+	assert.Equal(t, "pls.guid.eastus.azure.privatelinkservice", svc.Annotations[consts.ServiceAnnotationPLSAlias])
+	assert.Equal(t, "pls-id", svc.Annotations[consts.ServiceAnnotationPLSID])
+	assert.Equal(t, "1", svc.Annotations[consts.ServiceAnnotationPLSApprovedConnectionCount])
+	assert.Equal(t, "1", svc.Annotations[consts.ServiceAnnotationPLSPendingConnectionCount])
+	_, found := az.plsConnectionTargets.Load("default/deleted")
+	assert.False(t, found, "the deleted service should no longer be checked")
+}
+
+func TestResyncPLSConnectionsWithApprovalPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	service := newTestPLSConnectionService(map[string]string{
+		consts.ServiceAnnotationPLSConnectionApprovalSubscriptions: "sub1",
+	})
+	kubeClient := fake.NewSimpleClientset(service)
+	az.KubeClient = kubeClient
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	az.serviceLister = informerFactory.Core().V1().Services().Lister()
+	informerFactory.Start(wait.NeverStop)
+	informerFactory.WaitForCacheSync(wait.NeverStop)
+	az.plsConnectionTargets.Store("default/svc", &plsConnectionTarget{resourceGroup: "rg", fipConfigID: testPLSFrontendIPConfigID})
+
+	// The pending connections are reviewed on the latest private link service.
+	mockPLSRepo := az.plsRepo.(*privatelinkservice.MockRepository)
+	mockPLSRepo.EXPECT().Get(gomock.Any(), "rg", testPLSFrontendIPConfigID, azcache.CacheReadTypeForceRefresh).Return(&armnetwork.PrivateLinkService{
+		ID:   ptr.To("pls-id"),
+		Name: ptr.To("pls"),
+		Tags: map[string]*string{consts.OwnerServiceTagKey: ptr.To("default/svc")},
+		Properties: &armnetwork.PrivateLinkServiceProperties{
+			PrivateEndpointConnections: []*armnetwork.PrivateEndpointConnection{
+				newTestPEConnection("pe", "sub1", plsConnectionStatusPending),
+			},
+		},
+	}, nil).Times(1)
+	mockPLSRepo.EXPECT().UpdatePEConnection(gomock.Any(), "rg", "pls", testPLSFrontendIPConfigID, gomock.Any()).Return(nil, nil).Times(1)
+
+	az.resyncPLSConnections(context.Background())
+
+	svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "svc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", svc.Annotations[consts.ServiceAnnotationPLSApprovedConnectionCount])
+}
+
+func TestRemovePLSAnnotations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	service := newTestPLSConnectionService(map[string]string{
+		consts.ServiceAnnotationPLSAlias:                   "alias",
+		consts.ServiceAnnotationPLSID:                      "pls-id",
+		consts.ServiceAnnotationPLSApprovedConnectionCount: "1",
+		consts.ServiceAnnotationPLSPendingConnectionCount:  "0",
+	})
+	kubeClient := fake.NewSimpleClientset(service)
+	az.KubeClient = kubeClient
+
+	assert.NoError(t, az.removePLSAnnotations(context.Background(), service))
+
+	svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "svc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	for _, key := range []string{
+		consts.ServiceAnnotationPLSAlias,
+		consts.ServiceAnnotationPLSID,
+		consts.ServiceAnnotationPLSApprovedConnectionCount,
+		consts.ServiceAnnotationPLSPendingConnectionCount,
+	} {
+		assert.NotContains(t, svc.Annotations, key)
+	}
+	assert.Equal(t, "true", svc.Annotations[consts.ServiceAnnotationPLSCreation])
+}
+
+func TestSummarizePLSConnections(t *testing.T) {
+	var states []plsConnection
+	for i := 0; i < consts.PLSConnectionsAnnotationMaxEntries; i++ {
+		states = append(states, plsConnection{Name: fmt.Sprintf("approved-%d", i), Status: plsConnectionStatusApproved})
+	}
+	assert.Equal(t, states, summarizePLSConnections(states))
+
+	states = append(states, plsConnection{Name: "pending", Status: plsConnectionStatusPending})
+	summary := summarizePLSConnections(states)
+	assert.Len(t, summary, consts.PLSConnectionsAnnotationMaxEntries)
+	assert.Equal(t, "pending", summary[0].Name)
+	assert.Equal(t, "approved-0", summary[1].Name)
+
+	annotations, err := getPLSAnnotations(&armnetwork.PrivateLinkService{ID: ptr.To("pls-id")}, states)
+	assert.NoError(t, err)
+	assert.Equal(t, "20", *annotations[consts.ServiceAnnotationPLSApprovedConnectionCount])
+	assert.Equal(t, "1", *annotations[consts.ServiceAnnotationPLSPendingConnectionCount])
+	var recorded []plsConnection
+	assert.NoError(t, json.Unmarshal([]byte(*annotations[consts.ServiceAnnotationPLSConnections]), &recorded))
+	assert.Equal(t, summary, recorded)
+}
@@ -1024,6 +1024,156 @@ func TestReconcilePLSIpConfigs(t *testing.T) {
 	}
 }
 
+func TestReconcilePLSIpConfigsMultipleSubnets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newIPConfig := func(name, subnetID, ip string, version armnetwork.IPVersion, primary bool) *armnetwork.PrivateLinkServiceIPConfiguration {
+		ipConfig := &armnetwork.PrivateLinkServiceIPConfiguration{
+			Name: ptr.To(name),
+			Properties: &armnetwork.PrivateLinkServiceIPConfigurationProperties{
+				PrivateIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodDynamic),
+				Subnet:                    &armnetwork.Subnet{ID: ptr.To(subnetID)},
+				Primary:                   ptr.To(primary),
+				PrivateIPAddressVersion:   to.Ptr(version),
+			},
+		}
+		if ip != "" {
+			ipConfig.Properties.PrivateIPAddress = ptr.To(ip)
+			ipConfig.Properties.PrivateIPAllocationMethod = to.Ptr(armnetwork.IPAllocationMethodStatic)
+		}
+		return ipConfig
+	}
+	expectedIPConfigs := []*armnetwork.PrivateLinkServiceIPConfiguration{
+		newIPConfig("subnet-b-testpls-static-10.0.1.5", "subnetIDB", "10.0.1.5", armnetwork.IPVersionIPv4, true),
+		newIPConfig("subnet-a-testpls-dynamic-0", "subnetIDA", "", armnetwork.IPVersionIPv4, false),
+		newIPConfig("subnet-b-testpls-dynamic-0", "subnetIDB", "", armnetwork.IPVersionIPv4, false),
+		newIPConfig("subnet-a-testpls-static-fd00-a--5", "subnetIDA", "fd00:a::5", armnetwork.IPVersionIPv6, false),
+		newIPConfig("subnet-a-testpls-dynamic-ipv6-0", "subnetIDA", "", armnetwork.IPVersionIPv6, false),
+	}
+
+	for _, test := range []struct {
+		desc              string
+		annotations       map[string]string
+		existingIPConfigs []*armnetwork.PrivateLinkServiceIPConfiguration
+		expectedIPConfigs []*armnetwork.PrivateLinkServiceIPConfiguration
+		expectedChanged   bool
+		expectedErr       bool
+	}{
+		{
+			desc: "static IPs should be put in their subnets and dynamic IPs should be spread across the subnets of the same IP family",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPLSIpConfigurationSubnet:           "subnet-a subnet-b",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddressCount:   "3",
+				consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount: "2",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddress:        "fd00:a::5 10.0.1.5",
+			},
+			expectedIPConfigs: expectedIPConfigs,
+			expectedChanged:   true,
+		},
+		{
+			desc: "existing IP configurations with different names should not be changed",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPLSIpConfigurationSubnet:           "subnet-a subnet-b",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddressCount:   "3",
+				consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount: "2",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddress:        "fd00:a::5 10.0.1.5",
+			},
+			existingIPConfigs: []*armnetwork.PrivateLinkServiceIPConfiguration{
+				newIPConfig("ipv6-dynamic", "SUBNETIDA", "", armnetwork.IPVersionIPv6, false),
+				newIPConfig("dynamic-b", "subnetIDB", "", armnetwork.IPVersionIPv4, false),
+				newIPConfig("dynamic-a", "subnetIDA", "", armnetwork.IPVersionIPv4, false),
+				newIPConfig("ipv6-static", "subnetIDA", "fd00:a::5", armnetwork.IPVersionIPv6, false),
+				newIPConfig("static", "subnetIDB", "10.0.1.5", armnetwork.IPVersionIPv4, true),
+			},
+		},
+		{
+			desc: "removing a subnet should move the IP configurations",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPLSIpConfigurationSubnet:         "subnet-a",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddressCount: "2",
+			},
+			existingIPConfigs: []*armnetwork.PrivateLinkServiceIPConfiguration{
+				newIPConfig("subnet-a-testpls-dynamic-0", "subnetIDA", "", armnetwork.IPVersionIPv4, true),
+				newIPConfig("subnet-b-testpls-dynamic-0", "subnetIDB", "", armnetwork.IPVersionIPv4, false),
+			},
+			expectedIPConfigs: []*armnetwork.PrivateLinkServiceIPConfiguration{
+				newIPConfig("subnet-a-testpls-dynamic-0", "subnetIDA", "", armnetwork.IPVersionIPv4, true),
+				newIPConfig("subnet-a-testpls-dynamic-1", "subnetIDA", "", armnetwork.IPVersionIPv4, false),
+			},
+			expectedChanged: true,
+		},
+		{
+			desc: "static IP out of the subnets should report error",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPLSIpConfigurationSubnet:    "subnet-a subnet-b",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddress: "10.0.2.5",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "IPv6 IPs without IPv6 subnets should report error",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPLSIpConfigurationSubnet:           "subnet-b",
+				consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount: "1",
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "more than 8 IP configurations should report error",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPLSIpConfigurationSubnet:           "subnet-a",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddressCount:   "5",
+				consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount: "4",
+			},
+			expectedErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			cloud := GetTestCloud(ctrl)
+			service := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Name:        "service",
+					Annotations: test.annotations,
+				},
+			}
+			pls := &armnetwork.PrivateLinkService{
+				Name: ptr.To("testpls"),
+				Properties: &armnetwork.PrivateLinkServiceProperties{
+					IPConfigurations: test.existingIPConfigs,
+				},
+			}
+			subnetClient := cloud.subnetRepo.(*subnet.MockRepository)
+			subnetClient.EXPECT().Get(gomock.Any(), "rg", "vnet", "subnet-a").Return(&armnetwork.Subnet{
+				ID:   ptr.To("subnetIDA"),
+				Name: ptr.To("subnet-a"),
+				Properties: &armnetwork.SubnetPropertiesFormat{
+					AddressPrefixes: []*string{ptr.To("10.0.0.0/24"), ptr.To("fd00:a::/64")},
+				},
+			}, nil).AnyTimes()
+			subnetClient.EXPECT().Get(gomock.Any(), "rg", "vnet", "subnet-b").Return(&armnetwork.Subnet{
+				ID:   ptr.To("subnetIDB"),
+				Name: ptr.To("subnet-b"),
+				Properties: &armnetwork.SubnetPropertiesFormat{
+					AddressPrefix: ptr.To("10.0.1.0/24"),
+				},
+			}, nil).AnyTimes()
+
+			changed, err := cloud.reconcilePLSIpConfigs(context.TODO(), pls, service)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedChanged, changed)
+			if test.expectedChanged {
+				testSamePLSIpConfigs(t, pls.Properties.IPConfigurations, test.expectedIPConfigs)
+			}
+		})
+	}
+}
+
 func testSamePLSIpConfigs(t *testing.T, actual []*armnetwork.PrivateLinkServiceIPConfiguration, expected []*armnetwork.PrivateLinkServiceIPConfiguration) {
 	t.Helper()
 	actualIPConfigs := make(map[string]armnetwork.PrivateLinkServiceIPConfiguration)
@@ -1484,11 +1634,11 @@ func TestReconcilePLSTags(t *testing.T) {
 	})
 }
 
-func TestGetPLSSubnetName(t *testing.T) {
+func TestGetPLSSubnetNames(t *testing.T) {
 	tests := []struct {
-		desc           string
-		annotations    map[string]string
-		expectedSubnet *string
+		desc            string
+		annotations     map[string]string
+		expectedSubnets []string
 	}{
 		{
 			desc: "Service with nil annotations should return nil",
@@ -1501,7 +1651,14 @@ func TestGetPLSSubnetName(t *testing.T) {
 			annotations: map[string]string{
 				consts.ServiceAnnotationPLSIpConfigurationSubnet: "pls-subnet",
 			},
-			expectedSubnet: ptr.To("pls-subnet"),
+			expectedSubnets: []string{"pls-subnet"},
+		},
+		{
+			desc: "Service with multiple private link subnets specified should return them without duplicates",
+			annotations: map[string]string{
+				consts.ServiceAnnotationPLSIpConfigurationSubnet: " subnet-a  subnet-b subnet-a ",
+			},
+			expectedSubnets: []string{"subnet-a", "subnet-b"},
 		},
 		{
 			desc: "Service with empty private link subnet specified but LB subnet specified should return LB subnet",
@@ -1510,7 +1667,7 @@ func TestGetPLSSubnetName(t *testing.T) {
 				consts.ServiceAnnotationPLSIpConfigurationSubnet:   "",
 				consts.ServiceAnnotationLoadBalancerInternalSubnet: "lb-subnet",
 			},
-			expectedSubnet: ptr.To("lb-subnet"),
+			expectedSubnets: []string{"lb-subnet"},
 		},
 		{
 			desc: "Service with LB subnet specified should return it",
@@ -1518,7 +1675,7 @@ func TestGetPLSSubnetName(t *testing.T) {
 				consts.ServiceAnnotationLoadBalancerInternal:       "true",
 				consts.ServiceAnnotationLoadBalancerInternalSubnet: "lb-subnet",
 			},
-			expectedSubnet: ptr.To("lb-subnet"),
+			expectedSubnets: []string{"lb-subnet"},
 		},
 		{
 			desc: "Service with both empty subnets specified should return nil",
@@ -1532,8 +1689,8 @@ func TestGetPLSSubnetName(t *testing.T) {
 	for i, test := range tests {
 		s := &v1.Service{}
 		s.Annotations = test.annotations
-		actualSubnet := getPLSSubnetName(s)
-		assert.Equal(t, test.expectedSubnet, actualSubnet, "TestCase[%d]: %s", i, test.desc)
+		actualSubnets := getPLSSubnetNames(s)
+		assert.Equal(t, test.expectedSubnets, actualSubnets, "TestCase[%d]: %s", i, test.desc)
 	}
 }
 
@@ -1802,11 +1959,15 @@ func TestGetPLSStaticIPs(t *testing.T) {
 			expectedErr: true,
 		},
 		{
-			desc: "Service with ipv6 address should return error",
+			desc: "Service with ipv6 address should include it in map and set the first ipv4 address as primary ip",
 			annotations: map[string]string{
-				consts.ServiceAnnotationPLSIpConfigurationIPAddress: "fc00:f853:ccd:e793::1",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddress: "fc00:f853:ccd:e793::1 10.2.0.4",
 			},
-			expectedErr: true,
+			expectedIPs: map[string]bool{
+				"fc00:f853:ccd:e793::1": true,
+				"10.2.0.4":              true,
+			},
+			expectedPrimaryIP: "10.2.0.4",
 		},
 		{
 			desc: "All redundant spaces should be removed",
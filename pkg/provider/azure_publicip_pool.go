@@ -0,0 +1,511 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+const (
+	// The results of taking a public IP from the pool, which are used in the metrics.
+	publicIPPoolResultHit  = "hit"
+	publicIPPoolResultMiss = "miss"
+)
+
+var (
+	publicIPPoolAvailable = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "public_ip_pool_available",
+			Help:           "Number of unassigned public IPs in the pool found by the last refill",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"ip_family"},
+	)
+	publicIPPoolAcquisitions = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "public_ip_pool_acquisitions_total",
+			Help:           "Number of attempts to take a public IP from the pool for a new service",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"ip_family", "result"},
+	)
+	publicIPPoolOperations = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "public_ip_pool_operations_total",
+			Help:           "Number of public IPs created or deleted by the public IP pool controller",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"ip_family", "operation", "result"},
+	)
+	registerPublicIPPoolMetricsOnce sync.Once
+)
+
+func registerPublicIPPoolMetrics() {
+	registerPublicIPPoolMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(publicIPPoolAvailable)
+		legacyregistry.MustRegister(publicIPPoolAcquisitions)
+		legacyregistry.MustRegister(publicIPPoolOperations)
+	})
+}
+
+func getIPFamilyLabel(isIPv6 bool) string {
+	if isIPv6 {
+		return consts.IPVersionIPv6String
+	}
+	return consts.IPVersionIPv4String
+}
+
+func observePublicIPPoolOperation(isIPv6 bool, operation string, err error) {
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	publicIPPoolOperations.WithLabelValues(getIPFamilyLabel(isIPv6), operation, result).Inc()
+}
+
+// validatePublicIPPoolConfig checks the public IP pool configurations in the cloud config.
+func (az *Cloud) validatePublicIPPoolConfig() error {
+	if az.PublicIPPoolSize == 0 {
+		return nil
+	}
+	if az.PublicIPPoolSize < 0 || az.PublicIPPoolSize > consts.MaxPublicIPPoolSize {
+		return fmt.Errorf("publicIPPoolSize %d should be between 0 and %d", az.PublicIPPoolSize, consts.MaxPublicIPPoolSize)
+	}
+	if !az.UseStandardLoadBalancer() {
+		return fmt.Errorf("publicIPPoolSize can only be used with standard load balancer")
+	}
+	if az.HasExtendedLocation() {
+		return fmt.Errorf("publicIPPoolSize cannot be used with extended location")
+	}
+	for _, ipFamily := range az.PublicIPPoolIPFamilies {
+		if !strings.EqualFold(ipFamily, consts.IPVersionIPv4String) && !strings.EqualFold(ipFamily, consts.IPVersionIPv6String) {
+			return fmt.Errorf("unsupported IP family %q in publicIPPoolIPFamilies", ipFamily)
+		}
+	}
+	return nil
+}
+
+// isPublicIPPoolEnabled returns true if the public IPs of the given IP family are kept in the pool.
+func (az *Cloud) isPublicIPPoolEnabled(isIPv6 bool) bool {
+	if az.PublicIPPoolSize <= 0 || !az.UseStandardLoadBalancer() {
+		return false
+	}
+	if len(az.PublicIPPoolIPFamilies) == 0 {
+		return !isIPv6
+	}
+	for _, ipFamily := range az.PublicIPPoolIPFamilies {
+		if strings.EqualFold(ipFamily, getIPFamilyLabel(isIPv6)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isServiceEligibleForPublicIPPool returns true if the public IP of the service can be taken from the pool, i.e., the
+// service is external and its public IP would be created in the cluster resource group with the defaults in the cloud
+// config. The DDoS settings can be changed on the existing public IPs, so they are not checked.
+func (az *Cloud) isServiceEligibleForPublicIPPool(service *v1.Service, isIPv6 bool) bool {
+	if !az.isPublicIPPoolEnabled(isIPv6) || requiresInternalLoadBalancer(service) {
+		return false
+	}
+	if !strings.EqualFold(az.getPublicIPAddressResourceGroup(service), az.ResourceGroup) {
+		return false
+	}
+	for _, key := range []string{
+		consts.ServiceAnnotationIPTagsForPublicIP,
+		consts.ServiceAnnotationPIPRoutingPreference,
+		consts.ServiceAnnotationPIPTier,
+	} {
+		if _, found := service.Annotations[key]; found {
+			return false
+		}
+	}
+	return true
+}
+
+// isPublicIPInPool returns true if the public IP is created for the pool of the cluster.
+func isPublicIPInPool(pip *armnetwork.PublicIPAddress, clusterName string, isIPv6 bool) bool {
+	if pip.Properties == nil || pip.Tags == nil || ptr.Deref(pip.Tags[consts.PublicIPPoolTagKey], "") == "" {
+		return false
+	}
+	if !strings.EqualFold(getClusterFromPIPClusterTags(pip.Tags), clusterName) {
+		return false
+	}
+	return (ptr.Deref(pip.Properties.PublicIPAddressVersion, "") == armnetwork.IPVersionIPv6) == isIPv6
+}
+
+// isUnassignedPublicIPInPool returns true if the public IP in the pool is neither used by any service nor referenced.
+func isUnassignedPublicIPInPool(pip *armnetwork.PublicIPAddress, clusterName string, isIPv6 bool) bool {
+	return isPublicIPInPool(pip, clusterName, isIPv6) &&
+		getServiceFromPIPServiceTags(pip.Tags) == "" &&
+		pip.Properties.IPConfiguration == nil
+}
+
+// isPublicIPProvisioned returns true if the public IP has been provisioned with an IP address.
+func isPublicIPProvisioned(pip *armnetwork.PublicIPAddress) bool {
+	return pip.Properties != nil &&
+		ptr.Deref(pip.Properties.IPAddress, "") != "" &&
+		strings.EqualFold(string(ptr.Deref(pip.Properties.ProvisioningState, "")), string(armnetwork.ProvisioningStateSucceeded))
+}
+
+// listUnassignedPublicIPsInPool lists the unassigned public IPs in the pool sorted by name.
+func (az *Cloud) listUnassignedPublicIPsInPool(ctx context.Context, clusterName string, isIPv6 bool, crt azcache.AzureCacheReadType) ([]*armnetwork.PublicIPAddress, error) {
+	pips, err := az.listPIP(ctx, az.ResourceGroup, crt)
+	if err != nil {
+		return nil, err
+	}
+
+	var unassigned []*armnetwork.PublicIPAddress
+	for _, pip := range pips {
+		if isUnassignedPublicIPInPool(pip, clusterName, isIPv6) {
+			unassigned = append(unassigned, pip)
+		}
+	}
+	sort.Slice(unassigned, func(i, j int) bool {
+		return ptr.Deref(unassigned[i].Name, "") < ptr.Deref(unassigned[j].Name, "")
+	})
+	return unassigned, nil
+}
+
+// getPublicIPNameFromPool returns the name of the public IP in the pool assigned to the service, or an empty
+// name if there is none. It only reads the public IPs, the assignment is done by assignPublicIPsFromPool.
+func (az *Cloud) getPublicIPNameFromPool(ctx context.Context, clusterName string, service *v1.Service, isIPv6 bool) (string, error) {
+	pips, err := az.listPIP(ctx, az.ResourceGroup, azcache.CacheReadTypeDefault)
+	if err != nil {
+		return "", err
+	}
+
+	serviceName := getServiceName(service)
+	for _, pip := range pips {
+		if isPublicIPInPool(pip, clusterName, isIPv6) && isSVCNameInPIPTag(getServiceFromPIPServiceTags(pip.Tags), serviceName) {
+			return ptr.Deref(pip.Name, ""), nil
+		}
+	}
+	return "", nil
+}
+
+// assignPublicIPsFromPool assigns an unassigned public IP in the pool to the new service for each IP family, by
+// binding the service tag, before the frontend IP configurations are reconciled. It must be called with the
+// serviceReconcileLock held and only when the service wants a load balancer, so the public IPs in the pool are
+// neither taken by the read-only calls nor by the services being deleted, and not deleted while being assigned.
+func (az *Cloud) assignPublicIPsFromPool(ctx context.Context, clusterName string, service *v1.Service) error {
+	if isLoadBalancerPlanContext(ctx) || service.DeletionTimestamp != nil {
+		return nil
+	}
+	v4Enabled, v6Enabled := getIPFamiliesEnabled(service)
+	for _, isIPv6 := range []bool{false, true} {
+		if (!isIPv6 && !v4Enabled) || (isIPv6 && !v6Enabled) {
+			continue
+		}
+		if !az.isServiceEligibleForPublicIPPool(service, isIPv6) ||
+			getServicePIPName(service, isIPv6) != "" ||
+			getServicePIPPrefixID(service, isIPv6) != "" ||
+			getServiceLoadBalancerIP(service, isIPv6) != "" {
+			continue
+		}
+		defaultPIPName, err := az.getPublicIPName(clusterName, service, isIPv6)
+		if err != nil {
+			return err
+		}
+		if err := az.assignPublicIPFromPool(ctx, clusterName, service, defaultPIPName, isIPv6); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignPublicIPFromPool assigns an unassigned public IP in the pool to the service with the given IP family. Nothing
+// is assigned if the service already has a public IP in the pool or the public IP with the given default name.
+func (az *Cloud) assignPublicIPFromPool(ctx context.Context, clusterName string, service *v1.Service, defaultPIPName string, isIPv6 bool) error {
+	pips, err := az.listPIP(ctx, az.ResourceGroup, azcache.CacheReadTypeDefault)
+	if err != nil {
+		return err
+	}
+
+	serviceName := getServiceName(service)
+	for _, pip := range pips {
+		if strings.EqualFold(ptr.Deref(pip.Name, ""), defaultPIPName) {
+			return nil
+		}
+		if isPublicIPInPool(pip, clusterName, isIPv6) && isSVCNameInPIPTag(getServiceFromPIPServiceTags(pip.Tags), serviceName) {
+			return nil
+		}
+	}
+
+	unassigned, err := az.listUnassignedPublicIPsInPool(ctx, clusterName, isIPv6, azcache.CacheReadTypeDefault)
+	if err != nil {
+		return err
+	}
+	az.notifyPublicIPPoolRefill()
+	for _, pip := range unassigned {
+		if !isPublicIPProvisioned(pip) {
+			continue
+		}
+		if _, deleting := az.publicIPPoolDeleting.Load(strings.ToLower(ptr.Deref(pip.Name, ""))); deleting {
+			continue
+		}
+		pipCopy := *pip
+		pipCopy.Tags = make(map[string]*string, len(pip.Tags))
+		for k, v := range pip.Tags {
+			pipCopy.Tags[k] = v
+		}
+		if _, err := bindServicesToPIP(&pipCopy, []string{serviceName}, false); err != nil {
+			return err
+		}
+		if err := az.CreateOrUpdatePIP(ctx, service, az.ResourceGroup, &pipCopy); err != nil {
+			klog.Warningf("assignPublicIPFromPool: failed to assign the public IP %s in the pool to service %s: %s", ptr.Deref(pip.Name, ""), serviceName, err.Error())
+			continue
+		}
+
+		klog.V(2).Infof("assignPublicIPFromPool: assigned the public IP %s in the pool to service %s", ptr.Deref(pip.Name, ""), serviceName)
+		publicIPPoolAcquisitions.WithLabelValues(getIPFamilyLabel(isIPv6), publicIPPoolResultHit).Inc()
+		return nil
+	}
+
+	klog.V(2).Infof("assignPublicIPFromPool: no unassigned public IP in the pool for service %s, creating public IP %s", serviceName, defaultPIPName)
+	publicIPPoolAcquisitions.WithLabelValues(getIPFamilyLabel(isIPv6), publicIPPoolResultMiss).Inc()
+	return nil
+}
+
+// notifyPublicIPPoolRefill notifies the public IP pool controller to refill the pool without blocking.
+func (az *Cloud) notifyPublicIPPoolRefill() {
+	select {
+	case az.publicIPPoolRefillCh <- struct{}{}:
+	default:
+	}
+}
+
+// PublicIPPoolController keeps a pool of unassigned standard public IPs tagged with the cluster name, so the public
+// IPs of the new services can be taken from the pool instead of being created during the service reconciliation.
+// The unassigned public IPs are deleted when the pool is scaled down. They are kept when the controller stops,
+// so a restart or a change of the leader does not empty the pool.
+type PublicIPPoolController struct {
+	az          *Cloud
+	clusterName string
+	interval    time.Duration
+}
+
+// NewPublicIPPoolController creates a new PublicIPPoolController for the cluster.
+func NewPublicIPPoolController(az *Cloud, clusterName string) *PublicIPPoolController {
+	interval := az.PublicIPPoolRefillIntervalInSeconds
+	if interval <= 0 {
+		interval = consts.DefaultPublicIPPoolRefillIntervalInSeconds
+	}
+	return &PublicIPPoolController{
+		az:          az,
+		clusterName: clusterName,
+		interval:    time.Duration(interval) * time.Second,
+	}
+}
+
+// Run starts the PublicIPPoolController, and stops if the context exits.
+func (c *PublicIPPoolController) Run(ctx context.Context) {
+	registerPublicIPPoolMetrics()
+	klog.V(2).Infof("PublicIPPoolController.Run: started with size %d and interval %s", c.az.PublicIPPoolSize, c.interval)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.refill(ctx); err != nil {
+			klog.Errorf("PublicIPPoolController.Run: failed to refill the public IP pool: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			klog.Infof("PublicIPPoolController.Run: stopped due to %s", ctx.Err().Error())
+			return
+		case <-ticker.C:
+		case <-c.az.publicIPPoolRefillCh:
+		}
+	}
+}
+
+// refill creates the public IPs if there are fewer unassigned ones than the pool size, and deletes the extra ones.
+// At most MaxPublicIPPoolCreationsPerRefill public IPs are created per IP family.
+func (c *PublicIPPoolController) refill(ctx context.Context) error {
+	var errs []error
+	for _, isIPv6 := range []bool{false, true} {
+		unassigned, err := c.az.listUnassignedPublicIPsInPool(ctx, c.clusterName, isIPv6, azcache.CacheReadTypeForceRefresh)
+		if err != nil {
+			return err
+		}
+		publicIPPoolAvailable.WithLabelValues(getIPFamilyLabel(isIPv6)).Set(float64(len(unassigned)))
+
+		size := 0
+		if c.az.isPublicIPPoolEnabled(isIPv6) {
+			size = c.az.PublicIPPoolSize
+		}
+		switch {
+		case len(unassigned) < size:
+			count := min(size-len(unassigned), consts.MaxPublicIPPoolCreationsPerRefill)
+			klog.V(2).Infof("PublicIPPoolController.refill: creating %d %s public IPs, %d unassigned", count, getIPFamilyLabel(isIPv6), len(unassigned))
+			errs = append(errs, c.createPublicIPs(ctx, count, isIPv6))
+		case len(unassigned) > size:
+			klog.V(2).Infof("PublicIPPoolController.refill: deleting %d %s public IPs, %d unassigned", len(unassigned)-size, getIPFamilyLabel(isIPv6), len(unassigned))
+			errs = append(errs, c.deletePublicIPs(ctx, unassigned[size:], isIPv6))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// createPublicIPs creates the given number of unassigned public IPs in the pool with the defaults in the cloud config.
+func (c *PublicIPPoolController) createPublicIPs(ctx context.Context, count int, isIPv6 bool) error {
+	settings := c.az.getDefaultPublicIPSettings()
+	if err := validatePublicIPSettings(settings, c.az.UseStandardLoadBalancer()); err != nil {
+		return err
+	}
+	zones, err := c.az.getRegionZonesBackoff(ctx, c.az.Location)
+	if err != nil {
+		return err
+	}
+
+	var createFuncs []func() error
+	for i := 0; i < count; i++ {
+		pip := c.newPublicIP(settings, zones, isIPv6)
+		createFuncs = append(createFuncs, func() error {
+			_, err := c.az.NetworkClientFactory.GetPublicIPAddressClient().CreateOrUpdate(ctx, c.az.ResourceGroup, ptr.Deref(pip.Name, ""), *pip)
+			observePublicIPPoolOperation(isIPv6, "create", err)
+			if err != nil {
+				return fmt.Errorf("failed to create public IP %s for the pool: %w", ptr.Deref(pip.Name, ""), err)
+			}
+			return nil
+		})
+	}
+	errs := utilerrors.AggregateGoroutines(createFuncs...)
+	_ = c.az.pipCache.Delete(c.az.ResourceGroup)
+	return errs
+}
+
+// newPublicIP returns a new unassigned public IP of the pool.
+func (c *PublicIPPoolController) newPublicIP(settings *publicIPSettings, zones []*string, isIPv6 bool) *armnetwork.PublicIPAddress {
+	name := fmt.Sprintf("%s-pip-pool-%s", c.clusterName, utilrand.String(8))
+	ipVersion := to.Ptr(armnetwork.IPVersionIPv4)
+	if isIPv6 {
+		name = fmt.Sprintf("%s-%s", name, consts.IPVersionIPv6String)
+		ipVersion = to.Ptr(armnetwork.IPVersionIPv6)
+	}
+
+	tags := parseTags(c.az.Tags, c.az.TagsMap)
+	tags[consts.ServiceTagKey] = ptr.To("")
+	tags[consts.ClusterNameKey] = ptr.To(c.clusterName)
+	tags[consts.PublicIPPoolTagKey] = ptr.To("true")
+	pip := &armnetwork.PublicIPAddress{
+		Name:     ptr.To(name),
+		Location: ptr.To(c.az.Location),
+		Tags:     tags,
+		SKU: &armnetwork.PublicIPAddressSKU{
+			Name: to.Ptr(armnetwork.PublicIPAddressSKUNameStandard),
+		},
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
+			PublicIPAddressVersion:   ipVersion,
+			IPTags:                   applyRoutingPreferenceToIPTags(nil, settings.routingPreference),
+		},
+	}
+	if settings.tier != "" {
+		pip.SKU.Tier = to.Ptr(armnetwork.PublicIPAddressSKUTier(settings.tier))
+	}
+	if len(zones) > 0 {
+		pip.Zones = zones
+	}
+	if settings.ddosProtectionMode != "" {
+		setDDoSSettings(pip, settings)
+	}
+	return pip
+}
+
+// deletePublicIPs deletes the unassigned public IPs in the pool. The public IPs still unassigned are marked as being
+// deleted while the service reconciliation is blocked, so they are not assigned to the services afterwards. The
+// deletions run without blocking the service reconciliation.
+func (c *PublicIPPoolController) deletePublicIPs(ctx context.Context, pips []*armnetwork.PublicIPAddress, isIPv6 bool) error {
+	if len(pips) == 0 {
+		return nil
+	}
+
+	toDelete, err := c.markPublicIPsDeleting(ctx, pips, isIPv6)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// The cache is refreshed before the marks are removed, so the deleted public IPs cannot be assigned.
+		_ = c.az.pipCache.Delete(c.az.ResourceGroup)
+		for _, pipName := range toDelete {
+			c.az.publicIPPoolDeleting.Delete(strings.ToLower(pipName))
+		}
+	}()
+
+	var errs []error
+	for _, pipName := range toDelete {
+		err := c.az.NetworkClientFactory.GetPublicIPAddressClient().Delete(ctx, c.az.ResourceGroup, pipName)
+		observePublicIPPoolOperation(isIPv6, "delete", err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete public IP %s in the pool: %w", pipName, err))
+			continue
+		}
+		klog.V(2).Infof("PublicIPPoolController.deletePublicIPs: deleted public IP %s", pipName)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// markPublicIPsDeleting marks the given public IPs that are still unassigned as being deleted,
+// and returns their names. The public IPs may have been assigned to the services since they were listed.
+func (c *PublicIPPoolController) markPublicIPsDeleting(ctx context.Context, pips []*armnetwork.PublicIPAddress, isIPv6 bool) ([]string, error) {
+	c.az.serviceReconcileLock.Lock()
+	defer c.az.serviceReconcileLock.Unlock()
+
+	latest, err := c.az.listUnassignedPublicIPsInPool(ctx, c.clusterName, isIPv6, azcache.CacheReadTypeForceRefresh)
+	if err != nil {
+		return nil, err
+	}
+	unassignedNames := utilsets.NewString()
+	for _, pip := range latest {
+		unassignedNames.Insert(ptr.Deref(pip.Name, ""))
+	}
+
+	var names []string
+	for _, pip := range pips {
+		pipName := ptr.Deref(pip.Name, "")
+		if !unassignedNames.Has(pipName) {
+			continue
+		}
+		c.az.publicIPPoolDeleting.Store(strings.ToLower(pipName), struct{}{})
+		names = append(names, pipName)
+	}
+	return names, nil
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/publicipaddressclient/mock_publicipaddressclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+func getTestPoolPublicIP(name, serviceName string, isIPv6 bool) *armnetwork.PublicIPAddress {
+	ipVersion := to.Ptr(armnetwork.IPVersionIPv4)
+	if isIPv6 {
+		ipVersion = to.Ptr(armnetwork.IPVersionIPv6)
+	}
+	return &armnetwork.PublicIPAddress{
+		Name: ptr.To(name),
+		Tags: map[string]*string{
+			consts.ServiceTagKey:      ptr.To(serviceName),
+			consts.ClusterNameKey:     ptr.To(testClusterName),
+			consts.PublicIPPoolTagKey: ptr.To("true"),
+		},
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			PublicIPAddressVersion: ipVersion,
+			IPAddress:              ptr.To("1.2.3.4"),
+			ProvisioningState:      to.Ptr(armnetwork.ProvisioningStateSucceeded),
+		},
+	}
+}
+
+func TestValidatePublicIPPoolConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		desc        string
+		sku         string
+		size        int
+		ipFamilies  []string
+		expectedErr bool
+	}{
+		{
+			desc: "should allow the disabled pool",
+		},
+		{
+			desc:       "should allow the pool with standard load balancer",
+			sku:        consts.LoadBalancerSKUStandard,
+			size:       5,
+			ipFamilies: []string{"ipv4", "IPv6"},
+		},
+		{
+			desc:        "should report an error if the size exceeds the limit",
+			sku:         consts.LoadBalancerSKUStandard,
+			size:        consts.MaxPublicIPPoolSize + 1,
+			expectedErr: true,
+		},
+		{
+			desc:        "should report an error with basic load balancer",
+			size:        5,
+			expectedErr: true,
+		},
+		{
+			desc:        "should report an error if the IP family is not supported",
+			sku:         consts.LoadBalancerSKUStandard,
+			size:        5,
+			ipFamilies:  []string{"IPv5"},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerSKU = tc.sku
+			az.PublicIPPoolSize = tc.size
+			az.PublicIPPoolIPFamilies = tc.ipFamilies
+			assert.Equal(t, tc.expectedErr, az.validatePublicIPPoolConfig() != nil)
+		})
+	}
+}
+
+func TestIsServiceEligibleForPublicIPPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.PublicIPPoolSize = 2
+
+	service := getTestService("test", v1.ProtocolTCP, nil, false, 80)
+	assert.True(t, az.isServiceEligibleForPublicIPPool(&service, false))
+	// Only IPv4 public IPs are kept in the pool by default.
+	assert.False(t, az.isServiceEligibleForPublicIPPool(&service, true))
+
+	service = getTestService("test", v1.ProtocolTCP, map[string]string{consts.ServiceAnnotationPIPTier: "Global"}, false, 80)
+	assert.False(t, az.isServiceEligibleForPublicIPPool(&service, false))
+	service = getTestService("test", v1.ProtocolTCP, map[string]string{consts.ServiceAnnotationLoadBalancerResourceGroup: "another-rg"}, false, 80)
+	assert.False(t, az.isServiceEligibleForPublicIPPool(&service, false))
+	service = getInternalTestService("test", 80)
+	assert.False(t, az.isServiceEligibleForPublicIPPool(&service, false))
+
+	az.PublicIPPoolSize = 0
+	service = getTestService("test", v1.ProtocolTCP, nil, false, 80)
+	assert.False(t, az.isServiceEligibleForPublicIPPool(&service, false))
+}
+
+func TestGetPublicIPNameFromPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.PublicIPPoolSize = 2
+	service := getTestService("test", v1.ProtocolTCP, nil, false, 80)
+
+	mockPIPsClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+	mockPIPsClient.EXPECT().List(gomock.Any(), "rg").Return([]*armnetwork.PublicIPAddress{
+		getTestPoolPublicIP("pool-pip-1", "", false),
+		getTestPoolPublicIP("pool-pip-2", "default/test", false),
+	}, nil).Times(1)
+	// The public IPs are never assigned by the read.
+	mockPIPsClient.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	pipName, err := az.getPublicIPNameFromPool(context.Background(), testClusterName, &service, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "pool-pip-2", pipName)
+
+	service = getTestService("another", v1.ProtocolTCP, nil, false, 80)
+	pipName, err = az.getPublicIPNameFromPool(context.Background(), testClusterName, &service, false)
+	assert.NoError(t, err)
+	assert.Empty(t, pipName)
+}
+
+func TestAssignPublicIPsFromPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	unprovisioned := getTestPoolPublicIP("pool-pip-0", "", false)
+	unprovisioned.Properties.ProvisioningState = to.Ptr(armnetwork.ProvisioningStateUpdating)
+	for _, tc := range []struct {
+		desc            string
+		service         v1.Service
+		existingPIPs    []*armnetwork.PublicIPAddress
+		deletingPIPs    []string
+		expectedPIPName string
+	}{
+		{
+			desc:    "should not assign another public IP to the service with a public IP in the pool",
+			service: getTestService("test", v1.ProtocolTCP, nil, false, 80),
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				getTestPoolPublicIP("pool-pip-1", "default/test", false),
+				getTestPoolPublicIP("pool-pip-2", "", false),
+			},
+		},
+		{
+			desc:    "should not assign a public IP to the service with the public IP of the default name",
+			service: getTestService("test", v1.ProtocolTCP, nil, false, 80),
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				{Name: ptr.To("testCluster-atest")},
+				getTestPoolPublicIP("pool-pip-2", "", false),
+			},
+		},
+		{
+			desc:    "should assign an unassigned public IP in the pool to the service",
+			service: getTestService("test", v1.ProtocolTCP, nil, false, 80),
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				unprovisioned,
+				getTestPoolPublicIP("pool-pip-1", "default/another", false),
+				getTestPoolPublicIP("pool-pip-2", "", true),
+				getTestPoolPublicIP("pool-pip-3", "", false),
+				getTestPoolPublicIP("pool-pip-4", "", false),
+			},
+			deletingPIPs:    []string{"pool-pip-3"},
+			expectedPIPName: "pool-pip-4",
+		},
+		{
+			desc:    "should not assign a public IP to the internal service",
+			service: getInternalTestService("test", 80),
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				getTestPoolPublicIP("pool-pip-1", "", false),
+			},
+		},
+		{
+			desc: "should not assign a public IP to the service being deleted",
+			service: func() v1.Service {
+				svc := getTestService("test", v1.ProtocolTCP, nil, false, 80)
+				svc.DeletionTimestamp = &metav1.Time{}
+				return svc
+			}(),
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				getTestPoolPublicIP("pool-pip-1", "", false),
+			},
+		},
+		{
+			desc:    "should not assign a public IP if there is no unassigned public IP in the pool",
+			service: getTestService("test", v1.ProtocolTCP, nil, false, 80),
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				unprovisioned,
+				getTestPoolPublicIP("pool-pip-1", "default/another", false),
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+			az.PublicIPPoolSize = 2
+			az.publicIPPoolRefillCh = make(chan struct{}, 1)
+			for _, name := range tc.deletingPIPs {
+				az.publicIPPoolDeleting.Store(name, struct{}{})
+			}
+
+			mockPIPsClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+			mockPIPsClient.EXPECT().List(gomock.Any(), "rg").Return(tc.existingPIPs, nil).AnyTimes()
+			expectedAssignments := 0
+			if tc.expectedPIPName != "" {
+				expectedAssignments = 1
+			}
+			mockPIPsClient.EXPECT().CreateOrUpdate(gomock.Any(), "rg", gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, name string, pip armnetwork.PublicIPAddress) (*armnetwork.PublicIPAddress, error) {
+					assert.Equal(t, tc.expectedPIPName, name)
+					assert.Equal(t, "default/test", ptr.Deref(pip.Tags[consts.ServiceTagKey], ""))
+					return nil, nil
+				}).Times(expectedAssignments)
+
+			assert.NoError(t, az.assignPublicIPsFromPool(context.Background(), testClusterName, &tc.service))
+			// The cached public IPs are not changed.
+			for _, pip := range tc.existingPIPs {
+				if ptr.Deref(pip.Name, "") == tc.expectedPIPName {
+					assert.Empty(t, ptr.Deref(pip.Tags[consts.ServiceTagKey], ""))
+				}
+			}
+		})
+	}
+}
+
+func TestPublicIPPoolControllerRefill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		desc            string
+		size            int
+		existingPIPs    []*armnetwork.PublicIPAddress
+		expectedCreated int
+		expectedDeleted []string
+	}{
+		{
+			desc: "should create the missing public IPs",
+			size: 3,
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				getTestPoolPublicIP("pool-pip-1", "", false),
+				getTestPoolPublicIP("pool-pip-2", "default/test", false),
+			},
+			expectedCreated: 2,
+		},
+		{
+			desc: "should delete the extra public IPs",
+			size: 1,
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				getTestPoolPublicIP("pool-pip-1", "", false),
+				getTestPoolPublicIP("pool-pip-2", "", false),
+				getTestPoolPublicIP("pool-pip-3", "default/test", false),
+				getTestPoolPublicIP("pool-pip-4", "", true),
+			},
+			expectedDeleted: []string{"pool-pip-2", "pool-pip-4"},
+		},
+		{
+			desc: "should not change the full pool",
+			size: 1,
+			existingPIPs: []*armnetwork.PublicIPAddress{
+				getTestPoolPublicIP("pool-pip-1", "", false),
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+			az.PublicIPPoolSize = tc.size
+			az.regionZonesMap = map[string][]string{az.Location: {"1", "2", "3"}}
+			c := NewPublicIPPoolController(az, testClusterName)
+
+			mockPIPsClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+			mockPIPsClient.EXPECT().List(gomock.Any(), "rg").Return(tc.existingPIPs, nil).AnyTimes()
+			mockPIPsClient.EXPECT().CreateOrUpdate(gomock.Any(), "rg", gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, name string, pip armnetwork.PublicIPAddress) (*armnetwork.PublicIPAddress, error) {
+					assert.True(t, strings.HasPrefix(name, "testCluster-pip-pool-"))
+					assert.Equal(t, "true", ptr.Deref(pip.Tags[consts.PublicIPPoolTagKey], ""))
+					assert.Equal(t, testClusterName, ptr.Deref(pip.Tags[consts.ClusterNameKey], ""))
+					assert.Equal(t, armnetwork.PublicIPAddressSKUNameStandard, ptr.Deref(pip.SKU.Name, ""))
+					assert.Len(t, pip.Zones, 3)
+					return nil, nil
+				}).Times(tc.expectedCreated)
+			var deleted []string
+			mockPIPsClient.EXPECT().Delete(gomock.Any(), "rg", gomock.Any()).DoAndReturn(func(_ context.Context, _ string, name string) error {
+				deleted = append(deleted, name)
+				return nil
+			}).Times(len(tc.expectedDeleted))
+
+			assert.NoError(t, c.refill(context.Background()))
+			assert.Equal(t, tc.expectedDeleted, deleted)
+		})
+	}
+}
+
+func TestPublicIPPoolControllerDeletePublicIPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.PublicIPPoolSize = 2
+	c := NewPublicIPPoolController(az, testClusterName)
+
+	// pool-pip-2 has been assigned to a service since it was listed.
+	mockPIPsClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+	mockPIPsClient.EXPECT().List(gomock.Any(), "rg").Return([]*armnetwork.PublicIPAddress{
+		getTestPoolPublicIP("pool-pip-1", "", false),
+		getTestPoolPublicIP("pool-pip-2", "default/test", false),
+	}, nil).AnyTimes()
+	mockPIPsClient.EXPECT().Delete(gomock.Any(), "rg", "pool-pip-1").DoAndReturn(func(_ context.Context, _ string, name string) error {
+		// The service reconciliation is not blocked by the deletion, but the public IP cannot be assigned.
+		assert.True(t, az.serviceReconcileLock.TryLock())
+		az.serviceReconcileLock.Unlock()
+		_, deleting := az.publicIPPoolDeleting.Load(name)
+		assert.True(t, deleting)
+		return nil
+	}).Times(1)
+
+	assert.NoError(t, c.deletePublicIPs(context.Background(), []*armnetwork.PublicIPAddress{
+		getTestPoolPublicIP("pool-pip-1", "", false),
+		getTestPoolPublicIP("pool-pip-2", "", false),
+	}, false))
+	_, deleting := az.publicIPPoolDeleting.Load("pool-pip-1")
+	assert.False(t, deleting)
+}
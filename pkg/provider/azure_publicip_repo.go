@@ -37,8 +37,14 @@ import (
 	"sigs.k8s.io/cloud-provider-azure/pkg/util/deepcopy"
 )
 
-// CreateOrUpdatePIP invokes az.NetworkClientFactory.GetPublicIPAddressClient().CreateOrUpdate with exponential backoff retry
-func (az *Cloud) CreateOrUpdatePIP(service *v1.Service, pipResourceGroup string, pip *armnetwork.PublicIPAddress) error {
+// CreateOrUpdatePIP invokes az.NetworkClientFactory.GetPublicIPAddressClient().CreateOrUpdate with exponential backoff retry.
+// The service is nil if the public IP is not updated for a service, e.g. by the garbage collector.
+func (az *Cloud) CreateOrUpdatePIP(ctx context.Context, service *v1.Service, pipResourceGroup string, pip *armnetwork.PublicIPAddress) error {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return az.planPublicIPUpdate(ctx, recorder, pipResourceGroup, pip)
+	}
+	// The context of the caller is only used to detect the plan mode, so that the update
+	// is not canceled halfway together with the reconciliation.
 	ctx, cancel := getContextWithCancel()
 	defer cancel()
 
@@ -52,7 +58,9 @@ func (az *Cloud) CreateOrUpdatePIP(service *v1.Service, pipResourceGroup string,
 
 	pipJSON, _ := json.Marshal(pip)
 	klog.Warningf("NetworkClientFactory.GetPublicIPAddressClient().CreateOrUpdate(%s, %s) failed: %s, PublicIP request: %s", pipResourceGroup, ptr.Deref(pip.Name, ""), rerr.Error(), string(pipJSON))
-	az.Event(service, v1.EventTypeWarning, "CreateOrUpdatePublicIPAddress", rerr.Error())
+	if service != nil {
+		az.Event(service, v1.EventTypeWarning, "CreateOrUpdatePublicIPAddress", rerr.Error())
+	}
 
 	// Invalidate the cache because ETAG precondition mismatch.
 	var respError *azcore.ResponseError
@@ -73,15 +81,21 @@ func (az *Cloud) CreateOrUpdatePIP(service *v1.Service, pipResourceGroup string,
 	return rerr
 }
 
-// DeletePublicIP invokes az.NetworkClientFactory.GetPublicIPAddressClient().Delete with exponential backoff retry
-func (az *Cloud) DeletePublicIP(service *v1.Service, pipResourceGroup string, pipName string) error {
+// DeletePublicIP invokes az.NetworkClientFactory.GetPublicIPAddressClient().Delete with exponential backoff retry.
+// The service is nil if the public IP is not deleted for a service, e.g. by the garbage collector.
+func (az *Cloud) DeletePublicIP(ctx context.Context, service *v1.Service, pipResourceGroup string, pipName string) error {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		return recorder.record(LoadBalancerPlanResourceTypePublicIPAddress, pipResourceGroup, pipName, nil, az.getOriginalPublicIPAddressFunc(ctx, pipResourceGroup, pipName))
+	}
 	ctx, cancel := getContextWithCancel()
 	defer cancel()
 
 	rerr := az.NetworkClientFactory.GetPublicIPAddressClient().Delete(ctx, pipResourceGroup, pipName)
 	if rerr != nil {
 		klog.Errorf("NetworkClientFactory.GetPublicIPAddressClient().Delete(%s) failed: %s", pipName, rerr.Error())
-		az.Event(service, v1.EventTypeWarning, "DeletePublicIPAddress", rerr.Error())
+		if service != nil {
+			az.Event(service, v1.EventTypeWarning, "DeletePublicIPAddress", rerr.Error())
+		}
 
 		if strings.Contains(rerr.Error(), consts.CannotDeletePublicIPErrorMessageCode) {
 			klog.Warningf("DeletePublicIP for public IP %s failed with error %v, this is because other resources are referencing the public IP. The deletion of the service will continue.", pipName, rerr)
@@ -118,6 +132,15 @@ func (az *Cloud) newPIPCache() (azcache.Resource, error) {
 }
 
 func (az *Cloud) getPublicIPAddress(ctx context.Context, pipResourceGroup string, pipName string, crt azcache.AzureCacheReadType) (*armnetwork.PublicIPAddress, bool, error) {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		if planned, ok := recorder.get(LoadBalancerPlanResourceTypePublicIPAddress, pipResourceGroup, pipName); ok {
+			if planned == nil {
+				return nil, false, nil
+			}
+			return planned.(*armnetwork.PublicIPAddress), true, nil
+		}
+	}
+
 	cached, err := az.pipCache.Get(ctx, pipResourceGroup, crt)
 	if err != nil {
 		return nil, false, err
@@ -154,9 +177,22 @@ func (az *Cloud) listPIP(ctx context.Context, pipResourceGroup string, crt azcac
 		ret = append(ret, pip)
 		return true
 	})
+	ret = overlayPlannedResources(loadBalancerPlanRecorderFromContext(ctx), LoadBalancerPlanResourceTypePublicIPAddress, pipResourceGroup, ret, func(pip *armnetwork.PublicIPAddress) string {
+		return ptr.Deref(pip.Name, "")
+	})
 	return ret, nil
 }
 
+// getLatestPublicIPAddress gets the public IP from Azure without using the cache.
+func (az *Cloud) getLatestPublicIPAddress(ctx context.Context, pipResourceGroup string, pipName string) (*armnetwork.PublicIPAddress, error) {
+	if recorder := loadBalancerPlanRecorderFromContext(ctx); recorder != nil {
+		if planned, ok := recorder.get(LoadBalancerPlanResourceTypePublicIPAddress, pipResourceGroup, pipName); ok && planned != nil {
+			return planned.(*armnetwork.PublicIPAddress), nil
+		}
+	}
+	return az.NetworkClientFactory.GetPublicIPAddressClient().Get(ctx, pipResourceGroup, pipName, nil)
+}
+
 func (az *Cloud) findMatchedPIP(ctx context.Context, loadBalancerIP, pipName, pipResourceGroup string) (pip *armnetwork.PublicIPAddress, err error) {
 	pips, err := az.listPIP(ctx, pipResourceGroup, azcache.CacheReadTypeDefault)
 	if err != nil {
@@ -31,6 +31,8 @@ import (
 	"go.uber.org/mock/gomock"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/publicipaddressclient/mock_publicipaddressclient"
@@ -74,7 +76,7 @@ func TestCreateOrUpdatePIP(t *testing.T) {
 			mockPIPClient.EXPECT().List(gomock.Any(), az.ResourceGroup).Return([]*armnetwork.PublicIPAddress{}, nil)
 		}
 
-		err := az.CreateOrUpdatePIP(&v1.Service{}, az.ResourceGroup, &armnetwork.PublicIPAddress{Name: ptr.To("nic")})
+		err := az.CreateOrUpdatePIP(context.TODO(), &v1.Service{}, az.ResourceGroup, &armnetwork.PublicIPAddress{Name: ptr.To("nic")})
 		assert.Contains(t, err.Error(), test.expectedErr.Error())
 
 		cachedPIP, err := az.pipCache.GetWithDeepCopy(context.TODO(), az.ResourceGroup, cache.CacheReadTypeDefault)
@@ -95,10 +97,27 @@ func TestDeletePublicIP(t *testing.T) {
 	mockPIPClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
 	mockPIPClient.EXPECT().Delete(gomock.Any(), az.ResourceGroup, "pip").Return(&azcore.ResponseError{StatusCode: http.StatusInternalServerError})
 
-	err := az.DeletePublicIP(&v1.Service{}, az.ResourceGroup, "pip")
+	err := az.DeletePublicIP(context.TODO(), &v1.Service{}, az.ResourceGroup, "pip")
 	assert.Contains(t, err.Error(), "UNAVAILABLE")
 }
 
+func TestPublicIPRequestsWithoutService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	// The recorder of the controller manager fails on a nil service, unlike the fake one.
+	az.eventRecorder = record.NewBroadcaster().NewRecorder(scheme.Scheme, v1.EventSource{Component: "test"})
+	mockPIPClient := az.NetworkClientFactory.GetPublicIPAddressClient().(*mock_publicipaddressclient.MockInterface)
+	mockPIPClient.EXPECT().CreateOrUpdate(gomock.Any(), az.ResourceGroup, "pip", gomock.Any()).Return(nil, &azcore.ResponseError{StatusCode: http.StatusInternalServerError})
+	mockPIPClient.EXPECT().Delete(gomock.Any(), az.ResourceGroup, "pip").Return(&azcore.ResponseError{StatusCode: http.StatusInternalServerError})
+
+	assert.NotPanics(t, func() {
+		assert.Error(t, az.CreateOrUpdatePIP(context.TODO(), nil, az.ResourceGroup, &armnetwork.PublicIPAddress{Name: ptr.To("pip")}))
+		assert.Error(t, az.DeletePublicIP(context.TODO(), nil, az.ResourceGroup, "pip"))
+	})
+}
+
 func TestListPIP(t *testing.T) {
 	tests := []struct {
 		desc          string
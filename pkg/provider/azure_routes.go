@@ -18,15 +18,21 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	cloudprovider "k8s.io/cloud-provider"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
 	"k8s.io/utils/ptr"
@@ -34,6 +40,7 @@ import (
 	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
 	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
 	"sigs.k8s.io/cloud-provider-azure/pkg/metrics"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
 )
 
 var _ cloudprovider.Routes = (*Cloud)(nil)
@@ -48,11 +55,48 @@ const (
 	routeOperationAdd             routeOperation = "add"
 	routeOperationDelete          routeOperation = "delete"
 	routeTableOperationUpdateTags routeOperation = "updateRouteTableTags"
+	routeTableOperationSync       routeOperation = "syncRouteTables"
 )
 
+const (
+	// The types of the route drift, which are used in the events and metrics.
+	routeDriftTypeMissing     = "missing"
+	routeDriftTypeMismatched  = "mismatched"
+	routeDriftTypeConflicting = "conflicting"
+)
+
+var (
+	routeDriftFound = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "route_drift_found_total",
+			Help:           "Number of node routes found drifted from the pod CIDRs of the nodes by the route drift detector",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"type"},
+	)
+	routeDriftRepaired = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "route_drift_repaired_total",
+			Help:           "Number of drifted node routes repaired by the route drift detector",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"type"},
+	)
+	registerRouteDriftMetricsOnce sync.Once
+)
+
+func registerRouteDriftMetrics() {
+	registerRouteDriftMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(routeDriftFound, routeDriftRepaired)
+	})
+}
+
 // delayedRouteOperation defines a delayed route operation which is used in delayedRouteUpdater.
 type delayedRouteOperation struct {
 	route          *armnetwork.Route
+	routeTableName string
 	routeTableTags map[string]*string
 	operation      routeOperation
 	result         chan batchOperationResult
@@ -106,38 +150,64 @@ func (d *delayedRouteUpdater) updateRoutes(ctx context.Context) {
 		return
 	}
 
-	var err error
+	errs := make(map[string]error)
+	deletedRoutes := utilsets.NewString()
 	defer func() {
 		// Notify all the goroutines.
 		for _, op := range d.routesToUpdate {
 			rt := op.(*delayedRouteOperation)
+			var err error
+			if rt.routeTableName != "" {
+				err = errs[strings.ToLower(rt.routeTableName)]
+			} else {
+				for _, routeTableErr := range errs {
+					err = errors.Join(err, routeTableErr)
+				}
+			}
+			if rt.operation == routeOperationDelete && err == nil && !deletedRoutes.Has(ptr.Deref(rt.route.Name, "")) {
+				klog.Warningf("updateRoutes: route to be deleted %s does not match any of the existing route", ptr.Deref(rt.route.Name, ""))
+			}
 			rt.result <- newBatchOperationResult("", false, err)
 		}
 		// Clear all the jobs.
 		d.routesToUpdate = make([]batchOperation, 0)
 	}()
 
-	var (
-		routeTable *armnetwork.RouteTable
-	)
-	routeTable, err = d.az.routeTableRepo.Get(ctx, d.az.RouteTableName, azcache.CacheReadTypeDefault)
+	// The extra routes are left untouched if they cannot be determined.
+	extraRoutes, err := d.az.getExtraRoutes()
 	if err != nil {
-		klog.Errorf("getRouteTable() failed with error: %v", err)
-		return
+		klog.Errorf("updateRoutes: failed to get the extra routes: %v", err)
+	}
+	for _, routeTableName := range d.az.getRouteTableNames() {
+		if err := d.updateRouteTable(ctx, routeTableName, extraRoutes[strings.ToLower(routeTableName)], deletedRoutes); err != nil {
+			errs[strings.ToLower(routeTableName)] = err
+		}
+	}
+}
+
+// updateRouteTable applies the pending operations to the route table. Every node route is
+// written to all the route tables, so that the pods in any subnet can reach the pods on any
+// node. The extra routes are reconciled with the declared ones unless extraRoutes
+// is nil. The names of the deleted routes are recorded in deletedRoutes.
+func (d *delayedRouteUpdater) updateRouteTable(ctx context.Context, routeTableName string, extraRoutes map[string]*armnetwork.Route, deletedRoutes *utilsets.IgnoreCaseSet) error {
+	routeTable, err := d.az.routeTableRepo.Get(ctx, routeTableName, azcache.CacheReadTypeDefault)
+	if err != nil {
+		klog.Errorf("getRouteTable(%s) failed with error: %v", routeTableName, err)
+		return err
 	}
 
 	// create route table if it doesn't exists yet.
 	if routeTable == nil {
-		err = d.az.createRouteTable(ctx)
+		err = d.az.createRouteTable(ctx, routeTableName)
 		if err != nil {
-			klog.Errorf("createRouteTable() failed with error: %v", err)
-			return
+			klog.Errorf("createRouteTable(%s) failed with error: %v", routeTableName, err)
+			return err
 		}
 
-		routeTable, err = d.az.routeTableRepo.Get(ctx, d.az.RouteTableName, azcache.CacheReadTypeDefault)
+		routeTable, err = d.az.routeTableRepo.Get(ctx, routeTableName, azcache.CacheReadTypeDefault)
 		if err != nil {
-			klog.Errorf("getRouteTable() failed with error: %v", err)
-			return
+			klog.Errorf("getRouteTable(%s) failed with error: %v", routeTableName, err)
+			return err
 		}
 	}
 
@@ -148,7 +218,14 @@ func (d *delayedRouteUpdater) updateRoutes(ctx context.Context) {
 		routes = routeTable.Properties.Routes
 	}
 
-	routes, dirty = d.cleanupOutdatedRoutes(routes)
+	routes, dirty = d.cleanupOutdatedRoutes(routes, extraRoutes)
+	if extraRoutes != nil {
+		for _, route := range getMissingExtraRoutes(routes, extraRoutes) {
+			klog.V(2).Infof("updateRoutes: adding extra route %s to route table %s", ptr.Deref(route.Name, ""), routeTableName)
+			routes = append(routes, route)
+			dirty = true
+		}
+	}
 	if dirty {
 		onlyUpdateTags = false
 	}
@@ -156,8 +233,13 @@ func (d *delayedRouteUpdater) updateRoutes(ctx context.Context) {
 	for _, op := range d.routesToUpdate {
 		rt := op.(*delayedRouteOperation)
 		if rt.operation == routeTableOperationUpdateTags {
-			routeTable.Tags = rt.routeTableTags
-			dirty = true
+			if strings.EqualFold(rt.routeTableName, routeTableName) {
+				routeTable.Tags = rt.routeTableTags
+				dirty = true
+			}
+			continue
+		}
+		if rt.operation == routeTableOperationSync {
 			continue
 		}
 
@@ -174,14 +256,12 @@ func (d *delayedRouteUpdater) updateRoutes(ctx context.Context) {
 					routeMatch = true
 				}
 				if rt.operation == routeOperationDelete {
+					deletedRoutes.Insert(ptr.Deref(rt.route.Name, ""))
 					dirty = true
 				}
 				break
 			}
 		}
-		if rt.operation == routeOperationDelete && !dirty {
-			klog.Warningf("updateRoutes: route to be deleted %s does not match any of the existing route", ptr.Deref(rt.route.Name, ""))
-		}
 
 		// Add missing routes if the operation is add.
 		if rt.operation == routeOperationAdd {
@@ -189,35 +269,46 @@ func (d *delayedRouteUpdater) updateRoutes(ctx context.Context) {
 			if !routeMatch {
 				dirty = true
 			}
-			continue
 		}
 	}
+	reportRouteTableRoutes(routeTableName, routes)
 
 	if dirty {
 		if !onlyUpdateTags {
-			klog.V(2).Infof("updateRoutes: updating routes")
+			klog.V(2).Infof("updateRoutes: updating routes in route table %s", routeTableName)
 			routeTable.Properties.Routes = routes
 		}
 		_, err := d.az.routeTableRepo.CreateOrUpdate(ctx, *routeTable)
 		if err != nil {
-			klog.Errorf("CreateOrUpdateRouteTable() failed with error: %v", err)
-			return
+			klog.Errorf("CreateOrUpdateRouteTable(%s) failed with error: %v", routeTableName, err)
+			return err
 		}
 
 		// wait a while for route updates to take effect.
 		time.Sleep(time.Duration(d.az.Config.RouteUpdateWaitingInSeconds) * time.Second)
 	}
+	return nil
 }
 
 // cleanupOutdatedRoutes deletes all non-dualstack routes when dualstack is enabled,
 // and deletes all dualstack routes when dualstack is not enabled.
-func (d *delayedRouteUpdater) cleanupOutdatedRoutes(existingRoutes []*armnetwork.Route) (routes []*armnetwork.Route, changed bool) {
+// The extra routes that are no longer declared or have drifted are deleted as well unless extraRoutes is nil.
+func (d *delayedRouteUpdater) cleanupOutdatedRoutes(existingRoutes []*armnetwork.Route, extraRoutes map[string]*armnetwork.Route) (routes []*armnetwork.Route, changed bool) {
 	for i := len(existingRoutes) - 1; i >= 0; i-- {
 		existingRouteName := ptr.Deref(existingRoutes[i].Name, "")
 		split := strings.Split(existingRouteName, consts.RouteNameSeparator)
 
 		klog.V(4).Infof("cleanupOutdatedRoutes: checking route %s", existingRouteName)
 
+		if isExtraRouteName(existingRouteName) {
+			if extraRoutes != nil && isExtraRouteOutdated(existingRoutes[i], extraRoutes) {
+				klog.V(2).Infof("cleanupOutdatedRoutes: deleting outdated extra route %s", existingRouteName)
+				existingRoutes = append(existingRoutes[:i], existingRoutes[i+1:]...)
+				changed = true
+			}
+			continue
+		}
+
 		// filter out unmanaged routes
 		deleteRoute := false
 		if d.az.nodeNames.Has(split[0]) {
@@ -239,6 +330,7 @@ func (d *delayedRouteUpdater) cleanupOutdatedRoutes(existingRoutes []*armnetwork
 	return existingRoutes, changed
 }
 
+// getAddRouteOperation returns the operation adding the route to all the route tables.
 func getAddRouteOperation(route *armnetwork.Route) batchOperation {
 	return &delayedRouteOperation{
 		route:     route,
@@ -247,6 +339,7 @@ func getAddRouteOperation(route *armnetwork.Route) batchOperation {
 	}
 }
 
+// getDeleteRouteOperation returns the operation deleting the route from all the route tables.
 func getDeleteRouteOperation(route *armnetwork.Route) batchOperation {
 	return &delayedRouteOperation{
 		route:     route,
@@ -255,8 +348,17 @@ func getDeleteRouteOperation(route *armnetwork.Route) batchOperation {
 	}
 }
 
-func getUpdateRouteTableTagsOperation(tags map[string]*string) batchOperation {
+// getSyncRouteTablesOperation returns the operation that only triggers the reconciliation of the route tables.
+func getSyncRouteTablesOperation() batchOperation {
+	return &delayedRouteOperation{
+		operation: routeTableOperationSync,
+		result:    make(chan batchOperationResult),
+	}
+}
+
+func getUpdateRouteTableTagsOperation(routeTableName string, tags map[string]*string) batchOperation {
 	return &delayedRouteOperation{
+		routeTableName: routeTableName,
 		routeTableTags: tags,
 		operation:      routeTableOperationUpdateTags,
 		result:         make(chan batchOperationResult),
@@ -278,11 +380,44 @@ func (d *delayedRouteUpdater) removeOperation(_ string) {}
 // implements cloudprovider.Routes.ListRoutes
 func (az *Cloud) ListRoutes(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error) {
 	klog.V(10).Infof("ListRoutes: START clusterName=%q", clusterName)
-	routeTable, err := az.routeTableRepo.Get(ctx, az.RouteTableName, azcache.CacheReadTypeDefault)
-	routes, err := processRoutes(az.ipv6DualStackEnabled, routeTable, err)
+	var routes []*cloudprovider.Route
+	routeTables := make(map[string]*armnetwork.RouteTable)
+	listedRoutes := utilsets.NewString()
+	routeTableNames := az.getRouteTableNames()
+	for _, routeTableName := range routeTableNames {
+		routeTable, err := az.routeTableRepo.Get(ctx, routeTableName, azcache.CacheReadTypeDefault)
+		tableRoutes, err := processRoutes(az.ipv6DualStackEnabled, routeTable, err)
+		if err != nil {
+			return nil, err
+		}
+		// the node routes are written to all the route tables, so they are listed once.
+		for _, route := range tableRoutes {
+			if !listedRoutes.Has(route.Name) {
+				listedRoutes.Insert(route.Name)
+				routes = append(routes, route)
+			}
+		}
+		routeTables[routeTableName] = routeTable
+	}
+
+	// The routes of the existing nodes missing from some of the route tables are not recreated by the
+	// route controller since they are listed, so they are copied to all the route tables here.
+	// The routes of the deleted nodes are left to the route controller, which deletes them from all the route tables.
+	partialRoutes, err := az.getPartialNodeRoutes(routeTableNames, routeTables)
 	if err != nil {
 		return nil, err
 	}
+	for _, route := range partialRoutes {
+		klog.V(2).Infof("ListRoutes: copying route %s to all the route tables", ptr.Deref(route.Name, ""))
+		op := az.routeUpdater.addOperation(getAddRouteOperation(route))
+
+		// Wait for operation complete.
+		err = op.wait().err
+		if err != nil {
+			klog.Errorf("ListRoutes: failed to copy route %s with error: %v", ptr.Deref(route.Name, ""), err)
+			return nil, err
+		}
+	}
 
 	// Compose routes for unmanaged routes so that node controller won't retry creating routes for them.
 	unmanagedNodes, err := az.GetUnmanagedNodes()
@@ -301,17 +436,44 @@ func (az *Cloud) ListRoutes(ctx context.Context, clusterName string) ([]*cloudpr
 		}
 	}
 
-	// ensure the route table is tagged as configured
-	tags, changed := az.ensureRouteTableTagged(routeTable)
-	if changed {
-		klog.V(2).Infof("ListRoutes: updating tags on route table %s", ptr.Deref(routeTable.Name, ""))
-		op := az.routeUpdater.addOperation(getUpdateRouteTableTagsOperation(tags))
+	// ensure the route tables are tagged as configured
+	for _, routeTableName := range routeTableNames {
+		routeTable := routeTables[routeTableName]
+		if routeTable == nil {
+			continue
+		}
+		tags, changed := az.ensureRouteTableTagged(routeTable)
+		if changed {
+			klog.V(2).Infof("ListRoutes: updating tags on route table %s", routeTableName)
+			op := az.routeUpdater.addOperation(getUpdateRouteTableTagsOperation(routeTableName, tags))
+
+			// Wait for operation complete.
+			err = op.wait().err
+			if err != nil {
+				klog.Errorf("ListRoutes: failed to update route table tags with error: %v", err)
+				return nil, err
+			}
+		}
+	}
 
-		// Wait for operation complete.
-		err = op.wait().err
-		if err != nil {
-			klog.Errorf("ListRoutes: failed to update route table tags with error: %v", err)
-			return nil, err
+	// ensure the extra routes are as declared
+	extraRoutes, err := az.getExtraRoutes()
+	if err != nil {
+		klog.Errorf("ListRoutes: failed to get the extra routes: %v", err)
+		return routes, nil
+	}
+	for _, routeTableName := range routeTableNames {
+		if isRouteTableExtraRoutesDrifted(routeTables[routeTableName], extraRoutes[strings.ToLower(routeTableName)]) {
+			klog.V(2).Infof("ListRoutes: reconciling the extra routes in route table %s", routeTableName)
+			op := az.routeUpdater.addOperation(getSyncRouteTablesOperation())
+
+			// Wait for operation complete.
+			err = op.wait().err
+			if err != nil {
+				klog.Errorf("ListRoutes: failed to reconcile the extra routes with error: %v", err)
+				return nil, err
+			}
+			break
 		}
 	}
 
@@ -329,17 +491,21 @@ func processRoutes(ipv6DualStackEnabled bool, routeTable *armnetwork.RouteTable,
 
 	var kubeRoutes []*cloudprovider.Route
 	if routeTable.Properties != nil {
-		kubeRoutes = make([]*cloudprovider.Route, len(routeTable.Properties.Routes))
-		for i, route := range routeTable.Properties.Routes {
+		kubeRoutes = make([]*cloudprovider.Route, 0, len(routeTable.Properties.Routes))
+		for _, route := range routeTable.Properties.Routes {
+			// The extra routes are not node routes and are reconciled by the route updater.
+			if isExtraRouteName(*route.Name) {
+				continue
+			}
 			instance := MapRouteNameToNodeName(ipv6DualStackEnabled, *route.Name)
 			cidr := *route.Properties.AddressPrefix
 			klog.V(10).Infof("ListRoutes: * instance=%q, cidr=%q", instance, cidr)
 
-			kubeRoutes[i] = &cloudprovider.Route{
+			kubeRoutes = append(kubeRoutes, &cloudprovider.Route{
 				Name:            *route.Name,
 				TargetNode:      instance,
 				DestinationCIDR: cidr,
-			}
+			})
 		}
 	}
 
@@ -347,14 +513,14 @@ func processRoutes(ipv6DualStackEnabled bool, routeTable *armnetwork.RouteTable,
 	return kubeRoutes, nil
 }
 
-func (az *Cloud) createRouteTable(ctx context.Context) error {
+func (az *Cloud) createRouteTable(ctx context.Context, routeTableName string) error {
 	routeTable := armnetwork.RouteTable{
-		Name:       ptr.To(az.RouteTableName),
+		Name:       ptr.To(routeTableName),
 		Location:   ptr.To(az.Location),
 		Properties: &armnetwork.RouteTablePropertiesFormat{},
 	}
 
-	klog.V(3).Infof("createRouteTableIfNotExists: creating routetable. routeTableName=%q", az.RouteTableName)
+	klog.V(3).Infof("createRouteTableIfNotExists: creating routetable. routeTableName=%q", routeTableName)
 	_, err := az.routeTableRepo.CreateOrUpdate(ctx, routeTable)
 	return err
 }
@@ -385,30 +551,9 @@ func (az *Cloud) CreateRoute(ctx context.Context, clusterName string, _ string,
 		return nil
 	}
 
-	CIDRv6 := utilnet.IsIPv6CIDRString(kubeRoute.DestinationCIDR)
-	// if single stack IPv4 then get the IP for the primary ip config
-	// single stack IPv6 is supported on dual stack host. So the IPv6 IP is secondary IP for both single stack IPv6 and dual stack
-	// Get all private IPs for the machine and find the first one that matches the IPv6 family
-	if !az.ipv6DualStackEnabled && !CIDRv6 {
-		targetIP, _, err = az.getIPForMachine(ctx, kubeRoute.TargetNode)
-		if err != nil {
-			return err
-		}
-	} else {
-		// for dual stack and single stack IPv6 we need to select
-		// a private ip that matches family of the cidr
-		klog.V(4).Infof("CreateRoute: create route instance=%q cidr=%q is in dual stack mode", kubeRoute.TargetNode, kubeRoute.DestinationCIDR)
-		nodePrivateIPs, err := az.getPrivateIPsForMachine(ctx, kubeRoute.TargetNode)
-		if nil != err {
-			klog.V(3).Infof("CreateRoute: create route: failed(GetPrivateIPsByNodeName) instance=%q cidr=%q with error=%v", kubeRoute.TargetNode, kubeRoute.DestinationCIDR, err)
-			return err
-		}
-
-		targetIP, err = findFirstIPByFamily(nodePrivateIPs, CIDRv6)
-		if nil != err {
-			klog.V(3).Infof("CreateRoute: create route: failed(findFirstIpByFamily) instance=%q cidr=%q with error=%v", kubeRoute.TargetNode, kubeRoute.DestinationCIDR, err)
-			return err
-		}
+	targetIP, err = az.getRouteTargetIP(ctx, kubeRoute.TargetNode, kubeRoute.DestinationCIDR)
+	if err != nil {
+		return err
 	}
 	routeName := mapNodeNameToRouteName(az.ipv6DualStackEnabled, kubeRoute.TargetNode, kubeRoute.DestinationCIDR)
 	route := &armnetwork.Route{
@@ -420,7 +565,14 @@ func (az *Cloud) CreateRoute(ctx context.Context, clusterName string, _ string,
 		},
 	}
 
-	klog.V(2).Infof("CreateRoute: creating route for clusterName=%q instance=%q cidr=%q", clusterName, kubeRoute.TargetNode, kubeRoute.DestinationCIDR)
+	// the route table of the node routes the traffic from the pods on it to the other nodes.
+	routeTableName, err := az.getNodeRouteTableName(ctx, kubeRoute.TargetNode)
+	if err != nil {
+		klog.Errorf("CreateRoute: failed to get the route table of node %q with error: %v", kubeRoute.TargetNode, err)
+		return err
+	}
+
+	klog.V(2).Infof("CreateRoute: creating route for clusterName=%q instance=%q cidr=%q nodeRouteTable=%q", clusterName, kubeRoute.TargetNode, kubeRoute.DestinationCIDR, routeTableName)
 	op := az.routeUpdater.addOperation(getAddRouteOperation(route))
 
 	// Wait for operation complete.
@@ -436,6 +588,34 @@ func (az *Cloud) CreateRoute(ctx context.Context, clusterName string, _ string,
 	return nil
 }
 
+// getRouteTargetIP returns the private IP of the node that the route of the pod CIDR goes to.
+func (az *Cloud) getRouteTargetIP(ctx context.Context, nodeName types.NodeName, cidr string) (string, error) {
+	CIDRv6 := utilnet.IsIPv6CIDRString(cidr)
+	// if single stack IPv4 then get the IP for the primary ip config
+	// single stack IPv6 is supported on dual stack host. So the IPv6 IP is secondary IP for both single stack IPv6 and dual stack
+	// Get all private IPs for the machine and find the first one that matches the IPv6 family
+	if !az.ipv6DualStackEnabled && !CIDRv6 {
+		targetIP, _, err := az.getIPForMachine(ctx, nodeName)
+		return targetIP, err
+	}
+
+	// for dual stack and single stack IPv6 we need to select
+	// a private ip that matches family of the cidr
+	klog.V(4).Infof("CreateRoute: create route instance=%q cidr=%q is in dual stack mode", nodeName, cidr)
+	nodePrivateIPs, err := az.getPrivateIPsForMachine(ctx, nodeName)
+	if nil != err {
+		klog.V(3).Infof("CreateRoute: create route: failed(GetPrivateIPsByNodeName) instance=%q cidr=%q with error=%v", nodeName, cidr, err)
+		return "", err
+	}
+
+	targetIP, err := findFirstIPByFamily(nodePrivateIPs, CIDRv6)
+	if nil != err {
+		klog.V(3).Infof("CreateRoute: create route: failed(findFirstIpByFamily) instance=%q cidr=%q with error=%v", nodeName, cidr, err)
+		return "", err
+	}
+	return targetIP, nil
+}
+
 // DeleteRoute deletes the specified managed route
 // Route should be as returned by ListRoutes
 // implements cloudprovider.Routes.DeleteRoute
@@ -563,3 +743,214 @@ func (az *Cloud) ensureRouteTableTagged(rt *armnetwork.RouteTable) (map[string]*
 
 	return rt.Tags, changed
 }
+
+// routeDrift is a route in a route table that does not match the pod CIDR of a node.
+type routeDrift struct {
+	node           *v1.Node
+	route          *cloudprovider.Route
+	routeTableName string
+	// conflictingRouteName is the name of the route with the same address prefix, for the conflicting drifts.
+	conflictingRouteName string
+	driftType            string
+	message              string
+}
+
+// key identifies the drift across the checks.
+func (drift *routeDrift) key() string {
+	return strings.ToLower(strings.Join([]string{drift.routeTableName, drift.route.Name, drift.driftType, drift.conflictingRouteName}, "/"))
+}
+
+// runRouteDriftDetector periodically repairs the node routes drifted from the pod CIDRs of the nodes.
+func (az *Cloud) runRouteDriftDetector(ctx context.Context) {
+	registerRouteDriftMetrics()
+	interval := time.Duration(az.RouteDriftCheckIntervalInSeconds) * time.Second
+	klog.Infof("runRouteDriftDetector: started with interval %s", interval)
+	wait.UntilWithContext(ctx, az.detectRouteDrift, interval)
+}
+
+// detectRouteDrift repairs the missing and mismatched node routes through the route updater, so they
+// do not wait for the next full sync of the route controller. The conflicting routes are only reported,
+// since they may not be managed by the cloud provider.
+//
+// A drift is only counted and reported when it is found for the first time or its message changes, so
+// that a drift which persists across the checks, e.g. a conflicting route, is not reported on every check.
+func (az *Cloud) detectRouteDrift(ctx context.Context) {
+	drifts, err := az.findRouteDrifts(ctx)
+	if err != nil {
+		klog.Errorf("detectRouteDrift: failed to find the drifted routes: %s", err.Error())
+		return
+	}
+
+	found := make(map[string]string, len(drifts))
+	// every node route is written to all the route tables, so a route drifted in several route tables is repaired once.
+	repaired := utilsets.NewString()
+	for i := range drifts {
+		drift := &drifts[i]
+		key := drift.key()
+		found[key] = drift.message
+		if previous, ok := az.routeDrifts[key]; !ok || previous != drift.message {
+			klog.Warningf("detectRouteDrift: node %s: %s", drift.node.Name, drift.message)
+			routeDriftFound.WithLabelValues(drift.driftType).Inc()
+			az.eventRecorder.Event(drift.node, v1.EventTypeWarning, "RouteDriftDetected", drift.message)
+		}
+		if drift.driftType == routeDriftTypeConflicting || repaired.Has(drift.route.Name) {
+			continue
+		}
+
+		if err := az.CreateRoute(ctx, "", "", drift.route); err != nil {
+			klog.Errorf("detectRouteDrift: failed to repair the route %s of node %s: %s", drift.route.Name, drift.node.Name, err.Error())
+			az.eventRecorder.Eventf(drift.node, v1.EventTypeWarning, "RouteDriftRepairFailed", "Failed to repair the route to pod CIDR %s: %s", drift.route.DestinationCIDR, err.Error())
+			continue
+		}
+		repaired.Insert(drift.route.Name)
+		routeDriftRepaired.WithLabelValues(drift.driftType).Inc()
+		az.eventRecorder.Eventf(drift.node, v1.EventTypeNormal, "RouteDriftRepaired", "Repaired the route to pod CIDR %s", drift.route.DestinationCIDR)
+	}
+	az.routeDrifts = found
+}
+
+// findRouteDrifts compares the node routes in each route table with the pod CIDRs of the managed nodes.
+// A route is missing if no route in the route table has the expected name, mismatched if the route with the
+// expected name has another address prefix or next hop, and conflicting if a route with another name has
+// exactly the pod CIDR as its address prefix but another next hop. The routes with a broader or narrower
+// address prefix are not compared.
+func (az *Cloud) findRouteDrifts(ctx context.Context) ([]routeDrift, error) {
+	if az.nodeLister == nil || az.nodeInformerSynced == nil || !az.nodeInformerSynced() {
+		return nil, fmt.Errorf("node informer is not synced")
+	}
+	nodes, err := az.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(nodes, func(a, b *v1.Node) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	type nodeRoute struct {
+		node     *v1.Node
+		route    *cloudprovider.Route
+		targetIP string
+	}
+	var nodeRoutes []nodeRoute
+	for _, node := range nodes {
+		if node.DeletionTimestamp != nil {
+			continue
+		}
+		unmanaged, err := az.IsNodeUnmanaged(node.Name)
+		if err != nil {
+			return nil, err
+		}
+		if unmanaged {
+			continue
+		}
+
+		for _, cidr := range az.getNodeRouteCIDRs(node) {
+			routeName := mapNodeNameToRouteName(az.ipv6DualStackEnabled, types.NodeName(node.Name), cidr)
+			targetIP, err := az.getRouteTargetIP(ctx, types.NodeName(node.Name), cidr)
+			if err != nil {
+				klog.Warningf("findRouteDrifts: skipping the route %s since the IP of node %s is unknown: %s", routeName, node.Name, err.Error())
+				continue
+			}
+			nodeRoutes = append(nodeRoutes, nodeRoute{
+				node: node,
+				route: &cloudprovider.Route{
+					Name:            routeName,
+					TargetNode:      types.NodeName(node.Name),
+					DestinationCIDR: cidr,
+				},
+				targetIP: targetIP,
+			})
+		}
+	}
+
+	var drifts []routeDrift
+	for _, routeTableName := range az.getRouteTableNames() {
+		routeTable, err := az.routeTableRepo.Get(ctx, routeTableName, azcache.CacheReadTypeDefault)
+		if err != nil {
+			return nil, err
+		}
+		if routeTable == nil || routeTable.Properties == nil {
+			continue
+		}
+
+		existingRoutes := make(map[string]*armnetwork.Route)
+		routesByPrefix := make(map[string][]*armnetwork.Route)
+		for _, route := range routeTable.Properties.Routes {
+			routeName := ptr.Deref(route.Name, "")
+			if isExtraRouteName(routeName) {
+				continue
+			}
+			existingRoutes[strings.ToLower(routeName)] = route
+			if route.Properties != nil {
+				prefix := ptr.Deref(route.Properties.AddressPrefix, "")
+				routesByPrefix[prefix] = append(routesByPrefix[prefix], route)
+			}
+		}
+
+		for _, nodeRoute := range nodeRoutes {
+			routeName, cidr, targetIP := nodeRoute.route.Name, nodeRoute.route.DestinationCIDR, nodeRoute.targetIP
+			route, found := existingRoutes[strings.ToLower(routeName)]
+			if !found {
+				drifts = append(drifts, routeDrift{
+					node:           nodeRoute.node,
+					route:          nodeRoute.route,
+					routeTableName: routeTableName,
+					driftType:      routeDriftTypeMissing,
+					message:        fmt.Sprintf("The route %s to pod CIDR %s is missing in route table %s", routeName, cidr, routeTableName),
+				})
+			} else if !isNodeRouteMatched(route, cidr, targetIP) {
+				var prefix, nextHopIP string
+				if route.Properties != nil {
+					prefix, nextHopIP = ptr.Deref(route.Properties.AddressPrefix, ""), ptr.Deref(route.Properties.NextHopIPAddress, "")
+				}
+				drifts = append(drifts, routeDrift{
+					node:           nodeRoute.node,
+					route:          nodeRoute.route,
+					routeTableName: routeTableName,
+					driftType:      routeDriftTypeMismatched,
+					message: fmt.Sprintf("The route %s in route table %s routes %s to %s instead of pod CIDR %s to %s",
+						routeName, routeTableName, prefix, nextHopIP, cidr, targetIP),
+				})
+			}
+
+			for _, conflictingRoute := range routesByPrefix[cidr] {
+				conflictingRouteName := ptr.Deref(conflictingRoute.Name, "")
+				nextHopIP := ptr.Deref(conflictingRoute.Properties.NextHopIPAddress, "")
+				if strings.EqualFold(conflictingRouteName, routeName) || strings.EqualFold(nextHopIP, targetIP) {
+					continue
+				}
+				drifts = append(drifts, routeDrift{
+					node:                 nodeRoute.node,
+					route:                nodeRoute.route,
+					routeTableName:       routeTableName,
+					conflictingRouteName: conflictingRouteName,
+					driftType:            routeDriftTypeConflicting,
+					message: fmt.Sprintf("The route %s in route table %s has the same address prefix as pod CIDR %s, but routes it to %s instead of %s",
+						conflictingRouteName, routeTableName, cidr, nextHopIP, targetIP),
+				})
+			}
+		}
+	}
+	return drifts, nil
+}
+
+// getNodeRouteCIDRs returns the pod CIDRs of the node that are routed by the route controller.
+func (az *Cloud) getNodeRouteCIDRs(node *v1.Node) []string {
+	cidrs := node.Spec.PodCIDRs
+	if len(cidrs) == 0 && node.Spec.PodCIDR != "" {
+		cidrs = []string{node.Spec.PodCIDR}
+	}
+	// Only the first pod CIDR is routed if dual stack is not enabled.
+	if !az.ipv6DualStackEnabled && len(cidrs) > 1 {
+		cidrs = cidrs[:1]
+	}
+	return cidrs
+}
+
+// isNodeRouteMatched returns true if the route goes to the node as created by CreateRoute.
+func isNodeRouteMatched(route *armnetwork.Route, cidr, targetIP string) bool {
+	return route.Properties != nil &&
+		strings.EqualFold(ptr.Deref(route.Properties.AddressPrefix, ""), cidr) &&
+		ptr.Deref(route.Properties.NextHopType, "") == armnetwork.RouteNextHopTypeVirtualAppliance &&
+		strings.EqualFold(ptr.Deref(route.Properties.NextHopIPAddress, ""), targetIP)
+}
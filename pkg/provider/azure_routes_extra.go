@@ -0,0 +1,283 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+)
+
+// extraRouteNameRE matches the names of the extra routes, which are restricted by the Azure route names.
+var extraRouteNameRE = regexp.MustCompile(`^[A-Za-z0-9_.-]*[A-Za-z0-9_]$`)
+
+// extraRouteNameMaxLength is the maximum length of the Azure route names.
+const extraRouteNameMaxLength = 80
+
+// isExtraRouteName returns true if the route is an extra route managed by the route controller.
+func isExtraRouteName(routeName string) bool {
+	return strings.HasPrefix(strings.ToLower(routeName), consts.ExtraRouteNamePrefix)
+}
+
+// setUpExtraRoutesConfigMapInformer starts the informer of the extra routes ConfigMap. The informer only watches
+// the configured ConfigMap, so that the other ConfigMaps of the cluster are not cached.
+func (az *Cloud) setUpExtraRoutesConfigMapInformer(stop <-chan struct{}) {
+	if az.ExtraRoutesConfigMapName == "" {
+		return
+	}
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(az.KubeClient, 0,
+		informers.WithNamespace(az.ExtraRoutesConfigMapNamespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", az.ExtraRoutesConfigMapName).String()
+		}),
+	)
+	configMapInformer := informerFactory.Core().V1().ConfigMaps()
+	az.extraRoutesConfigMapSynced = configMapInformer.Informer().HasSynced
+	az.extraRoutesConfigMapLister = configMapInformer.Lister()
+	informerFactory.Start(stop)
+}
+
+// getExtraRoutes returns the expected extra routes keyed by the lower-cased names of the route tables
+// and the routes. Every route table has an entry even if no route is declared in it, so that the
+// undeclared extra routes are deleted. Invalid extra routes, the ones whose address prefix is already
+// used by another extra route in the same route table, and the ones within the pod CIDRs of the nodes,
+// which would take over the node routes by the longest prefix match, are logged and ignored.
+func (az *Cloud) getExtraRoutes() (map[string]map[string]*armnetwork.Route, error) {
+	extraRoutes := slices.Clone(az.ExtraRoutes)
+	if az.ExtraRoutesConfigMapName != "" {
+		routes, err := az.getExtraRoutesFromConfigMap()
+		if err != nil {
+			return nil, err
+		}
+		extraRoutes = append(extraRoutes, routes...)
+	}
+	var podCIDRs []netip.Prefix
+	if len(extraRoutes) > 0 {
+		var err error
+		if podCIDRs, err = az.getNodePodCIDRs(); err != nil {
+			return nil, err
+		}
+	}
+
+	routeTableNames := az.getRouteTableNames()
+	expected := make(map[string]map[string]*armnetwork.Route, len(routeTableNames))
+	// prefixes are the address prefixes of the extra routes keyed by the lower-cased names of the route tables.
+	prefixes := make(map[string]map[netip.Prefix]string, len(routeTableNames))
+	for _, routeTableName := range routeTableNames {
+		expected[strings.ToLower(routeTableName)] = make(map[string]*armnetwork.Route)
+		prefixes[strings.ToLower(routeTableName)] = make(map[netip.Prefix]string)
+	}
+	for _, extraRoute := range extraRoutes {
+		route, err := newExtraRoute(extraRoute)
+		if err != nil {
+			klog.Errorf("getExtraRoutes: ignoring the invalid extra route %q: %v", extraRoute.Name, err)
+			continue
+		}
+		prefix := netip.MustParsePrefix(extraRoute.AddressPrefix).Masked()
+		if podCIDR, found := findContainingPrefix(prefix, podCIDRs); found {
+			klog.Errorf("getExtraRoutes: ignoring the extra route %q, whose address prefix %s is within the pod CIDR %s", extraRoute.Name, extraRoute.AddressPrefix, podCIDR)
+			continue
+		}
+
+		routeTableName := extraRoute.RouteTableName
+		if routeTableName == "" {
+			routeTableName = az.RouteTableName
+		}
+		routes, found := expected[strings.ToLower(routeTableName)]
+		if !found {
+			klog.Errorf("getExtraRoutes: ignoring the extra route %q in route table %q, which is not managed by the cloud provider", extraRoute.Name, routeTableName)
+			continue
+		}
+		key := strings.ToLower(ptr.Deref(route.Name, ""))
+		if _, found := routes[key]; found {
+			klog.Errorf("getExtraRoutes: ignoring the duplicated extra route %q in route table %q", extraRoute.Name, routeTableName)
+			continue
+		}
+		routeTablePrefixes := prefixes[strings.ToLower(routeTableName)]
+		if name, found := routeTablePrefixes[prefix]; found {
+			klog.Errorf("getExtraRoutes: ignoring the extra route %q in route table %q, whose address prefix %s is already used by the extra route %q",
+				extraRoute.Name, routeTableName, extraRoute.AddressPrefix, name)
+			continue
+		}
+		routeTablePrefixes[prefix] = extraRoute.Name
+		routes[key] = route
+	}
+	return expected, nil
+}
+
+// getNodePodCIDRs returns the pod CIDRs routed to the nodes, which must not be overlapped by the extra routes.
+func (az *Cloud) getNodePodCIDRs() ([]netip.Prefix, error) {
+	if az.nodeLister == nil || az.nodeInformerSynced == nil || !az.nodeInformerSynced() {
+		return nil, fmt.Errorf("node informer is not synced")
+	}
+	nodes, err := az.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var podCIDRs []netip.Prefix
+	for _, node := range nodes {
+		for _, cidr := range az.getNodeRouteCIDRs(node) {
+			podCIDR, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				klog.Warningf("getNodePodCIDRs: ignoring the invalid pod CIDR %q of node %s: %v", cidr, node.Name, err)
+				continue
+			}
+			podCIDRs = append(podCIDRs, podCIDR.Masked())
+		}
+	}
+	return podCIDRs, nil
+}
+
+// findContainingPrefix returns the first prefix which contains or equals the given one.
+func findContainingPrefix(prefix netip.Prefix, prefixes []netip.Prefix) (netip.Prefix, bool) {
+	for _, candidate := range prefixes {
+		if candidate.Bits() <= prefix.Bits() && candidate.Contains(prefix.Addr()) {
+			return candidate, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// getExtraRoutesFromConfigMap returns the extra routes declared in the ConfigMap, which is read from the informer cache.
+// A missing ConfigMap declares no routes.
+func (az *Cloud) getExtraRoutesFromConfigMap() ([]config.ExtraRoute, error) {
+	if az.extraRoutesConfigMapLister == nil || az.extraRoutesConfigMapSynced == nil || !az.extraRoutesConfigMapSynced() {
+		return nil, fmt.Errorf("the informer of the extra routes ConfigMap %s/%s is not synced", az.ExtraRoutesConfigMapNamespace, az.ExtraRoutesConfigMapName)
+	}
+	configMap, err := az.extraRoutesConfigMapLister.ConfigMaps(az.ExtraRoutesConfigMapNamespace).Get(az.ExtraRoutesConfigMapName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("getExtraRoutesFromConfigMap: ConfigMap %s/%s not found", az.ExtraRoutesConfigMapNamespace, az.ExtraRoutesConfigMapName)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get the extra routes ConfigMap %s/%s: %w", az.ExtraRoutesConfigMapNamespace, az.ExtraRoutesConfigMapName, err)
+	}
+
+	var extraRoutes []config.ExtraRoute
+	if err := yaml.Unmarshal([]byte(configMap.Data[consts.ExtraRoutesConfigMapKey]), &extraRoutes); err != nil {
+		return nil, fmt.Errorf("failed to parse the extra routes in ConfigMap %s/%s: %w", az.ExtraRoutesConfigMapNamespace, az.ExtraRoutesConfigMapName, err)
+	}
+	return extraRoutes, nil
+}
+
+// newExtraRoute validates the extra route and converts it to the Azure route.
+func newExtraRoute(extraRoute config.ExtraRoute) (*armnetwork.Route, error) {
+	routeName := consts.ExtraRouteNamePrefix + extraRoute.Name
+	if !extraRouteNameRE.MatchString(extraRoute.Name) || len(routeName) > extraRouteNameMaxLength {
+		return nil, fmt.Errorf("the name should consist of letters, numbers, underscores, periods and hyphens, end with a letter, number or underscore, "+
+			"and be at most %d characters long", extraRouteNameMaxLength-len(consts.ExtraRouteNamePrefix))
+	}
+	prefix, err := netip.ParsePrefix(extraRoute.AddressPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address prefix %q: %w", extraRoute.AddressPrefix, err)
+	}
+
+	var nextHopType *armnetwork.RouteNextHopType
+	for _, candidate := range armnetwork.PossibleRouteNextHopTypeValues() {
+		if strings.EqualFold(string(candidate), extraRoute.NextHopType) {
+			nextHopType = ptr.To(candidate)
+			break
+		}
+	}
+	if nextHopType == nil {
+		return nil, fmt.Errorf("invalid next hop type %q", extraRoute.NextHopType)
+	}
+
+	var nextHopIPAddress *string
+	if *nextHopType == armnetwork.RouteNextHopTypeVirtualAppliance {
+		nextHopIP, err := netip.ParseAddr(extraRoute.NextHopIPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid next hop IP address %q: %w", extraRoute.NextHopIPAddress, err)
+		}
+		if nextHopIP.Unmap().Is4() != prefix.Addr().Unmap().Is4() {
+			return nil, fmt.Errorf("the next hop IP address %q is not in the IP family of the address prefix %q", extraRoute.NextHopIPAddress, extraRoute.AddressPrefix)
+		}
+		nextHopIPAddress = ptr.To(extraRoute.NextHopIPAddress)
+	} else if extraRoute.NextHopIPAddress != "" {
+		return nil, fmt.Errorf("the next hop IP address is only allowed with next hop type %s", armnetwork.RouteNextHopTypeVirtualAppliance)
+	}
+
+	return &armnetwork.Route{
+		Name: ptr.To(routeName),
+		Properties: &armnetwork.RoutePropertiesFormat{
+			AddressPrefix:    ptr.To(extraRoute.AddressPrefix),
+			NextHopType:      nextHopType,
+			NextHopIPAddress: nextHopIPAddress,
+		},
+	}, nil
+}
+
+// isExtraRouteOutdated returns true if the existing extra route is no longer declared or has drifted
+// from the declaration.
+func isExtraRouteOutdated(existingRoute *armnetwork.Route, extraRoutes map[string]*armnetwork.Route) bool {
+	expected, found := extraRoutes[strings.ToLower(ptr.Deref(existingRoute.Name, ""))]
+	if !found {
+		return true
+	}
+	if existingRoute.Properties == nil {
+		return true
+	}
+	return !strings.EqualFold(ptr.Deref(existingRoute.Properties.AddressPrefix, ""), ptr.Deref(expected.Properties.AddressPrefix, "")) ||
+		!strings.EqualFold(string(ptr.Deref(existingRoute.Properties.NextHopType, "")), string(ptr.Deref(expected.Properties.NextHopType, ""))) ||
+		!strings.EqualFold(ptr.Deref(existingRoute.Properties.NextHopIPAddress, ""), ptr.Deref(expected.Properties.NextHopIPAddress, ""))
+}
+
+// getMissingExtraRoutes returns the declared extra routes that are not in the route table, sorted by name.
+func getMissingExtraRoutes(existingRoutes []*armnetwork.Route, extraRoutes map[string]*armnetwork.Route) []*armnetwork.Route {
+	var missing []*armnetwork.Route
+	for key, route := range extraRoutes {
+		if !slices.ContainsFunc(existingRoutes, func(existingRoute *armnetwork.Route) bool {
+			return strings.EqualFold(ptr.Deref(existingRoute.Name, ""), key)
+		}) {
+			missing = append(missing, route)
+		}
+	}
+	slices.SortFunc(missing, func(a, b *armnetwork.Route) int {
+		return strings.Compare(ptr.Deref(a.Name, ""), ptr.Deref(b.Name, ""))
+	})
+	return missing
+}
+
+// isRouteTableExtraRoutesDrifted returns true if the extra routes in the route table do not match the declaration.
+func isRouteTableExtraRoutesDrifted(routeTable *armnetwork.RouteTable, extraRoutes map[string]*armnetwork.Route) bool {
+	var routes []*armnetwork.Route
+	if routeTable != nil && routeTable.Properties != nil {
+		routes = routeTable.Properties.Routes
+	}
+	for _, route := range routes {
+		if isExtraRouteName(ptr.Deref(route.Name, "")) && isExtraRouteOutdated(route, extraRoutes) {
+			return true
+		}
+	}
+	return len(getMissingExtraRoutes(routes, extraRoutes)) > 0
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/routetable"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+func newTestExtraRoute(name, prefix string, nextHopType armnetwork.RouteNextHopType, nextHopIP string) *armnetwork.Route {
+	route := &armnetwork.Route{
+		Name: ptr.To(consts.ExtraRouteNamePrefix + name),
+		Properties: &armnetwork.RoutePropertiesFormat{
+			AddressPrefix: ptr.To(prefix),
+			NextHopType:   ptr.To(nextHopType),
+		},
+	}
+	if nextHopIP != "" {
+		route.Properties.NextHopIPAddress = ptr.To(nextHopIP)
+	}
+	return route
+}
+
+func TestNewExtraRoute(t *testing.T) {
+	for _, tc := range []struct {
+		desc          string
+		extraRoute    config.ExtraRoute
+		expectedRoute *armnetwork.Route
+		expectedErr   bool
+	}{
+		{
+			desc:          "virtual appliance route should be converted",
+			extraRoute:    config.ExtraRoute{Name: "firewall", AddressPrefix: "0.0.0.0/0", NextHopType: "virtualappliance", NextHopIPAddress: "10.0.0.10"},
+			expectedRoute: newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.10"),
+		},
+		{
+			desc:          "virtual network gateway route should be converted",
+			extraRoute:    config.ExtraRoute{Name: "vpn", AddressPrefix: "192.168.0.0/16", NextHopType: "VirtualNetworkGateway"},
+			expectedRoute: newTestExtraRoute("vpn", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, ""),
+		},
+		{
+			desc:        "invalid name should be rejected",
+			extraRoute:  config.ExtraRoute{Name: "fire wall", AddressPrefix: "0.0.0.0/0", NextHopType: "Internet"},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid address prefix should be rejected",
+			extraRoute:  config.ExtraRoute{Name: "internet", AddressPrefix: "0.0.0.0", NextHopType: "Internet"},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid next hop type should be rejected",
+			extraRoute:  config.ExtraRoute{Name: "internet", AddressPrefix: "0.0.0.0/0", NextHopType: "Gateway"},
+			expectedErr: true,
+		},
+		{
+			desc:        "virtual appliance route without next hop IP should be rejected",
+			extraRoute:  config.ExtraRoute{Name: "firewall", AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance"},
+			expectedErr: true,
+		},
+		{
+			desc:        "next hop IP in another IP family should be rejected",
+			extraRoute:  config.ExtraRoute{Name: "firewall", AddressPrefix: "::/0", NextHopType: "VirtualAppliance", NextHopIPAddress: "10.0.0.10"},
+			expectedErr: true,
+		},
+		{
+			desc:        "next hop IP should only be allowed for virtual appliance routes",
+			extraRoute:  config.ExtraRoute{Name: "internet", AddressPrefix: "0.0.0.0/0", NextHopType: "Internet", NextHopIPAddress: "10.0.0.10"},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			route, err := newExtraRoute(tc.extraRoute)
+			assert.Equal(t, tc.expectedErr, err != nil, err)
+			assert.Equal(t, tc.expectedRoute, route)
+		})
+	}
+}
+
+func TestGetExtraRoutes(t *testing.T) {
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NoError(t, nodeIndexer.Add(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Spec:       v1.NodeSpec{PodCIDR: "10.244.0.0/24", PodCIDRs: []string{"10.244.0.0/24"}},
+	}))
+	cloud := &Cloud{
+		Config: config.Config{
+			RouteTableName:  "rt-a",
+			RouteTableNames: []string{"rt-b"},
+			ExtraRoutes: []config.ExtraRoute{
+				{Name: "firewall", AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance", NextHopIPAddress: "10.0.0.10"},
+				{Name: "unknown", RouteTableName: "rt-c", AddressPrefix: "0.0.0.0/0", NextHopType: "Internet"},
+				{Name: "invalid", AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance"},
+				{Name: "pods", AddressPrefix: "10.244.0.128/25", NextHopType: "Internet"},
+			},
+			ExtraRoutesConfigMapName:      "extra-routes",
+			ExtraRoutesConfigMapNamespace: "kube-system",
+		},
+		nodeLister:                 corelisters.NewNodeLister(nodeIndexer),
+		nodeInformerSynced:         func() bool { return true },
+		extraRoutesConfigMapLister: corelisters.NewConfigMapLister(configMapIndexer),
+		extraRoutesConfigMapSynced: func() bool { return true },
+	}
+
+	extraRoutes, err := cloud.getExtraRoutes()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*armnetwork.Route{
+		"rt-a": {"k8s_extra_firewall": newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.10")},
+		"rt-b": {},
+	}, extraRoutes, "the missing ConfigMap should declare no routes, and the route within the pod CIDRs should be ignored")
+
+	assert.NoError(t, configMapIndexer.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "extra-routes", Namespace: "kube-system"},
+		Data: map[string]string{consts.ExtraRoutesConfigMapKey: `
+- name: vpn
+  routeTableName: RT-B
+  addressPrefix: 192.168.0.0/16
+  nextHopType: VirtualNetworkGateway
+- name: firewall
+  addressPrefix: 0.0.0.0/0
+  nextHopType: Internet
+- name: internet
+  addressPrefix: 0.0.0.0/0
+  nextHopType: Internet
+- name: vpn-unmasked
+  routeTableName: rt-b
+  addressPrefix: 192.168.1.0/16
+  nextHopType: VirtualNetworkGateway
+- name: vpn
+  addressPrefix: 192.168.0.0/16
+  nextHopType: VirtualNetworkGateway
+`},
+	}))
+	extraRoutes, err = cloud.getExtraRoutes()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]*armnetwork.Route{
+		"rt-a": {
+			"k8s_extra_firewall": newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.10"),
+			"k8s_extra_vpn":      newTestExtraRoute("vpn", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, ""),
+		},
+		"rt-b": {"k8s_extra_vpn": newTestExtraRoute("vpn", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, "")},
+	}, extraRoutes, "the duplicated routes and address prefixes in the same route table should be ignored")
+
+	cloud.extraRoutesConfigMapSynced = func() bool { return false }
+	_, err = cloud.getExtraRoutes()
+	assert.Error(t, err)
+}
+
+func TestListRoutesReconcilesExtraRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRTRepo := routetable.NewMockRepository(ctrl)
+	cloud := &Cloud{
+		routeTableRepo: mockRTRepo,
+		Config: config.Config{
+			RouteTableResourceGroup: "rg",
+			RouteTableName:          "rt",
+			Location:                "location",
+			ExtraRoutes: []config.ExtraRoute{
+				{Name: "firewall", AddressPrefix: "0.0.0.0/0", NextHopType: "VirtualAppliance", NextHopIPAddress: "10.0.0.10"},
+			},
+		},
+		nodeNames:          utilsets.NewString("node"),
+		unmanagedNodes:     utilsets.NewString(),
+		nodeLister:         corelisters.NewNodeLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		nodeInformerSynced: func() bool { return true },
+	}
+	cloud.routeUpdater = newDelayedRouteUpdater(cloud, 100*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cloud.routeUpdater.run(ctx)
+
+	nodeRoute := &armnetwork.Route{
+		Name: ptr.To("node"),
+		Properties: &armnetwork.RoutePropertiesFormat{
+			AddressPrefix:    ptr.To("10.244.0.0/24"),
+			NextHopType:      ptr.To(armnetwork.RouteNextHopTypeVirtualAppliance),
+			NextHopIPAddress: ptr.To("10.0.0.4"),
+		},
+	}
+	mockRTRepo.EXPECT().Get(gomock.Any(), "rt", gomock.Any()).Return(&armnetwork.RouteTable{
+		Name: ptr.To("rt"),
+		Properties: &armnetwork.RouteTablePropertiesFormat{
+			Routes: []*armnetwork.Route{
+				nodeRoute,
+				newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.9"),
+				newTestExtraRoute("removed", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, ""),
+			},
+		},
+	}, nil).Times(2)
+	mockRTRepo.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, routeTable armnetwork.RouteTable) (*armnetwork.RouteTable, error) {
+			assert.Equal(t, []*armnetwork.Route{
+				nodeRoute,
+				newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.10"),
+			}, routeTable.Properties.Routes, "the drifted and undeclared extra routes should be corrected")
+			return &routeTable, nil
+		})
+
+	routes, err := cloud.ListRoutes(context.Background(), "cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, []*cloudprovider.Route{
+		{Name: "node", TargetNode: "node", DestinationCIDR: "10.244.0.0/24"},
+	}, routes, "the extra routes should not be listed as node routes")
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
@@ -28,10 +29,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 	cloudprovider "k8s.io/cloud-provider"
 	"k8s.io/utils/ptr"
 
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
 	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
 	"sigs.k8s.io/cloud-provider-azure/pkg/provider/routetable"
 	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
@@ -411,7 +418,7 @@ func TestCreateRouteTable(t *testing.T) {
 		Properties: &armnetwork.RouteTablePropertiesFormat{},
 	}
 	mockRTRepo.EXPECT().CreateOrUpdate(gomock.Any(), expectedTable).Return(nil, nil)
-	err := cloud.createRouteTable(context.Background())
+	err := cloud.createRouteTable(context.Background(), cloud.RouteTableName)
 	if err != nil {
 		t.Errorf("unexpected error in creating route table: %v", err)
 		t.FailNow()
@@ -744,8 +751,38 @@ func TestCleanupOutdatedRoutes(t *testing.T) {
 		description                          string
 		existingRoutes, expectedRoutes       []*armnetwork.Route
 		existingNodeNames                    *utilsets.IgnoreCaseSet
+		extraRoutes                          map[string]*armnetwork.Route
 		expectedChanged, enableIPV6DualStack bool
 	}{
+		{
+			description: "cleanupOutdatedRoutes should delete undeclared and drifted extra routes",
+			existingRoutes: []*armnetwork.Route{
+				{Name: ptr.To("aks-node1-vmss000000")},
+				newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.10"),
+				newTestExtraRoute("vpn", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualNetworkGateway, ""),
+				newTestExtraRoute("removed", "172.16.0.0/16", armnetwork.RouteNextHopTypeInternet, ""),
+			},
+			expectedRoutes: []*armnetwork.Route{
+				{Name: ptr.To("aks-node1-vmss000000")},
+				newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.10"),
+			},
+			existingNodeNames: utilsets.NewString("aks-node1-vmss000000"),
+			extraRoutes: map[string]*armnetwork.Route{
+				"k8s_extra_firewall": newTestExtraRoute("firewall", "0.0.0.0/0", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.10"),
+				"k8s_extra_vpn":      newTestExtraRoute("vpn", "192.168.0.0/16", armnetwork.RouteNextHopTypeVirtualAppliance, "10.0.0.11"),
+			},
+			expectedChanged: true,
+		},
+		{
+			description: "cleanupOutdatedRoutes should not delete extra routes if they cannot be determined",
+			existingRoutes: []*armnetwork.Route{
+				newTestExtraRoute("removed", "172.16.0.0/16", armnetwork.RouteNextHopTypeInternet, ""),
+			},
+			expectedRoutes: []*armnetwork.Route{
+				newTestExtraRoute("removed", "172.16.0.0/16", armnetwork.RouteNextHopTypeInternet, ""),
+			},
+			existingNodeNames: utilsets.NewString(),
+		},
 		{
 			description: "cleanupOutdatedRoutes should delete outdated non-dualstack routes when dualstack is enabled",
 			existingRoutes: []*armnetwork.Route{
@@ -807,7 +844,7 @@ func TestCleanupOutdatedRoutes(t *testing.T) {
 				az: cloud,
 			}
 
-			routes, changed := d.cleanupOutdatedRoutes(testCase.existingRoutes)
+			routes, changed := d.cleanupOutdatedRoutes(testCase.existingRoutes, testCase.extraRoutes)
 			assert.Equal(t, testCase.expectedChanged, changed)
 			assert.Equal(t, testCase.expectedRoutes, routes)
 		})
@@ -836,3 +873,156 @@ func TestEnsureRouteTableTagged(t *testing.T) {
 	assert.Nil(t, tags)
 	assert.False(t, changed)
 }
+
+func TestDetectRouteDrift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVMSet := NewMockVMSet(ctrl)
+	mockRTRepo := routetable.NewMockRepository(ctrl)
+	recorder := record.NewFakeRecorder(10)
+	cloud := &Cloud{
+		routeTableRepo: mockRTRepo,
+		VMSet:          mockVMSet,
+		Config: config.Config{
+			RouteTableResourceGroup: "rg",
+			RouteTableName:          "rt",
+			Location:                "location",
+		},
+		nodeNames:          utilsets.NewString("node1", "node2", "node3"),
+		unmanagedNodes:     utilsets.NewString(),
+		nodeInformerSynced: func() bool { return true },
+		eventRecorder:      recorder,
+	}
+	cloud.routeUpdater = newDelayedRouteUpdater(cloud, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cloud.routeUpdater.run(ctx)
+
+	newNode := func(name, podCIDR string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.NodeSpec{PodCIDR: podCIDR, PodCIDRs: []string{podCIDR}},
+		}
+	}
+	kubeClient := fake.NewSimpleClientset(
+		newNode("node1", "10.244.1.0/24"),
+		newNode("node2", "10.244.2.0/24"),
+		newNode("node3", "10.244.3.0/24"),
+	)
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	cloud.nodeLister = informerFactory.Core().V1().Nodes().Lister()
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+
+	for node, ip := range map[string]string{"node1": "10.0.0.1", "node2": "10.0.0.2", "node3": "10.0.0.3"} {
+		mockVMSet.EXPECT().GetIPByNodeName(gomock.Any(), node).Return(ip, "", nil).AnyTimes()
+	}
+	newRoute := func(name, prefix, nextHop string) *armnetwork.Route {
+		return &armnetwork.Route{
+			Name: ptr.To(name),
+			Properties: &armnetwork.RoutePropertiesFormat{
+				AddressPrefix:    ptr.To(prefix),
+				NextHopType:      ptr.To(armnetwork.RouteNextHopTypeVirtualAppliance),
+				NextHopIPAddress: ptr.To(nextHop),
+			},
+		}
+	}
+	routes := []*armnetwork.Route{
+		newRoute("node1", "10.244.1.0/24", "10.0.0.1"),
+		newRoute("custom", "10.244.1.0/24", "10.0.0.50"),
+		newRoute("node3", "10.244.3.0/24", "10.0.0.99"),
+	}
+	mockRTRepo.EXPECT().Get(gomock.Any(), "rt", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ azcache.AzureCacheReadType) (*armnetwork.RouteTable, error) {
+			return &armnetwork.RouteTable{
+				Name:       ptr.To("rt"),
+				Properties: &armnetwork.RouteTablePropertiesFormat{Routes: slices.Clone(routes)},
+			}, nil
+		}).AnyTimes()
+	mockRTRepo.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, routeTable armnetwork.RouteTable) (*armnetwork.RouteTable, error) {
+			routes = routeTable.Properties.Routes
+			return &routeTable, nil
+		}).Times(2)
+
+	drifts, err := cloud.findRouteDrifts(context.Background())
+	assert.NoError(t, err)
+	var driftTypes []string
+	for _, drift := range drifts {
+		driftTypes = append(driftTypes, drift.node.Name+"="+drift.driftType)
+	}
+	assert.Equal(t, []string{"node1=conflicting", "node2=missing", "node3=mismatched"}, driftTypes)
+
+	cloud.detectRouteDrift(context.Background())
+	assert.Equal(t, []*armnetwork.Route{
+		newRoute("node1", "10.244.1.0/24", "10.0.0.1"),
+		newRoute("custom", "10.244.1.0/24", "10.0.0.50"),
+		newRoute("node2", "10.244.2.0/24", "10.0.0.2"),
+		newRoute("node3", "10.244.3.0/24", "10.0.0.3"),
+	}, routes, "the missing and mismatched routes should be repaired and the conflicting route should be kept")
+	assert.Len(t, recorder.Events, 5)
+
+	cloud.detectRouteDrift(context.Background())
+	assert.Len(t, recorder.Events, 5, "the conflicting route should not be reported again")
+	assert.Len(t, cloud.routeDrifts, 1)
+
+	drifts, err = cloud.findRouteDrifts(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 1, "only the conflicting route should be reported after the repair")
+}
+
+func TestFindRouteDriftsPerRouteTable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVMSet := NewMockVMSet(ctrl)
+	mockRTRepo := routetable.NewMockRepository(ctrl)
+	cloud := &Cloud{
+		routeTableRepo: mockRTRepo,
+		VMSet:          mockVMSet,
+		Config: config.Config{
+			RouteTableResourceGroup: "rg",
+			RouteTableName:          "rt",
+			RouteTableNames:         []string{"rt2"},
+			Location:                "location",
+		},
+		nodeNames:          utilsets.NewString("node1"),
+		unmanagedNodes:     utilsets.NewString(),
+		nodeInformerSynced: func() bool { return true },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubeClient := fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Spec:       v1.NodeSpec{PodCIDR: "10.244.1.0/24", PodCIDRs: []string{"10.244.1.0/24"}},
+	})
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	cloud.nodeLister = informerFactory.Core().V1().Nodes().Lister()
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+
+	mockVMSet.EXPECT().GetIPByNodeName(gomock.Any(), "node1").Return("10.0.0.1", "", nil).AnyTimes()
+	mockRTRepo.EXPECT().Get(gomock.Any(), "rt", gomock.Any()).Return(&armnetwork.RouteTable{
+		Name: ptr.To("rt"),
+		Properties: &armnetwork.RouteTablePropertiesFormat{Routes: []*armnetwork.Route{{
+			Name: ptr.To("node1"),
+			Properties: &armnetwork.RoutePropertiesFormat{
+				AddressPrefix:    ptr.To("10.244.1.0/24"),
+				NextHopType:      ptr.To(armnetwork.RouteNextHopTypeVirtualAppliance),
+				NextHopIPAddress: ptr.To("10.0.0.1"),
+			},
+		}}},
+	}, nil)
+	mockRTRepo.EXPECT().Get(gomock.Any(), "rt2", gomock.Any()).Return(&armnetwork.RouteTable{
+		Name:       ptr.To("rt2"),
+		Properties: &armnetwork.RouteTablePropertiesFormat{},
+	}, nil)
+
+	drifts, err := cloud.findRouteDrifts(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 1, "the route should be missing in the route table without it")
+	assert.Equal(t, "rt2", drifts[0].routeTableName)
+	assert.Equal(t, routeDriftTypeMissing, drifts[0].driftType)
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+var (
+	routeTableRoutes = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      consts.AzureMetricsNamespace,
+			Name:           "route_table_routes",
+			Help:           "Number of routes in each route table managed by the route controller",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"route_table"},
+	)
+	registerRouteTableMetricsOnce sync.Once
+)
+
+func registerRouteTableMetrics() {
+	registerRouteTableMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(routeTableRoutes)
+	})
+}
+
+// reportRouteTableRoutes records the number of routes in the route table.
+func reportRouteTableRoutes(routeTableName string, routes []*armnetwork.Route) {
+	registerRouteTableMetrics()
+	routeTableRoutes.WithLabelValues(routeTableName).Set(float64(len(routes)))
+}
+
+// getRouteTableNames returns the route tables that the node routes are written to.
+// The configured RouteTableName always comes first, followed by RouteTableNames without duplicates.
+func (az *Cloud) getRouteTableNames() []string {
+	routeTableNames := []string{az.RouteTableName}
+	for _, name := range az.RouteTableNames {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		duplicated := false
+		for _, existing := range routeTableNames {
+			if strings.EqualFold(existing, name) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			routeTableNames = append(routeTableNames, name)
+		}
+	}
+	return routeTableNames
+}
+
+// getPartialNodeRoutes returns the routes of the existing nodes that are missing from some of the route tables.
+// The route tables that do not exist yet are regarded as empty.
+func (az *Cloud) getPartialNodeRoutes(routeTableNames []string, routeTables map[string]*armnetwork.RouteTable) ([]*armnetwork.Route, error) {
+	if len(routeTableNames) == 1 {
+		return nil, nil
+	}
+	nodeNames, err := az.GetNodeNames()
+	if err != nil || nodeNames == nil {
+		return nil, err
+	}
+
+	var routes []*armnetwork.Route
+	routeCounts := make(map[string]int)
+	for _, routeTableName := range routeTableNames {
+		routeTable := routeTables[routeTableName]
+		if routeTable == nil || routeTable.Properties == nil {
+			continue
+		}
+		for _, route := range routeTable.Properties.Routes {
+			routeName := strings.ToLower(ptr.Deref(route.Name, ""))
+			if isExtraRouteName(routeName) {
+				continue
+			}
+			if routeCounts[routeName] == 0 {
+				routes = append(routes, route)
+			}
+			routeCounts[routeName]++
+		}
+	}
+
+	var partialRoutes []*armnetwork.Route
+	for _, route := range routes {
+		routeName := ptr.Deref(route.Name, "")
+		if routeCounts[strings.ToLower(routeName)] < len(routeTableNames) &&
+			nodeNames.Has(string(MapRouteNameToNodeName(az.ipv6DualStackEnabled, routeName))) {
+			partialRoutes = append(partialRoutes, route)
+		}
+	}
+	return partialRoutes, nil
+}
+
+// newNodeSubnetCache caches the subnets of the nodes' primary IP configurations.
+// The key is the node name and the value is the subnet ID.
+func (az *Cloud) newNodeSubnetCache() (azcache.Resource, error) {
+	getter := func(ctx context.Context, key string) (interface{}, error) {
+		nic, err := az.VMSet.GetPrimaryInterface(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		ipConfig, err := getPrimaryIPConfig(nic)
+		if err != nil {
+			return nil, err
+		}
+		if ipConfig.Properties == nil || ipConfig.Properties.Subnet == nil || ptr.Deref(ipConfig.Properties.Subnet.ID, "") == "" {
+			return nil, fmt.Errorf("the primary IP configuration of node %q has no subnet", key)
+		}
+		return ptr.Deref(ipConfig.Properties.Subnet.ID, ""), nil
+	}
+	return azcache.NewTimedCache(az.getSubnetRouteTableCacheTTL(), getter, az.Config.DisableAPICallCache)
+}
+
+// newSubnetRouteTableCache caches the route tables associated with the subnets.
+// The key is the lower-case subnet ID and the value is the associated route table ID, which is empty
+// if the subnet has no route table.
+func (az *Cloud) newSubnetRouteTableCache() (azcache.Resource, error) {
+	getter := func(ctx context.Context, key string) (interface{}, error) {
+		subnetID, err := arm.ParseResourceID(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the subnet ID %q: %w", key, err)
+		}
+		subnet, err := az.subnetRepo.Get(ctx, subnetID.ResourceGroupName, subnetID.Parent.Name, subnetID.Name)
+		if err != nil {
+			return nil, err
+		}
+		if subnet == nil || subnet.Properties == nil || subnet.Properties.RouteTable == nil {
+			return "", nil
+		}
+		return ptr.Deref(subnet.Properties.RouteTable.ID, ""), nil
+	}
+	return azcache.NewTimedCache(az.getSubnetRouteTableCacheTTL(), getter, az.Config.DisableAPICallCache)
+}
+
+func (az *Cloud) getSubnetRouteTableCacheTTL() time.Duration {
+	if az.RouteTableCacheTTLInSeconds == 0 {
+		return time.Duration(subnetRouteTableCacheTTLDefaultInSeconds) * time.Second
+	}
+	return time.Duration(az.RouteTableCacheTTLInSeconds) * time.Second
+}
+
+// getNodeRouteTableName returns the managed route table associated with the subnet of the node's
+// primary IP configuration, which routes the traffic from the pods on the node. Without
+// RouteTableNames, it is always the configured RouteTableName. Otherwise, a node whose subnet is
+// not associated with any of the managed route tables is an error, because the pods on it would
+// not be able to reach the pods on other nodes.
+func (az *Cloud) getNodeRouteTableName(ctx context.Context, nodeName types.NodeName) (string, error) {
+	routeTableNames := az.getRouteTableNames()
+	if len(routeTableNames) == 1 {
+		return routeTableNames[0], nil
+	}
+
+	cachedSubnetID, err := az.nodeSubnetCache.Get(ctx, string(nodeName), azcache.CacheReadTypeDefault)
+	if err != nil {
+		return "", err
+	}
+	subnetID := cachedSubnetID.(string)
+	cachedRouteTableID, err := az.subnetRouteTableCache.Get(ctx, strings.ToLower(subnetID), azcache.CacheReadTypeDefault)
+	if err != nil {
+		return "", err
+	}
+
+	routeTableName, found := az.selectSubnetRouteTable(routeTableNames, cachedRouteTableID.(string))
+	if !found {
+		return "", fmt.Errorf("subnet %q of node %q is not associated with any of the route tables %v in resource group %q",
+			subnetID, nodeName, routeTableNames, az.RouteTableResourceGroup)
+	}
+	klog.V(4).Infof("getNodeRouteTableName: node %q in subnet %q uses route table %q", nodeName, subnetID, routeTableName)
+	return routeTableName, nil
+}
+
+// selectSubnetRouteTable returns the route table associated with the subnet if it is one of the managed route tables.
+func (az *Cloud) selectSubnetRouteTable(routeTableNames []string, associatedRouteTableID string) (string, bool) {
+	if associatedRouteTableID == "" {
+		return "", false
+	}
+	routeTableID, err := arm.ParseResourceID(associatedRouteTableID)
+	if err != nil || !strings.EqualFold(routeTableID.ResourceGroupName, az.RouteTableResourceGroup) {
+		return "", false
+	}
+	for _, name := range routeTableNames {
+		if strings.EqualFold(name, routeTableID.Name) {
+			return name, true
+		}
+	}
+	return "", false
+}
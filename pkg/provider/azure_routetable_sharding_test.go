@@ -0,0 +1,246 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/config"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/routetable"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/subnet"
+	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
+)
+
+func TestGetRouteTableNames(t *testing.T) {
+	cloud := &Cloud{Config: config.Config{RouteTableName: "rt-a"}}
+	assert.Equal(t, []string{"rt-a"}, cloud.getRouteTableNames())
+
+	cloud.RouteTableNames = []string{"rt-b", "RT-A", " ", "rt-c", "rt-b"}
+	assert.Equal(t, []string{"rt-a", "rt-b", "rt-c"}, cloud.getRouteTableNames())
+}
+
+func TestGetNodeRouteTableName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVMSet := NewMockVMSet(ctrl)
+	mockSubnetRepo := subnet.NewMockRepository(ctrl)
+	cloud := &Cloud{
+		VMSet:      mockVMSet,
+		subnetRepo: mockSubnetRepo,
+		Config: config.Config{
+			RouteTableResourceGroup: "rg",
+			RouteTableName:          "rt-a",
+		},
+	}
+	cloud.nodeSubnetCache, _ = cloud.newNodeSubnetCache()
+	cloud.subnetRouteTableCache, _ = cloud.newSubnetRouteTableCache()
+
+	routeTableName, err := cloud.getNodeRouteTableName(context.Background(), "node")
+	assert.NoError(t, err)
+	assert.Equal(t, "rt-a", routeTableName, "the node subnet should not be looked up with a single route table")
+
+	newInterface := func(subnetName string) *armnetwork.Interface {
+		return &armnetwork.Interface{
+			Name: ptr.To("nic"),
+			Properties: &armnetwork.InterfacePropertiesFormat{
+				IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+					{
+						Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+							Subnet: &armnetwork.Subnet{
+								ID: ptr.To("/subscriptions/sub/resourceGroups/vnet-rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/" + subnetName),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	cloud.RouteTableNames = []string{"rt-b", "rt-c"}
+	mockVMSet.EXPECT().GetPrimaryInterface(gomock.Any(), "node").Return(newInterface("subnet1"), nil).Times(1)
+	mockVMSet.EXPECT().GetPrimaryInterface(gomock.Any(), "node2").Return(newInterface("subnet1"), nil).Times(1)
+	mockSubnetRepo.EXPECT().Get(gomock.Any(), "vnet-rg", "vnet", "subnet1").Return(&armnetwork.Subnet{
+		Name: ptr.To("subnet1"),
+		Properties: &armnetwork.SubnetPropertiesFormat{
+			RouteTable: &armnetwork.RouteTable{
+				ID: ptr.To("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/routeTables/RT-C"),
+			},
+		},
+	}, nil).Times(1)
+	for _, nodeName := range []string{"node", "node", "node2"} {
+		routeTableName, err = cloud.getNodeRouteTableName(context.Background(), types.NodeName(nodeName))
+		assert.NoError(t, err)
+		assert.Equal(t, "rt-c", routeTableName, "the route table associated with the subnet should be used")
+	}
+
+	mockVMSet.EXPECT().GetPrimaryInterface(gomock.Any(), "node3").Return(newInterface("subnet3"), nil).Times(1)
+	mockSubnetRepo.EXPECT().Get(gomock.Any(), "vnet-rg", "vnet", "subnet3").Return(&armnetwork.Subnet{Name: ptr.To("subnet3")}, nil).Times(1)
+	_, err = cloud.getNodeRouteTableName(context.Background(), "node3")
+	assert.Error(t, err, "a node whose subnet has no managed route table should be an error")
+}
+
+func TestSelectSubnetRouteTable(t *testing.T) {
+	cloud := &Cloud{Config: config.Config{RouteTableResourceGroup: "rg"}}
+	routeTableNames := []string{"rt-a", "rt-b", "rt-c"}
+
+	for _, tc := range []struct {
+		desc                   string
+		associatedRouteTableID string
+		expectedRouteTableName string
+		expectedFound          bool
+	}{
+		{
+			desc:                   "the associated route table should be selected",
+			associatedRouteTableID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/routeTables/RT-B",
+			expectedRouteTableName: "rt-b",
+			expectedFound:          true,
+		},
+		{
+			desc:                   "a route table in another resource group should not be selected",
+			associatedRouteTableID: "/subscriptions/sub/resourceGroups/other-rg/providers/Microsoft.Network/routeTables/rt-b",
+		},
+		{
+			desc:                   "an unmanaged route table should not be selected",
+			associatedRouteTableID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/routeTables/rt-d",
+		},
+		{
+			desc: "a subnet without a route table should not be selected",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			routeTableName, found := cloud.selectSubnetRouteTable(routeTableNames, tc.associatedRouteTableID)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedRouteTableName, routeTableName)
+		})
+	}
+}
+
+func TestGetPartialNodeRoutes(t *testing.T) {
+	newRoute := func(name string) *armnetwork.Route {
+		return &armnetwork.Route{
+			Name:       ptr.To(name),
+			Properties: &armnetwork.RoutePropertiesFormat{AddressPrefix: ptr.To("10.244.0.0/24")},
+		}
+	}
+	cloud := &Cloud{
+		Config:             config.Config{RouteTableName: "rt-a", RouteTableNames: []string{"rt-b", "rt-c"}},
+		nodeNames:          utilsets.NewString("node1", "node2", "node3"),
+		nodeInformerSynced: func() bool { return true },
+	}
+	routeTables := map[string]*armnetwork.RouteTable{
+		"rt-a": {Properties: &armnetwork.RouteTablePropertiesFormat{Routes: []*armnetwork.Route{
+			newRoute("node1"), newRoute("node2"), newRoute("deleted-node"), newRoute(consts.ExtraRouteNamePrefix + "route"),
+		}}},
+		"rt-b": {Properties: &armnetwork.RouteTablePropertiesFormat{Routes: []*armnetwork.Route{
+			newRoute("node1"), newRoute("node3"),
+		}}},
+		"rt-c": {Properties: &armnetwork.RouteTablePropertiesFormat{Routes: []*armnetwork.Route{
+			newRoute("NODE1"),
+		}}},
+	}
+
+	routes, err := cloud.getPartialNodeRoutes(cloud.getRouteTableNames(), routeTables)
+	assert.NoError(t, err)
+	assert.Equal(t, []*armnetwork.Route{newRoute("node2"), newRoute("node3")}, routes,
+		"only the routes of the existing nodes missing from some route tables should be returned")
+
+	routes, err = cloud.getPartialNodeRoutes([]string{"rt-a"}, routeTables)
+	assert.NoError(t, err)
+	assert.Empty(t, routes)
+}
+
+func TestUpdateRoutesAcrossRouteTables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRTRepo := routetable.NewMockRepository(ctrl)
+	cloud := &Cloud{
+		routeTableRepo: mockRTRepo,
+		Config: config.Config{
+			RouteTableResourceGroup: "rg",
+			RouteTableName:          "rt-a",
+			RouteTableNames:         []string{"rt-b"},
+			Location:                "location",
+		},
+		nodeNames: utilsets.NewString(),
+	}
+	newRoute := func(name, prefix, nextHop string) *armnetwork.Route {
+		return &armnetwork.Route{
+			Name: ptr.To(name),
+			Properties: &armnetwork.RoutePropertiesFormat{
+				AddressPrefix:    ptr.To(prefix),
+				NextHopType:      ptr.To(armnetwork.RouteNextHopTypeVirtualAppliance),
+				NextHopIPAddress: ptr.To(nextHop),
+			},
+		}
+	}
+
+	mockRTRepo.EXPECT().Get(gomock.Any(), "rt-a", gomock.Any()).Return(&armnetwork.RouteTable{
+		Name: ptr.To("rt-a"),
+		Properties: &armnetwork.RouteTablePropertiesFormat{
+			Routes: []*armnetwork.Route{
+				newRoute("node1", "10.244.1.0/24", "10.0.0.4"),
+				newRoute("node3", "10.244.3.0/24", "10.0.0.6"),
+			},
+		},
+	}, nil)
+	mockRTRepo.EXPECT().Get(gomock.Any(), "rt-b", gomock.Any()).Return(&armnetwork.RouteTable{
+		Name: ptr.To("rt-b"),
+		Properties: &armnetwork.RouteTablePropertiesFormat{
+			Routes: []*armnetwork.Route{
+				newRoute("node2", "10.244.2.0/24", "10.1.0.4"),
+			},
+		},
+	}, nil)
+	updated := make(map[string][]*armnetwork.Route)
+	mockRTRepo.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, routeTable armnetwork.RouteTable) (*armnetwork.RouteTable, error) {
+			updated[*routeTable.Name] = routeTable.Properties.Routes
+			return &routeTable, nil
+		}).Times(2)
+
+	d := newDelayedRouteUpdater(cloud, 0).(*delayedRouteUpdater)
+	ops := []batchOperation{
+		d.addOperation(getAddRouteOperation(newRoute("node1", "10.244.1.0/24", "10.0.0.4"))),
+		d.addOperation(getDeleteRouteOperation(&armnetwork.Route{Name: ptr.To("node2")})),
+		d.addOperation(getAddRouteOperation(newRoute("node3", "10.244.3.0/24", "10.0.0.7"))),
+	}
+	var wg sync.WaitGroup
+	for _, op := range ops {
+		wg.Add(1)
+		go func(op *delayedRouteOperation) {
+			defer wg.Done()
+			assert.NoError(t, op.wait().err)
+		}(op.(*delayedRouteOperation))
+	}
+	d.updateRoutes(context.Background())
+	wg.Wait()
+
+	assert.Equal(t, map[string][]*armnetwork.Route{
+		"rt-a": {newRoute("node1", "10.244.1.0/24", "10.0.0.4"), newRoute("node3", "10.244.3.0/24", "10.0.0.7")},
+		"rt-b": {newRoute("node1", "10.244.1.0/24", "10.0.0.4"), newRoute("node3", "10.244.3.0/24", "10.0.0.7")},
+	}, updated, "the routes should be written to and deleted from all the route tables")
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/log"
+)
+
+var (
+	// serviceConditionTypes are the types of the service conditions managed by the cloud provider.
+	serviceConditionTypes = []string{
+		consts.ServiceConditionLoadBalancerReady,
+		consts.ServiceConditionSecurityGroupReady,
+		consts.ServiceConditionPrivateLinkServiceReady,
+	}
+
+	// serviceConditionErrorCodes are the ARM error codes which are used as the reason of a false
+	// service condition when they are found in the error message.
+	serviceConditionErrorCodes = []string{
+		consts.ReferencedResourceNotProvisionedMessageCode,
+		consts.CannotDeletePublicIPErrorMessageCode,
+		consts.ParentResourceNotFoundMessageCode,
+		consts.ResourceNotFoundMessageCode,
+	}
+
+	// serviceConditionReasonRE is the format of the reason of a condition, see metav1.Condition.
+	serviceConditionReasonRE = regexp.MustCompile(`^[A-Za-z]([A-Za-z0-9_,:]*[A-Za-z0-9_])?$`)
+)
+
+type serviceConditionsContextKey struct{}
+
+// serviceConditionsRecorder records the results of the reconciliation steps of a service.
+type serviceConditionsRecorder struct {
+	mu         sync.Mutex
+	generation int64
+	// conditions are keyed by the condition type, nil if the condition no longer applies to the service.
+	conditions map[string]*metav1.Condition
+}
+
+// newServiceConditionsContext returns a context which records the results of the
+// reconciliation steps of the service in the returned recorder.
+func newServiceConditionsContext(ctx context.Context, service *v1.Service) (context.Context, *serviceConditionsRecorder) {
+	recorder := &serviceConditionsRecorder{
+		generation: service.Generation,
+		conditions: make(map[string]*metav1.Condition),
+	}
+	return context.WithValue(ctx, serviceConditionsContextKey{}, recorder), recorder
+}
+
+func serviceConditionsRecorderFromContext(ctx context.Context) *serviceConditionsRecorder {
+	recorder, _ := ctx.Value(serviceConditionsContextKey{}).(*serviceConditionsRecorder)
+	return recorder
+}
+
+// setServiceCondition records the condition of the reconciliation step, which is true if err is nil.
+// It is a no-op if the context does not record the service conditions.
+func setServiceCondition(ctx context.Context, conditionType string, err error) {
+	recorder := serviceConditionsRecorderFromContext(ctx)
+	if recorder == nil {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	condition := &metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: recorder.generation,
+		Reason:             consts.ServiceConditionReasonReconciled,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = getServiceConditionReason(err)
+		condition.Message = err.Error()
+		if len(condition.Message) > consts.ServiceConditionMessageMaxLength {
+			condition.Message = condition.Message[:consts.ServiceConditionMessageMaxLength]
+		}
+	}
+	recorder.conditions[conditionType] = condition
+}
+
+// removeServiceCondition records that the condition no longer applies to the service.
+func removeServiceCondition(ctx context.Context, conditionType string) {
+	recorder := serviceConditionsRecorderFromContext(ctx)
+	if recorder == nil {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.conditions[conditionType] = nil
+}
+
+// getServiceConditionReason returns the ARM error code of the error if there is one, or a generic reason.
+func getServiceConditionReason(err error) string {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && serviceConditionReasonRE.MatchString(respErr.ErrorCode) {
+		return respErr.ErrorCode
+	}
+	message := strings.ToLower(err.Error())
+	for _, code := range serviceConditionErrorCodes {
+		if strings.Contains(message, strings.ToLower(code)) {
+			return code
+		}
+	}
+	return consts.ServiceConditionReasonReconcileFailed
+}
+
+// getServiceConditionsPatch returns the strategic merge patch of the conditions of the service,
+// or nil if the conditions are up to date. The last transition time is kept if the status does not change.
+func (r *serviceConditionsRecorder) getServiceConditionsPatch(service *v1.Service) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var conditions []interface{}
+	now := metav1.Now()
+	for _, conditionType := range serviceConditionTypes {
+		condition, recorded := r.conditions[conditionType]
+		if !recorded {
+			continue
+		}
+		existing := meta.FindStatusCondition(service.Status.Conditions, conditionType)
+		if condition == nil {
+			if existing != nil {
+				conditions = append(conditions, map[string]string{
+					"type":   conditionType,
+					"$patch": "delete",
+				})
+			}
+			continue
+		}
+		if existing != nil &&
+			existing.Status == condition.Status &&
+			existing.Reason == condition.Reason &&
+			existing.Message == condition.Message &&
+			existing.ObservedGeneration == condition.ObservedGeneration {
+			continue
+		}
+		patched := *condition
+		patched.LastTransitionTime = now
+		if existing != nil && existing.Status == condition.Status {
+			patched.LastTransitionTime = existing.LastTransitionTime
+		}
+		conditions = append(conditions, patched)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": conditions,
+		},
+	})
+}
+
+// updateServiceConditions patches the conditions recorded during the reconciliation to the service status.
+// Failing to update the conditions does not fail the reconciliation.
+func (az *Cloud) updateServiceConditions(ctx context.Context, service *v1.Service, recorder *serviceConditionsRecorder) {
+	logger := log.FromContextOrBackground(ctx).WithName("updateServiceConditions")
+	if az.KubeClient == nil {
+		logger.V(5).Info("Skipping because the kube client is nil")
+		return
+	}
+
+	patch, err := recorder.getServiceConditionsPatch(service)
+	if err != nil {
+		logger.Error(err, "Failed to generate the patch of the service conditions")
+		return
+	}
+	if patch == nil {
+		return
+	}
+
+	logger.V(4).Info("Patching the service conditions", "patch", string(patch))
+	if _, err := az.KubeClient.CoreV1().Services(service.Namespace).Patch(ctx, service.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(4).Info("Skipping because the service does not exist anymore")
+			return
+		}
+		logger.Error(fmt.Errorf("failed to patch the status of service %s: %w", getServiceName(service), err), "Failed to update the service conditions")
+	}
+}
+
+// removeServiceConditions removes the conditions managed by the cloud provider from the service status,
+// which is used when the service no longer has an Azure load balancer.
+func (az *Cloud) removeServiceConditions(ctx context.Context, service *v1.Service) {
+	ctx, recorder := newServiceConditionsContext(ctx, service)
+	for _, conditionType := range serviceConditionTypes {
+		removeServiceCondition(ctx, conditionType)
+	}
+	az.updateServiceConditions(ctx, service, recorder)
+}
+
+// getServiceIngressIPMode returns how the traffic to the load balancer IP is delivered to the nodes.
+// With floating IP, the destination is the load balancer IP, so kube-proxy handles the traffic
+// to the IP on the nodes. Without it, the load balancer translates the destination to the backend IP.
+func (az *Cloud) getServiceIngressIPMode(service *v1.Service) *v1.LoadBalancerIPMode {
+	if consts.IsK8sServiceDisableLoadBalancerFloatingIP(service) || az.IsLBBackendPoolTypePodIP() {
+		return ptr.To(v1.LoadBalancerIPModeProxy)
+	}
+	return ptr.To(v1.LoadBalancerIPModeVIP)
+}
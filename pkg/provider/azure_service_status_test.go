@@ -0,0 +1,246 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+)
+
+func TestGetServiceConditionReason(t *testing.T) {
+	for _, tc := range []struct {
+		description    string
+		err            error
+		expectedReason string
+	}{
+		{
+			description:    "should use the error code of the response error",
+			err:            fmt.Errorf("wrapped: %w", &azcore.ResponseError{StatusCode: http.StatusConflict, ErrorCode: "AnotherOperationInProgress"}),
+			expectedReason: "AnotherOperationInProgress",
+		},
+		{
+			description:    "should use the known error code in the error message",
+			err:            errors.New("Code=\"ReferencedResourceNotProvisioned\" Message=\"Cannot proceed with operation\""),
+			expectedReason: consts.ReferencedResourceNotProvisionedMessageCode,
+		},
+		{
+			description:    "should match the known error code case-insensitively",
+			err:            errors.New("publicipaddresscannotbedeleted: the public IP is in use"),
+			expectedReason: consts.CannotDeletePublicIPErrorMessageCode,
+		},
+		{
+			description:    "should use the generic reason if the response error code is not a valid reason",
+			err:            &azcore.ResponseError{StatusCode: http.StatusNotFound, ErrorCode: "not a reason", RawResponse: &http.Response{Body: http.NoBody}},
+			expectedReason: consts.ServiceConditionReasonReconcileFailed,
+		},
+		{
+			description:    "should use the generic reason for unclassified errors",
+			err:            errors.New("something went wrong"),
+			expectedReason: consts.ServiceConditionReasonReconcileFailed,
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expectedReason, getServiceConditionReason(tc.err))
+		})
+	}
+}
+
+func TestGetServiceIngressIPMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	az := GetTestCloud(ctrl)
+
+	service := getTestService("service1", v1.ProtocolTCP, nil, false, 80)
+	assert.Equal(t, ptr.To(v1.LoadBalancerIPModeVIP), az.getServiceIngressIPMode(&service))
+
+	service.Annotations[consts.ServiceAnnotationDisableLoadBalancerFloatingIP] = consts.TrueAnnotationValue
+	assert.Equal(t, ptr.To(v1.LoadBalancerIPModeProxy), az.getServiceIngressIPMode(&service))
+
+	service = getTestService("service1", v1.ProtocolTCP, nil, false, 80)
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypePODIP
+	assert.Equal(t, ptr.To(v1.LoadBalancerIPModeProxy), az.getServiceIngressIPMode(&service))
+}
+
+func TestSetServiceCondition(t *testing.T) {
+	service := getTestService("service1", v1.ProtocolTCP, nil, false, 80)
+	service.Generation = 2
+
+	// no-op without the recorder
+	setServiceCondition(context.Background(), consts.ServiceConditionLoadBalancerReady, nil)
+	removeServiceCondition(context.Background(), consts.ServiceConditionLoadBalancerReady)
+
+	ctx, recorder := newServiceConditionsContext(context.Background(), &service)
+	setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, errors.New("ResourceNotFound"))
+	setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, nil)
+	setServiceCondition(ctx, consts.ServiceConditionSecurityGroupReady, errors.New("failed"))
+	removeServiceCondition(ctx, consts.ServiceConditionPrivateLinkServiceReady)
+
+	assert.Equal(t, map[string]*metav1.Condition{
+		consts.ServiceConditionLoadBalancerReady: {
+			Type:               consts.ServiceConditionLoadBalancerReady,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 2,
+			Reason:             consts.ServiceConditionReasonReconciled,
+		},
+		consts.ServiceConditionSecurityGroupReady: {
+			Type:               consts.ServiceConditionSecurityGroupReady,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 2,
+			Reason:             consts.ServiceConditionReasonReconcileFailed,
+			Message:            "failed",
+		},
+		consts.ServiceConditionPrivateLinkServiceReady: nil,
+	}, recorder.conditions)
+}
+
+func TestUpdateServiceConditions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lastTransitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	for _, tc := range []struct {
+		description        string
+		existing           []metav1.Condition
+		update             func(ctx context.Context)
+		expectPatch        bool
+		expectedConditions []metav1.Condition
+	}{
+		{
+			description: "should add the conditions",
+			existing: []metav1.Condition{
+				{Type: "Other", Status: metav1.ConditionTrue, Reason: "Other", LastTransitionTime: lastTransitionTime},
+			},
+			update: func(ctx context.Context) {
+				setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, nil)
+				setServiceCondition(ctx, consts.ServiceConditionSecurityGroupReady, &azcore.ResponseError{ErrorCode: "SecurityRuleConflict"})
+			},
+			expectPatch: true,
+			expectedConditions: []metav1.Condition{
+				{Type: "Other", Status: metav1.ConditionTrue, Reason: "Other"},
+				{Type: consts.ServiceConditionLoadBalancerReady, Status: metav1.ConditionTrue, ObservedGeneration: 1, Reason: consts.ServiceConditionReasonReconciled},
+				{Type: consts.ServiceConditionSecurityGroupReady, Status: metav1.ConditionFalse, ObservedGeneration: 1, Reason: "SecurityRuleConflict"},
+			},
+		},
+		{
+			description: "should keep the last transition time if the status does not change and remove the stale condition",
+			existing: []metav1.Condition{
+				{Type: consts.ServiceConditionLoadBalancerReady, Status: metav1.ConditionFalse, ObservedGeneration: 1, Reason: "ResourceNotFound", LastTransitionTime: lastTransitionTime},
+				{Type: consts.ServiceConditionPrivateLinkServiceReady, Status: metav1.ConditionTrue, ObservedGeneration: 1, Reason: consts.ServiceConditionReasonReconciled, LastTransitionTime: lastTransitionTime},
+			},
+			update: func(ctx context.Context) {
+				setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, errors.New("ReferencedResourceNotProvisioned"))
+				removeServiceCondition(ctx, consts.ServiceConditionPrivateLinkServiceReady)
+			},
+			expectPatch: true,
+			expectedConditions: []metav1.Condition{
+				{Type: consts.ServiceConditionLoadBalancerReady, Status: metav1.ConditionFalse, ObservedGeneration: 1, Reason: consts.ReferencedResourceNotProvisionedMessageCode, LastTransitionTime: lastTransitionTime},
+			},
+		},
+		{
+			description: "should not patch the service if the conditions are up to date",
+			existing: []metav1.Condition{
+				{Type: consts.ServiceConditionLoadBalancerReady, Status: metav1.ConditionTrue, ObservedGeneration: 1, Reason: consts.ServiceConditionReasonReconciled, LastTransitionTime: lastTransitionTime},
+			},
+			update: func(ctx context.Context) {
+				setServiceCondition(ctx, consts.ServiceConditionLoadBalancerReady, nil)
+				removeServiceCondition(ctx, consts.ServiceConditionPrivateLinkServiceReady)
+			},
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			az := GetTestCloud(ctrl)
+			service := getTestService("service1", v1.ProtocolTCP, nil, false, 80)
+			service.Generation = 1
+			service.Status.Conditions = tc.existing
+			kubeClient := fake.NewSimpleClientset(&service)
+			az.KubeClient = kubeClient
+
+			ctx, recorder := newServiceConditionsContext(context.Background(), &service)
+			tc.update(ctx)
+			az.updateServiceConditions(ctx, &service, recorder)
+
+			patched := false
+			for _, action := range kubeClient.Actions() {
+				if action.GetVerb() == "patch" {
+					patched = true
+					assert.Equal(t, "status", action.GetSubresource())
+				}
+			}
+			assert.Equal(t, tc.expectPatch, patched)
+			if !tc.expectPatch {
+				return
+			}
+
+			updated, err := kubeClient.CoreV1().Services(service.Namespace).Get(context.Background(), service.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Len(t, updated.Status.Conditions, len(tc.expectedConditions))
+			for _, expected := range tc.expectedConditions {
+				actual := meta.FindStatusCondition(updated.Status.Conditions, expected.Type)
+				if !assert.NotNil(t, actual, expected.Type) {
+					continue
+				}
+				assert.Equal(t, expected.Status, actual.Status)
+				assert.Equal(t, expected.Reason, actual.Reason)
+				assert.Equal(t, expected.ObservedGeneration, actual.ObservedGeneration)
+				assert.False(t, actual.LastTransitionTime.IsZero())
+				if !expected.LastTransitionTime.IsZero() {
+					assert.True(t, expected.LastTransitionTime.Equal(&actual.LastTransitionTime))
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveServiceConditions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	service := getTestService("service1", v1.ProtocolTCP, nil, false, 80)
+	service.Status.Conditions = []metav1.Condition{
+		{Type: "Other", Status: metav1.ConditionTrue, Reason: "Other"},
+		{Type: consts.ServiceConditionLoadBalancerReady, Status: metav1.ConditionTrue, Reason: consts.ServiceConditionReasonReconciled},
+		{Type: consts.ServiceConditionSecurityGroupReady, Status: metav1.ConditionTrue, Reason: consts.ServiceConditionReasonReconciled},
+	}
+	kubeClient := fake.NewSimpleClientset(&service)
+	az.KubeClient = kubeClient
+
+	az.removeServiceConditions(context.Background(), &service)
+	updated, err := kubeClient.CoreV1().Services(service.Namespace).Get(context.Background(), service.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, updated.Status.Conditions, 1)
+	assert.Equal(t, "Other", updated.Status.Conditions[0].Type)
+
+	// The service has been deleted.
+	az.KubeClient = fake.NewSimpleClientset()
+	az.removeServiceConditions(context.Background(), &service)
+}
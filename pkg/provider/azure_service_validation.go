@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/loadbalancer"
+)
+
+// ValidateService checks the Azure annotations of the LoadBalancer service with the same parsers used by the
+// service reconciliation, so that the invalid configurations can be rejected before the service is created or
+// updated, e.g. by a validating admission webhook. It returns all the errors found.
+func ValidateService(service *v1.Service) error {
+	if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+		return nil
+	}
+
+	var errs []error
+	errs = append(errs, validateServiceAccessControl(service)...)
+	errs = append(errs, validateServiceHealthProbes(service)...)
+	errs = append(errs, validateServicePrivateLinkService(service)...)
+	return errors.Join(errs...)
+}
+
+func validateServiceAccessControl(service *v1.Service) []error {
+	var errs []error
+
+	sourceRanges, _, err := loadbalancer.SourceRanges(service)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	allowedIPRanges, _, err := loadbalancer.AllowedIPRanges(service)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	allowedIPGroups, err := loadbalancer.AllowedIPGroups(service)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := loadbalancer.AllowedApplicationSecurityGroups(service); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := loadbalancer.DestinationApplicationSecurityGroup(service); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := loadbalancer.PortSourceRestrictions(service); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := loadbalancer.AdditionalPublicIPs(service); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(sourceRanges) > 0 {
+		if len(allowedIPRanges) > 0 || len(allowedIPGroups) > 0 {
+			errs = append(errs, loadbalancer.ErrSetBothLoadBalancerSourceRangesAndAllowedIPRanges)
+		}
+		if len(loadbalancer.AllowedServiceTags(service)) > 0 {
+			errs = append(errs, fmt.Errorf("cannot set both spec.LoadBalancerSourceRanges and service annotation %s", consts.ServiceAnnotationAllowedServiceTags))
+		}
+	}
+	return errs
+}
+
+func validateServiceHealthProbes(service *v1.Service) []error {
+	var errs []error
+
+	for _, port := range service.Spec.Ports {
+		if port.Protocol == v1.ProtocolUDP || port.Protocol == v1.ProtocolSCTP {
+			continue
+		}
+		if _, err := consts.GetHealthProbeConfigOfPortFromK8sSvcAnnotation(service.Annotations, port.Port, consts.HealthProbeParamsPort, healthProbePortValidator(service)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse annotation %s: %w", consts.BuildHealthProbeAnnotationKeyForPort(port.Port, consts.HealthProbeParamsPort), err))
+		}
+		if _, _, err := getHealthProbeConfigProbeIntervalAndNumOfProbe(service, port.Port); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse health probe config for port %d: %w", port.Port, err))
+		}
+	}
+	return errs
+}
+
+func validateServicePrivateLinkService(service *v1.Service) []error {
+	if !serviceRequiresPLS(service) && !serviceHasAdditionalConfigs(service) {
+		return nil
+	}
+
+	var errs []error
+	if !requiresInternalLoadBalancer(service) && !consts.IsK8sServiceDisableLoadBalancerFloatingIP(service) {
+		errs = append(errs, fmt.Errorf("private link service annotations require an internal service or a service with floating IP disabled"))
+	}
+
+	ipConfigCount, countErr := getPLSIPConfigCount(service)
+	if countErr != nil {
+		errs = append(errs, fmt.Errorf("failed to parse annotation %s: %w", consts.ServiceAnnotationPLSIpConfigurationIPAddressCount, countErr))
+	}
+	ipv6ConfigCount, ipv6CountErr := getPLSIPv6ConfigCount(service)
+	if ipv6CountErr != nil {
+		errs = append(errs, fmt.Errorf("failed to parse annotation %s: %w", consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount, ipv6CountErr))
+	}
+	staticIPs, _, ipsErr := getPLSStaticIPs(service)
+	if ipsErr != nil {
+		errs = append(errs, fmt.Errorf("failed to parse annotation %s: %w", consts.ServiceAnnotationPLSIpConfigurationIPAddress, ipsErr))
+	}
+	if countErr == nil && ipv6CountErr == nil && ipsErr == nil {
+		staticIPv4s, staticIPv6s := splitPLSStaticIPs(staticIPs)
+		if err := validatePLSIPConfigCounts(ipConfigCount, ipv6ConfigCount, len(staticIPv4s), len(staticIPv6s)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := getPLSConnectionApprovalPolicy(service); err != nil {
+		errs = append(errs, fmt.Errorf("failed to parse annotation %s: %w", consts.ServiceAnnotationPLSMaxConnections, err))
+	}
+	return errs
+}
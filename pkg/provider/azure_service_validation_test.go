@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cloud-provider-azure/internal/testutil/fixture"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
+	"sigs.k8s.io/cloud-provider-azure/pkg/provider/loadbalancer"
+)
+
+func TestValidateService(t *testing.T) {
+	newService := func(annotations map[string]string) v1.Service {
+		svc := fixture.NewFixture().Kubernetes().Service().Build()
+		svc.Annotations = annotations
+		return svc
+	}
+	clusterIPService := newService(map[string]string{consts.ServiceAnnotationAllowedIPRanges: "invalid"})
+	clusterIPService.Spec.Type = v1.ServiceTypeClusterIP
+
+	for _, tc := range []struct {
+		desc        string
+		service     v1.Service
+		expectedErr []string
+	}{
+		{
+			desc:    "valid service",
+			service: fixture.NewFixture().Kubernetes().Service().WithAllowedIPRanges("10.0.0.0/24").Build(),
+		},
+		{
+			desc:    "non-LoadBalancer services are not validated",
+			service: clusterIPService,
+		},
+		{
+			desc:        "invalid allowed IP ranges",
+			service:     fixture.NewFixture().Kubernetes().Service().WithAllowedIPRanges("10.0.0.0/33").Build(),
+			expectedErr: []string{consts.ServiceAnnotationAllowedIPRanges},
+		},
+		{
+			desc: "source ranges together with allowed IP ranges",
+			service: fixture.NewFixture().Kubernetes().Service().
+				WithLoadBalancerSourceRanges("10.0.0.0/24").WithAllowedIPRanges("10.0.1.0/24").Build(),
+			expectedErr: []string{loadbalancer.ErrSetBothLoadBalancerSourceRangesAndAllowedIPRanges.Error()},
+		},
+		{
+			desc: "invalid health probe interval",
+			service: newService(map[string]string{
+				consts.BuildHealthProbeAnnotationKeyForPort(80, consts.HealthProbeParamsProbeInterval): "5s",
+			}),
+			expectedErr: []string{"port 80"},
+		},
+		{
+			desc: "private link service on a public service",
+			service: newService(map[string]string{
+				consts.ServiceAnnotationPLSCreation: "true",
+			}),
+			expectedErr: []string{"private link service annotations require an internal service"},
+		},
+		{
+			desc: "private link service with fewer IP configurations than static IPs",
+			service: newService(map[string]string{
+				consts.ServiceAnnotationLoadBalancerInternal:             "true",
+				consts.ServiceAnnotationPLSCreation:                      "true",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddressCount: "1",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddress:      "10.0.0.4 10.0.0.5",
+			}),
+			expectedErr: []string{"ipConfigCount(1) must be no smaller than number of static IPs specified(2)"},
+		},
+		{
+			desc: "private link service with too many IPv4 and IPv6 IP configurations",
+			service: newService(map[string]string{
+				consts.ServiceAnnotationLoadBalancerInternal:               "true",
+				consts.ServiceAnnotationPLSCreation:                        "true",
+				consts.ServiceAnnotationPLSIpConfigurationIPAddressCount:   "6",
+				consts.ServiceAnnotationPLSIpConfigurationIPv6AddressCount: "3",
+			}),
+			expectedErr: []string{"maximum number of private link service ipConfig is 8"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ValidateService(&tc.service)
+			if len(tc.expectedErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, expected := range tc.expectedErr {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
@@ -1288,6 +1288,12 @@ func getResourceGroupTestService(identifier, resourceGroup, loadBalancerIP strin
 	return svc
 }
 
+func getTestServiceWithLoadBalancerClass(identifier, loadBalancerClass string, requestedPorts ...int32) v1.Service {
+	svc := getTestService(identifier, v1.ProtocolTCP, nil, false, requestedPorts...)
+	svc.Spec.LoadBalancerClass = ptr.To(loadBalancerClass)
+	return svc
+}
+
 func getServiceSourceRanges(service *v1.Service) []string {
 	if len(service.Spec.LoadBalancerSourceRanges) == 0 {
 		if !requiresInternalLoadBalancer(service) {
@@ -2458,6 +2464,10 @@ func TestSetLBDefaults(t *testing.T) {
 	config := &config.Config{}
 	_ = az.setLBDefaults(config)
 	assert.Equal(t, config.LoadBalancerSKU, consts.LoadBalancerSKUStandard)
+
+	config.LoadBalancerSKU = consts.LoadBalancerSKUBasic
+	config.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypePODIP
+	assert.Error(t, az.setLBDefaults(config))
 }
 
 func TestCheckEnableMultipleStandardLoadBalancers(t *testing.T) {
@@ -2487,7 +2497,7 @@ func TestCheckEnableMultipleStandardLoadBalancers(t *testing.T) {
 		},
 	}
 
-	err := az.checkEnableMultipleStandardLoadBalancers()
+	err := az.checkEnableMultipleStandardLoadBalancers(az.MultipleStandardLoadBalancerConfigurations)
 	assert.Equal(t, "duplicated multiple standard load balancer configuration name kubernetes", err.Error())
 
 	az.MultipleStandardLoadBalancerConfigurations = []config.MultipleStandardLoadBalancerConfiguration{
@@ -2502,7 +2512,7 @@ func TestCheckEnableMultipleStandardLoadBalancers(t *testing.T) {
 		},
 	}
 
-	err = az.checkEnableMultipleStandardLoadBalancers()
+	err = az.checkEnableMultipleStandardLoadBalancers(az.MultipleStandardLoadBalancerConfigurations)
 	assert.Equal(t, "multiple standard load balancer configuration lb1 must have primary VMSet", err.Error())
 
 	az.MultipleStandardLoadBalancerConfigurations = []config.MultipleStandardLoadBalancerConfiguration{
@@ -2526,10 +2536,45 @@ func TestCheckEnableMultipleStandardLoadBalancers(t *testing.T) {
 		},
 	}
 
-	err = az.checkEnableMultipleStandardLoadBalancers()
+	err = az.checkEnableMultipleStandardLoadBalancers(az.MultipleStandardLoadBalancerConfigurations)
 	assert.Equal(t, "duplicated primary VMSet vmss-2 in multiple standard load balancer configurations lb2", err.Error())
 }
 
+func TestCheckLoadBalancerClasses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	az := GetTestCloud(ctrl)
+
+	az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: "a"}, {Name: "a"}}
+	err := az.checkLoadBalancerClasses()
+	assert.Equal(t, "duplicated load balancer class a", err.Error())
+
+	az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: ""}}
+	err = az.checkLoadBalancerClasses()
+	assert.Equal(t, "load balancer class name must not be empty", err.Error())
+
+	az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: "a", LoadBalancerConfigurationName: "lb1"}}
+	err = az.checkLoadBalancerClasses()
+	assert.Equal(t, "load balancer class a refers to load balancer configuration lb1, but multiple standard load balancers are not enabled", err.Error())
+
+	az.LoadBalancerSKU = consts.LoadBalancerSKUStandard
+	az.LoadBalancerBackendPoolConfigurationType = consts.LoadBalancerBackendPoolConfigurationTypeNodeIP
+	az.MultipleStandardLoadBalancerConfigurations = []config.MultipleStandardLoadBalancerConfiguration{
+		{
+			Name: "kubernetes",
+			MultipleStandardLoadBalancerConfigurationSpec: config.MultipleStandardLoadBalancerConfigurationSpec{
+				PrimaryVMSet: "vmss-0",
+			},
+		},
+	}
+	err = az.checkLoadBalancerClasses()
+	assert.Equal(t, "load balancer class a refers to load balancer configuration lb1 which is not found in multiple standard load balancer configurations", err.Error())
+
+	az.LoadBalancerClasses = []config.LoadBalancerClassConfiguration{{Name: "a", LoadBalancerConfigurationName: "kubernetes"}, {Name: "b"}}
+	err = az.checkLoadBalancerClasses()
+	assert.NoError(t, err)
+}
+
 func TestIsNodeReady(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -28,6 +28,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/ptr"
 
 	azcache "sigs.k8s.io/cloud-provider-azure/pkg/cache"
@@ -1041,3 +1042,31 @@ func TestIsInternalLoadBalancer(t *testing.T) {
 		})
 	}
 }
+
+func TestPatchServiceAnnotations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	az := GetTestCloud(ctrl)
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "svc",
+			Namespace:   "default",
+			Annotations: map[string]string{"keep": "true", "remove": "true"},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(service)
+	az.KubeClient = kubeClient
+
+	assert.NoError(t, az.patchServiceAnnotations(context.Background(), service, map[string]*string{
+		"add":    ptr.To("true"),
+		"remove": nil,
+	}))
+	svc, err := kubeClient.CoreV1().Services("default").Get(context.Background(), "svc", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"keep": "true", "add": "true"}, svc.Annotations)
+
+	// The deleted service is ignored.
+	service.Name = "deleted"
+	assert.NoError(t, az.patchServiceAnnotations(context.Background(), service, map[string]*string{"add": ptr.To("true")}))
+}
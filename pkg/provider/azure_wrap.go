@@ -32,6 +32,9 @@ var (
 	vmCacheTTLDefaultInSeconds           = 60
 	loadBalancerCacheTTLDefaultInSeconds = 120
 	publicIPCacheTTLDefaultInSeconds     = 120
+	ipGroupCacheTTLDefaultInSeconds      = 300
+
+	subnetRouteTableCacheTTLDefaultInSeconds = 300
 
 	azureNodeProviderIDRE    = regexp.MustCompile(`^azure:///subscriptions/(?:.*)/resourceGroups/(?:.*)/providers/Microsoft.Compute/(?:.*)`)
 	azureResourceGroupNameRE = regexp.MustCompile(`.*/subscriptions/(?:.*)/resourceGroups/(.+)/providers/(?:.*)`)
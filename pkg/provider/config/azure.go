@@ -60,6 +60,21 @@ type Config struct {
 	RouteTableName string `json:"routeTableName,omitempty" yaml:"routeTableName,omitempty"`
 	// The name of the resource group that the RouteTable is deployed in
 	RouteTableResourceGroup string `json:"routeTableResourceGroup,omitempty" yaml:"routeTableResourceGroup,omitempty"`
+	// (Optional) The names of the additional route tables in RouteTableResourceGroup for the node subnets that
+	// are associated with their own route tables. Every node route is written to RouteTableName and all of
+	// them, so that the pods in any subnet can reach the pods on any node, and the per-table route limit
+	// still applies to the whole cluster. The subnet of every node must be associated with one of them.
+	RouteTableNames []string `json:"routeTableNames,omitempty" yaml:"routeTableNames,omitempty"`
+	// (Optional) ExtraRoutes lists the routes managed by the route controller in addition to the node routes.
+	// They are merged with the routes in ExtraRoutesConfigMapName, and the managed routes that are no longer
+	// declared are deleted.
+	ExtraRoutes []ExtraRoute `json:"extraRoutes,omitempty" yaml:"extraRoutes,omitempty"`
+	// (Optional) ExtraRoutesConfigMapName is the name of the ConfigMap whose "routes" key holds a JSON or YAML
+	// list of extra routes, which can be changed without restarting the cloud controller manager.
+	ExtraRoutesConfigMapName string `json:"extraRoutesConfigMapName,omitempty" yaml:"extraRoutesConfigMapName,omitempty"`
+	// (Optional) ExtraRoutesConfigMapNamespace is the namespace of ExtraRoutesConfigMapName.
+	// If not set, it will be default to kube-system.
+	ExtraRoutesConfigMapNamespace string `json:"extraRoutesConfigMapNamespace,omitempty" yaml:"extraRoutesConfigMapNamespace,omitempty"`
 	// (Optional) The name of the availability set that should be used as the load balancer backend
 	// If this is set, the Azure cloudprovider will only add nodes from that availability set to the load
 	// balancer backend pool. If this is not set, and multiple agent pools (availability sets) are used, then
@@ -123,6 +138,24 @@ type Config struct {
 	// DisableOutboundSNAT disables the outbound SNAT for public load balancer rules.
 	// It should only be set when loadBalancerSku is standard. If not set, it will be default to false.
 	DisableOutboundSNAT *bool `json:"disableOutboundSNAT,omitempty" yaml:"disableOutboundSNAT,omitempty"`
+	// GatewayLoadBalancerFrontendIPConfigurationID is the ID of the gateway load balancer frontend IP configuration
+	// the public frontends of the services are chained to by default. The service annotation
+	// `service.beta.kubernetes.io/azure-load-balancer-gateway-frontend-ip-configuration-id` overrides it.
+	// It should only be set when loadBalancerSku is standard.
+	GatewayLoadBalancerFrontendIPConfigurationID string `json:"gatewayLoadBalancerFrontendIPConfigurationID,omitempty" yaml:"gatewayLoadBalancerFrontendIPConfigurationID,omitempty"`
+	// PublicIPDDoSProtectionMode is the default DDoS protection mode of the managed public IPs. Supported values
+	// are `VirtualNetworkInherited`, `Enabled` and `Disabled`. If not set, the DDoS settings are not managed.
+	PublicIPDDoSProtectionMode string `json:"publicIPDDoSProtectionMode,omitempty" yaml:"publicIPDDoSProtectionMode,omitempty"`
+	// PublicIPDDoSProtectionPlanID is the ID of the default custom DDoS protection plan of the managed public IPs.
+	// It can only be set when publicIPDDoSProtectionMode is `Enabled`.
+	PublicIPDDoSProtectionPlanID string `json:"publicIPDDoSProtectionPlanID,omitempty" yaml:"publicIPDDoSProtectionPlanID,omitempty"`
+	// PublicIPRoutingPreference is the default routing preference of the new managed public IPs. Supported values are
+	// `MicrosoftNetwork` and `Internet`. It should only be set when loadBalancerSku is standard. Changing it does not
+	// recreate the existing public IPs.
+	PublicIPRoutingPreference string `json:"publicIPRoutingPreference,omitempty" yaml:"publicIPRoutingPreference,omitempty"`
+	// PublicIPTier is the default SKU tier of the new managed public IPs. The supported value is `Regional`.
+	// It should only be set when loadBalancerSku is standard. Changing it does not recreate the existing public IPs.
+	PublicIPTier string `json:"publicIPTier,omitempty" yaml:"publicIPTier,omitempty"`
 
 	// Maximum allowed LoadBalancer Rule Count is the limit enforced by Azure Load balancer
 	MaximumLoadBalancerRuleCount int `json:"maximumLoadBalancerRuleCount,omitempty" yaml:"maximumLoadBalancerRuleCount,omitempty"`
@@ -150,11 +183,77 @@ type Config struct {
 	// If the length is not 0, it is assumed the multiple standard load balancers mode is on. In this case,
 	// there must be one configuration named "<clustername>" or an error will be reported.
 	MultipleStandardLoadBalancerConfigurations []MultipleStandardLoadBalancerConfiguration `json:"multipleStandardLoadBalancerConfigurations,omitempty" yaml:"multipleStandardLoadBalancerConfigurations,omitempty"`
+	// LoadBalancerRebalanceIntervalInSeconds is the interval for rebalancing the services across the multiple standard
+	// load balancers. The services are moved off the load balancers with AllowServicePlacement set to false, and from
+	// the load balancers with the most load balancing rules to the ones with the fewest. Rebalancing is disabled if it is 0,
+	// which is the default.
+	LoadBalancerRebalanceIntervalInSeconds int `json:"loadBalancerRebalanceIntervalInSeconds,omitempty" yaml:"loadBalancerRebalanceIntervalInSeconds,omitempty"`
+	// LoadBalancerRebalanceMaxMoves is the maximum number of services moved by one rebalance. Default is 1.
+	LoadBalancerRebalanceMaxMoves int `json:"loadBalancerRebalanceMaxMoves,omitempty" yaml:"loadBalancerRebalanceMaxMoves,omitempty"`
+	// LoadBalancerRebalanceRuleCountThreshold is the minimum difference of the load balancing rule counts between two
+	// load balancers to move services from one to the other. Default is 10.
+	LoadBalancerRebalanceRuleCountThreshold int `json:"loadBalancerRebalanceRuleCountThreshold,omitempty" yaml:"loadBalancerRebalanceRuleCountThreshold,omitempty"`
+	// LoadBalancerRebalanceMoveTimeoutInSeconds is the time a service can take to be moved to another load balancer.
+	// The move is abandoned after the timeout, so that the other moves are not blocked. Default is 1800 seconds.
+	LoadBalancerRebalanceMoveTimeoutInSeconds int `json:"loadBalancerRebalanceMoveTimeoutInSeconds,omitempty" yaml:"loadBalancerRebalanceMoveTimeoutInSeconds,omitempty"`
+	// IPGroupResyncIntervalInSeconds is the interval for checking the changes of the IP Groups allowed by the services
+	// through the annotation service.beta.kubernetes.io/azure-allowed-ip-groups. The services are reconciled again by the
+	// ip-group controller if the addresses of their IP Groups change. Default is 300. The check is disabled if it is negative.
+	IPGroupResyncIntervalInSeconds int `json:"ipGroupResyncIntervalInSeconds,omitempty" yaml:"ipGroupResyncIntervalInSeconds,omitempty"`
+	// PLSConnectionResyncIntervalInSeconds is the interval for checking the private endpoint connections of the private
+	// link services owned by the services, which approves or rejects the pending connections according to the service
+	// annotations and publishes the private link services and their connections on the services. Default is 60.
+	// The check is disabled if it is negative. The private link services of the services without an approval policy
+	// are read from the cache, so the published connections may lag behind by up to plsCacheTTLInSeconds.
+	PLSConnectionResyncIntervalInSeconds int `json:"plsConnectionResyncIntervalInSeconds,omitempty" yaml:"plsConnectionResyncIntervalInSeconds,omitempty"`
+
+	// LoadBalancerClasses lists the values of `spec.loadBalancerClass` that the cloud provider reconciles.
+	// The services of these classes are reconciled by the load-balancer-class controller, since the
+	// service controller skips every service with a load balancer class. Services with a load balancer
+	// class that is not in the list are left to other controllers, and the Azure resources they owned
+	// will be released.
+	LoadBalancerClasses []LoadBalancerClassConfiguration `json:"loadBalancerClasses,omitempty" yaml:"loadBalancerClasses,omitempty"`
+	// ReconcileServicesWithoutLoadBalancerClass determines whether the services without `spec.loadBalancerClass`
+	// are reconciled by the cloud provider. If not set, it will be default to true.
+	ReconcileServicesWithoutLoadBalancerClass *bool `json:"reconcileServicesWithoutLoadBalancerClass,omitempty" yaml:"reconcileServicesWithoutLoadBalancerClass,omitempty"`
 
 	// RouteUpdateIntervalInSeconds is the interval for updating routes. Default is 30 seconds.
 	RouteUpdateIntervalInSeconds int `json:"routeUpdateIntervalInSeconds,omitempty" yaml:"routeUpdateIntervalInSeconds,omitempty"`
+	// RouteDriftCheckIntervalInSeconds is the interval for comparing the node routes in the route tables with
+	// the pod CIDRs of the nodes. The missing and drifted routes are repaired through the route updater, and the
+	// routes with another name and the same address prefix as a pod CIDR are reported. The check is disabled if
+	// it is 0, which is the default.
+	RouteDriftCheckIntervalInSeconds int `json:"routeDriftCheckIntervalInSeconds,omitempty" yaml:"routeDriftCheckIntervalInSeconds,omitempty"`
 	// LoadBalancerBackendPoolUpdateIntervalInSeconds is the interval for updating load balancer backend pool of local services. Default is 30 seconds.
 	LoadBalancerBackendPoolUpdateIntervalInSeconds int `json:"loadBalancerBackendPoolUpdateIntervalInSeconds,omitempty" yaml:"loadBalancerBackendPoolUpdateIntervalInSeconds,omitempty"`
+	// LoadBalancerBackendDrainTimeoutInSeconds is how long the backend addresses of a draining node stay in the
+	// IP-based backend pools with admin state Down before they are removed, so the load balancer stops sending
+	// new flows to the node while the existing ones finish. A node is drained when it is unschedulable or
+	// carries the LoadBalancerBackendDrainTaintKey taint. Drain is disabled if it is 0, which is the default.
+	LoadBalancerBackendDrainTimeoutInSeconds int `json:"loadBalancerBackendDrainTimeoutInSeconds,omitempty" yaml:"loadBalancerBackendDrainTimeoutInSeconds,omitempty"`
+	// LoadBalancerBackendDrainTaintKey is the key of the taint which marks a node to be drained from the load balancers.
+	// Default is "ToBeDeletedByClusterAutoscaler".
+	LoadBalancerBackendDrainTaintKey string `json:"loadBalancerBackendDrainTaintKey,omitempty" yaml:"loadBalancerBackendDrainTaintKey,omitempty"`
+
+	// OrphanedResourceGCIntervalInSeconds is the interval for collecting the orphaned Azure network resources
+	// of the cluster. It only takes effect when the orphaned-resource-gc controller is enabled. Default is 3600 seconds.
+	OrphanedResourceGCIntervalInSeconds int `json:"orphanedResourceGCIntervalInSeconds,omitempty" yaml:"orphanedResourceGCIntervalInSeconds,omitempty"`
+	// OrphanedResourceGCGracePeriodInSeconds is how long a resource must stay orphaned before it is deleted. Default is 3600 seconds.
+	OrphanedResourceGCGracePeriodInSeconds int `json:"orphanedResourceGCGracePeriodInSeconds,omitempty" yaml:"orphanedResourceGCGracePeriodInSeconds,omitempty"`
+	// OrphanedResourceGCReportOnly determines whether the orphaned resources are only reported
+	// in the logs and metrics instead of being deleted. Default is false.
+	OrphanedResourceGCReportOnly bool `json:"orphanedResourceGCReportOnly,omitempty" yaml:"orphanedResourceGCReportOnly,omitempty"`
+
+	// PublicIPPoolSize is the number of unassigned standard public IPs kept in the pool per IP family, which are
+	// handed to the new services to speed up their creation. It only takes effect when the public-ip-pool controller
+	// is enabled. The pool is disabled if it is 0, which is the default. The maximum is 50. Only the external services
+	// take public IPs from the pool. Setting it to 0 deletes the unassigned public IPs in the pool.
+	PublicIPPoolSize int `json:"publicIPPoolSize,omitempty" yaml:"publicIPPoolSize,omitempty"`
+	// PublicIPPoolIPFamilies are the IP families of the public IPs in the pool. Supported values are `IPv4` and `IPv6`.
+	// Default is `IPv4`.
+	PublicIPPoolIPFamilies []string `json:"publicIPPoolIPFamilies,omitempty" yaml:"publicIPPoolIPFamilies,omitempty"`
+	// PublicIPPoolRefillIntervalInSeconds is the interval for refilling the public IP pool. Default is 60 seconds.
+	PublicIPPoolRefillIntervalInSeconds int `json:"publicIPPoolRefillIntervalInSeconds,omitempty" yaml:"publicIPPoolRefillIntervalInSeconds,omitempty"`
 
 	// ClusterServiceLoadBalancerHealthProbeMode determines the health probe mode for cluster service load balancer.
 	// Supported values are `shared` and `servicenodeport`.
@@ -180,6 +279,10 @@ func (az *Config) IsLBBackendPoolTypeNodeIP() bool {
 	return strings.EqualFold(az.LoadBalancerBackendPoolConfigurationType, consts.LoadBalancerBackendPoolConfigurationTypeNodeIP)
 }
 
+func (az *Config) IsLBBackendPoolTypePodIP() bool {
+	return strings.EqualFold(az.LoadBalancerBackendPoolConfigurationType, consts.LoadBalancerBackendPoolConfigurationTypePODIP)
+}
+
 func (az *Config) GetPutVMSSVMBatchSize() int {
 	return az.PutVMSSVMBatchSize
 }
@@ -208,6 +311,26 @@ func (az *Config) UseSingleStandardLoadBalancer() bool {
 	return az.UseStandardLoadBalancer() && len(az.MultipleStandardLoadBalancerConfigurations) == 0
 }
 
+// GetLoadBalancerClassConfiguration returns the configuration of the given load balancer class,
+// or nil if the class is not reconciled by the cloud provider.
+func (az *Config) GetLoadBalancerClassConfiguration(loadBalancerClass string) *LoadBalancerClassConfiguration {
+	for i := range az.LoadBalancerClasses {
+		if az.LoadBalancerClasses[i].Name == loadBalancerClass {
+			return &az.LoadBalancerClasses[i]
+		}
+	}
+	return nil
+}
+
+// IsLoadBalancerClassManaged returns true if the services with the given load balancer class
+// should be reconciled by the cloud provider. A nil class means the service does not set one.
+func (az *Config) IsLoadBalancerClassManaged(loadBalancerClass *string) bool {
+	if loadBalancerClass == nil {
+		return az.ReconcileServicesWithoutLoadBalancerClass == nil || *az.ReconcileServicesWithoutLoadBalancerClass
+	}
+	return az.GetLoadBalancerClassConfiguration(*loadBalancerClass) != nil
+}
+
 func (az *Config) IsStackCloud() bool {
 	return strings.EqualFold(az.Cloud, consts.AzureStackCloudName) && !az.DisableAzureStackCloud
 }
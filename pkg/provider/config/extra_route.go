@@ -0,0 +1,40 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// ExtraRoute stores a route that is managed by the route controller in addition to the node routes,
+// such as the default route to a firewall appliance or the routes to a VPN gateway.
+type ExtraRoute struct {
+	// Name is the unique name of the route. The route is created in Azure with the name prefixed by "k8s_extra_".
+	Name string `json:"name" yaml:"name"`
+
+	// RouteTableName is the route table of the route, which must be RouteTableName or one of RouteTableNames.
+	// If not set, it will be default to RouteTableName.
+	RouteTableName string `json:"routeTableName,omitempty" yaml:"routeTableName,omitempty"`
+
+	// AddressPrefix is the destination CIDR of the route. It must be unique in the route table,
+	// and must not be within the pod CIDR of any node.
+	AddressPrefix string `json:"addressPrefix" yaml:"addressPrefix"`
+
+	// NextHopType is the type of the next hop. Candidate values are: VirtualAppliance, VirtualNetworkGateway,
+	// VnetLocal, Internet and None.
+	NextHopType string `json:"nextHopType" yaml:"nextHopType"`
+
+	// NextHopIPAddress is the IP address of the next hop, which is only allowed and required
+	// when NextHopType is VirtualAppliance. It must be in the IP family of AddressPrefix.
+	NextHopIPAddress string `json:"nextHopIpAddress,omitempty" yaml:"nextHopIpAddress,omitempty"`
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// LoadBalancerClassConfiguration stores the properties regarding a load balancer class
+// that is reconciled by the cloud provider.
+type LoadBalancerClassConfiguration struct {
+	// Name is the value of `spec.loadBalancerClass` of the services
+	// that should be reconciled by the cloud provider.
+	Name string `json:"name" yaml:"name"`
+
+	// LoadBalancerConfigurationName is the name of one of the multiple standard load balancer
+	// configurations. If set, services of this class are placed on that load balancer unless
+	// the service selects load balancers by the `service.beta.kubernetes.io/azure-load-balancer-configurations`
+	// annotation. It can only be used when multiple standard load balancers are enabled.
+	LoadBalancerConfigurationName string `json:"loadBalancerConfigurationName,omitempty" yaml:"loadBalancerConfigurationName,omitempty"`
+}
@@ -18,6 +18,7 @@ package config
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	utilsets "sigs.k8s.io/cloud-provider-azure/pkg/util/sets"
 )
@@ -77,3 +78,39 @@ type MultipleStandardLoadBalancerConfigurationStatus struct {
 	// It will be used in EnsureHostsInPool to make sure the given ones are in the backend pool.
 	ActiveNodes *utilsets.IgnoreCaseSet `json:"activeNodes" yaml:"activeNodes"`
 }
+
+const (
+	// MultipleStandardLoadBalancerConfigurationGroup is the API group of the MultipleStandardLoadBalancerConfiguration custom resource.
+	MultipleStandardLoadBalancerConfigurationGroup = "cloudprovider.azure.x-k8s.io"
+	// MultipleStandardLoadBalancerConfigurationVersion is the API version of the MultipleStandardLoadBalancerConfiguration custom resource.
+	MultipleStandardLoadBalancerConfigurationVersion = "v1alpha1"
+	// MultipleStandardLoadBalancerConfigurationKind is the kind of the MultipleStandardLoadBalancerConfiguration custom resource.
+	MultipleStandardLoadBalancerConfigurationKind = "MultipleStandardLoadBalancerConfiguration"
+)
+
+// MultipleStandardLoadBalancerConfigurationGVR is the resource of the cluster-scoped MultipleStandardLoadBalancerConfiguration custom resource.
+var MultipleStandardLoadBalancerConfigurationGVR = schema.GroupVersionResource{
+	Group:    MultipleStandardLoadBalancerConfigurationGroup,
+	Version:  MultipleStandardLoadBalancerConfigurationVersion,
+	Resource: "multiplestandardloadbalancerconfigurations",
+}
+
+// MultipleStandardLoadBalancerConfigurationResource is the MultipleStandardLoadBalancerConfiguration custom resource.
+// The name of the resource is the name of the load balancer. It has the same spec as the configuration
+// in the cloud config file, and its status holds the services and nodes on the load balancer.
+type MultipleStandardLoadBalancerConfigurationResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MultipleStandardLoadBalancerConfigurationSpec           `json:"spec,omitempty"`
+	Status MultipleStandardLoadBalancerConfigurationResourceStatus `json:"status,omitempty"`
+}
+
+// MultipleStandardLoadBalancerConfigurationResourceStatus is the status of the MultipleStandardLoadBalancerConfiguration custom resource.
+type MultipleStandardLoadBalancerConfigurationResourceStatus struct {
+	// ActiveServices are the services that are supposed to use the load balancer, in the format of "namespace/name".
+	ActiveServices []string `json:"activeServices,omitempty"`
+
+	// ActiveNodes are the nodes that are supposed to be in the load balancer.
+	ActiveNodes []string `json:"activeNodes,omitempty"`
+}
@@ -19,6 +19,7 @@ package loadbalancer
 import (
 	"fmt"
 	"net/netip"
+	"slices"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
@@ -46,15 +47,27 @@ type AccessControl struct {
 	// immutable pre-compute states.
 	SourceRanges                           []netip.Prefix
 	AllowedIPRanges                        []netip.Prefix
+	AllowedIPGroups                        []string
 	AllowedServiceTags                     []string
+	AllowedApplicationSecurityGroups       []string
+	DestinationApplicationSecurityGroup    string
+	PortSourceRestrictions                 map[int32]*PortSourceRestriction
 	invalidRanges                          []string
 	securityRuleDestinationPortsByProtocol map[armnetwork.SecurityRuleProtocol][]int32
+	// the allow lists of the destination ports of the security rules restricted by the port annotations.
+	// protocol -> destination port -> allow list
+	restrictedDestinationPorts map[armnetwork.SecurityRuleProtocol]map[int32]*PortSourceRestriction
 }
 
 type accessControlOptions struct {
-	EventEmitter K8sEventEmitter
+	EventEmitter                           K8sEventEmitter
+	SecurityRuleDestinationPortsByProtocol map[armnetwork.SecurityRuleProtocol][]int32
+	IPGroupResolver                        IPGroupResolver
 }
 
+// IPGroupResolver returns the addresses of the Azure IP Group with the given resource ID.
+type IPGroupResolver func(id string) ([]string, error)
+
 var defaultAccessControlOptions = accessControlOptions{
 	EventEmitter: noopEventEmitter,
 }
@@ -67,6 +80,23 @@ func WithEventEmitter(emitter K8sEventEmitter) AccessControlOption {
 	}
 }
 
+// WithSecurityRuleDestinationPortsByProtocol overrides the destination ports of the security rules,
+// which are derived from the service ports by default. The destination ports of each protocol should be
+// in the same order as the service ports of the protocol.
+func WithSecurityRuleDestinationPortsByProtocol(ports map[armnetwork.SecurityRuleProtocol][]int32) AccessControlOption {
+	return func(o *accessControlOptions) {
+		o.SecurityRuleDestinationPortsByProtocol = ports
+	}
+}
+
+// WithIPGroupResolver sets the resolver of the IP Groups allowed by the service. The addresses of the IP Groups
+// are merged into the allowed IP ranges. The IP Groups are not resolved without the resolver.
+func WithIPGroupResolver(resolver IPGroupResolver) AccessControlOption {
+	return func(o *accessControlOptions) {
+		o.IPGroupResolver = resolver
+	}
+}
+
 func NewAccessControl(logger logr.Logger, svc *v1.Service, sg *armnetwork.SecurityGroup, opts ...AccessControlOption) (*AccessControl, error) {
 	logger = logger.WithName("AccessControl").WithValues("security-group", ptr.To(sg.Name))
 
@@ -95,10 +125,56 @@ func NewAccessControl(logger logr.Logger, svc *v1.Service, sg *armnetwork.Securi
 		// Backward compatibility: no error but emit a warning event.
 		eventEmitter(svc, v1.EventTypeWarning, "InvalidAllowedIPRanges", EventMessageOfInvalidAllowedIPRanges(invalidAllowedIPRanges))
 	}
+	allowedIPGroups, err := AllowedIPGroups(svc)
+	if err != nil {
+		logger.Error(err, "Failed to parse AllowedIPGroups configuration")
+		return nil, err
+	}
+	if len(allowedIPGroups) > 0 && options.IPGroupResolver == nil {
+		logger.V(4).Info("Skip resolving the allowed IP Groups without resolver", "ip-groups", allowedIPGroups)
+	} else {
+		for _, id := range allowedIPGroups {
+			addresses, err := options.IPGroupResolver(id)
+			if err != nil {
+				logger.Error(err, "Failed to resolve IP Group", "ip-group", id)
+				return nil, fmt.Errorf("resolve IP Group %s: %w", id, err)
+			}
+			prefixes, invalidAddresses := ParseIPGroupAddresses(addresses)
+			if len(invalidAddresses) > 0 {
+				// Same as the invalid allowed IP ranges: no error but emit a warning event.
+				eventEmitter(svc, v1.EventTypeWarning, "InvalidAllowedIPGroupAddresses", EventMessageOfInvalidAllowedIPGroupAddresses(id, invalidAddresses))
+				invalidAllowedIPRanges = append(invalidAllowedIPRanges, invalidAddresses...)
+			}
+			allowedIPRanges = append(allowedIPRanges, prefixes...)
+		}
+	}
 	allowedServiceTags := AllowedServiceTags(svc)
-	securityRuleDestinationPortsByProtocol, err := SecurityRuleDestinationPortsByProtocol(svc)
+	allowedASGs, err := AllowedApplicationSecurityGroups(svc)
+	if err != nil {
+		logger.Error(err, "Failed to parse AllowedApplicationSecurityGroups configuration")
+		return nil, err
+	}
+	dstASG, err := DestinationApplicationSecurityGroup(svc)
+	if err != nil {
+		logger.Error(err, "Failed to parse DestinationApplicationSecurityGroup configuration")
+		return nil, err
+	}
+	securityRuleDestinationPortsByProtocol := options.SecurityRuleDestinationPortsByProtocol
+	if securityRuleDestinationPortsByProtocol == nil {
+		securityRuleDestinationPortsByProtocol, err = SecurityRuleDestinationPortsByProtocol(svc)
+		if err != nil {
+			logger.Error(err, "Failed to parse service Spec.Ports")
+			return nil, err
+		}
+	}
+	portSourceRestrictions, err := PortSourceRestrictions(svc)
 	if err != nil {
-		logger.Error(err, "Failed to parse service Spec.Ports")
+		logger.Error(err, "Failed to parse PortSourceRestrictions configuration")
+		return nil, err
+	}
+	restrictedDstPorts, err := restrictedDestinationPorts(svc, securityRuleDestinationPortsByProtocol, portSourceRestrictions)
+	if err != nil {
+		logger.Error(err, "Failed to map the port source restrictions to the destination ports")
 		return nil, err
 	}
 	if len(sourceRanges) > 0 && len(allowedIPRanges) > 0 {
@@ -121,18 +197,90 @@ func NewAccessControl(logger logr.Logger, svc *v1.Service, sg *armnetwork.Securi
 		sgHelper:                               sgHelper,
 		SourceRanges:                           sourceRanges,
 		AllowedIPRanges:                        allowedIPRanges,
+		AllowedIPGroups:                        allowedIPGroups,
 		AllowedServiceTags:                     allowedServiceTags,
+		AllowedApplicationSecurityGroups:       allowedASGs,
+		DestinationApplicationSecurityGroup:    dstASG,
+		PortSourceRestrictions:                 portSourceRestrictions,
 		invalidRanges:                          append(invalidSourceRanges, invalidAllowedIPRanges...),
 		securityRuleDestinationPortsByProtocol: securityRuleDestinationPortsByProtocol,
+		restrictedDestinationPorts:             restrictedDstPorts,
 	}, nil
 }
 
+// restrictedDestinationPorts maps the destination ports of the security rules to the allow lists of their service ports.
+// The destination ports of each protocol are in the same order as the service ports of the protocol.
+// Multiple service ports may share a destination port, e.g. the same target port in pod IP mode, and it returns
+// an error if they have different allow lists, as the security rules cannot tell them apart.
+func restrictedDestinationPorts(
+	svc *v1.Service,
+	dstPortsByProtocol map[armnetwork.SecurityRuleProtocol][]int32,
+	restrictions map[int32]*PortSourceRestriction,
+) (map[armnetwork.SecurityRuleProtocol]map[int32]*PortSourceRestriction, error) {
+	rv := make(map[armnetwork.SecurityRuleProtocol]map[int32]*PortSourceRestriction)
+	if len(restrictions) == 0 {
+		return rv, nil
+	}
+
+	var (
+		indexes = make(map[armnetwork.SecurityRuleProtocol]int)
+		// protocol -> destination port -> the service port using it
+		servicePorts = make(map[armnetwork.SecurityRuleProtocol]map[int32]int32)
+	)
+	for _, port := range svc.Spec.Ports {
+		protocol, err := securitygroup.ProtocolFromKubernetes(port.Protocol)
+		if err != nil {
+			return nil, err
+		}
+		idx := indexes[protocol]
+		indexes[protocol]++
+
+		dstPorts := dstPortsByProtocol[protocol]
+		if idx >= len(dstPorts) {
+			if _, found := restrictions[port.Port]; found {
+				return nil, fmt.Errorf("no destination port of the security rules for service port %d/%s", port.Port, port.Protocol)
+			}
+			continue
+		}
+		dstPort := dstPorts[idx]
+
+		if servicePorts[protocol] == nil {
+			servicePorts[protocol] = make(map[int32]int32)
+		}
+		if prev, found := servicePorts[protocol][dstPort]; found {
+			if !restrictions[prev].equalSources(restrictions[port.Port]) {
+				return nil, fmt.Errorf(
+					"service ports %d and %d share the destination port %d/%s of the security rules but have different allowed sources",
+					prev, port.Port, dstPort, port.Protocol,
+				)
+			}
+			continue
+		}
+		servicePorts[protocol][dstPort] = port.Port
+
+		restriction, found := restrictions[port.Port]
+		if !found {
+			continue
+		}
+		if rv[protocol] == nil {
+			rv[protocol] = make(map[int32]*PortSourceRestriction)
+		}
+		rv[protocol][dstPort] = restriction
+	}
+	return rv, nil
+}
+
 // IsAllowFromInternet returns true if the given service is allowed to be accessed from internet.
 // To be specific,
-// 1. For all types of LB, it returns false if the given service is specified with `service tags` or `not allowed all IP ranges`, including invalid IP ranges.
+// 1. For all types of LB, it returns false if the given service is specified with `service tags`, `application security groups`,
+// empty `IP Groups` or `not allowed all IP ranges`, including invalid IP ranges.
 // 2. For internal LB, it returns true iff the given service is explicitly specified with `allowed all IP ranges`. Refer: https://github.com/kubernetes-sigs/cloud-provider-azure/issues/698
 func (ac *AccessControl) IsAllowFromInternet() bool {
-	if len(ac.AllowedServiceTags) > 0 {
+	if len(ac.AllowedServiceTags) > 0 || len(ac.AllowedApplicationSecurityGroups) > 0 {
+		return false
+	}
+	if len(ac.AllowedIPGroups) > 0 && len(ac.AllowedIPRanges) == 0 {
+		// The IP Groups are empty or not resolved.
 		return false
 	}
 	if len(ac.SourceRanges) > 0 && !iputil.IsPrefixesAllowAll(ac.SourceRanges) {
@@ -155,8 +303,9 @@ func (ac *AccessControl) IsAllowFromInternet() bool {
 // By default, NSG allow traffic from the VNet.
 func (ac *AccessControl) DenyAllExceptSourceRanges() bool {
 	var (
-		annotationEnabled      = strings.EqualFold(ac.svc.Annotations[consts.ServiceAnnotationDenyAllExceptLoadBalancerSourceRanges], "true")
-		sourceRangeSpecified   = len(ac.SourceRanges) > 0 || len(ac.AllowedIPRanges) > 0
+		annotationEnabled    = strings.EqualFold(ac.svc.Annotations[consts.ServiceAnnotationDenyAllExceptLoadBalancerSourceRanges], "true")
+		sourceRangeSpecified = len(ac.SourceRanges) > 0 || len(ac.AllowedIPRanges) > 0 || len(ac.AllowedIPGroups) > 0 ||
+			len(ac.AllowedApplicationSecurityGroups) > 0
 		invalidRangesSpecified = len(ac.invalidRanges) > 0
 	)
 	return (annotationEnabled && sourceRangeSpecified) || invalidRangesSpecified
@@ -233,60 +382,204 @@ func (ac *AccessControl) PatchSecurityGroup(dstIPv4Addresses, dstIPv6Addresses [
 		if !found {
 			continue
 		}
-		if len(dstIPv4Addresses) > 0 {
-			for _, tag := range allowedServiceTags {
-				err := ac.sgHelper.AddRuleForAllowedServiceTag(tag, protocol, dstIPv4Addresses, dstPorts)
-				if err != nil {
-					return fmt.Errorf("add rule for allowed service tag on IPv4: %w", err)
+		var (
+			restrictedDstPorts   = ac.restrictedDestinationPorts[protocol]
+			unrestrictedDstPorts = fnutil.Filter(func(p int32) bool {
+				_, restricted := restrictedDstPorts[p]
+				return !restricted
+			}, dstPorts)
+		)
+		if len(unrestrictedDstPorts) > 0 {
+			if len(dstIPv4Addresses) > 0 {
+				if err := ac.patchAllowRules(protocol, iputil.IPv4, dstIPv4Addresses, allowedServiceTags, allowedIPv4Ranges, ac.AllowedApplicationSecurityGroups, unrestrictedDstPorts); err != nil {
+					return err
 				}
 			}
-
-			if len(allowedIPv4Ranges) > 0 {
-				err := ac.sgHelper.AddRuleForAllowedIPRanges(allowedIPv4Ranges, protocol, dstIPv4Addresses, dstPorts)
-				if err != nil {
-					return fmt.Errorf("add rule for allowed IP ranges on IPv4: %w", err)
+			if len(dstIPv6Addresses) > 0 {
+				if err := ac.patchAllowRules(protocol, iputil.IPv6, dstIPv6Addresses, allowedServiceTags, allowedIPv6Ranges, ac.AllowedApplicationSecurityGroups, unrestrictedDstPorts); err != nil {
+					return err
 				}
 			}
 		}
-		if len(dstIPv6Addresses) > 0 {
-			for _, tag := range allowedServiceTags {
-				err := ac.sgHelper.AddRuleForAllowedServiceTag(tag, protocol, dstIPv6Addresses, dstPorts)
-				if err != nil {
-					return fmt.Errorf("add rule for allowed service tag on IPv6: %w", err)
-				}
+		if err := ac.patchRestrictedAllowRules(protocol, dstIPv4Addresses, dstIPv6Addresses, restrictedDstPorts); err != nil {
+			return err
+		}
+		if !ac.DenyAllExceptSourceRanges() {
+			// The restricted ports are not covered by the deny all rule.
+			if err := ac.patchRestrictedDenyRules(protocol, dstIPv4Addresses, dstIPv6Addresses, restrictedDstPorts); err != nil {
+				return err
 			}
+		}
+	}
 
-			if len(allowedIPv6Ranges) > 0 {
-				err := ac.sgHelper.AddRuleForAllowedIPRanges(allowedIPv6Ranges, protocol, dstIPv6Addresses, dstPorts)
-				if err != nil {
-					return fmt.Errorf("add rule for allowed IP ranges on IPv6: %w", err)
-				}
+	if ac.DenyAllExceptSourceRanges() {
+		if err := ac.patchDenyAllRules(dstIPv4Addresses, dstIPv6Addresses); err != nil {
+			return err
+		}
+	}
+
+	logger.V(10).Info("Completed patching")
+
+	return nil
+}
+
+// patchRestrictedAllowRules adds the allow rules for each destination port restricted by the port annotations.
+func (ac *AccessControl) patchRestrictedAllowRules(
+	protocol armnetwork.SecurityRuleProtocol,
+	dstIPv4Addresses, dstIPv6Addresses []netip.Addr,
+	restrictedDstPorts map[int32]*PortSourceRestriction,
+) error {
+	dstPorts := make([]int32, 0, len(restrictedDstPorts))
+	for p := range restrictedDstPorts {
+		dstPorts = append(dstPorts, p)
+	}
+	slices.Sort(dstPorts)
+
+	for _, dstPort := range dstPorts {
+		var (
+			restriction                          = restrictedDstPorts[dstPort]
+			allowedIPv4Ranges, allowedIPv6Ranges = iputil.GroupPrefixesByFamily(iputil.AggregatePrefixes(restriction.AllowedIPRanges))
+		)
+		if len(dstIPv4Addresses) > 0 {
+			if err := ac.patchAllowRules(protocol, iputil.IPv4, dstIPv4Addresses, restriction.AllowedServiceTags, allowedIPv4Ranges, nil, []int32{dstPort}); err != nil {
+				return fmt.Errorf("patch rules of port %d: %w", restriction.Port, err)
+			}
+		}
+		if len(dstIPv6Addresses) > 0 {
+			if err := ac.patchAllowRules(protocol, iputil.IPv6, dstIPv6Addresses, restriction.AllowedServiceTags, allowedIPv6Ranges, nil, []int32{dstPort}); err != nil {
+				return fmt.Errorf("patch rules of port %d: %w", restriction.Port, err)
 			}
 		}
 	}
+	return nil
+}
 
-	if ac.DenyAllExceptSourceRanges() {
+// patchRestrictedDenyRules adds the deny rules for each destination port restricted by the port annotations,
+// so that the traffic inside the VNet, which is allowed by the default rule `AllowVnetInBound`, is limited as well.
+func (ac *AccessControl) patchRestrictedDenyRules(
+	protocol armnetwork.SecurityRuleProtocol,
+	dstIPv4Addresses, dstIPv6Addresses []netip.Addr,
+	restrictedDstPorts map[int32]*PortSourceRestriction,
+) error {
+	dstPorts := make([]int32, 0, len(restrictedDstPorts))
+	for p := range restrictedDstPorts {
+		dstPorts = append(dstPorts, p)
+	}
+	slices.Sort(dstPorts)
+
+	for _, dstPort := range dstPorts {
+		port := restrictedDstPorts[dstPort].Port
+		if dstASGID := ac.DestinationApplicationSecurityGroup; dstASGID != "" {
+			if len(dstIPv4Addresses) == 0 && len(dstIPv6Addresses) == 0 {
+				continue
+			}
+			if err := ac.sgHelper.AddRuleForDenyPortToApplicationSecurityGroup(protocol, dstASGID, dstPort); err != nil {
+				return fmt.Errorf("add deny rule of port %d to application security group: %w", port, err)
+			}
+			continue
+		}
 		if len(dstIPv4Addresses) > 0 {
-			if err := ac.sgHelper.AddRuleForDenyAll(dstIPv4Addresses); err != nil {
-				return fmt.Errorf("add rule for deny all on IPv4: %w", err)
+			if err := ac.sgHelper.AddRuleForDenyPort(protocol, dstIPv4Addresses, dstPort); err != nil {
+				return fmt.Errorf("add deny rule of port %d on IPv4: %w", port, err)
 			}
 		}
 		if len(dstIPv6Addresses) > 0 {
-			if err := ac.sgHelper.AddRuleForDenyAll(dstIPv6Addresses); err != nil {
-				return fmt.Errorf("add rule for deny all on IPv6: %w", err)
+			if err := ac.sgHelper.AddRuleForDenyPort(protocol, dstIPv6Addresses, dstPort); err != nil {
+				return fmt.Errorf("add deny rule of port %d on IPv6: %w", port, err)
 			}
 		}
 	}
+	return nil
+}
+
+// patchAllowRules adds the allow rules for the destination addresses of the given IP family,
+// or for the destination application security group if it is specified.
+func (ac *AccessControl) patchAllowRules(
+	protocol armnetwork.SecurityRuleProtocol,
+	ipFamily iputil.Family,
+	dstAddresses []netip.Addr,
+	allowedServiceTags []string,
+	allowedIPRanges []netip.Prefix,
+	allowedASGs []string,
+	dstPorts []int32,
+) error {
+	dstASGID := ac.DestinationApplicationSecurityGroup
+
+	for _, tag := range allowedServiceTags {
+		var err error
+		if dstASGID != "" {
+			err = ac.sgHelper.AddRuleForApplicationSecurityGroupDestination(protocol, ipFamily, []string{tag}, nil, dstASGID, dstPorts)
+		} else {
+			err = ac.sgHelper.AddRuleForAllowedServiceTag(tag, protocol, dstAddresses, dstPorts)
+		}
+		if err != nil {
+			return fmt.Errorf("add rule for allowed service tag on %s: %w", ipFamily, err)
+		}
+	}
 
-	logger.V(10).Info("Completed patching")
+	if len(allowedIPRanges) > 0 {
+		var err error
+		if dstASGID != "" {
+			srcPrefixes := fnutil.Map(func(p netip.Prefix) string { return p.String() }, allowedIPRanges)
+			err = ac.sgHelper.AddRuleForApplicationSecurityGroupDestination(protocol, ipFamily, srcPrefixes, nil, dstASGID, dstPorts)
+		} else {
+			err = ac.sgHelper.AddRuleForAllowedIPRanges(allowedIPRanges, protocol, dstAddresses, dstPorts)
+		}
+		if err != nil {
+			return fmt.Errorf("add rule for allowed IP ranges on %s: %w", ipFamily, err)
+		}
+	}
+
+	if len(allowedASGs) > 0 {
+		var err error
+		if dstASGID != "" {
+			err = ac.sgHelper.AddRuleForApplicationSecurityGroupDestination(protocol, ipFamily, nil, allowedASGs, dstASGID, dstPorts)
+		} else {
+			err = ac.sgHelper.AddRuleForAllowedApplicationSecurityGroups(allowedASGs, protocol, dstAddresses, dstPorts)
+		}
+		if err != nil {
+			return fmt.Errorf("add rule for allowed application security groups on %s: %w", ipFamily, err)
+		}
+	}
 
 	return nil
 }
 
-// CleanSecurityGroup removes the given IP addresses from the SecurityGroup.
+// patchDenyAllRules adds the deny all rules for the destination addresses of each IP family,
+// or a single deny all rule for the destination application security group if it is specified.
+func (ac *AccessControl) patchDenyAllRules(dstIPv4Addresses, dstIPv6Addresses []netip.Addr) error {
+	if dstASGID := ac.DestinationApplicationSecurityGroup; dstASGID != "" {
+		if len(dstIPv4Addresses) == 0 && len(dstIPv6Addresses) == 0 {
+			return nil
+		}
+		if err := ac.sgHelper.AddRuleForDenyAllToApplicationSecurityGroup(dstASGID); err != nil {
+			return fmt.Errorf("add rule for deny all to application security group: %w", err)
+		}
+		return nil
+	}
+
+	if len(dstIPv4Addresses) > 0 {
+		if err := ac.sgHelper.AddRuleForDenyAll(dstIPv4Addresses); err != nil {
+			return fmt.Errorf("add rule for deny all on IPv4: %w", err)
+		}
+	}
+	if len(dstIPv6Addresses) > 0 {
+		if err := ac.sgHelper.AddRuleForDenyAll(dstIPv6Addresses); err != nil {
+			return fmt.Errorf("add rule for deny all on IPv6: %w", err)
+		}
+	}
+	return nil
+}
+
+// CleanSecurityGroup removes the given IP addresses and the destination application security groups from the SecurityGroup.
+// The ports in retainPortRanges are used by the other services sharing the IP addresses, so they are kept.
+// All the destination application security groups in the rules are cleaned, so that the rules are removed once
+// no service refers to them, e.g. after the annotation is removed from the service. The ports in retainASGPortRanges,
+// keyed by the lower-case resource ID of the application security group, are used by the other services referring to it.
 func (ac *AccessControl) CleanSecurityGroup(
 	dstIPv4Addresses, dstIPv6Addresses []netip.Addr,
 	retainPortRanges map[armnetwork.SecurityRuleProtocol][]int32,
+	retainASGPortRanges map[string]map[armnetwork.SecurityRuleProtocol][]int32,
 ) error {
 	logger := ac.logger.WithName("CleanSecurityGroup").
 		WithValues("num-dst-ipv4-addresses", len(dstIPv4Addresses)).
@@ -303,6 +596,7 @@ func (ac *AccessControl) CleanSecurityGroup(
 		armnetwork.SecurityRuleProtocolUDP,
 		armnetwork.SecurityRuleProtocolAsterisk,
 	}
+	dstASGIDs := ac.sgHelper.ListDestinationApplicationSecurityGroupIDs()
 
 	for _, protocol := range protocols {
 		retainDstPorts := retainPortRanges[protocol]
@@ -315,12 +609,81 @@ func (ac *AccessControl) CleanSecurityGroup(
 			logger.Error(err, "Failed to remove IPv6 destination from rules")
 			return err
 		}
+
+		for _, dstASGID := range dstASGIDs {
+			if err := ac.sgHelper.RemoveApplicationSecurityGroupDestinationFromRules(
+				protocol, dstASGID, retainASGPortRanges[strings.ToLower(dstASGID)][protocol],
+			); err != nil {
+				logger.Error(err, "Failed to remove destination application security group from rules", "dst-asg", dstASGID)
+				return err
+			}
+		}
 	}
 
 	logger.V(10).Info("Completed cleaning")
 	return nil
 }
 
+// ReconcileNodeInboundNATRules adds the rules which allow the traffic from the allowed sources of the service to
+// the backend port of its per-node inbound NAT rules on the given node addresses, and removes the other rules
+// whose names start with the given prefix. All of them are removed if there is no destination address.
+func (ac *AccessControl) ReconcileNodeInboundNATRules(
+	namePrefix string,
+	dstIPv4Addresses, dstIPv6Addresses []netip.Addr,
+	dstPort int32,
+) error {
+	var (
+		allowedServiceTags                   = ac.AllowedServiceTags
+		allowedIPv4Ranges, allowedIPv6Ranges = iputil.GroupPrefixesByFamily(
+			iputil.AggregatePrefixes(append(ac.AllowedIPv4Ranges(), ac.AllowedIPv6Ranges()...)),
+		)
+		ruleNames []string
+	)
+	if ac.IsAllowFromInternet() {
+		allowedServiceTags = append(allowedServiceTags, securitygroup.ServiceTagInternet)
+	}
+
+	for _, dst := range []struct {
+		ipFamily        iputil.Family
+		addresses       []netip.Addr
+		allowedIPRanges []netip.Prefix
+	}{
+		{iputil.IPv4, dstIPv4Addresses, allowedIPv4Ranges},
+		{iputil.IPv6, dstIPv6Addresses, allowedIPv6Ranges},
+	} {
+		if len(dst.addresses) == 0 {
+			continue
+		}
+		addRule := func(source string, srcPrefixes, srcASGIDs []string) error {
+			name := fmt.Sprintf("%s-%s-%d-%s-%s", namePrefix, armnetwork.SecurityRuleProtocolTCP, dstPort, dst.ipFamily, source)
+			ruleNames = append(ruleNames, name)
+			if err := ac.sgHelper.AddRuleForNodeInboundNAT(name, srcPrefixes, srcASGIDs, dst.addresses, dstPort); err != nil {
+				return fmt.Errorf("add rule for node inbound NAT on %s: %w", dst.ipFamily, err)
+			}
+			return nil
+		}
+		for _, tag := range allowedServiceTags {
+			if err := addRule(tag, []string{tag}, nil); err != nil {
+				return err
+			}
+		}
+		if len(dst.allowedIPRanges) > 0 {
+			srcPrefixes := fnutil.Map(func(p netip.Prefix) string { return p.String() }, dst.allowedIPRanges)
+			if err := addRule("IPRanges", srcPrefixes, nil); err != nil {
+				return err
+			}
+		}
+		if len(ac.AllowedApplicationSecurityGroups) > 0 {
+			if err := addRule("ASGs", nil, ac.AllowedApplicationSecurityGroups); err != nil {
+				return err
+			}
+		}
+	}
+
+	ac.sgHelper.RemoveRulesWithNamePrefix(namePrefix+"-", ruleNames)
+	return nil
+}
+
 // SecurityGroup returns the SecurityGroup object with patched rules and indicates if the rules had been changed.
 // There are mainly two operations to alter the SecurityGroup:
 // 1. `PatchSecurityGroup`: Add rules for the given destination IP addresses.
@@ -330,6 +693,11 @@ func (ac *AccessControl) SecurityGroup() (*armnetwork.SecurityGroup, bool, error
 	return ac.sgHelper.SecurityGroup()
 }
 
+// SecurityGroupCapacity returns the usage of the SecurityGroup against the limits of Azure.
+func (ac *AccessControl) SecurityGroupCapacity() securitygroup.Capacity {
+	return ac.sgHelper.Capacity()
+}
+
 // SecurityRuleDestinationPortsByProtocol returns the service ports grouped by SecurityGroup protocol.
 func SecurityRuleDestinationPortsByProtocol(svc *v1.Service) (map[armnetwork.SecurityRuleProtocol][]int32, error) {
 	rv := make(map[armnetwork.SecurityRuleProtocol][]int32)
@@ -17,7 +17,9 @@ limitations under the License.
 package loadbalancer
 
 import (
+	"fmt"
 	"net/netip"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -29,6 +31,7 @@ import (
 
 	"sigs.k8s.io/cloud-provider-azure/internal/testutil"
 	"sigs.k8s.io/cloud-provider-azure/internal/testutil/fixture"
+	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
 	"sigs.k8s.io/cloud-provider-azure/pkg/log"
 	"sigs.k8s.io/cloud-provider-azure/pkg/provider/securitygroup"
 	fnutil "sigs.k8s.io/cloud-provider-azure/pkg/util/collectionutil"
@@ -1151,7 +1154,7 @@ func TestAccessControl_CleanSecurityGroup(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
-		assert.NoError(t, ac.CleanSecurityGroup(fx.RandomIPv4Addresses(2), fx.RandomIPv6Addresses(2), make(map[armnetwork.SecurityRuleProtocol][]int32)))
+		assert.NoError(t, ac.CleanSecurityGroup(fx.RandomIPv4Addresses(2), fx.RandomIPv6Addresses(2), make(map[armnetwork.SecurityRuleProtocol][]int32), nil))
 		_, updated, err := ac.SecurityGroup()
 		assert.NoError(t, err)
 		assert.False(t, updated)
@@ -1198,7 +1201,7 @@ func TestAccessControl_CleanSecurityGroup(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
-		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, make(map[armnetwork.SecurityRuleProtocol][]int32)))
+		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, make(map[armnetwork.SecurityRuleProtocol][]int32), nil))
 		_, updated, err := ac.SecurityGroup()
 		assert.NoError(t, err)
 		assert.False(t, updated)
@@ -1258,7 +1261,7 @@ func TestAccessControl_CleanSecurityGroup(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
-		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, make(map[armnetwork.SecurityRuleProtocol][]int32)))
+		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, make(map[armnetwork.SecurityRuleProtocol][]int32), nil))
 		outputSG, updated, err := ac.SecurityGroup()
 		assert.NoError(t, err)
 		assert.True(t, updated)
@@ -1373,7 +1376,7 @@ func TestAccessControl_CleanSecurityGroup(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
-		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, make(map[armnetwork.SecurityRuleProtocol][]int32)))
+		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, make(map[armnetwork.SecurityRuleProtocol][]int32), nil))
 		outputSG, updated, err := ac.SecurityGroup()
 		assert.NoError(t, err)
 
@@ -1464,7 +1467,7 @@ func TestAccessControl_CleanSecurityGroup(t *testing.T) {
 
 		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, map[armnetwork.SecurityRuleProtocol][]int32{
 			armnetwork.SecurityRuleProtocolUDP: {56, 53},
-		}))
+		}, nil))
 		outputSG, updated, err := ac.SecurityGroup()
 		assert.NoError(t, err)
 		assert.True(t, updated)
@@ -1525,3 +1528,454 @@ func TestAccessControl_CleanSecurityGroup(t *testing.T) {
 		}, outputSG.Properties.SecurityRules)
 	})
 }
+
+func TestAccessControl_ApplicationSecurityGroups(t *testing.T) {
+	const (
+		srcASGID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/src"
+		dstASGID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/dst"
+	)
+	var (
+		fx               = fixture.NewFixture()
+		azureFx          = fx.Azure()
+		k8sFx            = fx.Kubernetes()
+		dstIPv4Addresses = []netip.Addr{netip.MustParseAddr("10.0.0.1")}
+		dstIPv6Addresses = []netip.Addr{netip.MustParseAddr("2001:db8::1")}
+	)
+
+	t.Run("it should reject the invalid application security group IDs", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().WithDestinationApplicationSecurityGroup("foobar").Build()
+		)
+		_, err := NewAccessControl(log.Noop(), &svc, sg)
+		assert.Error(t, err)
+	})
+
+	t.Run("it should allow the source application security groups to the destination addresses", func(t *testing.T) {
+		var (
+			sg      = azureFx.SecurityGroup().Build()
+			svc     = k8sFx.Service().WithAllowedApplicationSecurityGroups(srcASGID).Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.False(t, ac.IsAllowFromInternet())
+		assert.NoError(t, ac.PatchSecurityGroup(dstIPv4Addresses, nil))
+
+		outputSG, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Len(t, outputSG.Properties.SecurityRules, 2, "expect exact 2 (TCP + UDP) rules")
+		for _, rule := range outputSG.Properties.SecurityRules {
+			assert.Equal(t, []string{srcASGID}, securitygroup.ListSourceApplicationSecurityGroupIDs(rule))
+			assert.Empty(t, securitygroup.ListSourcePrefixes(rule))
+			assert.Equal(t, []string{"10.0.0.1"}, securitygroup.ListDestinationPrefixes(rule))
+		}
+	})
+
+	t.Run("it should attach the rules to the destination application security group and clean them up", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithAllowedApplicationSecurityGroups(srcASGID).
+				WithDestinationApplicationSecurityGroup(dstASGID).
+				WithDenyAllExceptLoadBalancerSourceRanges().
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.True(t, ac.DenyAllExceptSourceRanges())
+		assert.NoError(t, ac.PatchSecurityGroup(dstIPv4Addresses, dstIPv6Addresses))
+
+		outputSG, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Len(t, outputSG.Properties.SecurityRules, 5, "expect exact 5 (2 TCP + 2 UDP + 1 deny all) rules")
+		for _, rule := range outputSG.Properties.SecurityRules {
+			assert.Empty(t, securitygroup.ListDestinationPrefixes(rule))
+			assert.Equal(t, []string{dstASGID}, securitygroup.ListDestinationApplicationSecurityGroupIDs(rule))
+		}
+		testutil.ExpectHasSecurityRules(t, outputSG, []*armnetwork.SecurityRule{
+			{
+				Name: ptr.To(securitygroup.GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(dstASGID)),
+				Properties: &armnetwork.SecurityRulePropertiesFormat{
+					Protocol:                             to.Ptr(armnetwork.SecurityRuleProtocolAsterisk),
+					Access:                               to.Ptr(armnetwork.SecurityRuleAccessDeny),
+					Direction:                            to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+					SourceAddressPrefix:                  ptr.To("*"),
+					SourcePortRange:                      ptr.To("*"),
+					DestinationApplicationSecurityGroups: []*armnetwork.ApplicationSecurityGroup{{ID: ptr.To(dstASGID)}},
+					DestinationPortRange:                 ptr.To("*"),
+					Priority:                             ptr.To(int32(4095)),
+				},
+			},
+		})
+
+		ac, err = NewAccessControl(log.Noop(), &svc, outputSG)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, dstIPv6Addresses, nil, nil))
+		cleanedSG, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Empty(t, cleanedSG.Properties.SecurityRules)
+	})
+
+	t.Run("it should clean the rules of the destination application security group after the annotation is removed", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithAllowedApplicationSecurityGroups(srcASGID).
+				WithDestinationApplicationSecurityGroup(dstASGID).
+				WithDenyAllExceptLoadBalancerSourceRanges().
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.PatchSecurityGroup(dstIPv4Addresses, nil))
+		outputSG, _, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.Len(t, outputSG.Properties.SecurityRules, 3, "expect exact 3 (1 TCP + 1 UDP + 1 deny all) rules")
+
+		delete(svc.Annotations, consts.ServiceAnnotationDestinationApplicationSecurityGroup)
+		ac, err = NewAccessControl(log.Noop(), &svc, outputSG)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, nil, nil))
+		cleanedSG, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Empty(t, cleanedSG.Properties.SecurityRules)
+	})
+
+	t.Run("it should retain the ports of the other services referring to the destination application security group", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithAllowedApplicationSecurityGroups(srcASGID).
+				WithDestinationApplicationSecurityGroup(dstASGID).
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.PatchSecurityGroup(dstIPv4Addresses, nil))
+		outputSG, _, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+
+		delete(svc.Annotations, consts.ServiceAnnotationDestinationApplicationSecurityGroup)
+		ac, err = NewAccessControl(log.Noop(), &svc, outputSG)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.CleanSecurityGroup(dstIPv4Addresses, nil, nil, map[string]map[armnetwork.SecurityRuleProtocol][]int32{
+			strings.ToLower(dstASGID): {
+				armnetwork.SecurityRuleProtocolTCP: k8sFx.Service().TCPPorts(),
+				armnetwork.SecurityRuleProtocolUDP: k8sFx.Service().UDPPorts(),
+			},
+		}))
+		_, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.False(t, updated)
+	})
+}
+
+func TestAccessControl_IPGroups(t *testing.T) {
+	const (
+		ipGroupID1 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/ipGroups/ipg1"
+		ipGroupID2 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/ipGroups/ipg2"
+	)
+	var (
+		fx      = fixture.NewFixture()
+		azureFx = fx.Azure()
+		k8sFx   = fx.Kubernetes()
+	)
+	resolver := func(addressesByID map[string][]string) AccessControlOption {
+		return WithIPGroupResolver(func(id string) ([]string, error) {
+			addresses, ok := addressesByID[id]
+			if !ok {
+				return nil, fmt.Errorf("IP Group %s not found", id)
+			}
+			return addresses, nil
+		})
+	}
+
+	t.Run("it should merge the addresses of the IP Groups into the allowed IP ranges", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithAllowedIPRanges("10.0.0.1/32").
+				WithAllowedIPGroups(ipGroupID1, ipGroupID2).
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg, resolver(map[string][]string{
+				ipGroupID1: {"10.0.1.0/24", "2001:db8::/64"},
+				ipGroupID2: {"10.0.2.0-10.0.2.1"},
+			}))
+		)
+		assert.NoError(t, err)
+		assert.False(t, ac.IsAllowFromInternet())
+		assert.Equal(t, []string{ipGroupID1, ipGroupID2}, ac.AllowedIPGroups)
+		assert.Equal(t, []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.1/32"),
+			netip.MustParsePrefix("10.0.1.0/24"),
+			netip.MustParsePrefix("10.0.2.0/31"),
+		}, ac.AllowedIPv4Ranges())
+		assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("2001:db8::/64")}, ac.AllowedIPv6Ranges())
+	})
+
+	t.Run("it should deny the traffic from internet if the IP Groups are empty", func(t *testing.T) {
+		var (
+			sg      = azureFx.SecurityGroup().Build()
+			svc     = k8sFx.Service().WithAllowedIPGroups(ipGroupID1).Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg, resolver(map[string][]string{ipGroupID1: {}}))
+		)
+		assert.NoError(t, err)
+		assert.False(t, ac.IsAllowFromInternet())
+		assert.Empty(t, ac.AllowedIPRanges)
+	})
+
+	t.Run("it should emit an event for the invalid addresses", func(t *testing.T) {
+		var (
+			sg      = azureFx.SecurityGroup().Build()
+			svc     = k8sFx.Service().WithAllowedIPGroups(ipGroupID1).Build()
+			reasons []string
+			emitter = func(_ runtime.Object, _, reason, _ string) { reasons = append(reasons, reason) }
+			ac, err = NewAccessControl(log.Noop(), &svc, sg, WithEventEmitter(emitter), resolver(map[string][]string{ipGroupID1: {"10.0.1.0/24", "foo"}}))
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"InvalidAllowedIPGroupAddresses"}, reasons)
+		assert.True(t, ac.DenyAllExceptSourceRanges())
+		assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.1.0/24")}, ac.AllowedIPRanges)
+	})
+
+	t.Run("it should report an error if the IP Group cannot be resolved", func(t *testing.T) {
+		var (
+			sg     = azureFx.SecurityGroup().Build()
+			svc    = k8sFx.Service().WithAllowedIPGroups(ipGroupID1).Build()
+			_, err = NewAccessControl(log.Noop(), &svc, sg, resolver(map[string][]string{}))
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("it should skip the IP Groups without resolver", func(t *testing.T) {
+		var (
+			sg      = azureFx.SecurityGroup().Build()
+			svc     = k8sFx.Service().WithAllowedIPGroups(ipGroupID1).Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.Empty(t, ac.AllowedIPRanges)
+		assert.False(t, ac.DenyAllExceptSourceRanges())
+	})
+}
+
+func TestAccessControl_PortSourceRestrictions(t *testing.T) {
+	var (
+		fx      = fixture.NewFixture()
+		azureFx = fx.Azure()
+		k8sFx   = fx.Kubernetes()
+	)
+
+	t.Run("it should generate the rules scoped to the restricted ports", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithAllowedIPRanges("10.0.0.0/16").
+				WithPortAllowedIPRanges(443, "192.168.0.0/24").
+				WithPortAllowedServiceTags(53, "AzureCloud").
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.PatchSecurityGroup([]netip.Addr{netip.MustParseAddr("10.1.0.1")}, nil))
+
+		outputSG, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		ruleNames := fnutil.Map(func(r *armnetwork.SecurityRule) string { return *r.Name }, outputSG.Properties.SecurityRules)
+		assert.ElementsMatch(t, []string{
+			securitygroup.GenerateAllowSecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, []string{"10.0.0.0/16"}, []int32{80}),
+			securitygroup.GenerateAllowSecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, []string{"192.168.0.0/24"}, []int32{443}),
+			securitygroup.GenerateAllowSecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, []string{"AzureCloud"}, []int32{53}),
+			securitygroup.GenerateAllowSecurityRuleName(armnetwork.SecurityRuleProtocolUDP, iputil.IPv4, []string{"AzureCloud"}, []int32{53}),
+			securitygroup.GenerateDenySecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, 443),
+			securitygroup.GenerateDenySecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, 53),
+			securitygroup.GenerateDenySecurityRuleName(armnetwork.SecurityRuleProtocolUDP, iputil.IPv4, 53),
+		}, ruleNames)
+	})
+
+	t.Run("it should deny the other traffic to the restricted ports of internal LB", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithInternalEnabled().
+				WithPortAllowedIPRanges(443, "192.168.0.0/24").
+				Build()
+			dstAddresses = []netip.Addr{netip.MustParseAddr("10.1.0.1")}
+			ac, err      = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.PatchSecurityGroup(dstAddresses, nil))
+
+		outputSG, _, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		testutil.ExpectHasSecurityRules(t, outputSG, []*armnetwork.SecurityRule{
+			{
+				Name: ptr.To(securitygroup.GenerateDenySecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, 443)),
+				Properties: &armnetwork.SecurityRulePropertiesFormat{
+					Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+					Access:                   to.Ptr(armnetwork.SecurityRuleAccessDeny),
+					Direction:                to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+					SourceAddressPrefix:      ptr.To("*"),
+					SourcePortRange:          ptr.To("*"),
+					DestinationAddressPrefix: ptr.To("10.1.0.1"),
+					DestinationPortRange:     ptr.To("443"),
+					Priority:                 ptr.To(int32(4095)),
+				},
+			},
+		})
+
+		ac, err = NewAccessControl(log.Noop(), &svc, outputSG)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.CleanSecurityGroup(dstAddresses, nil, nil, nil))
+		cleanedSG, _, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.Empty(t, cleanedSG.Properties.SecurityRules)
+	})
+
+	t.Run("it should not add the deny rules of the restricted ports if all the other traffic is denied", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithAllowedIPRanges("10.0.0.0/16").
+				WithDenyAllExceptLoadBalancerSourceRanges().
+				WithPortAllowedIPRanges(443, "192.168.0.0/24").
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.PatchSecurityGroup([]netip.Addr{netip.MustParseAddr("10.1.0.1")}, nil))
+
+		outputSG, _, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		ruleNames := fnutil.Map(func(r *armnetwork.SecurityRule) string { return *r.Name }, outputSG.Properties.SecurityRules)
+		assert.Contains(t, ruleNames, securitygroup.GenerateDenyAllSecurityRuleName(iputil.IPv4))
+		assert.NotContains(t, ruleNames, securitygroup.GenerateDenySecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, 443))
+	})
+
+	t.Run("it should reject the service ports sharing a destination port with different allowed sources", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithPortAllowedIPRanges(443, "192.168.0.0/24").
+				Build()
+			// 80/TCP and 443/TCP share the target port 8080 in pod IP mode.
+			_, err = NewAccessControl(log.Noop(), &svc, sg, WithSecurityRuleDestinationPortsByProtocol(map[armnetwork.SecurityRuleProtocol][]int32{
+				armnetwork.SecurityRuleProtocolTCP: {8080, 8080, 5353},
+				armnetwork.SecurityRuleProtocolUDP: {5353},
+			}))
+		)
+		assert.ErrorContains(t, err, "share the destination port 8080/TCP")
+
+		svc = k8sFx.Service().
+			WithPortAllowedIPRanges(80, "192.168.0.0/24").
+			WithPortAllowedIPRanges(443, "192.168.0.0/24").
+			Build()
+		ac, err := NewAccessControl(log.Noop(), &svc, sg, WithSecurityRuleDestinationPortsByProtocol(map[armnetwork.SecurityRuleProtocol][]int32{
+			armnetwork.SecurityRuleProtocolTCP: {8080, 8080, 5353},
+			armnetwork.SecurityRuleProtocolUDP: {5353},
+		}))
+		assert.NoError(t, err, "the service ports with the same allowed sources can share the destination port")
+		assert.NoError(t, ac.PatchSecurityGroup([]netip.Addr{netip.MustParseAddr("10.1.0.1")}, nil))
+	})
+
+	t.Run("it should map the restricted ports to the overridden destination ports", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithPortAllowedIPRanges(443, "192.168.0.0/24").
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg, WithSecurityRuleDestinationPortsByProtocol(map[armnetwork.SecurityRuleProtocol][]int32{
+				armnetwork.SecurityRuleProtocolTCP: {8080, 8443, 5353},
+				armnetwork.SecurityRuleProtocolUDP: {5353},
+			}))
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.PatchSecurityGroup([]netip.Addr{netip.MustParseAddr("10.1.0.1")}, nil))
+
+		outputSG, _, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		ruleNames := fnutil.Map(func(r *armnetwork.SecurityRule) string { return *r.Name }, outputSG.Properties.SecurityRules)
+		assert.ElementsMatch(t, []string{
+			securitygroup.GenerateAllowSecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, []string{"Internet"}, []int32{8080, 5353}),
+			securitygroup.GenerateAllowSecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, []string{"192.168.0.0/24"}, []int32{8443}),
+			securitygroup.GenerateAllowSecurityRuleName(armnetwork.SecurityRuleProtocolUDP, iputil.IPv4, []string{"Internet"}, []int32{5353}),
+			securitygroup.GenerateDenySecurityRuleName(armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, 8443),
+		}, ruleNames)
+	})
+
+	t.Run("it should reject the invalid IP ranges", func(t *testing.T) {
+		var (
+			sg     = azureFx.SecurityGroup().Build()
+			svc    = k8sFx.Service().WithPortAllowedIPRanges(443, "foo").Build()
+			_, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestAccessControl_ReconcileNodeInboundNATRules(t *testing.T) {
+	var (
+		fx      = fixture.NewFixture()
+		azureFx = fx.Azure()
+		k8sFx   = fx.Kubernetes()
+	)
+
+	t.Run("it should add the rules from the allowed sources to the backend port on the nodes", func(t *testing.T) {
+		var (
+			sg  = azureFx.SecurityGroup().Build()
+			svc = k8sFx.Service().
+				WithAllowedIPRanges("10.0.0.0/16", "fd00::/64").
+				WithAllowedServiceTags("AzureCloud").
+				Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.ReconcileNodeInboundNATRules("aservice-nat", []netip.Addr{netip.MustParseAddr("10.1.0.1")}, []netip.Addr{netip.MustParseAddr("fd01::1")}, 22))
+
+		outputSG, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		ruleNames := fnutil.Map(func(r *armnetwork.SecurityRule) string { return *r.Name }, outputSG.Properties.SecurityRules)
+		assert.ElementsMatch(t, []string{
+			"aservice-nat-Tcp-22-IPv4-AzureCloud",
+			"aservice-nat-Tcp-22-IPv4-IPRanges",
+			"aservice-nat-Tcp-22-IPv6-AzureCloud",
+			"aservice-nat-Tcp-22-IPv6-IPRanges",
+		}, ruleNames)
+	})
+
+	t.Run("it should remove the rules without the destination addresses", func(t *testing.T) {
+		var (
+			rules = []*armnetwork.SecurityRule{
+				{
+					Name: ptr.To("aservice-nat-Tcp-22-IPv4-Internet"),
+					Properties: &armnetwork.SecurityRulePropertiesFormat{
+						Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+						Access:                   to.Ptr(armnetwork.SecurityRuleAccessAllow),
+						Direction:                to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+						SourceAddressPrefix:      ptr.To("Internet"),
+						SourcePortRange:          ptr.To("*"),
+						DestinationAddressPrefix: ptr.To("10.1.0.1"),
+						DestinationPortRange:     ptr.To("22"),
+						Priority:                 ptr.To(int32(500)),
+					},
+				},
+			}
+			sg      = azureFx.SecurityGroup().WithRules(rules).Build()
+			svc     = k8sFx.Service().Build()
+			ac, err = NewAccessControl(log.Noop(), &svc, sg)
+		)
+		assert.NoError(t, err)
+		assert.NoError(t, ac.ReconcileNodeInboundNATRules("aservice-nat", nil, nil, 0))
+
+		outputSG, updated, err := ac.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Empty(t, outputSG.Properties.SecurityRules)
+	})
+}
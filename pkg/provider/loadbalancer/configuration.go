@@ -20,8 +20,10 @@ import (
 	"errors"
 	"fmt"
 	"net/netip"
+	"slices"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	v1 "k8s.io/api/core/v1"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/consts"
@@ -117,6 +119,222 @@ func SourceRanges(svc *v1.Service) ([]netip.Prefix, []string, error) {
 	return validRanges, invalidRanges, nil
 }
 
+// PortSourceRestriction is the allow list of a single service port. It replaces the allow lists of the service for the port.
+type PortSourceRestriction struct {
+	Port               int32
+	AllowedIPRanges    []netip.Prefix
+	AllowedServiceTags []string
+}
+
+// equalSources returns true if both of the restrictions allow the same sources. A nil restriction,
+// i.e. the port is not restricted, only equals to another nil restriction.
+func (r *PortSourceRestriction) equalSources(other *PortSourceRestriction) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return slices.Equal(r.AllowedIPRanges, other.AllowedIPRanges) &&
+		slices.Equal(r.AllowedServiceTags, other.AllowedServiceTags)
+}
+
+// PortSourceRestrictions returns the allow lists of the service ports configured by user through the annotations
+// service.beta.kubernetes.io/port_{port}_allowed-ip-ranges and service.beta.kubernetes.io/port_{port}_allowed-service-tags.
+// The key is the service port.
+func PortSourceRestrictions(svc *v1.Service) (map[int32]*PortSourceRestriction, error) {
+	const (
+		Sep = ","
+	)
+	var (
+		rv   = make(map[int32]*PortSourceRestriction)
+		errs []error
+	)
+
+	for _, port := range svc.Spec.Ports {
+		if _, found := rv[port.Port]; found {
+			// The same port of another protocol.
+			continue
+		}
+		var (
+			ipRangesKey                  = consts.BuildAnnotationKeyForPort(port.Port, consts.PortAnnotationAllowedIPRanges)
+			serviceTagsKey               = consts.BuildAnnotationKeyForPort(port.Port, consts.PortAnnotationAllowedServiceTags)
+			ipRangesValue, ipRangesFound = svc.Annotations[ipRangesKey]
+			serviceTagsValue, tagsFound  = svc.Annotations[serviceTagsKey]
+		)
+		if !ipRangesFound && !tagsFound {
+			continue
+		}
+
+		restriction := &PortSourceRestriction{Port: port.Port}
+		if value := strings.TrimSpace(ipRangesValue); value != "" {
+			var errsByKey []error
+			for _, p := range strings.Split(value, Sep) {
+				prefix, err := iputil.ParsePrefix(strings.TrimSpace(p))
+				if err != nil {
+					errsByKey = append(errsByKey, err)
+					continue
+				}
+				restriction.AllowedIPRanges = append(restriction.AllowedIPRanges, prefix)
+			}
+			if len(errsByKey) > 0 {
+				errs = append(errs, NewErrAnnotationValue(ipRangesKey, ipRangesValue, errors.Join(errsByKey...)))
+			}
+		}
+		if value := strings.TrimSpace(serviceTagsValue); value != "" {
+			for _, tag := range strings.Split(value, Sep) {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					restriction.AllowedServiceTags = append(restriction.AllowedServiceTags, tag)
+				}
+			}
+		}
+		rv[port.Port] = restriction
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rv, nil
+}
+
+// AllowedApplicationSecurityGroups returns the resource IDs of the application security groups allowed to access
+// the service, configured by user through annotation service.beta.kubernetes.io/azure-allowed-application-security-groups.
+func AllowedApplicationSecurityGroups(svc *v1.Service) ([]string, error) {
+	const (
+		Sep = ","
+		Key = consts.ServiceAnnotationAllowedApplicationSecurityGroups
+	)
+
+	value, found := svc.Annotations[Key]
+	if !found || strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var (
+		rv   []string
+		errs []error
+	)
+	for _, id := range strings.Split(strings.TrimSpace(value), Sep) {
+		id = strings.TrimSpace(id)
+		if err := validateResourceID(id, applicationSecurityGroupResourceType); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rv = append(rv, id)
+	}
+	if len(errs) > 0 {
+		return nil, NewErrAnnotationValue(Key, value, errors.Join(errs...))
+	}
+	return rv, nil
+}
+
+// DestinationApplicationSecurityGroup returns the resource ID of the application security group the security rules
+// are attached to, configured by user through annotation service.beta.kubernetes.io/azure-destination-application-security-group.
+func DestinationApplicationSecurityGroup(svc *v1.Service) (string, error) {
+	const Key = consts.ServiceAnnotationDestinationApplicationSecurityGroup
+
+	value := strings.TrimSpace(svc.Annotations[Key])
+	if value == "" {
+		return "", nil
+	}
+	if err := validateResourceID(value, applicationSecurityGroupResourceType); err != nil {
+		return "", NewErrAnnotationValue(Key, value, err)
+	}
+	return value, nil
+}
+
+// AllowedIPGroups returns the resource IDs of the Azure IP Groups allowed to access the service,
+// configured by user through annotation service.beta.kubernetes.io/azure-allowed-ip-groups.
+func AllowedIPGroups(svc *v1.Service) ([]string, error) {
+	const (
+		Sep = ","
+		Key = consts.ServiceAnnotationAllowedIPGroups
+	)
+
+	value, found := svc.Annotations[Key]
+	if !found || strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var (
+		rv   []string
+		errs []error
+	)
+	for _, id := range strings.Split(strings.TrimSpace(value), Sep) {
+		id = strings.TrimSpace(id)
+		if err := validateResourceID(id, ipGroupResourceType); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rv = append(rv, id)
+	}
+	if len(errs) > 0 {
+		return nil, NewErrAnnotationValue(Key, value, errors.Join(errs...))
+	}
+	return rv, nil
+}
+
+// ParseIPGroupAddresses parses the addresses of an IP Group into prefixes. The addresses can be
+// IP addresses, CIDRs or IP ranges like 10.0.0.1-10.0.0.10. It returns the valid prefixes and the invalid addresses.
+func ParseIPGroupAddresses(addresses []string) ([]netip.Prefix, []string) {
+	var (
+		validRanges   []netip.Prefix
+		invalidRanges []string
+	)
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		switch {
+		case strings.Contains(address, "/"):
+			prefix, err := netip.ParsePrefix(address)
+			if err != nil {
+				invalidRanges = append(invalidRanges, address)
+				continue
+			}
+			validRanges = append(validRanges, prefix.Masked())
+		case strings.Contains(address, "-"):
+			parts := strings.Split(address, "-")
+			if len(parts) != 2 {
+				invalidRanges = append(invalidRanges, address)
+				continue
+			}
+			start, startErr := netip.ParseAddr(strings.TrimSpace(parts[0]))
+			end, endErr := netip.ParseAddr(strings.TrimSpace(parts[1]))
+			if startErr != nil || endErr != nil {
+				invalidRanges = append(invalidRanges, address)
+				continue
+			}
+			prefixes, err := iputil.RangeToPrefixes(start, end)
+			if err != nil {
+				invalidRanges = append(invalidRanges, address)
+				continue
+			}
+			validRanges = append(validRanges, prefixes...)
+		default:
+			addr, err := netip.ParseAddr(address)
+			if err != nil {
+				invalidRanges = append(invalidRanges, address)
+				continue
+			}
+			validRanges = append(validRanges, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return validRanges, invalidRanges
+}
+
+const (
+	applicationSecurityGroupResourceType = "Microsoft.Network/applicationSecurityGroups"
+	ipGroupResourceType                  = "Microsoft.Network/ipGroups"
+)
+
+// validateResourceID checks whether the given ID is a valid resource ID of the given resource type.
+func validateResourceID(id, resourceType string) error {
+	resourceID, err := arm.ParseResourceID(id)
+	if err != nil {
+		return fmt.Errorf("parse resource ID %q: %w", id, err)
+	}
+	if !strings.EqualFold(resourceID.ResourceType.String(), resourceType) {
+		return fmt.Errorf("%q is not a resource ID of %s", id, resourceType)
+	}
+	return nil
+}
+
 func AdditionalPublicIPs(svc *v1.Service) ([]netip.Addr, error) {
 	const (
 		Sep = ","
@@ -339,3 +339,164 @@ func TestAdditionalPublicIPs(t *testing.T) {
 		assert.Equal(t, e.AnnotationKey, consts.ServiceAnnotationAdditionalPublicIPs)
 	})
 }
+
+func TestApplicationSecurityGroups(t *testing.T) {
+	const (
+		asgID1 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/asg1"
+		asgID2 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/asg2"
+		nsgID  = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/nsg"
+	)
+	newService := func(annotations map[string]string) *v1.Service {
+		return &v1.Service{
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeLoadBalancer,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: annotations,
+			},
+		}
+	}
+
+	t.Run("no annotation", func(t *testing.T) {
+		svc := newService(map[string]string{})
+		allowed, err := AllowedApplicationSecurityGroups(svc)
+		assert.NoError(t, err)
+		assert.Empty(t, allowed)
+		dst, err := DestinationApplicationSecurityGroup(svc)
+		assert.NoError(t, err)
+		assert.Empty(t, dst)
+	})
+	t.Run("with valid IDs", func(t *testing.T) {
+		svc := newService(map[string]string{
+			consts.ServiceAnnotationAllowedApplicationSecurityGroups:    asgID1 + " , " + asgID2,
+			consts.ServiceAnnotationDestinationApplicationSecurityGroup: " " + asgID1,
+		})
+		allowed, err := AllowedApplicationSecurityGroups(svc)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{asgID1, asgID2}, allowed)
+		dst, err := DestinationApplicationSecurityGroup(svc)
+		assert.NoError(t, err)
+		assert.Equal(t, asgID1, dst)
+	})
+	t.Run("with invalid IDs", func(t *testing.T) {
+		svc := newService(map[string]string{
+			consts.ServiceAnnotationAllowedApplicationSecurityGroups:    asgID1 + ",foobar",
+			consts.ServiceAnnotationDestinationApplicationSecurityGroup: nsgID,
+		})
+		_, err := AllowedApplicationSecurityGroups(svc)
+		var e *ErrAnnotationValue
+		assert.ErrorAs(t, err, &e)
+		assert.Equal(t, consts.ServiceAnnotationAllowedApplicationSecurityGroups, e.AnnotationKey)
+
+		_, err = DestinationApplicationSecurityGroup(svc)
+		assert.ErrorAs(t, err, &e)
+		assert.Equal(t, consts.ServiceAnnotationDestinationApplicationSecurityGroup, e.AnnotationKey)
+	})
+}
+
+func TestAllowedIPGroups(t *testing.T) {
+	const (
+		ipGroupID1 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/ipGroups/ipg1"
+		ipGroupID2 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/ipGroups/ipg2"
+		asgID      = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/asg"
+	)
+	newService := func(annotations map[string]string) *v1.Service {
+		return &v1.Service{
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeLoadBalancer,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: annotations,
+			},
+		}
+	}
+
+	t.Run("no annotation", func(t *testing.T) {
+		ids, err := AllowedIPGroups(newService(map[string]string{}))
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+	t.Run("with valid IDs", func(t *testing.T) {
+		ids, err := AllowedIPGroups(newService(map[string]string{
+			consts.ServiceAnnotationAllowedIPGroups: ipGroupID1 + " , " + ipGroupID2,
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{ipGroupID1, ipGroupID2}, ids)
+	})
+	t.Run("with invalid IDs", func(t *testing.T) {
+		_, err := AllowedIPGroups(newService(map[string]string{
+			consts.ServiceAnnotationAllowedIPGroups: ipGroupID1 + "," + asgID,
+		}))
+		var e *ErrAnnotationValue
+		assert.ErrorAs(t, err, &e)
+		assert.Equal(t, consts.ServiceAnnotationAllowedIPGroups, e.AnnotationKey)
+	})
+}
+
+func TestParseIPGroupAddresses(t *testing.T) {
+	prefixes, invalid := ParseIPGroupAddresses([]string{
+		"10.0.0.1",
+		"10.0.1.1/24",
+		"10.0.2.0-10.0.2.5",
+		"2001:db8::1",
+		"10.0.3.5-10.0.3.1",
+		"foo",
+	})
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.1/32"),
+		netip.MustParsePrefix("10.0.1.0/24"),
+		netip.MustParsePrefix("10.0.2.0/30"),
+		netip.MustParsePrefix("10.0.2.4/31"),
+		netip.MustParsePrefix("2001:db8::1/128"),
+	}, prefixes)
+	assert.Equal(t, []string{"10.0.3.5-10.0.3.1", "foo"}, invalid)
+}
+
+func TestPortSourceRestrictions(t *testing.T) {
+	newService := func(annotations map[string]string) *v1.Service {
+		return &v1.Service{
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeLoadBalancer,
+				Ports: []v1.ServicePort{
+					{Port: 80, Protocol: v1.ProtocolTCP},
+					{Port: 8443, Protocol: v1.ProtocolTCP},
+					{Port: 8443, Protocol: v1.ProtocolUDP},
+				},
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: annotations,
+			},
+		}
+	}
+
+	t.Run("no annotation", func(t *testing.T) {
+		rv, err := PortSourceRestrictions(newService(map[string]string{
+			consts.ServiceAnnotationAllowedIPRanges: "10.0.0.0/16",
+		}))
+		assert.NoError(t, err)
+		assert.Empty(t, rv)
+	})
+	t.Run("with valid annotations", func(t *testing.T) {
+		rv, err := PortSourceRestrictions(newService(map[string]string{
+			"service.beta.kubernetes.io/port_8443_allowed-ip-ranges":    "10.0.0.0/16, 2001:db8::/64",
+			"service.beta.kubernetes.io/port_8443_allowed-service-tags": "AzureCloud, ",
+			"service.beta.kubernetes.io/port_9000_allowed-ip-ranges":    "10.1.0.0/16",
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, map[int32]*PortSourceRestriction{
+			8443: {
+				Port:               8443,
+				AllowedIPRanges:    []netip.Prefix{netip.MustParsePrefix("10.0.0.0/16"), netip.MustParsePrefix("2001:db8::/64")},
+				AllowedServiceTags: []string{"AzureCloud"},
+			},
+		}, rv)
+	})
+	t.Run("with invalid IP ranges", func(t *testing.T) {
+		_, err := PortSourceRestrictions(newService(map[string]string{
+			"service.beta.kubernetes.io/port_80_allowed-ip-ranges": "10.0.0.0/16,foo",
+		}))
+		var e *ErrAnnotationValue
+		assert.ErrorAs(t, err, &e)
+		assert.Equal(t, "service.beta.kubernetes.io/port_80_allowed-ip-ranges", e.AnnotationKey)
+	})
+}
@@ -42,6 +42,14 @@ func EventMessageOfInvalidAllowedIPRanges(allowedIPRanges []string) string {
 	)
 }
 
+func EventMessageOfInvalidAllowedIPGroupAddresses(ipGroupID string, addresses []string) string {
+	return fmt.Sprintf("Found invalid addresses %q in IP Group %s of %s, ignoring and adding a default DenyAll rule in security group.",
+		addresses,
+		ipGroupID,
+		consts.ServiceAnnotationAllowedIPGroups,
+	)
+}
+
 func EventMessageOfConflictLoadBalancerSourceRangesAndAllowedIPRanges() string {
 	return fmt.Sprintf(
 		"Please use annotation %s instead of spec.loadBalancerSourceRanges while using %s annotation at the same time.",
@@ -129,3 +129,18 @@ func (mr *MockRepositoryMockRecorder) List(ctx, resourceGroup any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, resourceGroup)
 }
+
+// UpdatePEConnection mocks base method.
+func (m *MockRepository) UpdatePEConnection(ctx context.Context, resourceGroup, plsName, lbFrontendID string, peConn armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePEConnection", ctx, resourceGroup, plsName, lbFrontendID, peConn)
+	ret0, _ := ret[0].(*armnetwork.PrivateEndpointConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePEConnection indicates an expected call of UpdatePEConnection.
+func (mr *MockRepositoryMockRecorder) UpdatePEConnection(ctx, resourceGroup, plsName, lbFrontendID, peConn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePEConnection", reflect.TypeOf((*MockRepository)(nil).UpdatePEConnection), ctx, resourceGroup, plsName, lbFrontendID, peConn)
+}
@@ -36,6 +36,7 @@ type Repository interface {
 	CreateOrUpdate(ctx context.Context, resourceGroup string, pls armnetwork.PrivateLinkService) (*armnetwork.PrivateLinkService, error)
 	Delete(ctx context.Context, resourceGroup, plsName, lbFrontendID string) error
 	DeletePEConnection(ctx context.Context, resourceGroup, plsName, peConnName string) error
+	UpdatePEConnection(ctx context.Context, resourceGroup, plsName, lbFrontendID string, peConn armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error)
 }
 
 type repo struct {
@@ -62,6 +63,7 @@ func NewRepo(
 var (
 	ErrMissingPLSName                               = fmt.Errorf("missing PLS name")
 	ErrLoadBalancerFrontendIPConfigurationsNotFound = fmt.Errorf("load balancer frontend IP configurations not found")
+	ErrMissingPEConnectionName                      = fmt.Errorf("missing PE connection name")
 )
 
 func (r *repo) Get(ctx context.Context, resourceGroup, frontendIPConfigID string, crt cache.AzureCacheReadType) (*armnetwork.PrivateLinkService, error) {
@@ -126,3 +128,22 @@ func (r *repo) DeletePEConnection(ctx context.Context, resourceGroup, plsName, p
 
 	return nil
 }
+
+func (r *repo) UpdatePEConnection(ctx context.Context, resourceGroup, plsName, lbFrontendID string, peConn armnetwork.PrivateEndpointConnection) (*armnetwork.PrivateEndpointConnection, error) {
+	if plsName == "" {
+		return nil, ErrMissingPLSName
+	}
+	if peConn.Name == nil {
+		return nil, ErrMissingPEConnectionName
+	}
+	cacheKey := getPLSCacheKey(resourceGroup, lbFrontendID)
+
+	resp, err := r.client.UpdatePrivateEndpointConnection(ctx, resourceGroup, plsName, *peConn.Name, peConn)
+	if err != nil {
+		return nil, fmt.Errorf("update PLS PE connection: %w", err)
+	}
+	// clear cache
+	_ = r.cache.Delete(cacheKey)
+
+	return resp, nil
+}
@@ -344,3 +344,64 @@ func TestRepo_DeletePEConnection(t *testing.T) {
 		assert.ErrorIs(t, err, expectedErr)
 	})
 }
+
+func TestRepo_UpdatePEConnection(t *testing.T) {
+	t.Parallel()
+	t.Run("update one", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		cli := mock_privatelinkserviceclient.NewMockInterface(ctrl)
+		repo, err := NewRepo(cli, 60*time.Second, false)
+		assert.NoError(t, err)
+		ctx := context.Background()
+
+		const (
+			rg                 = "resource-group"
+			plsName            = "pls-name"
+			frontendIPConfigID = "frontend-ip-config-id"
+			peConnName         = "pe-conn-name"
+		)
+
+		peConn := armnetwork.PrivateEndpointConnection{
+			Name: to.Ptr(peConnName),
+			Properties: &armnetwork.PrivateEndpointConnectionProperties{
+				PrivateLinkServiceConnectionState: &armnetwork.PrivateLinkServiceConnectionState{
+					Status: to.Ptr("Approved"),
+				},
+			},
+		}
+		cli.EXPECT().UpdatePrivateEndpointConnection(gomock.Any(), rg, plsName, peConnName, peConn).Return(&peConn, nil).Times(1)
+
+		updated, err := repo.UpdatePEConnection(ctx, rg, plsName, frontendIPConfigID, peConn)
+		assert.NoError(t, err)
+		assert.Equal(t, &peConn, updated)
+	})
+
+	t.Run("update one with missing PE connection name", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		cli := mock_privatelinkserviceclient.NewMockInterface(ctrl)
+		repo, err := NewRepo(cli, 60*time.Second, false)
+		assert.NoError(t, err)
+
+		_, err = repo.UpdatePEConnection(context.Background(), "resource-group", "pls-name", "frontend-ip-config-id", armnetwork.PrivateEndpointConnection{})
+		assert.ErrorIs(t, err, ErrMissingPEConnectionName)
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		cli := mock_privatelinkserviceclient.NewMockInterface(ctrl)
+		repo, err := NewRepo(cli, 60*time.Second, false)
+		assert.NoError(t, err)
+
+		expectedErr := fmt.Errorf("API error")
+		cli.EXPECT().UpdatePrivateEndpointConnection(gomock.Any(), "resource-group", "pls-name", "pe-conn-name", gomock.Any()).Return(nil, expectedErr).Times(1)
+
+		_, err = repo.UpdatePEConnection(context.Background(), "resource-group", "pls-name", "frontend-ip-config-id", armnetwork.PrivateEndpointConnection{Name: to.Ptr("pe-conn-name")})
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
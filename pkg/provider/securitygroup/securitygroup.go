@@ -19,10 +19,13 @@ package securitygroup
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/netip"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
@@ -118,6 +121,12 @@ func (helper *RuleHelper) nextRulePriority(prefer rulePriorityPrefer) (int32, er
 	if prefer == rulePriorityPreferFromEnd {
 		init, end, delta = end-1, init-1, -1
 	}
+	// The allow rules are allocated from the start and the deny rules from the end. A new rule should not
+	// cross the managed rules allocated from the other end, otherwise the allow rules could be shadowed
+	// by the deny rules. The range is considered fragmented and exhausted in that case.
+	if boundary, found := helper.priorityBoundary(prefer); found {
+		end = int(boundary)
+	}
 
 	for init != end {
 		p := int32(init)
@@ -131,6 +140,31 @@ func (helper *RuleHelper) nextRulePriority(prefer rulePriorityPrefer) (int32, er
 	return 0, ErrSecurityRulePriorityExhausted
 }
 
+// priorityBoundary returns the first priority of the managed rules allocated from the other end of the range.
+func (helper *RuleHelper) priorityBoundary(prefer rulePriorityPrefer) (int32, bool) {
+	var (
+		boundary int32
+		found    bool
+	)
+	for _, rule := range helper.rules {
+		if !isManagedRule(rule) || rule.Properties.Access == nil {
+			continue
+		}
+		p := *rule.Properties.Priority
+		switch {
+		case prefer == rulePriorityPreferFromStart && *rule.Properties.Access == armnetwork.SecurityRuleAccessDeny:
+			if !found || p < boundary {
+				boundary, found = p, true
+			}
+		case prefer == rulePriorityPreferFromEnd && *rule.Properties.Access == armnetwork.SecurityRuleAccessAllow:
+			if !found || p > boundary {
+				boundary, found = p, true
+			}
+		}
+	}
+	return boundary, found
+}
+
 // getOrCreateRule returns an existing rule or create a new one if it doesn't exist.
 func (helper *RuleHelper) getOrCreateRule(name string, priorityPrefer rulePriorityPrefer) (*armnetwork.SecurityRule, error) {
 	logger := helper.logger.WithName("getOrCreateRule").WithValues("rule-name", name)
@@ -141,9 +175,15 @@ func (helper *RuleHelper) getOrCreateRule(name string, priorityPrefer rulePriori
 	}
 
 	priority, err := helper.nextRulePriority(priorityPrefer)
+	if errors.Is(err, ErrSecurityRulePriorityExhausted) {
+		// The priority range can be fragmented by the deleted rules and the rules not managed by the cloud provider.
+		// Close the gaps between the managed rules and retry.
+		if n := helper.CompactRulePriorities(); n > 0 {
+			logger.V(2).Info("Compacted the rule priorities", "num-renumbered-rules", n)
+			priority, err = helper.nextRulePriority(priorityPrefer)
+		}
+	}
 	if err != nil {
-		// NOTE: right now it won't happen because the number of rules is limited.
-		//       maxPriority[4096] - minPriority[500] > maxSecurityRulesPerGroup[1000]
 		helper.logger.Error(err, "Failed to get an available rule priority")
 		return nil, err
 	}
@@ -163,14 +203,18 @@ func (helper *RuleHelper) getOrCreateRule(name string, priorityPrefer rulePriori
 }
 
 // addAllowRule adds a rule that allows certain traffic.
+// The source is either the prefixes or the application security groups,
+// and the destination is either the prefixes or the application security group.
 func (helper *RuleHelper) addAllowRule(
 	protocol armnetwork.SecurityRuleProtocol,
 	ipFamily iputil.Family,
 	srcPrefixes []string,
+	srcASGIDs []string,
 	dstPrefixes []string,
+	dstASGID string,
 	dstPorts []int32,
 ) error {
-	name := GenerateAllowSecurityRuleName(protocol, ipFamily, srcPrefixes, dstPorts)
+	name := GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, srcPrefixes, srcASGIDs, dstASGID, dstPorts)
 	rule, err := helper.getOrCreateRule(name, rulePriorityPreferFromStart)
 	if err != nil {
 		return err
@@ -183,7 +227,9 @@ func (helper *RuleHelper) addAllowRule(
 	rule.Properties.Direction = to.Ptr(armnetwork.SecurityRuleDirectionInbound)
 	{
 		// Source
-		if len(srcPrefixes) == 1 {
+		if len(srcASGIDs) > 0 {
+			rule.Properties.SourceApplicationSecurityGroups = NewApplicationSecurityGroups(srcASGIDs)
+		} else if len(srcPrefixes) == 1 {
 			rule.Properties.SourceAddressPrefix = to.Ptr(srcPrefixes[0])
 		} else {
 			rule.Properties.SourceAddressPrefixes = to.SliceOfPtrs(srcPrefixes...)
@@ -192,8 +238,12 @@ func (helper *RuleHelper) addAllowRule(
 	}
 	{
 		// Destination
-		addresses := append(ListDestinationPrefixes(rule), dstPrefixes...)
-		SetDestinationPrefixes(rule, addresses)
+		if dstASGID != "" {
+			rule.Properties.DestinationApplicationSecurityGroups = NewApplicationSecurityGroups([]string{dstASGID})
+		} else {
+			addresses := append(ListDestinationPrefixes(rule), dstPrefixes...)
+			SetDestinationPrefixes(rule, addresses)
+		}
 		rule.Properties.DestinationPortRanges = to.SliceOfPtrs(dstPortRanges...)
 	}
 
@@ -221,7 +271,7 @@ func (helper *RuleHelper) AddRuleForAllowedServiceTag(
 
 	helper.logger.V(4).Info("Patching a rule for allowed service tag", "ip-family", ipFamily)
 
-	return helper.addAllowRule(protocol, ipFamily, srcPrefixes, dstPrefixes, dstPorts)
+	return helper.addAllowRule(protocol, ipFamily, srcPrefixes, nil, dstPrefixes, "", dstPorts)
 }
 
 // AddRuleForAllowedIPRanges adds a rule for traffic from certain IP ranges.
@@ -249,7 +299,43 @@ func (helper *RuleHelper) AddRuleForAllowedIPRanges(
 
 	helper.logger.V(4).Info("Patching a rule for allowed IP ranges", "ip-family", ipFamily)
 
-	return helper.addAllowRule(protocol, ipFamily, srcPrefixes, dstPrefixes, dstPorts)
+	return helper.addAllowRule(protocol, ipFamily, srcPrefixes, nil, dstPrefixes, "", dstPorts)
+}
+
+// AddRuleForAllowedApplicationSecurityGroups adds a rule for traffic from certain application security groups.
+func (helper *RuleHelper) AddRuleForAllowedApplicationSecurityGroups(
+	asgIDs []string,
+	protocol armnetwork.SecurityRuleProtocol,
+	dstAddresses []netip.Addr,
+	dstPorts []int32,
+) error {
+	if !iputil.AreAddressesFromSameFamily(dstAddresses) {
+		return ErrSecurityRuleDestinationAddressesNotFromSameIPFamily
+	}
+
+	var (
+		ipFamily    = iputil.FamilyOfAddr(dstAddresses[0])
+		dstPrefixes = fnutil.Map(func(ip netip.Addr) string { return ip.String() }, dstAddresses)
+	)
+
+	helper.logger.V(4).Info("Patching a rule for allowed application security groups", "ip-family", ipFamily)
+
+	return helper.addAllowRule(protocol, ipFamily, nil, asgIDs, dstPrefixes, "", dstPorts)
+}
+
+// AddRuleForApplicationSecurityGroupDestination adds a rule for traffic to the given destination application security group
+// from either the source prefixes (a service tag or IP ranges of the given IP family) or the source application security groups.
+func (helper *RuleHelper) AddRuleForApplicationSecurityGroupDestination(
+	protocol armnetwork.SecurityRuleProtocol,
+	ipFamily iputil.Family,
+	srcPrefixes []string,
+	srcASGIDs []string,
+	dstASGID string,
+	dstPorts []int32,
+) error {
+	helper.logger.V(4).Info("Patching a rule for destination application security group", "ip-family", ipFamily, "dst-asg", dstASGID)
+
+	return helper.addAllowRule(protocol, ipFamily, srcPrefixes, srcASGIDs, nil, dstASGID, dstPorts)
 }
 
 // AddRuleForDenyAll adds a rule to deny all traffic from the given destination addresses.
@@ -293,6 +379,156 @@ func (helper *RuleHelper) AddRuleForDenyAll(dstAddresses []netip.Addr) error {
 	return nil
 }
 
+// AddRuleForDenyAllToApplicationSecurityGroup adds a rule to deny all traffic to the given destination application security group.
+// Unlike the rule for the destination addresses, the rule is not shared with the other destinations,
+// and it covers both IP families as the source is `*`.
+func (helper *RuleHelper) AddRuleForDenyAllToApplicationSecurityGroup(dstASGID string) error {
+	ruleName := GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(dstASGID)
+
+	helper.logger.V(4).Info("Patching a rule for deny all to application security group", "dst-asg", dstASGID)
+
+	rule, err := helper.getOrCreateRule(ruleName, rulePriorityPreferFromEnd)
+	if err != nil {
+		return err
+	}
+	rule.Properties.Protocol = to.Ptr(armnetwork.SecurityRuleProtocolAsterisk)
+	rule.Properties.Access = to.Ptr(armnetwork.SecurityRuleAccessDeny)
+	rule.Properties.Direction = to.Ptr(armnetwork.SecurityRuleDirectionInbound)
+	rule.Properties.SourceAddressPrefix = ptr.To("*")
+	rule.Properties.SourcePortRange = ptr.To("*")
+	rule.Properties.DestinationApplicationSecurityGroups = NewApplicationSecurityGroups([]string{dstASGID})
+	rule.Properties.DestinationPortRange = ptr.To("*")
+
+	helper.logger.V(4).Info("Patched a rule for deny all to application security group", "rule-name", ptr.To(rule.Name))
+
+	return nil
+}
+
+// AddRuleForDenyPort adds a rule to deny all traffic to the given destination port of the destination addresses.
+// Like the deny all rule, it is to limit the traffic inside the VNet, and it is shared with the other destinations.
+func (helper *RuleHelper) AddRuleForDenyPort(
+	protocol armnetwork.SecurityRuleProtocol,
+	dstAddresses []netip.Addr,
+	dstPort int32,
+) error {
+	if !iputil.AreAddressesFromSameFamily(dstAddresses) {
+		return ErrSecurityRuleDestinationAddressesNotFromSameIPFamily
+	}
+
+	var (
+		ipFamily = iputil.FamilyOfAddr(dstAddresses[0])
+		ruleName = GenerateDenySecurityRuleName(protocol, ipFamily, dstPort)
+	)
+
+	helper.logger.V(4).Info("Patching a rule for deny port", "ip-family", ipFamily, "protocol", protocol, "dst-port", dstPort)
+
+	rule, err := helper.getOrCreateRule(ruleName, rulePriorityPreferFromEnd)
+	if err != nil {
+		return err
+	}
+	setDenyPortRuleProperties(rule, protocol, dstPort)
+	addresses := fnutil.Map(func(ip netip.Addr) string { return ip.String() }, dstAddresses)
+	addresses = append(addresses, ListDestinationPrefixes(rule)...)
+	SetDestinationPrefixes(rule, addresses)
+
+	helper.logger.V(4).Info("Patched a rule for deny port", "rule-name", ptr.To(rule.Name))
+
+	return nil
+}
+
+// AddRuleForDenyPortToApplicationSecurityGroup adds a rule to deny all traffic to the given destination port
+// of the destination application security group.
+func (helper *RuleHelper) AddRuleForDenyPortToApplicationSecurityGroup(
+	protocol armnetwork.SecurityRuleProtocol,
+	dstASGID string,
+	dstPort int32,
+) error {
+	ruleName := GenerateDenySecurityRuleNameForApplicationSecurityGroup(protocol, dstPort, dstASGID)
+
+	helper.logger.V(4).Info("Patching a rule for deny port to application security group", "protocol", protocol, "dst-port", dstPort, "dst-asg", dstASGID)
+
+	rule, err := helper.getOrCreateRule(ruleName, rulePriorityPreferFromEnd)
+	if err != nil {
+		return err
+	}
+	setDenyPortRuleProperties(rule, protocol, dstPort)
+	rule.Properties.DestinationApplicationSecurityGroups = NewApplicationSecurityGroups([]string{dstASGID})
+
+	helper.logger.V(4).Info("Patched a rule for deny port to application security group", "rule-name", ptr.To(rule.Name))
+
+	return nil
+}
+
+// setDenyPortRuleProperties sets the properties of the rule denying all traffic to the given destination port.
+func setDenyPortRuleProperties(rule *armnetwork.SecurityRule, protocol armnetwork.SecurityRuleProtocol, dstPort int32) {
+	rule.Properties.Protocol = to.Ptr(protocol)
+	rule.Properties.Access = to.Ptr(armnetwork.SecurityRuleAccessDeny)
+	rule.Properties.Direction = to.Ptr(armnetwork.SecurityRuleDirectionInbound)
+	rule.Properties.SourceAddressPrefix = ptr.To("*")
+	rule.Properties.SourcePortRange = ptr.To("*")
+	rule.Properties.DestinationPortRange = ptr.To(strconv.FormatInt(int64(dstPort), 10))
+}
+
+// AddRuleForNodeInboundNAT adds a rule which allows the traffic from the source prefixes, or the source application
+// security groups, to the backend port of the per-node inbound NAT rules on the given node addresses.
+// Unlike the other allow rules, the rule is owned by a single service and named by it, and its destination
+// addresses are replaced instead of being merged, so that the nodes leaving the backend pool are removed.
+func (helper *RuleHelper) AddRuleForNodeInboundNAT(
+	name string,
+	srcPrefixes []string,
+	srcASGIDs []string,
+	dstAddresses []netip.Addr,
+	dstPort int32,
+) error {
+	if !iputil.AreAddressesFromSameFamily(dstAddresses) {
+		return ErrSecurityRuleDestinationAddressesNotFromSameIPFamily
+	}
+	rule, err := helper.getOrCreateRule(name, rulePriorityPreferFromStart)
+	if err != nil {
+		return err
+	}
+
+	rule.Properties.Protocol = to.Ptr(armnetwork.SecurityRuleProtocolTCP)
+	rule.Properties.Access = to.Ptr(armnetwork.SecurityRuleAccessAllow)
+	rule.Properties.Direction = to.Ptr(armnetwork.SecurityRuleDirectionInbound)
+	{
+		// Source
+		rule.Properties.SourceAddressPrefix = nil
+		rule.Properties.SourceAddressPrefixes = nil
+		rule.Properties.SourceApplicationSecurityGroups = nil
+		if len(srcASGIDs) > 0 {
+			rule.Properties.SourceApplicationSecurityGroups = NewApplicationSecurityGroups(srcASGIDs)
+		} else if len(srcPrefixes) == 1 {
+			rule.Properties.SourceAddressPrefix = to.Ptr(srcPrefixes[0])
+		} else {
+			rule.Properties.SourceAddressPrefixes = to.SliceOfPtrs(srcPrefixes...)
+		}
+		rule.Properties.SourcePortRange = ptr.To("*")
+	}
+	{
+		// Destination
+		SetDestinationPrefixes(rule, fnutil.Map(func(ip netip.Addr) string { return ip.String() }, dstAddresses))
+		rule.Properties.DestinationPortRange = ptr.To(strconv.FormatInt(int64(dstPort), 10))
+	}
+
+	helper.logger.V(4).Info("Patched a rule for node inbound NAT", "rule-name", name)
+
+	return nil
+}
+
+// RemoveRulesWithNamePrefix removes the rules whose names start with the given prefix, except the retained ones.
+// It is used to clean up the rules owned by a single service, e.g., the ones of the per-node inbound NAT rules.
+func (helper *RuleHelper) RemoveRulesWithNamePrefix(prefix string, retainNames []string) {
+	for name, rule := range helper.rules {
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) || slices.Contains(retainNames, name) {
+			continue
+		}
+		helper.logger.V(4).Info("Removing a rule", "rule-name", name)
+		delete(helper.rules, name)
+		delete(helper.priorities, ptr.Deref(rule.Properties.Priority, 0))
+	}
+}
+
 // RemoveDestinationFromRules removes the given destination addresses from rules that match the given protocol and ports is in the retainDstPorts list.
 // It may add a new rule if the original rule needs to be split.
 func (helper *RuleHelper) RemoveDestinationFromRules(
@@ -316,6 +552,10 @@ func (helper *RuleHelper) RemoveDestinationFromRules(
 		if *rule.Properties.Protocol != protocol {
 			continue
 		}
+		if len(rule.Properties.DestinationApplicationSecurityGroups) > 0 {
+			// The rules attached to the application security groups are cleaned by RemoveApplicationSecurityGroupDestinationFromRules.
+			continue
+		}
 
 		if err := helper.removeDestinationFromRule(rule, dstPrefixes, retainDstPorts); err != nil {
 			logger.Error(err, "Failed to remove destination from rule", "rule-name", *rule.Name)
@@ -375,15 +615,136 @@ func (helper *RuleHelper) removeDestinationFromRule(rule *armnetwork.SecurityRul
 		return fmt.Errorf("parse prefix as IP address %q: %w", prefixes[0], err)
 	}
 	ipFamily := iputil.FamilyOfAddr(addr)
-	return helper.addAllowRule(*rule.Properties.Protocol, ipFamily, ListSourcePrefixes(rule), prefixes, expectedPorts)
+	return helper.addAllowRule(*rule.Properties.Protocol, ipFamily, ListSourcePrefixes(rule), ListSourceApplicationSecurityGroupIDs(rule), prefixes, "", expectedPorts)
+}
+
+// RemoveApplicationSecurityGroupDestinationFromRules removes the given destination application security group from rules
+// that match the given protocol, unless the destination ports of the rule are all in the retainDstPorts list.
+// It may add a new rule if the original rule needs to be split.
+func (helper *RuleHelper) RemoveApplicationSecurityGroupDestinationFromRules(
+	protocol armnetwork.SecurityRuleProtocol,
+	dstASGID string,
+	retainDstPorts []int32,
+) error {
+	logger := helper.logger.WithName("RemoveApplicationSecurityGroupDestinationFromRules").WithValues("protocol", protocol, "dst-asg", dstASGID)
+	logger.V(10).Info("Cleaning destination application security group from SecurityGroup")
+
+	for _, rule := range helper.rules {
+		if rule.Properties.Priority == nil || rule.Properties.Protocol == nil {
+			continue
+		}
+		priority := *rule.Properties.Priority
+		if priority < consts.LoadBalancerMinimumPriority || consts.LoadBalancerMaximumPriority < priority {
+			continue
+		}
+		if *rule.Properties.Protocol != protocol {
+			continue
+		}
+		if !slices.ContainsFunc(ListDestinationApplicationSecurityGroupIDs(rule), func(id string) bool { return strings.EqualFold(id, dstASGID) }) {
+			continue
+		}
+
+		// Clean DenyAll rule
+		if *rule.Properties.Access == armnetwork.SecurityRuleAccessDeny {
+			if len(retainDstPorts) == 0 {
+				rule.Properties.DestinationApplicationSecurityGroups = nil
+			}
+			continue
+		}
+
+		// Clean Allow rule
+		currentPorts, err := ListDestinationPortRanges(rule)
+		if err != nil {
+			logger.Info("Skip because it contains `*` or port-ranges as destination port ranges.", "rule-name", *rule.Name)
+			continue
+		}
+		expectedPorts := fnutil.Intersection(currentPorts, retainDstPorts)
+		if len(currentPorts) == len(expectedPorts) {
+			continue
+		}
+
+		rule.Properties.DestinationApplicationSecurityGroups = nil
+		if len(expectedPorts) == 0 {
+			continue
+		}
+		// There are additional ports are expected, need to create a new rule for them.
+		if err := helper.addAllowRule(
+			protocol, ipFamilyOfAllowRuleName(*rule.Name),
+			ListSourcePrefixes(rule), ListSourceApplicationSecurityGroupIDs(rule),
+			nil, dstASGID, expectedPorts,
+		); err != nil {
+			logger.Error(err, "Failed to split rule", "rule-name", *rule.Name)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListDestinationApplicationSecurityGroupIDs returns the distinct destination application security groups
+// of the rules managed by the cloud provider.
+func (helper *RuleHelper) ListDestinationApplicationSecurityGroupIDs() []string {
+	var (
+		rv   []string
+		seen = make(map[string]bool)
+	)
+	for _, rule := range helper.rules {
+		if !isManagedRule(rule) {
+			continue
+		}
+		for _, id := range ListDestinationApplicationSecurityGroupIDs(rule) {
+			if key := strings.ToLower(id); !seen[key] {
+				seen[key] = true
+				rv = append(rv, id)
+			}
+		}
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+// ipFamilyOfAllowRuleName returns the IP family encoded in the name of the rule generated by GenerateAllowSecurityRuleName.
+func ipFamilyOfAllowRuleName(name string) iputil.Family {
+	if parts := strings.Split(name, SecurityRuleNameSep); len(parts) > 2 && parts[2] == string(iputil.IPv6) {
+		return iputil.IPv6
+	}
+	return iputil.IPv4
 }
 
 // SecurityGroup returns the underlying SecurityGroup object and a bool indicating whether any changes were made to the RuleHelper.
 func (helper *RuleHelper) SecurityGroup() (*armnetwork.SecurityGroup, bool, error) {
 	var (
-		rv    = helper.sg
-		rules = make([]*armnetwork.SecurityRule, 0, len(helper.rules))
+		rv       = helper.sg
+		rules    = helper.effectiveRules()
+		capacity = capacityOfRules(rules)
 	)
+
+	rv.Properties.SecurityRules = rules
+
+	var (
+		snapshot = makeSecurityGroupSnapshot(rv)
+		updated  = !bytes.Equal(helper.snapshot, snapshot)
+	)
+	{
+		// Check whether the SecurityGroup exceeds the limit.
+		helper.logger.V(10).Info("Checking the number of rules and IP addresses", "num-rules", capacity.Rules, "num-src-ips", capacity.SourceIPs, "num-dst-ips", capacity.DestinationIPs)
+		if capacity.Rules > MaxSecurityRulesPerGroup {
+			return nil, false, fmt.Errorf("exceeds the maximum number of rules (%d > %d)", capacity.Rules, MaxSecurityRulesPerGroup)
+		}
+		if capacity.SourceIPs > MaxSecurityRuleSourceIPsPerGroup {
+			return nil, false, fmt.Errorf("exceeds the maximum number of source IP addresses (%d > %d)", capacity.SourceIPs, MaxSecurityRuleSourceIPsPerGroup)
+		}
+		if capacity.DestinationIPs > MaxSecurityRuleDestinationIPsPerGroup {
+			return nil, false, fmt.Errorf("exceeds the maximum number of destination IP addresses (%d > %d)", capacity.DestinationIPs, MaxSecurityRuleDestinationIPsPerGroup)
+		}
+	}
+
+	return rv, updated, nil
+}
+
+// effectiveRules returns the rules to be written to the SecurityGroup.
+func (helper *RuleHelper) effectiveRules() []*armnetwork.SecurityRule {
+	rules := make([]*armnetwork.SecurityRule, 0, len(helper.rules))
 	for _, r := range helper.rules {
 		var (
 			dstAddresses = ListDestinationPrefixes(r)
@@ -396,46 +757,112 @@ func (helper *RuleHelper) SecurityGroup() (*armnetwork.SecurityGroup, bool, erro
 		}
 		rules = append(rules, r)
 	}
+	return rules
+}
 
-	rv.Properties.SecurityRules = rules
+// Capacity is the usage of a SecurityGroup against the limits of Azure.
+type Capacity struct {
+	Rules          int
+	SourceIPs      int
+	DestinationIPs int
+}
 
-	var (
-		snapshot = makeSecurityGroupSnapshot(rv)
-		updated  = !bytes.Equal(helper.snapshot, snapshot)
-		nRules   int
-		nSrcIPs  int
-		nDstIPs  int
+// Headroom returns the number of rules, source IPs and destination IPs that can still be added to the SecurityGroup.
+func (c Capacity) Headroom() Capacity {
+	return Capacity{
+		Rules:          MaxSecurityRulesPerGroup - c.Rules,
+		SourceIPs:      MaxSecurityRuleSourceIPsPerGroup - c.SourceIPs,
+		DestinationIPs: MaxSecurityRuleDestinationIPsPerGroup - c.DestinationIPs,
+	}
+}
+
+// UsageRatio returns the highest ratio of the usage to the limit among the rules, source IPs and destination IPs.
+func (c Capacity) UsageRatio() float64 {
+	return max(
+		float64(c.Rules)/MaxSecurityRulesPerGroup,
+		float64(c.SourceIPs)/MaxSecurityRuleSourceIPsPerGroup,
+		float64(c.DestinationIPs)/MaxSecurityRuleDestinationIPsPerGroup,
 	)
-	{
-		// Check whether the SecurityGroup exceeds the limit.
-		for _, rule := range rv.Properties.SecurityRules {
-			nRules++
-			if rule.Properties.SourceAddressPrefixes != nil {
-				nSrcIPs += len(rule.Properties.SourceAddressPrefixes)
-			}
-			if rule.Properties.SourceAddressPrefix != nil {
-				nSrcIPs++
-			}
-			if rule.Properties.DestinationAddressPrefixes != nil {
-				nDstIPs += len(rule.Properties.DestinationAddressPrefixes)
-			}
-			if rule.Properties.DestinationAddressPrefix != nil {
-				nDstIPs++
-			}
+}
+
+func capacityOfRules(rules []*armnetwork.SecurityRule) Capacity {
+	var rv Capacity
+	for _, rule := range rules {
+		rv.Rules++
+		rv.SourceIPs += len(ListSourcePrefixes(rule))
+		rv.DestinationIPs += len(ListDestinationPrefixes(rule))
+	}
+	return rv
+}
+
+// Capacity returns the usage of the SecurityGroup with the pending changes.
+func (helper *RuleHelper) Capacity() Capacity {
+	return capacityOfRules(helper.effectiveRules())
+}
+
+// isManagedRule returns true if the rule is created by the cloud provider.
+func isManagedRule(rule *armnetwork.SecurityRule) bool {
+	if rule.Name == nil || rule.Properties == nil || rule.Properties.Priority == nil {
+		return false
+	}
+	priority := *rule.Properties.Priority
+	return strings.HasPrefix(*rule.Name, SecurityRuleNamePrefix+SecurityRuleNameSep) &&
+		consts.LoadBalancerMinimumPriority <= priority && priority <= consts.LoadBalancerMaximumPriority
+}
+
+// rulesInPriorityRange returns the rules whose priorities are in the range of the managed rules sorted by their priorities.
+func (helper *RuleHelper) rulesInPriorityRange() []*armnetwork.SecurityRule {
+	var rv []*armnetwork.SecurityRule
+	for _, rule := range helper.rules {
+		if rule.Properties == nil || rule.Properties.Priority == nil {
+			continue
 		}
-		helper.logger.V(10).Info("Checking the number of rules and IP addresses", "num-rules", nRules, "num-src-ips", nSrcIPs, "num-dst-ips", nDstIPs)
-		if nRules > MaxSecurityRulesPerGroup {
-			return nil, false, fmt.Errorf("exceeds the maximum number of rules (%d > %d)", nRules, MaxSecurityRulesPerGroup)
+		if p := *rule.Properties.Priority; consts.LoadBalancerMinimumPriority <= p && p <= consts.LoadBalancerMaximumPriority {
+			rv = append(rv, rule)
+		}
+	}
+	sort.Slice(rv, func(i, j int) bool {
+		return *rv[i].Properties.Priority < *rv[j].Properties.Priority
+	})
+	return rv
+}
+
+// CompactRulePriorities renumbers the managed rules to close the gaps between their priorities.
+// The allow rules are moved towards the minimum priority and the deny rules towards the maximum priority,
+// but a rule is never moved across another rule, so the order of all the rules, including the ones not
+// managed by the cloud provider, is kept. It returns the number of the renumbered rules.
+func (helper *RuleHelper) CompactRulePriorities() int {
+	var renumbered int
+	move := func(rule *armnetwork.SecurityRule, p int32) {
+		delete(helper.priorities, *rule.Properties.Priority)
+		rule.Properties.Priority = ptr.To(p)
+		helper.priorities[p] = *rule.Name
+		renumbered++
+	}
+
+	// Move the managed allow rules down to the first free priority above the previous rule.
+	rules := helper.rulesInPriorityRange()
+	next := int32(consts.LoadBalancerMinimumPriority)
+	for _, rule := range rules {
+		if isManagedRule(rule) && ptr.Deref(rule.Properties.Access, "") == armnetwork.SecurityRuleAccessAllow && *rule.Properties.Priority > next {
+			move(rule, next)
 		}
-		if nSrcIPs > MaxSecurityRuleSourceIPsPerGroup {
-			return nil, false, fmt.Errorf("exceeds the maximum number of source IP addresses (%d > %d)", nSrcIPs, MaxSecurityRuleSourceIPsPerGroup)
+		next = *rule.Properties.Priority + 1
+	}
+
+	// Move the managed deny rules up to the first free priority below the next rule.
+	next = consts.LoadBalancerMaximumPriority - 1
+	for i := len(rules) - 1; i >= 0; i-- {
+		rule := rules[i]
+		if isManagedRule(rule) && ptr.Deref(rule.Properties.Access, "") == armnetwork.SecurityRuleAccessDeny && *rule.Properties.Priority < next {
+			move(rule, next)
 		}
-		if nDstIPs > MaxSecurityRuleDestinationIPsPerGroup {
-			return nil, false, fmt.Errorf("exceeds the maximum number of destination IP addresses (%d > %d)", nDstIPs, MaxSecurityRuleDestinationIPsPerGroup)
+		if p := *rule.Properties.Priority - 1; p < next {
+			next = p
 		}
 	}
 
-	return rv, updated, nil
+	return renumbered
 }
 
 // makeSecurityGroupSnapshot returns a byte array as the snapshot of the given SecurityGroup.
@@ -19,6 +19,7 @@ package securitygroup_test
 import (
 	"net/netip"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -1740,3 +1741,342 @@ func TestGenerateDenyAllSecurityRuleName(t *testing.T) {
 	assert.Equal(t, GenerateDenyAllSecurityRuleName(iputil.IPv4), "k8s-azure-lb_deny-all_IPv4")
 	assert.Equal(t, GenerateDenyAllSecurityRuleName(iputil.IPv6), "k8s-azure-lb_deny-all_IPv6")
 }
+
+func TestGenerateAllowSecurityRuleNameWithApplicationSecurityGroups(t *testing.T) {
+	var (
+		protocol    = armnetwork.SecurityRuleProtocolTCP
+		ipFamily    = iputil.IPv4
+		srcPrefixes = []string{"foo"}
+		dstPorts    = []int32{80, 443}
+		asgID1      = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/asg1"
+		asgID2      = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/asg2"
+	)
+
+	t.Run("should be the same as GenerateAllowSecurityRuleName without application security groups", func(t *testing.T) {
+		assert.Equal(t,
+			GenerateAllowSecurityRuleName(protocol, ipFamily, srcPrefixes, dstPorts),
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, srcPrefixes, nil, "", dstPorts),
+		)
+	})
+
+	t.Run("should be application-security-group-specific", func(t *testing.T) {
+		assert.Len(t, map[string]bool{
+			GenerateAllowSecurityRuleName(protocol, ipFamily, nil, dstPorts):                                                        true,
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, nil, []string{asgID1}, "", dstPorts):     true,
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, nil, []string{asgID2}, "", dstPorts):     true,
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, nil, nil, asgID1, dstPorts):              true,
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, srcPrefixes, nil, asgID1, dstPorts):      true,
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, nil, []string{asgID2}, asgID1, dstPorts): true,
+		}, 6)
+	})
+
+	t.Run("order-insensitive and case-insensitive", func(t *testing.T) {
+		assert.Equal(t,
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, nil, []string{asgID1, asgID2}, asgID1, dstPorts),
+			GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, nil, []string{strings.ToUpper(asgID2), asgID1}, strings.ToUpper(asgID1), dstPorts),
+		)
+	})
+}
+
+func TestGenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(t *testing.T) {
+	var (
+		asgID1 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/asg1"
+		asgID2 = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/asg2"
+	)
+	assert.Len(t, map[string]bool{
+		GenerateDenyAllSecurityRuleName(iputil.IPv4):                                        true,
+		GenerateDenyAllSecurityRuleName(iputil.IPv6):                                        true,
+		GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(asgID1):                  true,
+		GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(asgID2):                  true,
+		GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(strings.ToUpper(asgID1)): true,
+	}, 4)
+	assert.True(t, strings.HasPrefix(GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(asgID1), "k8s-azure-lb_deny-all_"))
+}
+
+func TestRuleHelper_ApplicationSecurityGroups(t *testing.T) {
+	var (
+		fx       = fixture.NewFixture()
+		protocol = armnetwork.SecurityRuleProtocolTCP
+		dstPorts = []int32{80, 443}
+		srcASGID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/src"
+		dstASGID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationSecurityGroups/dst"
+	)
+
+	t.Run("it should add a rule from the source application security groups to the destination addresses", func(t *testing.T) {
+		var (
+			sg           = fx.Azure().SecurityGroup().Build()
+			helper       = ExpectNewSecurityGroupHelper(t, sg)
+			dstAddresses = fx.RandomIPv4Addresses(2)
+		)
+		assert.NoError(t, helper.AddRuleForAllowedApplicationSecurityGroups([]string{srcASGID}, protocol, dstAddresses, dstPorts))
+
+		outputSG, updated, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		testutil.ExpectExactSecurityRules(t, outputSG, []*armnetwork.SecurityRule{
+			{
+				Name: ptr.To(GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, iputil.IPv4, nil, []string{srcASGID}, "", dstPorts)),
+				Properties: &armnetwork.SecurityRulePropertiesFormat{
+					Protocol:                        to.Ptr(protocol),
+					Access:                          to.Ptr(armnetwork.SecurityRuleAccessAllow),
+					Direction:                       to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+					SourceApplicationSecurityGroups: []*armnetwork.ApplicationSecurityGroup{{ID: ptr.To(srcASGID)}},
+					SourcePortRange:                 ptr.To("*"),
+					DestinationAddressPrefixes:      to.SliceOfPtrs(NormalizeSecurityRuleAddressPrefixes(fnutil.Map(func(addr netip.Addr) string { return addr.String() }, dstAddresses))...),
+					DestinationPortRanges:           to.SliceOfPtrs(NormalizeDestinationPortRanges(dstPorts)...),
+					Priority:                        ptr.To(int32(500)),
+				},
+			},
+		})
+	})
+
+	t.Run("it should add rules to the destination application security group and clean them up", func(t *testing.T) {
+		var (
+			sg            = fx.Azure().SecurityGroup().Build()
+			helper        = ExpectNewSecurityGroupHelper(t, sg)
+			allowRuleName = GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, iputil.IPv4, []string{ServiceTagInternet}, nil, dstASGID, dstPorts)
+			denyRuleName  = GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(dstASGID)
+		)
+		assert.NoError(t, helper.AddRuleForApplicationSecurityGroupDestination(protocol, iputil.IPv4, []string{ServiceTagInternet}, nil, dstASGID, dstPorts))
+		assert.NoError(t, helper.AddRuleForDenyAllToApplicationSecurityGroup(dstASGID))
+
+		outputSG, updated, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		testutil.ExpectExactSecurityRules(t, outputSG, []*armnetwork.SecurityRule{
+			{
+				Name: ptr.To(allowRuleName),
+				Properties: &armnetwork.SecurityRulePropertiesFormat{
+					Protocol:                             to.Ptr(protocol),
+					Access:                               to.Ptr(armnetwork.SecurityRuleAccessAllow),
+					Direction:                            to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+					SourceAddressPrefix:                  ptr.To(ServiceTagInternet),
+					SourcePortRange:                      ptr.To("*"),
+					DestinationApplicationSecurityGroups: []*armnetwork.ApplicationSecurityGroup{{ID: ptr.To(dstASGID)}},
+					DestinationPortRanges:                to.SliceOfPtrs(NormalizeDestinationPortRanges(dstPorts)...),
+					Priority:                             ptr.To(int32(500)),
+				},
+			},
+			{
+				Name: ptr.To(denyRuleName),
+				Properties: &armnetwork.SecurityRulePropertiesFormat{
+					Protocol:                             to.Ptr(armnetwork.SecurityRuleProtocolAsterisk),
+					Access:                               to.Ptr(armnetwork.SecurityRuleAccessDeny),
+					Direction:                            to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+					SourceAddressPrefix:                  ptr.To("*"),
+					SourcePortRange:                      ptr.To("*"),
+					DestinationApplicationSecurityGroups: []*armnetwork.ApplicationSecurityGroup{{ID: ptr.To(dstASGID)}},
+					DestinationPortRange:                 ptr.To("*"),
+					Priority:                             ptr.To(int32(4095)),
+				},
+			},
+		})
+
+		// The rules attached to the application security group are not touched when cleaning the destination addresses.
+		helper = ExpectNewSecurityGroupHelper(t, outputSG)
+		for _, p := range []armnetwork.SecurityRuleProtocol{protocol, armnetwork.SecurityRuleProtocolAsterisk} {
+			assert.NoError(t, helper.RemoveDestinationFromRules(p, []string{"10.0.0.1"}, nil))
+		}
+		_, updated, err = helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.False(t, updated)
+
+		// The ports used by the other services are retained.
+		helper = ExpectNewSecurityGroupHelper(t, outputSG)
+		assert.NoError(t, helper.RemoveApplicationSecurityGroupDestinationFromRules(protocol, strings.ToUpper(dstASGID), []int32{443}))
+		splitSG, updated, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Len(t, splitSG.Properties.SecurityRules, 2)
+		splitRuleName := GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, iputil.IPv4, []string{ServiceTagInternet}, nil, dstASGID, []int32{443})
+		assert.Equal(t, splitRuleName, ptr.Deref(splitSG.Properties.SecurityRules[0].Name, ""))
+		assert.Equal(t, []*string{ptr.To("443")}, splitSG.Properties.SecurityRules[0].Properties.DestinationPortRanges)
+
+		// All the rules are removed without the retained ports.
+		helper = ExpectNewSecurityGroupHelper(t, splitSG)
+		for _, p := range []armnetwork.SecurityRuleProtocol{protocol, armnetwork.SecurityRuleProtocolAsterisk} {
+			assert.NoError(t, helper.RemoveApplicationSecurityGroupDestinationFromRules(p, dstASGID, nil))
+		}
+		emptySG, updated, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Empty(t, emptySG.Properties.SecurityRules)
+	})
+}
+
+func TestRuleHelper_NodeInboundNATRules(t *testing.T) {
+	var (
+		fx       = fixture.NewFixture()
+		ruleName = "aservice-nat-Tcp-22-IPv4-Internet"
+	)
+
+	t.Run("it should replace the destination addresses of the rule", func(t *testing.T) {
+		var (
+			sg           = fx.Azure().SecurityGroup().Build()
+			helper       = ExpectNewSecurityGroupHelper(t, sg)
+			dstAddresses = fx.RandomIPv4Addresses(3)
+		)
+		assert.NoError(t, helper.AddRuleForNodeInboundNAT(ruleName, []string{ServiceTagInternet}, nil, dstAddresses, 22))
+		assert.NoError(t, helper.AddRuleForNodeInboundNAT(ruleName, []string{ServiceTagInternet}, nil, dstAddresses[:1], 22))
+
+		outputSG, updated, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		testutil.ExpectExactSecurityRules(t, outputSG, []*armnetwork.SecurityRule{
+			{
+				Name: ptr.To(ruleName),
+				Properties: &armnetwork.SecurityRulePropertiesFormat{
+					Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+					Access:                   to.Ptr(armnetwork.SecurityRuleAccessAllow),
+					Direction:                to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+					SourceAddressPrefix:      ptr.To(ServiceTagInternet),
+					SourcePortRange:          ptr.To("*"),
+					DestinationAddressPrefix: ptr.To(dstAddresses[0].String()),
+					DestinationPortRange:     ptr.To("22"),
+					Priority:                 ptr.To(int32(500)),
+				},
+			},
+		})
+	})
+
+	t.Run("it should remove the rules with the name prefix except the retained ones", func(t *testing.T) {
+		var (
+			rules  = fx.Azure().NoiseSecurityRules()
+			sg     = fx.Azure().SecurityGroup().WithRules(rules).Build()
+			helper = ExpectNewSecurityGroupHelper(t, sg)
+		)
+		assert.NoError(t, helper.AddRuleForNodeInboundNAT(ruleName, []string{ServiceTagInternet}, nil, fx.RandomIPv4Addresses(1), 22))
+		assert.NoError(t, helper.AddRuleForNodeInboundNAT("aservice-nat-Tcp-2222-IPv4-Internet", []string{ServiceTagInternet}, nil, fx.RandomIPv4Addresses(1), 2222))
+		helper.RemoveRulesWithNamePrefix("aservice-nat-", []string{ruleName})
+
+		outputSG, _, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.Equal(t, len(rules)+1, len(outputSG.Properties.SecurityRules))
+		testutil.ExpectHasSecurityRules(t, outputSG, rules)
+
+		helper.RemoveRulesWithNamePrefix("aservice-nat-", nil)
+		outputSG, updated, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.False(t, updated)
+		testutil.ExpectExactSecurityRules(t, outputSG, rules)
+	})
+}
+
+func TestRuleHelper_CompactRulePriorities(t *testing.T) {
+	newRule := func(name string, access armnetwork.SecurityRuleAccess, priority int32) *armnetwork.SecurityRule {
+		return &armnetwork.SecurityRule{
+			Name: ptr.To(name),
+			Properties: &armnetwork.SecurityRulePropertiesFormat{
+				Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+				Access:                   to.Ptr(access),
+				Direction:                to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+				SourceAddressPrefix:      ptr.To("*"),
+				SourcePortRange:          ptr.To("*"),
+				DestinationAddressPrefix: ptr.To("10.0.0.1"),
+				DestinationPortRanges:    to.SliceOfPtrs("80"),
+				Priority:                 ptr.To(priority),
+			},
+		}
+	}
+	priorities := func(sg *armnetwork.SecurityGroup) map[string]int32 {
+		rv := make(map[string]int32)
+		for _, rule := range sg.Properties.SecurityRules {
+			rv[*rule.Name] = *rule.Properties.Priority
+		}
+		return rv
+	}
+
+	t.Run("it should close the gaps and keep the other rules", func(t *testing.T) {
+		sg := &armnetwork.SecurityGroup{
+			Name: ptr.To("nsg"),
+			Properties: &armnetwork.SecurityGroupPropertiesFormat{
+				SecurityRules: []*armnetwork.SecurityRule{
+					newRule("k8s-azure-lb_allow_IPv4_a", armnetwork.SecurityRuleAccessAllow, 500),
+					newRule("user-rule", armnetwork.SecurityRuleAccessAllow, 501),
+					newRule("k8s-azure-lb_allow_IPv4_b", armnetwork.SecurityRuleAccessAllow, 510),
+					newRule("k8s-azure-lb_allow_IPv4_c", armnetwork.SecurityRuleAccessAllow, 520),
+					newRule("k8s-azure-lb_deny-all_IPv4", armnetwork.SecurityRuleAccessDeny, 4090),
+					newRule("user-rule-out-of-range", armnetwork.SecurityRuleAccessAllow, 100),
+				},
+			},
+		}
+		helper := ExpectNewSecurityGroupHelper(t, sg)
+		assert.Equal(t, 3, helper.CompactRulePriorities())
+
+		outputSG, updated, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.Equal(t, map[string]int32{
+			"k8s-azure-lb_allow_IPv4_a":  500,
+			"user-rule":                  501,
+			"k8s-azure-lb_allow_IPv4_b":  502,
+			"k8s-azure-lb_allow_IPv4_c":  503,
+			"k8s-azure-lb_deny-all_IPv4": 4095,
+			"user-rule-out-of-range":     100,
+		}, priorities(outputSG))
+
+		assert.Equal(t, 0, helper.CompactRulePriorities(), "it should be idempotent")
+	})
+
+	t.Run("it should not move the rules across the other rules", func(t *testing.T) {
+		sg := &armnetwork.SecurityGroup{
+			Name: ptr.To("nsg"),
+			Properties: &armnetwork.SecurityGroupPropertiesFormat{
+				SecurityRules: []*armnetwork.SecurityRule{
+					newRule("user-deny-rule", armnetwork.SecurityRuleAccessDeny, 550),
+					newRule("k8s-azure-lb_allow_IPv4_a", armnetwork.SecurityRuleAccessAllow, 600),
+					newRule("k8s-azure-lb_deny-all_IPv4", armnetwork.SecurityRuleAccessDeny, 3000),
+					newRule("user-allow-rule", armnetwork.SecurityRuleAccessAllow, 3500),
+					newRule("k8s-azure-lb_deny-all_IPv6", armnetwork.SecurityRuleAccessDeny, 4096),
+				},
+			},
+		}
+		helper := ExpectNewSecurityGroupHelper(t, sg)
+		assert.Equal(t, 2, helper.CompactRulePriorities())
+
+		outputSG, _, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int32{
+			"user-deny-rule":             550,
+			"k8s-azure-lb_allow_IPv4_a":  551,
+			"k8s-azure-lb_deny-all_IPv4": 3499,
+			"user-allow-rule":            3500,
+			"k8s-azure-lb_deny-all_IPv6": 4096,
+		}, priorities(outputSG), "the managed rules should stay on the same side of the other rules")
+	})
+
+	t.Run("it should compact the priorities if the allow rule would cross the deny rules", func(t *testing.T) {
+		sg := &armnetwork.SecurityGroup{
+			Name: ptr.To("nsg"),
+			Properties: &armnetwork.SecurityGroupPropertiesFormat{
+				SecurityRules: []*armnetwork.SecurityRule{
+					newRule("k8s-azure-lb_allow_IPv4_a", armnetwork.SecurityRuleAccessAllow, 500),
+					newRule("k8s-azure-lb_deny-all_IPv4", armnetwork.SecurityRuleAccessDeny, 501),
+				},
+			},
+		}
+		helper := ExpectNewSecurityGroupHelper(t, sg)
+		assert.NoError(t, helper.AddRuleForAllowedIPRanges(
+			[]netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")},
+			armnetwork.SecurityRuleProtocolTCP,
+			[]netip.Addr{netip.MustParseAddr("10.0.0.2")},
+			[]int32{443},
+		))
+
+		outputSG, _, err := helper.SecurityGroup()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int32{
+			"k8s-azure-lb_allow_IPv4_a": 500,
+			GenerateAllowSecurityRuleName(
+				armnetwork.SecurityRuleProtocolTCP, iputil.IPv4, []string{"10.1.0.0/16"}, []int32{443},
+			): 501,
+			"k8s-azure-lb_deny-all_IPv4": 4095,
+		}, priorities(outputSG))
+	})
+}
+
+func TestCapacity(t *testing.T) {
+	capacity := Capacity{Rules: 900, SourceIPs: 100, DestinationIPs: 3_800}
+	assert.Equal(t, Capacity{Rules: 100, SourceIPs: 3_900, DestinationIPs: 200}, capacity.Headroom())
+	assert.InDelta(t, 0.95, capacity.UsageRatio(), 1e-9)
+}
@@ -37,6 +37,20 @@ func GenerateAllowSecurityRuleName(
 	ipFamily iputil.Family,
 	srcPrefixes []string,
 	dstPorts []int32,
+) string {
+	return GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(protocol, ipFamily, srcPrefixes, nil, "", dstPorts)
+}
+
+// GenerateAllowSecurityRuleNameWithApplicationSecurityGroups returns the AllowInbound rule name based on the given rule properties,
+// including the source application security groups and the destination application security group.
+// It returns the same name as GenerateAllowSecurityRuleName if no application security group is given.
+func GenerateAllowSecurityRuleNameWithApplicationSecurityGroups(
+	protocol armnetwork.SecurityRuleProtocol,
+	ipFamily iputil.Family,
+	srcPrefixes []string,
+	srcASGIDs []string,
+	dstASGID string,
+	dstPorts []int32,
 ) string {
 	var ruleID string
 	{
@@ -45,11 +59,18 @@ func GenerateAllowSecurityRuleName(
 		sort.Strings(srcPrefixes)
 		sort.Strings(dstPortRanges)
 
-		v := strings.Join([]string{
+		parts := []string{
 			string(protocol),
 			strings.Join(srcPrefixes, ","),
 			strings.Join(dstPortRanges, ","),
-		}, "_")
+		}
+		if len(srcASGIDs) > 0 || dstASGID != "" {
+			// The resource IDs are case-insensitive.
+			asgIDs := fnutil.Map(strings.ToLower, srcASGIDs)
+			sort.Strings(asgIDs)
+			parts = append(parts, strings.Join(asgIDs, ","), strings.ToLower(dstASGID))
+		}
+		v := strings.Join(parts, "_")
 
 		h := md5.New() //nolint:gosec
 		h.Write([]byte(v))
@@ -65,6 +86,27 @@ func GenerateDenyAllSecurityRuleName(ipFamily iputil.Family) string {
 	return strings.Join([]string{SecurityRuleNamePrefix, "deny-all", string(ipFamily)}, SecurityRuleNameSep)
 }
 
+// GenerateDenySecurityRuleName returns the DenyInbound rule name of a single destination port.
+func GenerateDenySecurityRuleName(protocol armnetwork.SecurityRuleProtocol, ipFamily iputil.Family, dstPort int32) string {
+	return strings.Join([]string{SecurityRuleNamePrefix, "deny", string(ipFamily), string(protocol), strconv.FormatInt(int64(dstPort), 10)}, SecurityRuleNameSep)
+}
+
+// GenerateDenySecurityRuleNameForApplicationSecurityGroup returns the DenyInbound rule name of a single destination port
+// of the given destination application security group.
+func GenerateDenySecurityRuleNameForApplicationSecurityGroup(protocol armnetwork.SecurityRuleProtocol, dstPort int32, dstASGID string) string {
+	h := md5.New() //nolint:gosec
+	h.Write([]byte(strings.ToLower(dstASGID)))
+	return strings.Join([]string{SecurityRuleNamePrefix, "deny", string(protocol), strconv.FormatInt(int64(dstPort), 10), fmt.Sprintf("%x", h.Sum(nil))}, SecurityRuleNameSep)
+}
+
+// GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup returns the DenyInbound rule name for the given destination application security group.
+// The rule applies to both IP families, so the name does not contain the IP family.
+func GenerateDenyAllSecurityRuleNameForApplicationSecurityGroup(dstASGID string) string {
+	h := md5.New() //nolint:gosec
+	h.Write([]byte(strings.ToLower(dstASGID)))
+	return strings.Join([]string{SecurityRuleNamePrefix, "deny-all", fmt.Sprintf("%x", h.Sum(nil))}, SecurityRuleNameSep)
+}
+
 // NormalizeSecurityRuleAddressPrefixes normalizes the given rule address prefixes.
 func NormalizeSecurityRuleAddressPrefixes(vs []string) []string {
 	// Remove redundant addresses.
@@ -120,6 +162,32 @@ func SetDestinationPrefixes(r *armnetwork.SecurityRule, prefixes []string) {
 	}
 }
 
+func ListSourceApplicationSecurityGroupIDs(r *armnetwork.SecurityRule) []string {
+	return listApplicationSecurityGroupIDs(r.Properties.SourceApplicationSecurityGroups)
+}
+
+func ListDestinationApplicationSecurityGroupIDs(r *armnetwork.SecurityRule) []string {
+	return listApplicationSecurityGroupIDs(r.Properties.DestinationApplicationSecurityGroups)
+}
+
+func listApplicationSecurityGroupIDs(asgs []*armnetwork.ApplicationSecurityGroup) []string {
+	var rv []string
+	for _, asg := range asgs {
+		if asg != nil && asg.ID != nil {
+			rv = append(rv, *asg.ID)
+		}
+	}
+	return rv
+}
+
+// NewApplicationSecurityGroups returns the application security group references of the given resource IDs.
+func NewApplicationSecurityGroups(ids []string) []*armnetwork.ApplicationSecurityGroup {
+	ids = NormalizeSecurityRuleAddressPrefixes(ids)
+	return fnutil.Map(func(id string) *armnetwork.ApplicationSecurityGroup {
+		return &armnetwork.ApplicationSecurityGroup{ID: to.Ptr(id)}
+	}, ids)
+}
+
 func ListDestinationPortRanges(r *armnetwork.SecurityRule) ([]int32, error) {
 	var values []*string
 	if r.Properties.DestinationPortRange != nil {
@@ -86,3 +86,18 @@ func (mr *MockRepositoryMockRecorder) Get(ctx, rg, vnetName, subnetName any) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), ctx, rg, vnetName, subnetName)
 }
+
+// GetWithIPConfigurations mocks base method.
+func (m *MockRepository) GetWithIPConfigurations(ctx context.Context, rg, vnetName, subnetName string) (*armnetwork.Subnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithIPConfigurations", ctx, rg, vnetName, subnetName)
+	ret0, _ := ret[0].(*armnetwork.Subnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithIPConfigurations indicates an expected call of GetWithIPConfigurations.
+func (mr *MockRepositoryMockRecorder) GetWithIPConfigurations(ctx, rg, vnetName, subnetName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithIPConfigurations", reflect.TypeOf((*MockRepository)(nil).GetWithIPConfigurations), ctx, rg, vnetName, subnetName)
+}
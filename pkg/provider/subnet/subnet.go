@@ -21,6 +21,7 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v6"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/subnetclient"
 )
@@ -28,6 +29,7 @@ import (
 type Repository interface {
 	CreateOrUpdate(ctx context.Context, rg string, vnetName string, subnetName string, subnet armnetwork.Subnet) error
 	Get(ctx context.Context, rg string, vnetName string, subnetName string) (*armnetwork.Subnet, error)
+	GetWithIPConfigurations(ctx context.Context, rg string, vnetName string, subnetName string) (*armnetwork.Subnet, error)
 }
 
 type repo struct {
@@ -60,3 +62,14 @@ func (az *repo) Get(ctx context.Context, rg string, vnetName string, subnetName
 	}
 	return subnet, nil
 }
+
+// GetWithIPConfigurations gets the subnet with the properties of its IP configurations expanded,
+// so that the private IPs of the NICs and frontends in the subnet are available.
+func (az *repo) GetWithIPConfigurations(ctx context.Context, rg string, vnetName string, subnetName string) (*armnetwork.Subnet, error) {
+	subnet, err := az.SubnetsClient.Get(ctx, rg, vnetName, subnetName, ptr.To("ipConfigurations"))
+	if err != nil {
+		klog.Errorf("SubnetClient.Get(%s) with ipConfigurations failed: %s", subnetName, err.Error())
+		return nil, err
+	}
+	return subnet, nil
+}
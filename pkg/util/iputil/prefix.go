@@ -47,6 +47,43 @@ func ParsePrefix(v string) (netip.Prefix, error) {
 	return prefix, nil
 }
 
+// lastAddrOfPrefix returns the last address in the prefix.
+func lastAddrOfPrefix(p netip.Prefix) netip.Addr {
+	bytes := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(bytes)*8; i++ {
+		setBitAt(bytes, i, 1)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// RangeToPrefixes returns the minimal list of prefixes covering the address range [start, end].
+// For example, 10.0.0.1-10.0.0.6 returns [10.0.0.1/32, 10.0.0.2/31, 10.0.0.4/31, 10.0.0.6/32].
+func RangeToPrefixes(start, end netip.Addr) ([]netip.Prefix, error) {
+	if !start.IsValid() || !end.IsValid() || start.Is4() != end.Is4() {
+		return nil, fmt.Errorf("invalid IP range %s-%s: addresses should be from the same IP family", start, end)
+	}
+	if start.Compare(end) > 0 {
+		return nil, fmt.Errorf("invalid IP range %s-%s: start should not be greater than end", start, end)
+	}
+
+	var rv []netip.Prefix
+	for start.IsValid() && start.Compare(end) <= 0 {
+		// Find the largest prefix beginning at start which does not exceed end.
+		best := netip.PrefixFrom(start, start.BitLen())
+		for bits := start.BitLen() - 1; bits >= 0; bits-- {
+			p := netip.PrefixFrom(start, bits)
+			if p.Masked().Addr() != start || lastAddrOfPrefix(p).Compare(end) > 0 {
+				break
+			}
+			best = p
+		}
+		rv = append(rv, best)
+		start = lastAddrOfPrefix(best).Next()
+	}
+	return rv, nil
+}
+
 // GroupPrefixesByFamily groups prefixes by IP family.
 func GroupPrefixesByFamily(vs []netip.Prefix) ([]netip.Prefix, []netip.Prefix) {
 	var (
@@ -119,6 +119,74 @@ func TestParsePrefix(t *testing.T) {
 	})
 }
 
+func TestRangeToPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "single address",
+			start:    "10.0.0.1",
+			end:      "10.0.0.1",
+			expected: []string{"10.0.0.1/32"},
+		},
+		{
+			name:     "aligned ipv4 range",
+			start:    "10.0.0.0",
+			end:      "10.0.0.255",
+			expected: []string{"10.0.0.0/24"},
+		},
+		{
+			name:     "unaligned ipv4 range",
+			start:    "10.0.0.1",
+			end:      "10.0.0.6",
+			expected: []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"},
+		},
+		{
+			name:     "whole ipv4 space",
+			start:    "0.0.0.0",
+			end:      "255.255.255.255",
+			expected: []string{"0.0.0.0/0"},
+		},
+		{
+			name:     "ipv6 range",
+			start:    "2001:db8::",
+			end:      "2001:db8::2",
+			expected: []string{"2001:db8::/127", "2001:db8::2/128"},
+		},
+		{
+			name:    "start greater than end",
+			start:   "10.0.0.2",
+			end:     "10.0.0.1",
+			wantErr: true,
+		},
+		{
+			name:    "different ip families",
+			start:   "10.0.0.1",
+			end:     "2001:db8::1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := RangeToPrefixes(netip.MustParseAddr(tt.start), netip.MustParseAddr(tt.end))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			var expected []netip.Prefix
+			for _, p := range tt.expected {
+				expected = append(expected, netip.MustParsePrefix(p))
+			}
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
 func TestGroupPrefixesByFamily(t *testing.T) {
 	tests := []struct {
 		Name  string
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook implements the admission webhooks of the Azure cloud provider.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ServiceValidationPath is the path served by the service validating webhook.
+	ServiceValidationPath = "/validate-service"
+
+	// maxRequestBodyBytes limits the size of the admission review, which is far below the etcd object limit.
+	maxRequestBodyBytes = 3 * 1024 * 1024
+)
+
+// ServiceValidator returns the error if the service is invalid.
+type ServiceValidator func(service *v1.Service) error
+
+// NewServiceValidationHandler returns the handler of the validating admission webhook of services.
+// The created and updated services are denied if the validator returns an error. The validator should join
+// the errors of each annotation with errors.Join, so that the errors existing before an update can be told apart.
+func NewServiceValidationHandler(validate ServiceValidator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, fmt.Sprintf("method %s is not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodyBytes))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read the request body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		review := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode the admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "the admission review has no request", http.StatusBadRequest)
+			return
+		}
+
+		review.Response = reviewService(review.Request, validate)
+		review.Response.UID = review.Request.UID
+		review.Request = nil
+		review.APIVersion = admissionv1.SchemeGroupVersion.String()
+		review.Kind = "AdmissionReview"
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			klog.Errorf("NewServiceValidationHandler: failed to write the admission review: %v", err)
+		}
+	})
+}
+
+// reviewService denies the created or updated service if the validator returns an error. For updates, only the errors
+// introduced by the update are denied, so that the services with invalid annotations created before the webhook is
+// deployed can still be updated, e.g. to remove the finalizers or to fix the other annotations.
+func reviewService(request *admissionv1.AdmissionRequest, validate ServiceValidator) *admissionv1.AdmissionResponse {
+	if request.Resource.Resource != "services" || request.SubResource != "" ||
+		(request.Operation != admissionv1.Create && request.Operation != admissionv1.Update) {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	service := &v1.Service{}
+	if err := json.Unmarshal(request.Object.Raw, service); err != nil {
+		return badRequestResponse(fmt.Errorf("failed to decode the service: %w", err))
+	}
+	if service.DeletionTimestamp != nil {
+		// The service is being deleted, and the update is to remove the finalizers.
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	var oldService *v1.Service
+	if request.Operation == admissionv1.Update {
+		oldService = &v1.Service{}
+		if err := json.Unmarshal(request.OldObject.Raw, oldService); err != nil {
+			return badRequestResponse(fmt.Errorf("failed to decode the old service: %w", err))
+		}
+		if equality.Semantic.DeepEqual(oldService.Annotations, service.Annotations) &&
+			equality.Semantic.DeepEqual(oldService.Spec, service.Spec) {
+			// Neither the annotations nor the spec is changed, e.g. updating the finalizers or labels.
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+	}
+
+	errs := validationErrors(validate(service))
+	if oldService != nil && len(errs) > 0 {
+		existingErrs := sets.New(validationErrors(validate(oldService))...)
+		errs = slices.DeleteFunc(errs, existingErrs.Has)
+	}
+	if len(errs) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+
+	klog.V(2).Infof("reviewService: denied service %s/%s: %v", request.Namespace, request.Name, errs)
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusUnprocessableEntity,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: strings.Join(errs, "\n"),
+		},
+	}
+}
+
+// validationErrors returns the messages of the errors joined by errors.Join, or the message of the error itself.
+func validationErrors(err error) []string {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var rv []string
+		for _, e := range joined.Unwrap() {
+			rv = append(rv, e.Error())
+		}
+		return rv
+	}
+	return []string{err.Error()}
+}
+
+func badRequestResponse(err error) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusBadRequest,
+			Reason:  metav1.StatusReasonBadRequest,
+			Message: err.Error(),
+		},
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestServiceValidationHandler(t *testing.T) {
+	validate := func(service *v1.Service) error {
+		var errs []error
+		for _, key := range []string{"invalid", "another-invalid"} {
+			if service.Annotations[key] != "" {
+				errs = append(errs, fmt.Errorf("invalid annotation %s", key))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	newService := func(annotations map[string]string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Annotations: annotations}}
+	}
+	newReview := func(operation admissionv1.Operation, service, oldService *v1.Service) *admissionv1.AdmissionReview {
+		review := &admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:       types.UID("uid"),
+				Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "services"},
+				Operation: operation,
+			},
+		}
+		review.Request.Object.Raw, _ = json.Marshal(service)
+		if oldService != nil {
+			review.Request.OldObject.Raw, _ = json.Marshal(oldService)
+		}
+		return review
+	}
+	invalid := map[string]string{"invalid": "true"}
+
+	for _, tc := range []struct {
+		desc            string
+		review          *admissionv1.AdmissionReview
+		expectedAllowed bool
+		expectedMessage string
+	}{
+		{
+			desc:            "valid service should be allowed",
+			review:          newReview(admissionv1.Create, newService(nil), nil),
+			expectedAllowed: true,
+		},
+		{
+			desc:            "invalid service should be denied",
+			review:          newReview(admissionv1.Create, newService(invalid), nil),
+			expectedMessage: "invalid annotation invalid",
+		},
+		{
+			desc:            "update introducing invalid annotations should be denied",
+			review:          newReview(admissionv1.Update, newService(invalid), newService(nil)),
+			expectedMessage: "invalid annotation invalid",
+		},
+		{
+			desc:            "update keeping the existing invalid annotations should be allowed",
+			review:          newReview(admissionv1.Update, newService(map[string]string{"invalid": "true", "foo": "bar"}), newService(invalid)),
+			expectedAllowed: true,
+		},
+		{
+			desc: "update should only be denied by the newly introduced invalid annotations",
+			review: newReview(admissionv1.Update,
+				newService(map[string]string{"invalid": "true", "another-invalid": "true"}), newService(invalid)),
+			expectedMessage: "invalid annotation another-invalid",
+		},
+		{
+			desc: "updating the finalizers should be allowed",
+			review: func() *admissionv1.AdmissionReview {
+				service := newService(invalid)
+				service.Finalizers = []string{"service.kubernetes.io/load-balancer-cleanup"}
+				return newReview(admissionv1.Update, service, newService(invalid))
+			}(),
+			expectedAllowed: true,
+		},
+		{
+			desc: "updating the service being deleted should be allowed",
+			review: func() *admissionv1.AdmissionReview {
+				service := newService(invalid)
+				now := metav1.Now()
+				service.DeletionTimestamp = &now
+				return newReview(admissionv1.Update, service, newService(nil))
+			}(),
+			expectedAllowed: true,
+		},
+		{
+			desc: "updating the status should be allowed",
+			review: func() *admissionv1.AdmissionReview {
+				review := newReview(admissionv1.Update, newService(invalid), newService(nil))
+				review.Request.SubResource = "status"
+				return review
+			}(),
+			expectedAllowed: true,
+		},
+		{
+			desc:            "deleting should always be allowed",
+			review:          newReview(admissionv1.Delete, newService(invalid), nil),
+			expectedAllowed: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			body, _ := json.Marshal(tc.review)
+			recorder := httptest.NewRecorder()
+			NewServiceValidationHandler(validate).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, ServiceValidationPath, bytes.NewReader(body)))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+
+			response := &admissionv1.AdmissionReview{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			assert.Equal(t, "AdmissionReview", response.Kind)
+			assert.Equal(t, types.UID("uid"), response.Response.UID)
+			assert.Equal(t, tc.expectedAllowed, response.Response.Allowed)
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, response.Response.Result.Message)
+			}
+		})
+	}
+
+	t.Run("malformed request should be rejected", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		NewServiceValidationHandler(validate).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, ServiceValidationPath, bytes.NewReader([]byte("{}"))))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}